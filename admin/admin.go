@@ -0,0 +1,325 @@
+// Package admin implements the operational tasks of the admin command on top
+// of db.Store and the Casbin enforcer, so ops no longer need raw SQL.
+package admin
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/LamThanhNguyen/banking-system/worker"
+	"github.com/casbin/casbin/v2"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	ErrReasonRequired = errors.New("a reason is required")
+	ErrZeroAmount     = errors.New("amount must not be zero")
+	ErrUnknownRole    = errors.New("unknown role")
+)
+
+// Admin runs operational tasks on behalf of an operator. Every change is
+// logged with the operator as actor.
+type Admin struct {
+	store    db.Store
+	enforcer *casbin.Enforcer
+	actor    string
+}
+
+func New(store db.Store, enforcer *casbin.Enforcer, actor string) *Admin {
+	return &Admin{
+		store:    store,
+		enforcer: enforcer,
+		actor:    actor,
+	}
+}
+
+// User is a user without its password hash.
+type User struct {
+	Username        string    `json:"username"`
+	FullName        string    `json:"full_name"`
+	Email           string    `json:"email"`
+	Role            string    `json:"role"`
+	TenantID        string    `json:"tenant_id"`
+	IsEmailVerified bool      `json:"is_email_verified"`
+	IsDisabled      bool      `json:"is_disabled"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+func newUser(user db.User) User {
+	return User{
+		Username:        user.Username,
+		FullName:        user.FullName,
+		Email:           user.Email,
+		Role:            user.Role,
+		TenantID:        user.TenantID,
+		IsEmailVerified: user.IsEmailVerified,
+		IsDisabled:      user.IsDisabled,
+		CreatedAt:       user.CreatedAt,
+	}
+}
+
+type CreateUserParams struct {
+	Username string
+	FullName string
+	Email    string
+	Password string
+	Role     string
+	TenantID string
+	// EmailVerified skips email verification; otherwise a verification email is sent.
+	EmailVerified bool
+}
+
+// CreateUser creates a user with the given role.
+func (admin *Admin) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	if !util.IsSupportedRole(arg.Role) {
+		return User{}, fmt.Errorf("%w %q", ErrUnknownRole, arg.Role)
+	}
+	if arg.TenantID == "" {
+		arg.TenantID = util.DefaultTenant
+	}
+
+	hashedPassword, err := util.HashPassword(arg.Password)
+	if err != nil {
+		return User{}, err
+	}
+
+	audit := db.AppendAuditEventTxParams{
+		TenantID:   arg.TenantID,
+		Action:     db.AuditActionUserCreate,
+		TargetType: db.AuditTargetUser,
+		TargetID:   arg.Username,
+	}
+
+	var user User
+	_, err = admin.store.CreateUserTx(ctx, db.CreateUserTxParams{
+		CreateUserParams: db.CreateUserParams{
+			Username:       arg.Username,
+			HashedPassword: hashedPassword,
+			FullName:       arg.FullName,
+			Email:          arg.Email,
+			TenantID:       arg.TenantID,
+		},
+		AfterCreate: func(q db.Querier, created db.User) error {
+			_, err := q.UpdateUser(ctx, db.UpdateUserParams{
+				Username:        created.Username,
+				Role:            pgtype.Text{String: arg.Role, Valid: true},
+				IsEmailVerified: pgtype.Bool{Bool: arg.EmailVerified, Valid: true},
+			})
+			if err != nil {
+				return err
+			}
+			if !arg.EmailVerified {
+				if err := distributeVerifyEmail(ctx, q, created); err != nil {
+					return err
+				}
+			}
+
+			// The created user predates the role update.
+			user = newUser(created)
+			user.Role = arg.Role
+			user.IsEmailVerified = arg.EmailVerified
+
+			event := audit
+			event.After = map[string]any{
+				"full_name":         user.FullName,
+				"email":             user.Email,
+				"role":              user.Role,
+				"is_email_verified": user.IsEmailVerified,
+			}
+			return admin.appendAudit(ctx, q, event)
+		},
+	})
+	if err != nil {
+		admin.recordAudit(ctx, audit, err)
+		return User{}, err
+	}
+
+	log.Info().Str("actor", admin.actor).Str("username", user.Username).Str("role", user.Role).Msg("admin created user")
+	return user, nil
+}
+
+// ResetPassword sets a new password and blocks the user's sessions. An empty
+// password is replaced by a random one, which is returned.
+func (admin *Admin) ResetPassword(ctx context.Context, username string, password string) (User, string, error) {
+	if password == "" {
+		var err error
+		password, err = randomPassword()
+		if err != nil {
+			return User{}, "", err
+		}
+	}
+
+	hashedPassword, err := util.HashPassword(password)
+	if err != nil {
+		return User{}, "", err
+	}
+
+	audit := db.AppendAuditEventTxParams{
+		Action:     db.AuditActionPasswordReset,
+		TargetType: db.AuditTargetUser,
+		TargetID:   username,
+	}
+	txResult, err := admin.store.ResetPasswordTx(ctx, db.ResetPasswordTxParams{
+		Username:       username,
+		HashedPassword: hashedPassword,
+		AfterReset: func(q db.Querier, result db.ResetPasswordTxResult) error {
+			event := audit
+			event.TenantID = result.User.TenantID
+			event.After = map[string]any{"password_changed_at": result.User.PasswordChangedAt}
+			return admin.appendAudit(ctx, q, event)
+		},
+	})
+	if err != nil {
+		admin.recordAudit(ctx, audit, err)
+		return User{}, "", err
+	}
+
+	log.Info().Str("actor", admin.actor).Str("username", username).Msg("admin reset password")
+	return newUser(txResult.User), password, nil
+}
+
+// BlockSessions blocks every session of a user, forcing a new login once the
+// current access tokens expire.
+func (admin *Admin) BlockSessions(ctx context.Context, username string) (User, error) {
+	audit := db.AppendAuditEventTxParams{
+		Action:     db.AuditActionSessionsBlock,
+		TargetType: db.AuditTargetUser,
+		TargetID:   username,
+	}
+	result, err := admin.store.BlockUserSessionsTx(ctx, db.BlockUserSessionsTxParams{
+		Username: username,
+		AfterBlock: func(q db.Querier, user db.User) error {
+			event := audit
+			event.TenantID = user.TenantID
+			return admin.appendAudit(ctx, q, event)
+		},
+	})
+	if err != nil {
+		admin.recordAudit(ctx, audit, err)
+		return User{}, err
+	}
+
+	log.Info().Str("actor", admin.actor).Str("username", username).Msg("admin blocked sessions")
+	return newUser(result.User), nil
+}
+
+// ChangeAccountStatus freezes, unfreezes or closes an account. The reason
+// must be one of the codes allowed for the change, see db.AccountReasons; the
+// optional note is only kept in the audit log. Transfers from and to a frozen
+// or closed account are rejected.
+func (admin *Admin) ChangeAccountStatus(ctx context.Context, accountID int64, status string, reason string, note string) (db.Account, error) {
+	if reason == "" {
+		return db.Account{}, ErrReasonRequired
+	}
+
+	audit := db.AppendAuditEventTxParams{
+		Action:     accountStatusAuditAction(status),
+		TargetType: db.AuditTargetAccount,
+		TargetID:   strconv.FormatInt(accountID, 10),
+	}
+	result, err := admin.store.ChangeAccountStatusTx(ctx, db.ChangeAccountStatusTxParams{
+		AccountID: accountID,
+		Status:    status,
+		Reason:    reason,
+		AfterChange: func(q db.Querier, result db.ChangeAccountStatusTxResult) error {
+			event := audit
+			event.TenantID = result.Account.TenantID
+			event.Before = map[string]any{"status": result.Before.Status, "reason": result.Before.StatusReason}
+			event.After = map[string]any{"status": result.Account.Status, "reason": result.Account.StatusReason, "note": note}
+			return admin.appendAudit(ctx, q, event)
+		},
+	})
+	if err != nil {
+		// The tenant of an account that was not changed is unknown.
+		admin.recordAudit(ctx, audit, err)
+		return db.Account{}, err
+	}
+
+	log.Info().Str("actor", admin.actor).Int64("account_id", accountID).
+		Str("status", status).Str("reason", reason).Str("note", note).Msg("admin changed account status")
+	return result.Account, nil
+}
+
+func accountStatusAuditAction(status string) string {
+	switch status {
+	case db.AccountStatusFrozen:
+		return db.AuditActionAccountFreeze
+	case db.AccountStatusClosed:
+		return db.AuditActionAccountClose
+	}
+	return db.AuditActionAccountUnfreeze
+}
+
+// AdjustBalance posts a manual credit (positive amount) or debit (negative
+// amount) to an account. The reason and actor are stored with the adjustment.
+func (admin *Admin) AdjustBalance(ctx context.Context, accountID int64, amount int64, reason string) (db.AdjustBalanceTxResult, error) {
+	if amount == 0 {
+		return db.AdjustBalanceTxResult{}, ErrZeroAmount
+	}
+	if reason == "" {
+		return db.AdjustBalanceTxResult{}, ErrReasonRequired
+	}
+
+	audit := db.AppendAuditEventTxParams{
+		Action:     db.AuditActionBalanceAdjust,
+		TargetType: db.AuditTargetAccount,
+		TargetID:   strconv.FormatInt(accountID, 10),
+		After:      map[string]any{"amount": amount, "reason": reason},
+	}
+	result, err := admin.store.AdjustBalanceTx(ctx, db.AdjustBalanceTxParams{
+		AccountID: accountID,
+		Amount:    amount,
+		Reason:    reason,
+		CreatedBy: admin.actor,
+		AfterAdjust: func(q db.Querier, result db.AdjustBalanceTxResult) error {
+			event := audit
+			event.TenantID = result.Account.TenantID
+			event.Before = map[string]any{"balance": result.Account.Balance - amount}
+			event.After = map[string]any{
+				"balance":       result.Account.Balance,
+				"amount":        amount,
+				"adjustment_id": result.Adjustment.ID,
+				"reason":        reason,
+			}
+			return admin.appendAudit(ctx, q, event)
+		},
+	})
+	if err != nil {
+		admin.recordAudit(ctx, audit, err)
+		return db.AdjustBalanceTxResult{}, err
+	}
+
+	log.Info().Str("actor", admin.actor).Int64("account_id", accountID).Int64("amount", amount).
+		Int64("adjustment_id", result.Adjustment.ID).Str("reason", reason).Msg("admin adjusted balance")
+	return result, nil
+}
+
+// distributeVerifyEmail schedules the verification email through the outbox of the running transaction.
+func distributeVerifyEmail(ctx context.Context, q db.Querier, user db.User) error {
+	taskPayload := &worker.PayloadSendVerifyEmail{
+		Username: user.Username,
+	}
+	opts := []asynq.Option{
+		asynq.MaxRetry(10),
+		asynq.Queue(worker.QueueCritical),
+	}
+
+	return worker.NewOutboxTaskDistributor(q).DistributeTaskSendVerifyEmail(ctx, taskPayload, opts...)
+}
+
+func randomPassword() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate password: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,481 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	mockdb "github.com/LamThanhNguyen/banking-system/db/mock"
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/LamThanhNguyen/banking-system/worker"
+	"github.com/casbin/casbin/v2"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+const testActor = "ops"
+
+// expectAppendedAuditEvent expects the success of action to be appended to the
+// audit chain of tenantID within the transaction of the change.
+func expectAppendedAuditEvent(t *testing.T, store *mockdb.MockStore, action string, tenantID string) *gomock.Call {
+	store.EXPECT().
+		LockAuditEvents(gomock.Any(), gomock.Eq(tenantID)).
+		Times(1)
+	store.EXPECT().
+		GetLastAuditEvent(gomock.Any(), gomock.Eq(tenantID)).
+		Times(1).
+		Return(db.AuditEvent{}, db.ErrRecordNotFound)
+	return store.EXPECT().
+		CreateAuditEvent(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateAuditEventParams) (db.AuditEvent, error) {
+			require.Equal(t, action, arg.Action)
+			require.Equal(t, testActor, arg.Actor)
+			require.Equal(t, auditActorRole, arg.ActorRole)
+			require.Equal(t, db.AuditOutcomeSuccess, arg.Outcome)
+			return db.AuditEvent{ID: 1, TenantID: arg.TenantID, Action: arg.Action}, nil
+		})
+}
+
+// auditJSON decodes the before or after of an audit event.
+func auditJSON(t *testing.T, data []byte) map[string]any {
+	var value map[string]any
+	require.NoError(t, json.Unmarshal(data, &value))
+	return value
+}
+
+func TestCreateUser(t *testing.T) {
+	arg := CreateUserParams{
+		Username: util.RandomOwner(),
+		FullName: util.RandomOwner(),
+		Email:    util.RandomEmail(),
+		Password: util.RandomString(10),
+		Role:     util.BankerRole,
+	}
+
+	testCases := []struct {
+		name       string
+		arg        func() CreateUserParams
+		buildStubs func(store *mockdb.MockStore)
+		check      func(t *testing.T, user User, err error)
+	}{
+		{
+			name: "OK",
+			arg:  func() CreateUserParams { return arg },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, txArg db.CreateUserTxParams) (db.CreateUserTxResult, error) {
+						require.Equal(t, arg.Username, txArg.Username)
+						require.Equal(t, util.DefaultTenant, txArg.TenantID)
+						require.NoError(t, util.CheckPassword(arg.Password, txArg.HashedPassword))
+
+						user := db.User{Username: txArg.Username, Role: util.DepositorRole, TenantID: txArg.TenantID}
+						return db.CreateUserTxResult{User: user}, txArg.AfterCreate(store, user)
+					})
+				store.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, updateArg db.UpdateUserParams) (db.User, error) {
+						require.Equal(t, util.BankerRole, updateArg.Role.String)
+						require.False(t, updateArg.IsEmailVerified.Bool)
+						return db.User{}, nil
+					})
+				store.EXPECT().
+					CreateOutbox(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, outbox db.CreateOutboxParams) (db.Outbox, error) {
+						require.Equal(t, worker.TaskSendVerifyEmail, outbox.TaskType)
+						return db.Outbox{}, nil
+					})
+				expectAppendedAuditEvent(t, store, db.AuditActionUserCreate, util.DefaultTenant).
+					Do(func(_ context.Context, event db.CreateAuditEventParams) {
+						require.Equal(t, arg.Username, event.TargetID)
+						require.Equal(t, util.BankerRole, auditJSON(t, event.After)["role"])
+					})
+			},
+			check: func(t *testing.T, user User, err error) {
+				require.NoError(t, err)
+				require.Equal(t, arg.Username, user.Username)
+				require.Equal(t, util.BankerRole, user.Role)
+			},
+		},
+		{
+			name: "EmailVerified",
+			arg: func() CreateUserParams {
+				verified := arg
+				verified.EmailVerified = true
+				return verified
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, txArg db.CreateUserTxParams) (db.CreateUserTxResult, error) {
+						user := db.User{Username: txArg.Username, TenantID: txArg.TenantID}
+						return db.CreateUserTxResult{User: user}, txArg.AfterCreate(store, user)
+					})
+				store.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, nil)
+				store.EXPECT().
+					CreateOutbox(gomock.Any(), gomock.Any()).
+					Times(0)
+				expectAppendedAuditEvent(t, store, db.AuditActionUserCreate, util.DefaultTenant)
+			},
+			check: func(t *testing.T, user User, err error) {
+				require.NoError(t, err)
+				require.True(t, user.IsEmailVerified)
+			},
+		},
+		{
+			name: "UnknownRole",
+			arg: func() CreateUserParams {
+				unknown := arg
+				unknown.Role = "auditor"
+				return unknown
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateUserTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			check: func(t *testing.T, user User, err error) {
+				require.ErrorIs(t, err, ErrUnknownRole)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			user, err := New(store, nil, testActor).CreateUser(context.Background(), tc.arg())
+			tc.check(t, user, err)
+		})
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	username := util.RandomOwner()
+	var hashedPassword string
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ResetPasswordTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.ResetPasswordTxParams) (db.ResetPasswordTxResult, error) {
+			require.Equal(t, username, arg.Username)
+			hashedPassword = arg.HashedPassword
+			result := db.ResetPasswordTxResult{User: db.User{Username: username, TenantID: util.DefaultTenant}}
+			return result, arg.AfterReset(store, result)
+		})
+	expectAppendedAuditEvent(t, store, db.AuditActionPasswordReset, util.DefaultTenant).
+		Do(func(_ context.Context, event db.CreateAuditEventParams) {
+			require.Equal(t, username, event.TargetID)
+			require.NotContains(t, auditJSON(t, event.After), "password")
+		})
+
+	user, password, err := New(store, nil, testActor).ResetPassword(context.Background(), username, "")
+	require.NoError(t, err)
+	require.Equal(t, username, user.Username)
+	require.NotEmpty(t, password)
+	require.NoError(t, util.CheckPassword(password, hashedPassword))
+}
+
+func TestChangeAccountStatus(t *testing.T) {
+	accountID := util.RandomInt(1, 1000)
+	active := db.Account{ID: accountID, TenantID: util.DefaultTenant, Status: db.AccountStatusActive}
+	frozen := active
+	frozen.Status = db.AccountStatusFrozen
+	frozen.StatusReason = db.AccountReasonLegalOrder
+
+	testCases := []struct {
+		name       string
+		status     string
+		reason     string
+		buildStubs func(store *mockdb.MockStore)
+		check      func(t *testing.T, account db.Account, err error)
+	}{
+		{
+			name:   "Freeze",
+			status: db.AccountStatusFrozen,
+			reason: db.AccountReasonLegalOrder,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ChangeAccountStatusTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.ChangeAccountStatusTxParams) (db.ChangeAccountStatusTxResult, error) {
+						require.Equal(t, accountID, arg.AccountID)
+						require.Equal(t, db.AccountStatusFrozen, arg.Status)
+						require.Equal(t, db.AccountReasonLegalOrder, arg.Reason)
+
+						result := db.ChangeAccountStatusTxResult{Account: frozen, Before: active}
+						return result, arg.AfterChange(store, result)
+					})
+				expectAppendedAuditEvent(t, store, db.AuditActionAccountFreeze, util.DefaultTenant).
+					Do(func(_ context.Context, event db.CreateAuditEventParams) {
+						require.Equal(t, db.AccountStatusActive, auditJSON(t, event.Before)["status"])
+						require.Equal(t, "case 1234", auditJSON(t, event.After)["note"])
+					})
+			},
+			check: func(t *testing.T, account db.Account, err error) {
+				require.NoError(t, err)
+				require.Equal(t, frozen, account)
+			},
+		},
+		{
+			name:   "NoReason",
+			status: db.AccountStatusFrozen,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ChangeAccountStatusTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			check: func(t *testing.T, account db.Account, err error) {
+				require.ErrorIs(t, err, ErrReasonRequired)
+			},
+		},
+		{
+			name:   "CloseNotEmpty",
+			status: db.AccountStatusClosed,
+			reason: db.AccountReasonCustomerRequest,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ChangeAccountStatusTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ChangeAccountStatusTxResult{}, db.ErrAccountNotEmpty)
+				store.EXPECT().
+					AppendAuditEventTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, event db.AppendAuditEventTxParams) (db.AuditEvent, error) {
+						require.Equal(t, db.AuditActionAccountClose, event.Action)
+						require.Equal(t, db.AuditOutcomeFailure, event.Outcome)
+						require.Equal(t, db.ErrAccountNotEmpty.Error(), event.Reason)
+						return db.AuditEvent{}, nil
+					})
+			},
+			check: func(t *testing.T, account db.Account, err error) {
+				require.ErrorIs(t, err, db.ErrAccountNotEmpty)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			account, err := New(store, nil, testActor).ChangeAccountStatus(context.Background(), accountID, tc.status, tc.reason, "case 1234")
+			tc.check(t, account, err)
+		})
+	}
+}
+
+func TestAdjustBalance(t *testing.T) {
+	accountID := util.RandomInt(1, 1000)
+
+	testCases := []struct {
+		name       string
+		amount     int64
+		reason     string
+		buildStubs func(store *mockdb.MockStore)
+		check      func(t *testing.T, result db.AdjustBalanceTxResult, err error)
+	}{
+		{
+			name:   "OK",
+			amount: -50,
+			reason: "chargeback #42",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdjustBalanceTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.AdjustBalanceTxParams) (db.AdjustBalanceTxResult, error) {
+						require.Equal(t, accountID, arg.AccountID)
+						require.Equal(t, int64(-50), arg.Amount)
+						require.Equal(t, "chargeback #42", arg.Reason)
+						require.Equal(t, testActor, arg.CreatedBy)
+
+						result := db.AdjustBalanceTxResult{
+							Adjustment: db.Adjustment{ID: 1, AccountID: accountID, Amount: -50},
+							Account:    db.Account{ID: accountID, Balance: 950, TenantID: util.DefaultTenant},
+						}
+						return result, arg.AfterAdjust(store, result)
+					})
+				expectAppendedAuditEvent(t, store, db.AuditActionBalanceAdjust, util.DefaultTenant).
+					Do(func(_ context.Context, event db.CreateAuditEventParams) {
+						require.JSONEq(t, `{"balance": 1000}`, string(event.Before))
+						require.EqualValues(t, 950, auditJSON(t, event.After)["balance"])
+					})
+			},
+			check: func(t *testing.T, result db.AdjustBalanceTxResult, err error) {
+				require.NoError(t, err)
+				require.Equal(t, int64(-50), result.Adjustment.Amount)
+			},
+		},
+		{
+			name:   "NoReason",
+			amount: 10,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdjustBalanceTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			check: func(t *testing.T, result db.AdjustBalanceTxResult, err error) {
+				require.ErrorIs(t, err, ErrReasonRequired)
+			},
+		},
+		{
+			name:   "ZeroAmount",
+			reason: "typo",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdjustBalanceTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			check: func(t *testing.T, result db.AdjustBalanceTxResult, err error) {
+				require.ErrorIs(t, err, ErrZeroAmount)
+			},
+		},
+		{
+			name:   "NegativeBalance",
+			amount: -1000,
+			reason: "chargeback #43",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdjustBalanceTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AdjustBalanceTxResult{}, db.ErrNegativeBalance)
+				store.EXPECT().
+					AppendAuditEventTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, event db.AppendAuditEventTxParams) (db.AuditEvent, error) {
+						require.Equal(t, db.AuditOutcomeFailure, event.Outcome)
+						require.Equal(t, db.ErrNegativeBalance.Error(), event.Reason)
+						require.Nil(t, event.Before)
+						return db.AuditEvent{}, nil
+					})
+			},
+			check: func(t *testing.T, result db.AdjustBalanceTxResult, err error) {
+				require.ErrorIs(t, err, db.ErrNegativeBalance)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			result, err := New(store, nil, testActor).AdjustBalance(context.Background(), accountID, tc.amount, tc.reason)
+			tc.check(t, result, err)
+		})
+	}
+}
+
+func TestImportPolicies(t *testing.T) {
+	enforcer, err := casbin.NewEnforcer("../model.conf")
+	require.NoError(t, err)
+	_, err = enforcer.AddPolicy("banker", "*", "*", "accounts:read")
+	require.NoError(t, err)
+	_, err = enforcer.AddPolicy("banker", "*", "*", "tasks:manage")
+	require.NoError(t, err)
+
+	input := `# exported policies
+p, banker, *, *, accounts:read
+p, depositor, *, *, accounts:read
+g, alice, banker, acme
+`
+	policies, err := ReadPolicies(strings.NewReader(input), FormatCSV)
+	require.NoError(t, err)
+	require.Len(t, policies, 3)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		AppendAuditEventTx(gomock.Any(), gomock.Any()).
+		Times(2).
+		DoAndReturn(func(_ context.Context, event db.AppendAuditEventTxParams) (db.AuditEvent, error) {
+			require.Equal(t, db.AuditActionPolicyImport, event.Action)
+			require.Equal(t, util.DefaultTenant, event.TenantID)
+			return db.AuditEvent{}, nil
+		})
+	admin := New(store, enforcer, testActor)
+
+	added, removed, err := admin.ImportPolicies(context.Background(), policies, false)
+	require.NoError(t, err)
+	require.Equal(t, 2, added)
+	require.Zero(t, removed)
+
+	// importing again is a no-op; replace drops the policy missing from the file
+	added, removed, err = admin.ImportPolicies(context.Background(), policies, true)
+	require.NoError(t, err)
+	require.Zero(t, added)
+	require.Equal(t, 1, removed)
+
+	exported, err := admin.ExportPolicies()
+	require.NoError(t, err)
+	require.ElementsMatch(t, policies, exported)
+
+	var csv bytes.Buffer
+	require.NoError(t, WritePoliciesCSV(&csv, exported))
+	roundTrip, err := ReadPolicies(&csv, FormatCSV)
+	require.NoError(t, err)
+	require.ElementsMatch(t, exported, roundTrip)
+
+	_, err = ReadPolicies(strings.NewReader("p\n"), FormatCSV)
+	require.Error(t, err)
+}
+
+func TestPrint(t *testing.T) {
+	account := db.Account{
+		ID:           7,
+		Owner:        "alice",
+		Balance:      100,
+		Currency:     util.USD,
+		TenantID:     util.DefaultTenant,
+		Status:       db.AccountStatusFrozen,
+		StatusReason: db.AccountReasonLegalOrder,
+	}
+
+	var table bytes.Buffer
+	require.NoError(t, Print(&table, FormatTable, account, AccountTable(account)))
+	lines := strings.Split(strings.TrimSpace(table.String()), "\n")
+	require.Len(t, lines, 2)
+	require.Equal(t, []string{"ID", "OWNER", "BALANCE", "CURRENCY", "TENANT", "STATUS", "REASON"}, strings.Fields(lines[0]))
+	require.Equal(t, []string{"7", "alice", "100", util.USD, util.DefaultTenant, db.AccountStatusFrozen, db.AccountReasonLegalOrder}, strings.Fields(lines[1]))
+
+	var out bytes.Buffer
+	require.NoError(t, Print(&out, FormatJSON, account, AccountTable(account)))
+	var got db.Account
+	require.NoError(t, json.Unmarshal(out.Bytes(), &got))
+	require.Equal(t, account, got)
+
+	require.Error(t, Print(&out, "yaml", account, AccountTable(account)))
+}
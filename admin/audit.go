@@ -0,0 +1,50 @@
+package admin
+
+import (
+	"context"
+
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/rs/zerolog/log"
+)
+
+// auditActorRole is the role recorded for operators, who act outside the
+// roles of the API.
+const auditActorRole = "admin"
+
+// recordAudit appends event, the failure of an operation with err, to the
+// audit log. Events without a tenant, such as policy imports, are recorded in
+// the default one. Nothing changed, so a failure to record is only logged.
+//
+// Policy imports also record their success here, as the policies are not
+// changed in a transaction of the store. Every other operation appends it
+// with appendAudit in the transaction of the change.
+func (admin *Admin) recordAudit(ctx context.Context, event db.AppendAuditEventTxParams, err error) {
+	admin.setAuditActor(&event)
+	event.Outcome = db.AuditOutcomeSuccess
+	if err != nil {
+		event.Outcome = db.AuditOutcomeFailure
+		event.Reason = err.Error()
+	}
+
+	if _, err := admin.store.AppendAuditEventTx(context.WithoutCancel(ctx), event); err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("action", event.Action).Msg("cannot record audit event")
+	}
+}
+
+// appendAudit appends event as a success within the transaction of q, which
+// fails, and rolls the change back, if the event cannot be recorded.
+func (admin *Admin) appendAudit(ctx context.Context, q db.Querier, event db.AppendAuditEventTxParams) error {
+	admin.setAuditActor(&event)
+	event.Outcome = db.AuditOutcomeSuccess
+	_, err := db.AppendAuditEvent(ctx, q, event)
+	return err
+}
+
+func (admin *Admin) setAuditActor(event *db.AppendAuditEventTxParams) {
+	event.Actor = admin.actor
+	event.ActorRole = auditActorRole
+	if event.TenantID == "" {
+		event.TenantID = util.DefaultTenant
+	}
+}
@@ -0,0 +1,103 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+)
+
+// Output formats. Every command supports table and json; csv is only used for policies.
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+	FormatCSV   = "csv"
+)
+
+// Table is the tabular form of a command result.
+type Table struct {
+	Header []string
+	Rows   [][]string
+}
+
+// Print writes v as indented JSON or table as aligned columns.
+func Print(w io.Writer, format string, v any, table Table) error {
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	case FormatTable:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.Join(table.Header, "\t"))
+		for _, row := range table.Rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unsupported output format %q", format)
+	}
+}
+
+func UserTable(users ...User) Table {
+	table := Table{Header: []string{"USERNAME", "FULL NAME", "EMAIL", "ROLE", "TENANT", "VERIFIED", "DISABLED", "CREATED AT"}}
+	for _, user := range users {
+		table.Rows = append(table.Rows, []string{
+			user.Username,
+			user.FullName,
+			user.Email,
+			user.Role,
+			user.TenantID,
+			strconv.FormatBool(user.IsEmailVerified),
+			strconv.FormatBool(user.IsDisabled),
+			user.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return table
+}
+
+func AccountTable(accounts ...db.Account) Table {
+	table := Table{Header: []string{"ID", "OWNER", "BALANCE", "CURRENCY", "TENANT", "STATUS", "REASON"}}
+	for _, account := range accounts {
+		table.Rows = append(table.Rows, []string{
+			strconv.FormatInt(account.ID, 10),
+			account.Owner,
+			strconv.FormatInt(account.Balance, 10),
+			account.Currency,
+			account.TenantID,
+			account.Status,
+			account.StatusReason,
+		})
+	}
+	return table
+}
+
+func AdjustmentTable(results ...db.AdjustBalanceTxResult) Table {
+	table := Table{Header: []string{"ID", "ACCOUNT", "AMOUNT", "BALANCE", "ENTRY", "REASON", "CREATED BY", "CREATED AT"}}
+	for _, result := range results {
+		table.Rows = append(table.Rows, []string{
+			strconv.FormatInt(result.Adjustment.ID, 10),
+			strconv.FormatInt(result.Adjustment.AccountID, 10),
+			strconv.FormatInt(result.Adjustment.Amount, 10),
+			strconv.FormatInt(result.Account.Balance, 10),
+			strconv.FormatInt(result.Entry.ID, 10),
+			result.Adjustment.Reason,
+			result.Adjustment.CreatedBy,
+			result.Adjustment.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return table
+}
+
+func PolicyTable(policies ...Policy) Table {
+	table := Table{Header: []string{"TYPE", "RULE"}}
+	for _, policy := range policies {
+		table.Rows = append(table.Rows, []string{policy.PType, strings.Join(policy.Rule, ", ")})
+	}
+	return table
+}
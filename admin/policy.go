@@ -0,0 +1,173 @@
+package admin
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/rs/zerolog/log"
+)
+
+// Policy is one Casbin rule: a "p" permission or a "g" role assignment.
+type Policy struct {
+	PType string   `json:"ptype"`
+	Rule  []string `json:"rule"`
+}
+
+func (policy Policy) key() string {
+	return policy.PType + "," + strings.Join(policy.Rule, ",")
+}
+
+// ExportPolicies returns every policy and role assignment of the enforcer.
+func (admin *Admin) ExportPolicies() ([]Policy, error) {
+	rules, err := admin.enforcer.GetPolicy()
+	if err != nil {
+		return nil, err
+	}
+	groupings, err := admin.enforcer.GetGroupingPolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	policies := make([]Policy, 0, len(rules)+len(groupings))
+	for _, rule := range rules {
+		policies = append(policies, Policy{PType: "p", Rule: rule})
+	}
+	for _, rule := range groupings {
+		policies = append(policies, Policy{PType: "g", Rule: rule})
+	}
+	return policies, nil
+}
+
+// ImportPolicies adds the policies that are missing. With replace, policies
+// that are not in the import are removed, so the enforcer matches it exactly.
+func (admin *Admin) ImportPolicies(ctx context.Context, policies []Policy, replace bool) (added int, removed int, err error) {
+	audit := db.AppendAuditEventTxParams{
+		Action:     db.AuditActionPolicyImport,
+		TargetType: db.AuditTargetPolicies,
+		TargetID:   "*",
+	}
+	defer func() {
+		if err == nil {
+			audit.After = map[string]any{"added": added, "removed": removed, "replace": replace}
+		}
+		admin.recordAudit(ctx, audit, err)
+	}()
+
+	current, err := admin.ExportPolicies()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	existing := make(map[string]bool, len(current))
+	for _, policy := range current {
+		existing[policy.key()] = true
+	}
+	wanted := make(map[string]bool, len(policies))
+	for _, policy := range policies {
+		wanted[policy.key()] = true
+	}
+
+	var addP, addG, removeP, removeG [][]string
+	for _, policy := range policies {
+		if existing[policy.key()] {
+			continue
+		}
+		existing[policy.key()] = true
+		switch policy.PType {
+		case "p":
+			addP = append(addP, policy.Rule)
+		case "g":
+			addG = append(addG, policy.Rule)
+		default:
+			return 0, 0, fmt.Errorf("unsupported policy type %q", policy.PType)
+		}
+	}
+	if replace {
+		for _, policy := range current {
+			if wanted[policy.key()] {
+				continue
+			}
+			if policy.PType == "p" {
+				removeP = append(removeP, policy.Rule)
+			} else {
+				removeG = append(removeG, policy.Rule)
+			}
+		}
+	}
+
+	if len(addP) > 0 {
+		if _, err := admin.enforcer.AddPolicies(addP); err != nil {
+			return 0, 0, err
+		}
+	}
+	if len(addG) > 0 {
+		if _, err := admin.enforcer.AddGroupingPolicies(addG); err != nil {
+			return 0, 0, err
+		}
+	}
+	if len(removeP) > 0 {
+		if _, err := admin.enforcer.RemovePolicies(removeP); err != nil {
+			return 0, 0, err
+		}
+	}
+	if len(removeG) > 0 {
+		if _, err := admin.enforcer.RemoveGroupingPolicies(removeG); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	added, removed = len(addP)+len(addG), len(removeP)+len(removeG)
+	log.Info().Str("actor", admin.actor).Int("added", added).Int("removed", removed).Msg("admin imported policies")
+	return added, removed, nil
+}
+
+// ReadPolicies parses policies in JSON or in the CSV format of Casbin policy
+// files ("p, banker, *, *, accounts:read").
+func ReadPolicies(r io.Reader, format string) ([]Policy, error) {
+	switch format {
+	case FormatJSON:
+		var policies []Policy
+		if err := json.NewDecoder(r).Decode(&policies); err != nil {
+			return nil, fmt.Errorf("cannot decode policies: %w", err)
+		}
+		return policies, nil
+	case FormatCSV:
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		reader.Comment = '#'
+
+		records, err := reader.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("cannot read policies: %w", err)
+		}
+
+		policies := make([]Policy, 0, len(records))
+		for i, record := range records {
+			if len(record) < 2 {
+				return nil, fmt.Errorf("line %d: a policy needs a type and a rule", i+1)
+			}
+			policies = append(policies, Policy{PType: record[0], Rule: slices.Clone(record[1:])})
+		}
+		return policies, nil
+	default:
+		return nil, fmt.Errorf("unsupported policy format %q", format)
+	}
+}
+
+// WritePoliciesCSV writes policies in the CSV format of Casbin policy files.
+func WritePoliciesCSV(w io.Writer, policies []Policy) error {
+	for _, policy := range policies {
+		fields := append([]string{policy.PType}, policy.Rule...)
+		if _, err := fmt.Fprintln(w, strings.Join(fields, ", ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
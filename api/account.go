@@ -6,6 +6,8 @@ import (
 
 	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
 	"github.com/LamThanhNguyen/banking-system/token"
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/LamThanhNguyen/banking-system/worker"
 	"github.com/gin-gonic/gin"
 )
 
@@ -14,43 +16,56 @@ type createAccountRequest struct {
 }
 
 // @Summary      Create account
-// @Description  Create a new bank account for the authenticated user
+// @Description  Create a new bank account for the authenticated user.
+// @Description  Send an Idempotency-Key to retry safely: a retry with the same key and body returns the first response.
 // @Tags         accounts
 // @Security     BearerAuth
 // @Accept       json
 // @Produce      json
-// @Param        body  body      createAccountRequest  true  "Account info"
+// @Param        body             body      createAccountRequest  true   "Account info"
+// @Param        Idempotency-Key  header    string                false  "Key that makes retries of this request safe"
 // @Success      200   {object}  db.Account
-// @Failure      400   {object}  api.ErrorResponse "Invalid request or validation error"
-// @Failure      403   {object}  api.ErrorResponse "Forbidden: account already exists or invalid foreign key"
-// @Failure      500   {object}  api.ErrorResponse "Internal server error"
+// @Failure      400   {object}  api.Problem "INVALID_REQUEST or VALIDATION_FAILED"
+// @Failure      401   {object}  api.Problem "UNAUTHENTICATED"
+// @Failure      403   {object}  api.Problem "FORBIDDEN or EMAIL_NOT_VERIFIED"
+// @Failure      404   {object}  api.Problem "USER_NOT_FOUND"
+// @Failure      409   {object}  api.Problem "ALREADY_EXISTS: the user already has an account in this currency; IDEMPOTENCY_KEY_IN_PROGRESS"
+// @Failure      422   {object}  api.Problem "IDEMPOTENCY_KEY_REUSED"
+// @Failure      500   {object}  api.Problem "INTERNAL_ERROR"
 // @Router       /api/v1/accounts [post]
 func (server *Server) createAccount(ctx *gin.Context) {
 	var req createAccountRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		abortWithError(ctx, invalidRequest(err))
 		return
 	}
 
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	arg := db.CreateAccountParams{
-		Owner:    authPayload.Username,
-		Currency: req.Currency,
-		Balance:  0,
+	arg := db.CreateAccountTxParams{
+		CreateAccountParams: db.CreateAccountParams{
+			Owner:    authPayload.Username,
+			Currency: req.Currency,
+			Balance:  0,
+			TenantID: authPayload.TenantID,
+		},
+		AfterCreate: func(q db.Querier, account db.Account) error {
+			return worker.DispatchWebhookEvent(ctx, q, account.Owner, util.WebhookAccountCreated, account)
+		},
 	}
 
-	account, err := server.store.CreateAccount(ctx, arg)
+	txResult, err := server.store.CreateAccountTx(ctx, arg)
 	if err != nil {
-		errCode := db.ErrorCode(err)
-		if errCode == db.ForeignKeyViolation || errCode == db.UniqueViolation {
-			ctx.JSON(http.StatusForbidden, errorResponse(err))
-			return
+		switch db.ErrorCode(err) {
+		case db.UniqueViolation:
+			err = newError(CodeAlreadyExists, "an account in %s already exists", req.Currency)
+		case db.ForeignKeyViolation:
+			err = newError(CodeUserNotFound, "user %s does not exist", authPayload.Username)
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	ctx.JSON(http.StatusOK, txResult.Account)
 }
 
 type getAccountRequest struct {
@@ -64,32 +79,34 @@ type getAccountRequest struct {
 // @Produce      json
 // @Param        id   path      int  true  "Account ID"
 // @Success      200  {object}  db.Account
-// @Failure      400  {object}  api.ErrorResponse "Invalid request"
-// @Failure      401  {object}  api.ErrorResponse "Unauthorized: not account owner"
-// @Failure      404  {object}  api.ErrorResponse "Account not found"
-// @Failure      500  {object}  api.ErrorResponse "Internal server error"
+// @Failure      400  {object}  api.Problem "INVALID_REQUEST or VALIDATION_FAILED"
+// @Failure      401  {object}  api.Problem "UNAUTHENTICATED"
+// @Failure      403  {object}  api.Problem "FORBIDDEN or ACCOUNT_NOT_OWNED"
+// @Failure      404  {object}  api.Problem "ACCOUNT_NOT_FOUND"
+// @Failure      500  {object}  api.Problem "INTERNAL_ERROR"
 // @Router       /api/v1/accounts/{id} [get]
 func (server *Server) getAccount(ctx *gin.Context) {
 	var req getAccountRequest
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		abortWithError(ctx, invalidRequest(err))
 		return
 	}
 
-	account, err := server.store.GetAccount(ctx, req.ID)
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	account, err := server.store.GetAccount(ctx, db.GetAccountParams{
+		ID:       req.ID,
+		TenantID: authPayload.TenantID,
+	})
 	if err != nil {
 		if errors.Is(err, db.ErrRecordNotFound) {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
+			err = newError(CodeAccountNotFound, "account [%d] not found", req.ID)
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, err)
 		return
 	}
 
-	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 	if account.Owner != authPayload.Username {
-		err := errors.New("account doesn't belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		abortWithError(ctx, newError(CodeAccountNotOwned, "account doesn't belong to the authenticated user"))
 		return
 	}
 
@@ -109,26 +126,29 @@ type listAccountRequest struct {
 // @Param        page_id   query     int  true  "Page number (min 1)"
 // @Param        page_size query     int  true  "Page size (min 5, max 10)"
 // @Success      200       {array}   db.Account
-// @Failure      400       {object}  api.ErrorResponse "Invalid request"
-// @Failure      500       {object}  api.ErrorResponse "Internal server error"
+// @Failure      400       {object}  api.Problem "INVALID_REQUEST or VALIDATION_FAILED"
+// @Failure      401       {object}  api.Problem "UNAUTHENTICATED"
+// @Failure      403       {object}  api.Problem "FORBIDDEN"
+// @Failure      500       {object}  api.Problem "INTERNAL_ERROR"
 // @Router       /api/v1/accounts [get]
 func (server *Server) listAccounts(ctx *gin.Context) {
 	var req listAccountRequest
 	if err := ctx.ShouldBindQuery(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		abortWithError(ctx, invalidRequest(err))
 		return
 	}
 
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 	arg := db.ListAccountsParams{
-		Owner:  authPayload.Username,
-		Limit:  req.PageSize,
-		Offset: (req.PageID - 1) * req.PageSize,
+		Owner:    authPayload.Username,
+		TenantID: authPayload.TenantID,
+		Limit:    req.PageSize,
+		Offset:   (req.PageID - 1) * req.PageSize,
 	}
 
 	accounts, err := server.store.ListAccounts(ctx, arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, err)
 		return
 	}
 
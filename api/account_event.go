@@ -0,0 +1,178 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/token"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	accountEventStreamPath = "/api/v1/events"
+	lastEventIDHeader      = "Last-Event-ID"
+	// accountEventReplayPage is how many stored events are read at a time on resume.
+	accountEventReplayPage = 100
+	// accountEventRetry tells EventSource clients how long to wait before reconnecting.
+	accountEventRetry = 3 * time.Second
+)
+
+type streamAccountEventsRequest struct {
+	LastEventID *int64 `form:"last_event_id" binding:"omitempty,min=0"`
+}
+
+// accountEventResponse is the data of an account event on the stream.
+type accountEventResponse struct {
+	ID        int64           `json:"id"`
+	AccountID int64           `json:"account_id"`
+	Type      string          `json:"type" example:"transfer.received"`
+	Data      json.RawMessage `json:"data" swaggertype:"object"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+func newAccountEventResponse(event db.AccountEvent) accountEventResponse {
+	return accountEventResponse{
+		ID:        event.ID,
+		AccountID: event.AccountID,
+		Type:      event.EventType,
+		Data:      event.Payload,
+		CreatedAt: event.CreatedAt,
+	}
+}
+
+// @Summary      Stream account events
+// @Description  Server-sent events for every committed transfer and balance adjustment on the
+// @Description  authenticated user's accounts. Each event has its ID, its type (transfer.sent,
+// @Description  transfer.received or balance.adjusted) and an accountEventResponse as data.
+// @Description  Idle streams receive a heartbeat comment. A new stream only gets new events. To resume,
+// @Description  send the last received ID in the Last-Event-ID header (EventSource does this on reconnect)
+// @Description  or last_event_id, and the events after it are sent first.
+// @Tags         accounts
+// @Security     BearerAuth
+// @Produce      text/event-stream
+// @Param        Last-Event-ID  header    int  false  "Resume after this event"
+// @Param        last_event_id  query     int  false  "Resume after this event"
+// @Success      200            {object}  accountEventResponse
+// @Failure      400            {object}  api.Problem "INVALID_REQUEST or VALIDATION_FAILED"
+// @Failure      401            {object}  api.Problem "UNAUTHENTICATED"
+// @Failure      403            {object}  api.Problem "FORBIDDEN"
+// @Failure      500            {object}  api.Problem "INTERNAL_ERROR"
+// @Router       /api/v1/events [get]
+func (server *Server) streamAccountEvents(ctx *gin.Context) {
+	var req streamAccountEventsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		abortWithError(ctx, invalidRequest(err))
+		return
+	}
+	if header := ctx.GetHeader(lastEventIDHeader); header != "" {
+		id, err := strconv.ParseInt(header, 10, 64)
+		if err != nil || id < 0 {
+			abortWithError(ctx, newError(CodeInvalidRequest, "invalid %s header", lastEventIDHeader))
+			return
+		}
+		req.LastEventID = &id
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	// Subscribe before reading back the stored events, so that nothing committed
+	// in between is missed. Live events already replayed are skipped by ID.
+	sub := server.eventBroker.Subscribe(authPayload.TenantID, authPayload.Username)
+	defer sub.Close()
+
+	var lastID int64
+	if req.LastEventID != nil {
+		lastID = *req.LastEventID
+		for {
+			events, err := server.store.ListAccountEventsAfter(ctx, db.ListAccountEventsAfterParams{
+				TenantID:   authPayload.TenantID,
+				Owner:      authPayload.Username,
+				AfterID:    lastID,
+				LimitCount: accountEventReplayPage,
+			})
+			if err != nil {
+				if !ctx.Writer.Written() {
+					abortWithError(ctx, err)
+				}
+				return
+			}
+
+			if err := startEventStream(ctx); err != nil {
+				return
+			}
+			for _, event := range events {
+				if err := writeAccountEvent(ctx, event); err != nil {
+					return
+				}
+				lastID = event.ID
+			}
+			ctx.Writer.Flush()
+
+			if len(events) < accountEventReplayPage {
+				break
+			}
+		}
+	} else if err := startEventStream(ctx); err != nil {
+		return
+	}
+	ctx.Writer.Flush()
+
+	heartbeat := time.NewTicker(server.config.StreamHeartbeatParsed)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+
+		case event, ok := <-sub.C:
+			if !ok {
+				// Dropped by the broker; the client reconnects and resumes from lastID.
+				return
+			}
+			if event.ID <= lastID {
+				continue
+			}
+			if err := writeAccountEvent(ctx, event); err != nil {
+				return
+			}
+			lastID = event.ID
+			ctx.Writer.Flush()
+
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(ctx.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			ctx.Writer.Flush()
+		}
+	}
+}
+
+// startEventStream writes the headers of the stream, once.
+func startEventStream(ctx *gin.Context) error {
+	if ctx.Writer.Written() {
+		return nil
+	}
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+	_, err := fmt.Fprintf(ctx.Writer, "retry: %d\n\n", accountEventRetry.Milliseconds())
+	return err
+}
+
+func writeAccountEvent(ctx *gin.Context, event db.AccountEvent) error {
+	data, err := json.Marshal(newAccountEventResponse(event))
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(ctx.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.EventType, data)
+	return err
+}
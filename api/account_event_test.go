@@ -0,0 +1,282 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "github.com/LamThanhNguyen/banking-system/db/mock"
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/token"
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func randomAccountEvent(id int64, account db.Account, eventType string) db.AccountEvent {
+	payload, _ := json.Marshal(db.AccountEventPayload{
+		AccountID: account.ID,
+		Amount:    util.RandomMoney(),
+		Balance:   account.Balance,
+		Currency:  account.Currency,
+		EntryID:   util.RandomInt(1, 1000),
+	})
+
+	return db.AccountEvent{
+		ID:        id,
+		AccountID: account.ID,
+		Owner:     account.Owner,
+		TenantID:  account.TenantID,
+		EventType: eventType,
+		Payload:   payload,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+// readSSEBlock returns the lines of the next event or comment on the stream.
+func readSSEBlock(t *testing.T, reader *bufio.Reader) []string {
+	var lines []string
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+
+		line = strings.TrimRight(line, "\n")
+		if line == "" {
+			if len(lines) > 0 {
+				return lines
+			}
+			continue
+		}
+		lines = append(lines, line)
+	}
+}
+
+// readAccountEvent skips heartbeats and returns the next event on the stream.
+func readAccountEvent(t *testing.T, reader *bufio.Reader) accountEventResponse {
+	for {
+		lines := readSSEBlock(t, reader)
+		if strings.HasPrefix(lines[0], ":") || strings.HasPrefix(lines[0], "retry:") {
+			continue
+		}
+
+		require.Len(t, lines, 3)
+		var event accountEventResponse
+		require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(lines[2], "data: ")), &event))
+		require.Equal(t, fmt.Sprintf("id: %d", event.ID), lines[0])
+		require.Equal(t, "event: "+event.Type, lines[1])
+		return event
+	}
+}
+
+func requireAccountEvent(t *testing.T, expected db.AccountEvent, actual accountEventResponse) {
+	require.Equal(t, expected.ID, actual.ID)
+	require.Equal(t, expected.AccountID, actual.AccountID)
+	require.Equal(t, expected.EventType, actual.Type)
+	require.JSONEq(t, string(expected.Payload), string(actual.Data))
+	require.WithinDuration(t, expected.CreatedAt, actual.CreatedAt, time.Second)
+}
+
+func TestStreamAccountEventsAPI(t *testing.T) {
+	user, _ := randomDistributorUser(t)
+	account := randomAccount(user.Username)
+
+	stored := []db.AccountEvent{
+		randomAccountEvent(6, account, db.AccountEventTransferSent),
+		randomAccountEvent(7, account, db.AccountEventBalanceAdjusted),
+	}
+	live := randomAccountEvent(8, account, db.AccountEventTransferReceived)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListAccountEventsAfter(gomock.Any(), gomock.Eq(db.ListAccountEventsAfterParams{
+			TenantID:   util.DefaultTenant,
+			Owner:      user.Username,
+			AfterID:    5,
+			LimitCount: accountEventReplayPage,
+		})).
+		Times(1).
+		Return(stored, nil)
+
+	server := newTestServer(t, store, nil, nil)
+	server.config.StreamHeartbeatParsed = 10 * time.Millisecond
+
+	httpServer := httptest.NewServer(server.router)
+	defer httpServer.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, httpServer.URL+accountEventStreamPath, nil)
+	require.NoError(t, err)
+	request.Header.Set(lastEventIDHeader, "5")
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	require.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(response.Body)
+	require.Equal(t, []string{"retry: 3000"}, readSSEBlock(t, reader))
+
+	// Stored events are replayed first.
+	requireAccountEvent(t, stored[0], readAccountEvent(t, reader))
+	requireAccountEvent(t, stored[1], readAccountEvent(t, reader))
+
+	// An idle stream gets heartbeats.
+	require.Equal(t, []string{": heartbeat"}, readSSEBlock(t, reader))
+
+	// Live events already replayed are skipped, and other users' events are not sent.
+	server.eventBroker.Publish(stored[1])
+	server.eventBroker.Publish(randomAccountEvent(9, randomAccount(util.RandomOwner()), db.AccountEventTransferReceived))
+	server.eventBroker.Publish(live)
+	requireAccountEvent(t, live, readAccountEvent(t, reader))
+}
+
+func TestStreamAccountEventsWithoutResumeAPI(t *testing.T) {
+	user, _ := randomDistributorUser(t)
+	account := randomAccount(user.Username)
+	live := randomAccountEvent(8, account, db.AccountEventTransferReceived)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// A new stream does not read back the stored events.
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListAccountEventsAfter(gomock.Any(), gomock.Any()).
+		Times(0)
+
+	server := newTestServer(t, store, nil, nil)
+
+	httpServer := httptest.NewServer(server.router)
+	defer httpServer.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, httpServer.URL+accountEventStreamPath, nil)
+	require.NoError(t, err)
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	require.Equal(t, http.StatusOK, response.StatusCode)
+
+	reader := bufio.NewReader(response.Body)
+	require.Equal(t, []string{"retry: 3000"}, readSSEBlock(t, reader))
+
+	server.eventBroker.Publish(live)
+	requireAccountEvent(t, live, readAccountEvent(t, reader))
+}
+
+func TestStreamAccountEventsErrorsAPI(t *testing.T) {
+	user, _ := randomDistributorUser(t)
+
+	testCases := []struct {
+		name          string
+		query         string
+		setupRequest  func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "NoAuthorization",
+			setupRequest: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAccountEventsAfter(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidLastEventIDHeader",
+			setupRequest: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+				request.Header.Set(lastEventIDHeader, "abc")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAccountEventsAfter(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblem(t, recorder, CodeInvalidRequest)
+			},
+		},
+		{
+			name:  "InvalidLastEventIDQuery",
+			query: "?last_event_id=-1",
+			setupRequest: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAccountEventsAfter(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblem(t, recorder, CodeValidationFailed)
+			},
+		},
+		{
+			name:  "InternalError",
+			query: "?last_event_id=0",
+			setupRequest: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAccountEventsAfter(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				requireProblem(t, recorder, CodeInternal)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, accountEventStreamPath+tc.query, nil)
+			require.NoError(t, err)
+
+			tc.setupRequest(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/token"
+	"github.com/gin-gonic/gin"
+)
+
+type changeAccountStatusRequest struct {
+	Reason string `json:"reason" binding:"required,max=50"`
+}
+
+// @Summary      Freeze account
+// @Description  Freeze an account of the caller's tenant. Transfers from and to a frozen account are rejected.
+// @Description  Reason codes: suspected_fraud, legal_order, compliance_review or customer_request.
+// @Tags         accounts
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id    path      int                         true  "Account ID"
+// @Param        body  body      changeAccountStatusRequest  true  "Reason code"
+// @Success      200   {object}  db.Account
+// @Failure      400   {object}  api.Problem "INVALID_REQUEST, VALIDATION_FAILED or INVALID_REASON_CODE"
+// @Failure      401   {object}  api.Problem "UNAUTHENTICATED"
+// @Failure      403   {object}  api.Problem "FORBIDDEN or ACCOUNT_CLOSED"
+// @Failure      404   {object}  api.Problem "ACCOUNT_NOT_FOUND"
+// @Failure      409   {object}  api.Problem "INVALID_STATUS_CHANGE: the account is not active"
+// @Failure      500   {object}  api.Problem "INTERNAL_ERROR"
+// @Router       /api/v1/accounts/{id}/freeze [post]
+func (server *Server) freezeAccount(ctx *gin.Context) {
+	server.changeAccountStatus(ctx, db.AccountStatusFrozen, db.AuditActionAccountFreeze)
+}
+
+// @Summary      Unfreeze account
+// @Description  Make a frozen account of the caller's tenant active again.
+// @Description  Reason codes: review_cleared or customer_request.
+// @Tags         accounts
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id    path      int                         true  "Account ID"
+// @Param        body  body      changeAccountStatusRequest  true  "Reason code"
+// @Success      200   {object}  db.Account
+// @Failure      400   {object}  api.Problem "INVALID_REQUEST, VALIDATION_FAILED or INVALID_REASON_CODE"
+// @Failure      401   {object}  api.Problem "UNAUTHENTICATED"
+// @Failure      403   {object}  api.Problem "FORBIDDEN or ACCOUNT_CLOSED"
+// @Failure      404   {object}  api.Problem "ACCOUNT_NOT_FOUND"
+// @Failure      409   {object}  api.Problem "INVALID_STATUS_CHANGE: the account is not frozen"
+// @Failure      500   {object}  api.Problem "INTERNAL_ERROR"
+// @Router       /api/v1/accounts/{id}/unfreeze [post]
+func (server *Server) unfreezeAccount(ctx *gin.Context) {
+	server.changeAccountStatus(ctx, db.AccountStatusActive, db.AuditActionAccountUnfreeze)
+}
+
+// changeAccountStatus moves an account of the caller's tenant to status with
+// the reason code of the request.
+func (server *Server) changeAccountStatus(ctx *gin.Context, status string, action string) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, invalidRequest(err))
+		return
+	}
+	var req changeAccountStatusRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, invalidRequest(err))
+		return
+	}
+
+	audit := db.AppendAuditEventTxParams{
+		Action:     action,
+		TargetType: db.AuditTargetAccount,
+		TargetID:   strconv.FormatInt(uri.ID, 10),
+	}
+	defer server.recordAudit(ctx, &audit)
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	result, err := server.store.ChangeAccountStatusTx(ctx, db.ChangeAccountStatusTxParams{
+		AccountID: uri.ID,
+		Status:    status,
+		Reason:    req.Reason,
+		BeforeChange: func(account db.Account) error {
+			if account.TenantID != authPayload.TenantID {
+				return db.ErrRecordNotFound
+			}
+			return nil
+		},
+		AfterChange: func(q db.Querier, result db.ChangeAccountStatusTxResult) error {
+			setAccountStatusAudit(&audit, result)
+			return appendAudit(ctx, q, &audit)
+		},
+	})
+	if err != nil {
+		abortWithAccountStatusError(ctx, uri.ID, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result.Account)
+}
+
+// @Summary      Close account
+// @Description  Close an account of the authenticated user. The balance must be zero and the account active; a frozen account has to be unfrozen first.
+// @Description  Holds are not supported yet, so the balance is the only money that can be left on an account; a check for pending holds will be added with them.
+// @Description  Closing is final. Its currency becomes free for a new account.
+// @Tags         accounts
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id   path      int  true  "Account ID"
+// @Success      200  {object}  db.Account
+// @Failure      400  {object}  api.Problem "INVALID_REQUEST or VALIDATION_FAILED"
+// @Failure      401  {object}  api.Problem "UNAUTHENTICATED"
+// @Failure      403  {object}  api.Problem "FORBIDDEN, ACCOUNT_NOT_OWNED or ACCOUNT_CLOSED"
+// @Failure      404  {object}  api.Problem "ACCOUNT_NOT_FOUND"
+// @Failure      409  {object}  api.Problem "INVALID_STATUS_CHANGE: the account is frozen"
+// @Failure      422  {object}  api.Problem "ACCOUNT_NOT_EMPTY"
+// @Failure      500  {object}  api.Problem "INTERNAL_ERROR"
+// @Router       /api/v1/accounts/{id}/close [post]
+func (server *Server) closeAccount(ctx *gin.Context) {
+	var req getAccountRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		abortWithError(ctx, invalidRequest(err))
+		return
+	}
+
+	audit := db.AppendAuditEventTxParams{
+		Action:     db.AuditActionAccountClose,
+		TargetType: db.AuditTargetAccount,
+		TargetID:   strconv.FormatInt(req.ID, 10),
+	}
+	defer server.recordAudit(ctx, &audit)
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	result, err := server.store.ChangeAccountStatusTx(ctx, db.ChangeAccountStatusTxParams{
+		AccountID: req.ID,
+		Status:    db.AccountStatusClosed,
+		Reason:    db.AccountReasonCustomerRequest,
+		BeforeChange: func(account db.Account) error {
+			if account.TenantID != authPayload.TenantID {
+				return db.ErrRecordNotFound
+			}
+			if account.Owner != authPayload.Username {
+				return newError(CodeAccountNotOwned, "account doesn't belong to the authenticated user")
+			}
+			return nil
+		},
+		AfterChange: func(q db.Querier, result db.ChangeAccountStatusTxResult) error {
+			setAccountStatusAudit(&audit, result)
+			return appendAudit(ctx, q, &audit)
+		},
+	})
+	if err != nil {
+		abortWithAccountStatusError(ctx, req.ID, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result.Account)
+}
+
+func abortWithAccountStatusError(ctx *gin.Context, accountID int64, err error) {
+	if errors.Is(err, db.ErrRecordNotFound) {
+		err = newError(CodeAccountNotFound, "account [%d] not found", accountID)
+	}
+	abortWithError(ctx, err)
+}
+
+func setAccountStatusAudit(audit *db.AppendAuditEventTxParams, result db.ChangeAccountStatusTxResult) {
+	audit.Before = map[string]any{"status": result.Before.Status, "reason": result.Before.StatusReason}
+	audit.After = map[string]any{"status": result.Account.Status, "reason": result.Account.StatusReason}
+}
@@ -0,0 +1,332 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/LamThanhNguyen/banking-system/db/mock"
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// changeAccountStatus stubs ChangeAccountStatusTx like the store does: the
+// BeforeChange check runs on account, then the transition is checked and
+// AfterChange runs on the mock store.
+func changeAccountStatus(store *mockdb.MockStore, account db.Account) func(ctx context.Context, arg db.ChangeAccountStatusTxParams) (db.ChangeAccountStatusTxResult, error) {
+	return func(ctx context.Context, arg db.ChangeAccountStatusTxParams) (db.ChangeAccountStatusTxResult, error) {
+		if err := arg.BeforeChange(account); err != nil {
+			return db.ChangeAccountStatusTxResult{}, err
+		}
+		if err := db.CheckAccountTransition(account, arg.Status, arg.Reason); err != nil {
+			return db.ChangeAccountStatusTxResult{}, err
+		}
+
+		changed := account
+		changed.Status = arg.Status
+		changed.StatusReason = arg.Reason
+		result := db.ChangeAccountStatusTxResult{Account: changed, Before: account}
+		return result, arg.AfterChange(store, result)
+	}
+}
+
+func TestChangeAccountStatusAPI(t *testing.T) {
+	banker, _ := randomBankerUser(t)
+	user, _ := randomDistributorUser(t)
+	account := randomAccount(user.Username)
+	frozen := account
+	frozen.Status = db.AccountStatusFrozen
+	frozen.StatusReason = db.AccountReasonSuspectedFraud
+	otherTenant := account
+	otherTenant.TenantID = "acme"
+
+	testCases := []struct {
+		name          string
+		action        string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "Freeze",
+			action: "freeze",
+			body:   gin.H{"reason": db.AccountReasonSuspectedFraud},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ChangeAccountStatusTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(changeAccountStatus(store, account))
+				expectAppendedAuditEvent(t, store, db.AuditActionAccountFreeze)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAccount(t, recorder.Body, frozen)
+			},
+		},
+		{
+			name:   "Unfreeze",
+			action: "unfreeze",
+			body:   gin.H{"reason": db.AccountReasonReviewCleared},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ChangeAccountStatusTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(changeAccountStatus(store, frozen))
+				expectAppendedAuditEvent(t, store, db.AuditActionAccountUnfreeze)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got db.Account
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, db.AccountStatusActive, got.Status)
+				require.Equal(t, db.AccountReasonReviewCleared, got.StatusReason)
+			},
+		},
+		{
+			name:   "UnfreezeActive",
+			action: "unfreeze",
+			body:   gin.H{"reason": db.AccountReasonReviewCleared},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ChangeAccountStatusTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(changeAccountStatus(store, account))
+				expectAuditEvent(t, store, db.AuditActionAccountUnfreeze, db.AuditOutcomeFailure, CodeInvalidStatusChange)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireProblem(t, recorder, CodeInvalidStatusChange)
+			},
+		},
+		{
+			name:   "InvalidReason",
+			action: "freeze",
+			body:   gin.H{"reason": db.AccountReasonReviewCleared},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ChangeAccountStatusTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(changeAccountStatus(store, account))
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblem(t, recorder, CodeInvalidReasonCode)
+			},
+		},
+		{
+			name:   "NoReason",
+			action: "freeze",
+			body:   gin.H{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ChangeAccountStatusTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblem(t, recorder, CodeValidationFailed)
+			},
+		},
+		{
+			name:   "OtherTenant",
+			action: "freeze",
+			body:   gin.H{"reason": db.AccountReasonSuspectedFraud},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ChangeAccountStatusTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(changeAccountStatus(store, otherTenant))
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireProblem(t, recorder, CodeAccountNotFound)
+			},
+		},
+		{
+			name:   "InternalError",
+			action: "freeze",
+			body:   gin.H{"reason": db.AccountReasonSuspectedFraud},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ChangeAccountStatusTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ChangeAccountStatusTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			store.EXPECT().AppendAuditEventTx(gomock.Any(), gomock.Any()).AnyTimes()
+
+			server := newTestServer(t, store, nil, nil)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/api/v1/accounts/%d/%s", account.ID, tc.action)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, banker.Username, banker.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestCloseAccountAPI(t *testing.T) {
+	user, _ := randomDistributorUser(t)
+	other, _ := randomDistributorUser(t)
+	account := randomAccount(user.Username)
+	account.Balance = 0
+	notEmpty := account
+	notEmpty.Balance = 100
+	frozen := account
+	frozen.Status = db.AccountStatusFrozen
+	closed := account
+	closed.Status = db.AccountStatusClosed
+
+	testCases := []struct {
+		name          string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ChangeAccountStatusTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(changeAccountStatus(store, account))
+				expectAppendedAuditEvent(t, store, db.AuditActionAccountClose)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got db.Account
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, db.AccountStatusClosed, got.Status)
+				require.Equal(t, db.AccountReasonCustomerRequest, got.StatusReason)
+			},
+		},
+		{
+			name:     "NotEmpty",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ChangeAccountStatusTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(changeAccountStatus(store, notEmpty))
+				expectAuditEvent(t, store, db.AuditActionAccountClose, db.AuditOutcomeFailure, CodeAccountNotEmpty)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireProblem(t, recorder, CodeAccountNotEmpty)
+			},
+		},
+		{
+			name:     "Frozen",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ChangeAccountStatusTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(changeAccountStatus(store, frozen))
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireProblem(t, recorder, CodeInvalidStatusChange)
+			},
+		},
+		{
+			name:     "AlreadyClosed",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ChangeAccountStatusTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(changeAccountStatus(store, closed))
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblem(t, recorder, CodeAccountClosed)
+			},
+		},
+		{
+			name:     "NotOwned",
+			username: other.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ChangeAccountStatusTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(changeAccountStatus(store, account))
+				expectAuditEvent(t, store, db.AuditActionAccountClose, db.AuditOutcomeDenied, CodeAccountNotOwned)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblem(t, recorder, CodeAccountNotOwned)
+			},
+		},
+		{
+			name:     "NotFound",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ChangeAccountStatusTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ChangeAccountStatusTxResult{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireProblem(t, recorder, CodeAccountNotFound)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			store.EXPECT().AppendAuditEventTx(gomock.Any(), gomock.Any()).AnyTimes()
+
+			server := newTestServer(t, store, nil, nil)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/api/v1/accounts/%d/close", account.ID)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, user.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -39,7 +40,7 @@ func TestGetAccountAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					GetAccount(gomock.Any(), gomock.Eq(db.GetAccountParams{ID: account.ID, TenantID: util.DefaultTenant})).
 					Times(1).
 					Return(account, nil)
 			},
@@ -56,12 +57,13 @@ func TestGetAccountAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					GetAccount(gomock.Any(), gomock.Eq(db.GetAccountParams{ID: account.ID, TenantID: util.DefaultTenant})).
 					Times(1).
 					Return(account, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblem(t, recorder, CodeAccountNotOwned)
 			},
 		},
 		{
@@ -86,7 +88,7 @@ func TestGetAccountAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					GetAccount(gomock.Any(), gomock.Eq(db.GetAccountParams{ID: account.ID, TenantID: util.DefaultTenant})).
 					Times(1).
 					Return(db.Account{}, db.ErrRecordNotFound)
 			},
@@ -102,7 +104,7 @@ func TestGetAccountAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					GetAccount(gomock.Any(), gomock.Eq(db.GetAccountParams{ID: account.ID, TenantID: util.DefaultTenant})).
 					Times(1).
 					Return(db.Account{}, sql.ErrConnDone)
 			},
@@ -175,12 +177,23 @@ func TestCreateAccountAPI(t *testing.T) {
 					Owner:    account.Owner,
 					Currency: account.Currency,
 					Balance:  0,
+					TenantID: util.DefaultTenant,
 				}
 
 				store.EXPECT().
-					CreateAccount(gomock.Any(), gomock.Eq(arg)).
+					CreateAccountTx(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(account, nil)
+					DoAndReturn(func(_ context.Context, txArg db.CreateAccountTxParams) (db.CreateAccountTxResult, error) {
+						require.Equal(t, arg, txArg.CreateAccountParams)
+						return db.CreateAccountTxResult{Account: account}, txArg.AfterCreate(store, account)
+					})
+				store.EXPECT().
+					ListWebhookSubscriptionsForEvent(gomock.Any(), gomock.Eq(db.ListWebhookSubscriptionsForEventParams{
+						Username:  account.Owner,
+						EventType: util.WebhookAccountCreated,
+					})).
+					Times(1).
+					Return(nil, nil)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
@@ -196,7 +209,7 @@ func TestCreateAccountAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					CreateAccount(gomock.Any(), gomock.Any()).
+					CreateAccountTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -213,9 +226,9 @@ func TestCreateAccountAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					CreateAccount(gomock.Any(), gomock.Any()).
+					CreateAccountTx(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(db.Account{}, sql.ErrConnDone)
+					Return(db.CreateAccountTxResult{}, sql.ErrConnDone)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
@@ -231,7 +244,7 @@ func TestCreateAccountAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					CreateAccount(gomock.Any(), gomock.Any()).
+					CreateAccountTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -299,9 +312,10 @@ func TestListAccountAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				arg := db.ListAccountsParams{
-					Owner:  user.Username,
-					Limit:  int32(n),
-					Offset: 0,
+					Owner:    user.Username,
+					TenantID: util.DefaultTenant,
+					Limit:    int32(n),
+					Offset:   0,
 				}
 				store.EXPECT().
 					ListAccounts(gomock.Any(), gomock.Eq(arg)).
@@ -422,6 +436,8 @@ func randomAccount(owner string) db.Account {
 		Owner:    owner,
 		Balance:  util.RandomMoney(),
 		Currency: util.RandomCurrency(),
+		TenantID: util.DefaultTenant,
+		Status:   db.AccountStatusActive,
 	}
 }
 
@@ -0,0 +1,170 @@
+package api
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/logging"
+	"github.com/LamThanhNguyen/banking-system/token"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+// recordAudit appends event to the audit log once the handler has written
+// its response. Handlers defer it right after binding the request and fill
+// the target and changes as they go. The outcome is taken from the problem
+// written by abortWithError, if any, and the actor from the access token
+// unless the handler set one, as login does.
+//
+// Handlers that change money or access append the success with appendAudit
+// in the transaction of the change instead, so it is recorded here only if
+// that transaction did not commit.
+func (server *Server) recordAudit(ctx *gin.Context, event *db.AppendAuditEventTxParams) {
+	code := ErrorCode(ctx.GetString(problemCodeKey))
+	if code == "" && event.Outcome == db.AuditOutcomeSuccess {
+		return
+	}
+
+	event.Outcome = db.AuditOutcomeSuccess
+	if code != "" {
+		event.Outcome = db.AuditOutcomeFailure
+		if errorCatalog[code].status == http.StatusForbidden {
+			event.Outcome = db.AuditOutcomeDenied
+		}
+		event.Reason = string(code)
+	}
+	setAuditCaller(ctx, event)
+
+	// Nothing changed, so a failure to record is only logged. The event is
+	// kept even if the client left.
+	_, err := server.store.AppendAuditEventTx(context.WithoutCancel(ctx), *event)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("action", event.Action).Msg("cannot record audit event")
+	}
+}
+
+// appendAudit appends event as a success within the transaction of q, which
+// fails, and rolls the change back, if the event cannot be recorded.
+func appendAudit(ctx *gin.Context, q db.Querier, event *db.AppendAuditEventTxParams) error {
+	event.Outcome = db.AuditOutcomeSuccess
+	setAuditCaller(ctx, event)
+	_, err := db.AppendAuditEvent(ctx, q, *event)
+	return err
+}
+
+// setAuditCaller sets the actor of event from the access token, unless the
+// handler set one, and the request ID and client IP.
+func setAuditCaller(ctx *gin.Context, event *db.AppendAuditEventTxParams) {
+	if p, ok := ctx.Get(authorizationPayloadKey); ok && event.Actor == "" {
+		payload := p.(*token.Payload)
+		event.Actor = payload.Username
+		event.ActorRole = payload.Role
+		event.TenantID = payload.TenantID
+	}
+	event.RequestID = logging.RequestIDFromContext(ctx)
+	event.ClientIP = ctx.ClientIP()
+}
+
+type auditEventResponse struct {
+	ID         int64           `json:"id"`
+	Actor      string          `json:"actor"`
+	ActorRole  string          `json:"actor_role"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetID   string          `json:"target_id"`
+	Outcome    string          `json:"outcome"`
+	Reason     string          `json:"reason,omitempty"`
+	RequestID  string          `json:"request_id,omitempty"`
+	ClientIP   string          `json:"client_ip,omitempty"`
+	Before     json.RawMessage `json:"before,omitempty" swaggertype:"object"`
+	After      json.RawMessage `json:"after,omitempty" swaggertype:"object"`
+	CreatedAt  time.Time       `json:"created_at"`
+	Hash       string          `json:"hash"`
+}
+
+func newAuditEventResponse(event db.AuditEvent) auditEventResponse {
+	return auditEventResponse{
+		ID:         event.ID,
+		Actor:      event.Actor,
+		ActorRole:  event.ActorRole,
+		Action:     event.Action,
+		TargetType: event.TargetType,
+		TargetID:   event.TargetID,
+		Outcome:    event.Outcome,
+		Reason:     event.Reason,
+		RequestID:  event.RequestID,
+		ClientIP:   event.ClientIp,
+		Before:     event.Before,
+		After:      event.After,
+		CreatedAt:  event.CreatedAt,
+		Hash:       hex.EncodeToString(event.Hash),
+	}
+}
+
+type listAuditEventsRequest struct {
+	Actor      string    `form:"actor" binding:"omitempty,max=100"`
+	Action     string    `form:"action" binding:"omitempty,max=50"`
+	TargetType string    `form:"target_type" binding:"omitempty,max=50"`
+	TargetID   string    `form:"target_id" binding:"omitempty,max=100"`
+	Outcome    string    `form:"outcome" binding:"omitempty,oneof=success failure denied"`
+	From       time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To         time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	PageID     int32     `form:"page_id" binding:"required,min=1"`
+	PageSize   int32     `form:"page_size" binding:"required,min=5,max=100"`
+}
+
+// @Summary      List audit events
+// @Description  Search the audit log of the caller's tenant, newest first. Filters are combined. `from` and `to` are RFC 3339 times; `to` is exclusive.
+// @Tags         admin
+// @Security     BearerAuth
+// @Produce      json
+// @Param        actor        query     string  false  "Username of the actor"
+// @Param        action       query     string  false  "Action, such as user.login or transfer.create"
+// @Param        target_type  query     string  false  "Type of the target: user, account or policies"
+// @Param        target_id    query     string  false  "ID of the target, such as a username or an account ID"
+// @Param        outcome      query     string  false  "success, failure or denied"
+// @Param        from         query     string  false  "Events at or after this time"
+// @Param        to           query     string  false  "Events before this time"
+// @Param        page_id      query     int     true   "Page number (min 1)"
+// @Param        page_size    query     int     true   "Page size (min 5, max 100)"
+// @Success      200          {array}   auditEventResponse
+// @Failure      400          {object}  api.Problem "INVALID_REQUEST or VALIDATION_FAILED"
+// @Failure      403          {object}  api.Problem "FORBIDDEN"
+// @Failure      500          {object}  api.Problem "INTERNAL_ERROR"
+// @Router       /api/v1/audit-events [get]
+func (server *Server) listAuditEvents(ctx *gin.Context) {
+	var req listAuditEventsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		abortWithError(ctx, invalidRequest(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	events, err := server.store.ListAuditEvents(ctx, db.ListAuditEventsParams{
+		TenantID:    authPayload.TenantID,
+		Actor:       pgtype.Text{String: req.Actor, Valid: req.Actor != ""},
+		Action:      pgtype.Text{String: req.Action, Valid: req.Action != ""},
+		TargetType:  pgtype.Text{String: req.TargetType, Valid: req.TargetType != ""},
+		TargetID:    pgtype.Text{String: req.TargetID, Valid: req.TargetID != ""},
+		Outcome:     pgtype.Text{String: req.Outcome, Valid: req.Outcome != ""},
+		CreatedFrom: pgtype.Timestamptz{Time: req.From, Valid: !req.From.IsZero()},
+		CreatedTo:   pgtype.Timestamptz{Time: req.To, Valid: !req.To.IsZero()},
+		Limit:       req.PageSize,
+		Offset:      (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	rsp := make([]auditEventResponse, len(events))
+	for i, event := range events {
+		rsp[i] = newAuditEventResponse(event)
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
@@ -0,0 +1,205 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/LamThanhNguyen/banking-system/db/mock"
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/token"
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// expectAuditEvent expects one audit event of action with outcome and the
+// problem code reason, which is empty on success.
+func expectAuditEvent(t *testing.T, store *mockdb.MockStore, action string, outcome string, reason ErrorCode) *gomock.Call {
+	return store.EXPECT().
+		AppendAuditEventTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, event db.AppendAuditEventTxParams) (db.AuditEvent, error) {
+			require.Equal(t, action, event.Action)
+			require.Equal(t, outcome, event.Outcome)
+			require.Equal(t, string(reason), event.Reason)
+			require.NotEmpty(t, event.Actor)
+			require.NotEmpty(t, event.TenantID)
+			require.NotEmpty(t, event.RequestID)
+			return db.AuditEvent{}, nil
+		})
+}
+
+// expectAppendedAuditEvent expects the success of action to be appended to the
+// audit chain of its tenant within the transaction of the change.
+func expectAppendedAuditEvent(t *testing.T, store *mockdb.MockStore, action string) *gomock.Call {
+	store.EXPECT().
+		LockAuditEvents(gomock.Any(), gomock.Any()).
+		Times(1)
+	store.EXPECT().
+		GetLastAuditEvent(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.AuditEvent{}, db.ErrRecordNotFound)
+	return store.EXPECT().
+		CreateAuditEvent(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateAuditEventParams) (db.AuditEvent, error) {
+			require.Equal(t, action, arg.Action)
+			require.Equal(t, db.AuditOutcomeSuccess, arg.Outcome)
+			require.Empty(t, arg.Reason)
+			require.NotEmpty(t, arg.Actor)
+			require.NotEmpty(t, arg.TenantID)
+			require.NotEmpty(t, arg.RequestID)
+			return db.AuditEvent{ID: 1, TenantID: arg.TenantID, Action: arg.Action}, nil
+		})
+}
+
+func TestListAuditEventsAPI(t *testing.T) {
+	banker, _ := randomBankerUser(t)
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	event := db.AuditEvent{
+		ID:         42,
+		TenantID:   util.DefaultTenant,
+		Actor:      banker.Username,
+		ActorRole:  banker.Role,
+		Action:     db.AuditActionUserUpdateRole,
+		TargetType: db.AuditTargetUser,
+		TargetID:   "alice",
+		Outcome:    db.AuditOutcomeSuccess,
+		Before:     []byte(`{"role": "depositor"}`),
+		After:      []byte(`{"role": "banker"}`),
+		CreatedAt:  from.Add(time.Hour),
+		Hash:       []byte{0xab, 0xcd},
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: fmt.Sprintf("page_id=2&page_size=5&actor=%s&outcome=success&from=%s", banker.Username, from.Format(time.RFC3339)),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, banker.Username, banker.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListAuditEventsParams{
+					TenantID:    util.DefaultTenant,
+					Actor:       pgtype.Text{String: banker.Username, Valid: true},
+					Outcome:     pgtype.Text{String: db.AuditOutcomeSuccess, Valid: true},
+					CreatedFrom: pgtype.Timestamptz{Time: from, Valid: true},
+					Limit:       5,
+					Offset:      5,
+				}
+				store.EXPECT().
+					ListAuditEvents(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return([]db.AuditEvent{event}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got []auditEventResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Len(t, got, 1)
+				require.Equal(t, event.ID, got[0].ID)
+				require.Equal(t, hex.EncodeToString(event.Hash), got[0].Hash)
+				require.JSONEq(t, `{"role": "banker"}`, string(got[0].After))
+			},
+		},
+		{
+			name:  "NoAuthorization",
+			query: "page_id=1&page_size=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAuditEvents(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidOutcome",
+			query: "page_id=1&page_size=5&outcome=maybe",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, banker.Username, banker.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAuditEvents(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidTime",
+			query: "page_id=1&page_size=5&from=yesterday",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, banker.Username, banker.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAuditEvents(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InternalError",
+			query: "page_id=1&page_size=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, banker.Username, banker.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAuditEvents(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			recorder := httptest.NewRecorder()
+
+			url := "/api/v1/audit-events?" + tc.query
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
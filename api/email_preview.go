@@ -0,0 +1,62 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/gin-gonic/gin"
+)
+
+type previewEmailRequest struct {
+	Name string `uri:"name" binding:"required"`
+}
+
+type previewEmailQuery struct {
+	Locale string `form:"locale" binding:"omitempty,locale"`
+	Format string `form:"format" binding:"omitempty,oneof=html text json"`
+}
+
+// @Summary      Preview email
+// @Description  Render an email template with sample data. Only available in the develop environment. The html format returns the HTML part, text the plain-text part and json the subject with both parts.
+// @Tags         dev
+// @Produce      html
+// @Produce      plain
+// @Produce      json
+// @Param        name    path      string  true   "Template name, e.g. verify_email"
+// @Param        locale  query     string  false  "Locale (en, vi)"
+// @Param        format  query     string  false  "html (default), text or json"
+// @Success      200     {object}  templates.Email
+// @Failure      400     {object}  api.Problem "INVALID_REQUEST or VALIDATION_FAILED"
+// @Failure      404     {object}  api.Problem "NOT_FOUND: unknown template"
+// @Router       /dev/emails/{name} [get]
+func (server *Server) previewEmail(ctx *gin.Context) {
+	var req previewEmailRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		abortWithError(ctx, invalidRequest(err))
+		return
+	}
+
+	var query previewEmailQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		abortWithError(ctx, invalidRequest(err))
+		return
+	}
+	if query.Locale == "" {
+		query.Locale = util.DefaultLocale
+	}
+
+	email, err := server.emailTemplates.Preview(req.Name, query.Locale)
+	if err != nil {
+		abortWithError(ctx, newError(CodeNotFound, "%s", err))
+		return
+	}
+
+	switch query.Format {
+	case "json":
+		ctx.JSON(http.StatusOK, email)
+	case "text":
+		ctx.String(http.StatusOK, email.Text)
+	default:
+		ctx.Data(http.StatusOK, "text/html; charset=utf-8", []byte(email.HTML))
+	}
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/LamThanhNguyen/banking-system/mail/templates"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviewEmailAPI(t *testing.T) {
+	testCases := []struct {
+		name          string
+		environment   string
+		url           string
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "HTML",
+			environment: "develop",
+			url:         "/dev/emails/verify_email",
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Header().Get("Content-Type"), "text/html")
+				require.Contains(t, recorder.Body.String(), "<!DOCTYPE html>")
+			},
+		},
+		{
+			name:        "JSONWithLocale",
+			environment: "develop",
+			url:         "/dev/emails/transfer_failed?locale=vi&format=json",
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var email templates.Email
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &email))
+				require.Equal(t, "Giao dịch chuyển khoản của bạn không thành công", email.Subject)
+				require.NotEmpty(t, email.Text)
+			},
+		},
+		{
+			name:        "Text",
+			environment: "develop",
+			url:         "/dev/emails/low_balance?format=text",
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Header().Get("Content-Type"), "text/plain")
+			},
+		},
+		{
+			name:        "UnsupportedLocale",
+			environment: "develop",
+			url:         "/dev/emails/verify_email?locale=xx",
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:        "UnknownTemplate",
+			environment: "develop",
+			url:         "/dev/emails/unknown",
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:        "NotInProduction",
+			environment: "production",
+			url:         "/dev/emails/verify_email",
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestServer(t, nil, nil, nil)
+			server.config.Environment = tc.environment
+			server.SetupRouter()
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, tc.url, nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/token"
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	idempotencyKeyHeader    = "Idempotency-Key"
+	idempotentReplayHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength = 255
+)
+
+// idempotencyWriter keeps a copy of the response so it can be replayed.
+type idempotencyWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w idempotencyWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// Idempotent lets clients retry a request safely by sending an Idempotency-Key
+// header. The first request with a key runs and its response is stored; later
+// requests with the same key and body get the stored response back instead of
+// running again. Responses with a 5xx status are not stored, so the request can
+// be retried. Requests without the header are not affected.
+func (server *Server) Idempotent() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(idempotencyKeyHeader)
+		if key == "" {
+			ctx.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			abortWithError(ctx, newError(CodeInvalidRequest, "%s must be at most %d characters", idempotencyKeyHeader, maxIdempotencyKeyLength))
+			return
+		}
+
+		body, err := io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			abortWithError(ctx, newError(CodeInvalidRequest, "cannot read request body"))
+			return
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+		requestHash := hashRequest(ctx.Request.Method, ctx.Request.URL.Path, body)
+
+		_, err = server.store.CreateIdempotencyKey(ctx, db.CreateIdempotencyKeyParams{
+			TenantID:      authPayload.TenantID,
+			Username:      authPayload.Username,
+			Key:           key,
+			RequestHash:   requestHash,
+			ExpiredBefore: time.Now().Add(-util.IdempotencyKeyTTL),
+		})
+		if errors.Is(err, db.ErrRecordNotFound) {
+			server.replayIdempotentResponse(ctx, authPayload, key, requestHash)
+			return
+		}
+		if err != nil {
+			abortWithError(ctx, err)
+			return
+		}
+
+		id := idempotencyKeyID{tenantID: authPayload.TenantID, username: authPayload.Username, key: key}
+
+		// Release the key when the request fails with a 5xx or panics, so that
+		// it can be retried.
+		completed := false
+		defer func() {
+			if !completed {
+				server.releaseIdempotencyKey(ctx, id)
+			}
+		}()
+
+		writer := &idempotencyWriter{ResponseWriter: ctx.Writer, body: bytes.NewBuffer(nil)}
+		ctx.Writer = writer
+		ctx.Next()
+
+		status := writer.Status()
+		if status >= http.StatusInternalServerError {
+			return
+		}
+		completed = true
+
+		// A key whose response cannot be saved stays in progress until it
+		// expires rather than risk running the request twice.
+		saveCtx, cancel := detachedContext(ctx)
+		defer cancel()
+
+		err = server.store.SaveIdempotencyKeyResponse(saveCtx, db.SaveIdempotencyKeyResponseParams{
+			TenantID:            id.tenantID,
+			Username:            id.username,
+			Key:                 id.key,
+			ResponseStatus:      pgtype.Int4{Int32: int32(status), Valid: true},
+			ResponseContentType: writer.Header().Get("Content-Type"),
+			ResponseBody:        writer.body.Bytes(),
+		})
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("cannot save idempotent response")
+		}
+	}
+}
+
+type idempotencyKeyID struct {
+	tenantID string
+	username string
+	key      string
+}
+
+func (server *Server) releaseIdempotencyKey(ctx *gin.Context, id idempotencyKeyID) {
+	releaseCtx, cancel := detachedContext(ctx)
+	defer cancel()
+
+	err := server.store.DeleteIdempotencyKey(releaseCtx, db.DeleteIdempotencyKeyParams{
+		TenantID: id.tenantID,
+		Username: id.username,
+		Key:      id.key,
+	})
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("cannot release idempotency key")
+	}
+}
+
+// detachedContext is for bookkeeping after the response is sent, which has to
+// finish even if the client went away or the request timed out.
+func detachedContext(ctx *gin.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx.Request.Context()), 5*time.Second)
+}
+
+// replayIdempotentResponse answers a request whose key is already taken.
+func (server *Server) replayIdempotentResponse(ctx *gin.Context, authPayload *token.Payload, key string, requestHash string) {
+	record, err := server.store.GetIdempotencyKey(ctx, db.GetIdempotencyKeyParams{
+		TenantID: authPayload.TenantID,
+		Username: authPayload.Username,
+		Key:      key,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	if record.RequestHash != requestHash {
+		abortWithError(ctx, newError(CodeIdempotencyKeyReused, "%s was already used for a different request", idempotencyKeyHeader))
+		return
+	}
+	if !record.ResponseStatus.Valid {
+		abortWithError(ctx, newError(CodeIdempotencyKeyInProgress, "a request with this %s is still in progress", idempotencyKeyHeader))
+		return
+	}
+
+	ctx.Header(idempotentReplayHeader, "true")
+	ctx.Data(int(record.ResponseStatus.Int32), record.ResponseContentType, record.ResponseBody)
+	ctx.Abort()
+}
+
+func hashRequest(method string, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(path))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
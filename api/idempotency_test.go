@@ -0,0 +1,218 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "github.com/LamThanhNguyen/banking-system/db/mock"
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestIdempotentAPI(t *testing.T) {
+	user, _ := randomDistributorUser(t)
+	account := randomAccount(user.Username)
+
+	const url = "/api/v1/accounts"
+	key := util.RandomString(16)
+	body := []byte(`{"currency":"` + account.Currency + `"}`)
+	requestHash := hashRequest(http.MethodPost, url, body)
+
+	keyParams := db.GetIdempotencyKeyParams{
+		TenantID: util.DefaultTenant,
+		Username: user.Username,
+		Key:      key,
+	}
+	storedBody, err := json.Marshal(account)
+	require.NoError(t, err)
+
+	// expectCreate stubs the attempt to claim key for this request.
+	expectCreate := func(store *mockdb.MockStore, err error) {
+		store.EXPECT().
+			CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+			Times(1).
+			DoAndReturn(func(_ context.Context, arg db.CreateIdempotencyKeyParams) (db.IdempotencyKey, error) {
+				require.Equal(t, keyParams.TenantID, arg.TenantID)
+				require.Equal(t, keyParams.Username, arg.Username)
+				require.Equal(t, key, arg.Key)
+				require.Equal(t, requestHash, arg.RequestHash)
+				require.WithinDuration(t, time.Now().Add(-util.IdempotencyKeyTTL), arg.ExpiredBefore, time.Second)
+				return db.IdempotencyKey{}, err
+			})
+	}
+
+	testCases := []struct {
+		name          string
+		key           string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "FirstRequest",
+			key:  key,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectCreate(store, nil)
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CreateAccountTxResult{Account: account}, nil)
+				store.EXPECT().
+					SaveIdempotencyKeyResponse(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.SaveIdempotencyKeyResponseParams) error {
+						require.Equal(t, key, arg.Key)
+						require.Equal(t, pgtype.Int4{Int32: http.StatusOK, Valid: true}, arg.ResponseStatus)
+						require.True(t, strings.HasPrefix(arg.ResponseContentType, "application/json"))
+						require.JSONEq(t, string(storedBody), string(arg.ResponseBody))
+						return nil
+					})
+				store.EXPECT().DeleteIdempotencyKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Empty(t, recorder.Header().Get(idempotentReplayHeader))
+				requireBodyMatchAccount(t, recorder.Body, account)
+			},
+		},
+		{
+			name: "Replay",
+			key:  key,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectCreate(store, db.ErrRecordNotFound)
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Eq(keyParams)).
+					Times(1).
+					Return(db.IdempotencyKey{
+						Key:                 key,
+						RequestHash:         requestHash,
+						ResponseStatus:      pgtype.Int4{Int32: http.StatusOK, Valid: true},
+						ResponseContentType: "application/json; charset=utf-8",
+						ResponseBody:        storedBody,
+					}, nil)
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().SaveIdempotencyKeyResponse(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "true", recorder.Header().Get(idempotentReplayHeader))
+				requireBodyMatchAccount(t, recorder.Body, account)
+			},
+		},
+		{
+			name: "KeyReused",
+			key:  key,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectCreate(store, db.ErrRecordNotFound)
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Eq(keyParams)).
+					Times(1).
+					Return(db.IdempotencyKey{
+						Key:            key,
+						RequestHash:    hashRequest(http.MethodPost, url, []byte(`{"currency":"other"}`)),
+						ResponseStatus: pgtype.Int4{Int32: http.StatusOK, Valid: true},
+					}, nil)
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireProblem(t, recorder, CodeIdempotencyKeyReused)
+			},
+		},
+		{
+			name: "InProgress",
+			key:  key,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectCreate(store, db.ErrRecordNotFound)
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Eq(keyParams)).
+					Times(1).
+					Return(db.IdempotencyKey{Key: key, RequestHash: requestHash}, nil)
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireProblem(t, recorder, CodeIdempotencyKeyInProgress)
+			},
+		},
+		{
+			name: "InternalErrorReleasesKey",
+			key:  key,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectCreate(store, nil)
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CreateAccountTxResult{}, sql.ErrConnDone)
+				store.EXPECT().
+					DeleteIdempotencyKey(gomock.Any(), gomock.Eq(db.DeleteIdempotencyKeyParams(keyParams))).
+					Times(1).
+					Return(nil)
+				store.EXPECT().SaveIdempotencyKeyResponse(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "KeyTooLong",
+			key:  strings.Repeat("k", maxIdempotencyKeyLength+1),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateIdempotencyKey(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblem(t, recorder, CodeInvalidRequest)
+			},
+		},
+		{
+			name: "NoKey",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateIdempotencyKey(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CreateAccountTxResult{Account: account}, nil)
+				store.EXPECT().SaveIdempotencyKeyResponse(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+			require.NoError(t, err)
+			if tc.key != "" {
+				request.Header.Set(idempotencyKeyHeader, tc.key)
+			}
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
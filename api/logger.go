@@ -1,48 +1,66 @@
 package api
 
 import (
-	"bytes"
 	"net/http"
 	"time"
 
+	"github.com/LamThanhNguyen/banking-system/logging"
 	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
 
-type bodyWriter struct {
-	gin.ResponseWriter
-	body *bytes.Buffer
+// requestIDMiddleware gives every request an ID, taken from X-Request-ID when
+// the caller sent a valid one, and returns it in the same header. The request
+// context carries the ID, which the task distributors copy into the tasks,
+// and a logger that adds it and the trace ID to every entry.
+func requestIDMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetHeader(logging.RequestIDHeader)
+		if !logging.ValidRequestID(requestID) {
+			requestID = logging.NewRequestID()
+		}
+		ctx.Header(logging.RequestIDHeader, requestID)
+
+		logger := log.With().
+			Str(logging.RequestIDKey, requestID).
+			Str("trace_id", ctx.GetString(traceIDKey)).
+			Logger()
+		reqCtx := logging.WithRequestID(ctx.Request.Context(), requestID)
+		ctx.Request = ctx.Request.WithContext(logger.WithContext(reqCtx))
+		ctx.Next()
+	}
 }
 
-func (w bodyWriter) Write(b []byte) (int, error) {
-	w.body.Write(b)
-	return w.ResponseWriter.Write(b)
+// withLogFields adds fields, such as the authenticated user, to the logger of
+// the request for the handlers that run after the caller.
+func withLogFields(ctx *gin.Context, fields func(zerolog.Context) zerolog.Context) {
+	logger := fields(log.Ctx(ctx).With()).Logger()
+	ctx.Request = ctx.Request.WithContext(logger.WithContext(ctx.Request.Context()))
 }
 
+// HttpLogger writes an entry for every request with the logger of the
+// request. Failed requests are logged with their problem code rather than
+// the response body, and the query string, which can hold secrets such as
+// the code of a verification link, is left out.
 func HttpLogger() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
-
-		bw := &bodyWriter{
-			body:           bytes.NewBuffer(nil),
-			ResponseWriter: c.Writer,
-		}
-		c.Writer = bw
 		c.Next()
 
 		duration := time.Since(start)
-		statusCode := bw.Status()
-
-		logger := log.Info()
+		statusCode := c.Writer.Status()
+		logger := log.Ctx(c)
 
+		event := logger.Info()
 		if statusCode >= 400 {
-			logger = log.Error().Bytes("body", bw.body.Bytes())
+			event = logger.Error().Str("error_code", c.GetString(problemCodeKey))
 		}
 
-		logger.
+		event.
 			Str("protocol", "http").
 			Str("method", c.Request.Method).
-			Str("path", c.Request.RequestURI).
+			Str("path", c.Request.URL.Path).
 			Int("status_code", statusCode).
 			Str("status_text", http.StatusText(statusCode)).
 			Dur("duration", duration).
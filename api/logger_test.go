@@ -0,0 +1,125 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/LamThanhNguyen/banking-system/logging"
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/require"
+)
+
+// captureLogs sends the entries of the global logger to the returned buffer
+// for the rest of the test.
+func captureLogs(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	prevLogger := log.Logger
+	log.Logger = zerolog.New(&buf)
+	t.Cleanup(func() {
+		log.Logger = prevLogger
+	})
+	return &buf
+}
+
+// logEntries decodes the entries written to buf, keyed by their message.
+func logEntries(t *testing.T, buf *bytes.Buffer) map[string]map[string]any {
+	entries := make(map[string]map[string]any)
+	decoder := json.NewDecoder(buf)
+	for decoder.More() {
+		var entry map[string]any
+		require.NoError(t, decoder.Decode(&entry))
+		entries[entry["message"].(string)] = entry
+	}
+	return entries
+}
+
+func TestRequestLogger(t *testing.T) {
+	username := util.RandomOwner()
+
+	testCases := []struct {
+		name          string
+		requestID     string
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, requestID string)
+	}{
+		{
+			name:      "IncomingRequestID",
+			requestID: "req-42",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, requestID string) {
+				require.Equal(t, "req-42", requestID)
+			},
+		},
+		{
+			name: "NewRequestID",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, requestID string) {
+				require.True(t, logging.ValidRequestID(requestID))
+			},
+		},
+		{
+			name:      "InvalidRequestID",
+			requestID: "forged\" \"level\":\"info",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, requestID string) {
+				require.NotEqual(t, "forged\" \"level\":\"info", requestID)
+				require.True(t, logging.ValidRequestID(requestID))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			buf := captureLogs(t)
+
+			server := newTestServer(t, nil, nil, nil)
+			path := "/api/v1/logged"
+			var handlerRequestID string
+			server.router.GET(
+				path,
+				authMiddleware(server.tokenMaker, server.store),
+				func(ctx *gin.Context) {
+					handlerRequestID = logging.RequestIDFromContext(ctx)
+					log.Ctx(ctx).Info().Msg("in handler")
+					abortWithError(ctx, newError(CodeAccountNotFound, "account not found"))
+				},
+			)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, path+"?secret_code=s3cr3t", nil)
+			require.NoError(t, err)
+			if tc.requestID != "" {
+				request.Header.Set(logging.RequestIDHeader, tc.requestID)
+			}
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, username, util.DepositorRole, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			requestID := recorder.Header().Get(logging.RequestIDHeader)
+			tc.checkResponse(t, recorder, requestID)
+			require.Equal(t, requestID, handlerRequestID)
+
+			entries := logEntries(t, buf)
+
+			// The entries of the handler carry the request ID and the user...
+			handler := entries["in handler"]
+			require.NotNil(t, handler)
+			require.Equal(t, requestID, handler[logging.RequestIDKey])
+			require.Equal(t, username, handler["username"])
+			require.Equal(t, recorder.Header().Get(traceIDHeader), handler["trace_id"])
+
+			// ...and so does the access log, which has the problem code but no body or query.
+			access := entries["received an HTTP request"]
+			require.NotNil(t, access)
+			require.Equal(t, requestID, access[logging.RequestIDKey])
+			require.Equal(t, username, access["username"])
+			require.Equal(t, string(CodeAccountNotFound), access["error_code"])
+			require.Equal(t, path, access["path"])
+			require.NotContains(t, access, "body")
+		})
+	}
+}
@@ -5,22 +5,26 @@ import (
 	"testing"
 	"time"
 
+	mockdb "github.com/LamThanhNguyen/banking-system/db/mock"
 	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/health"
+	"github.com/LamThanhNguyen/banking-system/stream"
 	"github.com/LamThanhNguyen/banking-system/util"
 	"github.com/LamThanhNguyen/banking-system/worker"
 	"github.com/casbin/casbin/v2"
 	"github.com/casbin/casbin/v2/model"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
 )
 
 func newTestEnforcer(t *testing.T) *casbin.Enforcer {
 	m, err := model.NewModelFromString(`
         [request_definition]
-        r = sub, obj, act
+        r = sub, dom, obj, act
 
         [policy_definition]
-        p = sub, obj, act
+        p = sub, dom, obj, act
 
         [policy_effect]
         e = some(where (p.eft == allow))
@@ -44,6 +48,7 @@ func newTestServer(
 	if enforcer == nil {
 		enforcer = newTestEnforcer(t)
 	}
+	store = withValidTokens(t, store)
 
 	config := util.RuntimeConfig{
 		Config: util.Config{
@@ -55,9 +60,10 @@ func newTestServer(
 		},
 		AccessTokenDurationParsed:  time.Minute,
 		RefreshTokenDurationParsed: 10 * time.Minute,
+		StreamHeartbeatParsed:      time.Minute,
 	}
 
-	server, err := NewServer(config, store, enforcer, taskDistributor)
+	server, err := NewServer(config, store, enforcer, taskDistributor, nil, stream.NewBroker(), health.NewChecker(time.Second))
 	require.NoError(t, err)
 
 	server.SetupRouter()
@@ -70,3 +76,20 @@ func TestMain(m *testing.M) {
 
 	os.Exit(m.Run())
 }
+
+// withValidTokens lets authMiddleware accept every token: none of the users
+// of store has had their tokens revoked. Expectations set by the test itself
+// come first, so a test can still revoke them. A nil store is replaced with a
+// mock that only serves this check.
+func withValidTokens(t *testing.T, store db.Store) db.Store {
+	if store == nil {
+		store = mockdb.NewMockStore(gomock.NewController(t))
+	}
+	if mock, ok := store.(*mockdb.MockStore); ok {
+		mock.EXPECT().
+			GetUserTokensValidAfter(gomock.Any(), gomock.Any()).
+			AnyTimes().
+			Return(time.Time{}, nil)
+	}
+	return store
+}
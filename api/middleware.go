@@ -3,14 +3,17 @@ package api
 import (
 	"context"
 	"errors"
-	"fmt"
-	"net/http"
+	"slices"
 	"strings"
 	"time"
 
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/metrics"
 	"github.com/LamThanhNguyen/banking-system/token"
 	"github.com/LamThanhNguyen/banking-system/util"
 	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
 )
 
 const (
@@ -19,39 +22,55 @@ const (
 	authorizationPayloadKey = "authorization_payload"
 )
 
-func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
+// authMiddleware verifies the access token and rejects it if it was issued
+// before the user's tokens were revoked, as changing a user's role or
+// disabling them does.
+func authMiddleware(tokenMaker token.Maker, store db.Store) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authorizationHeader := ctx.GetHeader(authorizationHeaderKey) // authorization
 
 		if len(authorizationHeader) == 0 {
-			err := errors.New("authorization header is not provided")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			abortWithError(ctx, newError(CodeUnauthenticated, "authorization header is not provided"))
 			return
 		}
 
 		fields := strings.Fields(authorizationHeader)
 		if len(fields) < 2 {
-			err := errors.New("invalid authorization header format")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			abortWithError(ctx, newError(CodeUnauthenticated, "invalid authorization header format"))
 			return
 		}
 
 		authorizationType := strings.ToLower(fields[0])
 		if authorizationType != authorizationTypeBearer {
 			// not bearer
-			err := fmt.Errorf("unsupported authorization type %s", authorizationType)
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			abortWithError(ctx, newError(CodeUnauthenticated, "unsupported authorization type %s", authorizationType))
 			return
 		}
 
 		accessToken := fields[1]
 		payload, err := tokenMaker.VerifyToken(accessToken, token.TokenTypeAccessToken)
 		if err != nil {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			abortWithError(ctx, newError(CodeUnauthenticated, "%s", err))
+			return
+		}
+
+		validAfter, err := store.GetUserTokensValidAfter(ctx, payload.Username)
+		if err != nil {
+			if errors.Is(err, db.ErrRecordNotFound) {
+				err = newError(CodeUnauthenticated, "user no longer exists")
+			}
+			abortWithError(ctx, err)
+			return
+		}
+		if payload.IssuedAt.Before(validAfter) {
+			abortWithError(ctx, newError(CodeUnauthenticated, "access token has been revoked"))
 			return
 		}
 
 		ctx.Set(authorizationPayloadKey, payload)
+		withLogFields(ctx, func(c zerolog.Context) zerolog.Context {
+			return c.Str("username", payload.Username).Str("tenant_id", payload.TenantID)
+		})
 		ctx.Next()
 	}
 }
@@ -61,9 +80,7 @@ func (s *Server) Require(action string) gin.HandlerFunc {
 		p, ok := ctx.Get(authorizationPayloadKey)
 
 		if !ok {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized,
-				gin.H{"error": "missing auth payload"},
-			)
+			abortWithError(ctx, newError(CodeUnauthenticated, "missing auth payload"))
 			return
 		}
 
@@ -72,14 +89,15 @@ func (s *Server) Require(action string) gin.HandlerFunc {
 		sub := util.Subject{Role: payload.Role, Name: payload.Username}
 		obj := util.Object{Name: "*"} // wildcard for everything except ABAC handlers
 
-		allowed, err := s.enforcer.Enforce(sub, obj, action)
+		allowed, err := s.enforcer.Enforce(sub, payload.TenantID, obj, action)
 		if err != nil {
-			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			abortWithError(ctx, err)
 			return
 		}
 
 		if !allowed {
-			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+			s.logDenial(ctx, sub, payload.TenantID, obj, action)
+			abortWithError(ctx, newError(CodeForbidden, "forbidden"))
 			return
 		}
 
@@ -87,11 +105,68 @@ func (s *Server) Require(action string) gin.HandlerFunc {
 	}
 }
 
-func timeoutMiddleware(d time.Duration) gin.HandlerFunc {
+// RequireVerifiedEmail blocks the request until the authenticated user has verified
+// their email address. It does nothing unless REQUIRE_VERIFIED_EMAIL is enabled.
+func (s *Server) RequireVerifiedEmail() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !s.config.RequireVerifiedEmail {
+			ctx.Next()
+			return
+		}
+
+		payload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+		user, err := s.store.GetUser(ctx, payload.Username)
+		if err != nil {
+			abortWithError(ctx, err)
+			return
+		}
+
+		if !user.IsEmailVerified {
+			abortWithError(ctx, newError(CodeEmailNotVerified, "email address must be verified before moving money"))
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// logDenial counts a denied authorization check and records the evaluated
+// request when LOG_AUTHZ_DENIALS is enabled.
+func (s *Server) logDenial(ctx *gin.Context, sub util.Subject, dom string, obj util.Object, action string) {
+	metrics.ObserveAuthorizationDenial(action)
+	if !s.config.LogAuthzDenials {
+		return
+	}
+
+	log.Ctx(ctx).Warn().
+		Str("sub_role", sub.Role).
+		Str("sub_name", sub.Name).
+		Str("dom", dom).
+		Str("obj", obj.Name).
+		Str("act", action).
+		Msg("authorization denied")
+}
+
+// timeoutMiddleware bounds every request to d, except the routes in skipPaths.
+func timeoutMiddleware(d time.Duration, skipPaths ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if slices.Contains(skipPaths, c.FullPath()) {
+			c.Next()
+			return
+		}
+
 		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
 		defer cancel()
 		c.Request = c.Request.WithContext(ctx)
 		c.Next()
 	}
 }
+
+// metricsMiddleware records the duration of every request by route template.
+func metricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		metrics.ObserveHTTPRequest(c.Request.Method, c.FullPath(), c.Writer.Status(), time.Since(start))
+	}
+}
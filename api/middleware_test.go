@@ -1,16 +1,23 @@
 package api
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	mockdb "github.com/LamThanhNguyen/banking-system/db/mock"
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/logging"
 	"github.com/LamThanhNguyen/banking-system/token"
 	"github.com/LamThanhNguyen/banking-system/util"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
 )
 
 func addAuthorization(
@@ -22,7 +29,7 @@ func addAuthorization(
 	role string,
 	duration time.Duration,
 ) {
-	token, payload, err := tokenMaker.CreateToken(username, role, duration, token.TokenTypeAccessToken)
+	token, payload, err := tokenMaker.CreateToken(username, role, util.DefaultTenant, duration, token.TokenTypeAccessToken)
 	require.NoError(t, err)
 	require.NotEmpty(t, payload)
 
@@ -93,7 +100,7 @@ func TestAuthMiddleware(t *testing.T) {
 			authPath := "/api/v1/auth"
 			server.router.GET(
 				authPath,
-				authMiddleware(server.tokenMaker),
+				authMiddleware(server.tokenMaker, server.store),
 				func(ctx *gin.Context) {
 					ctx.JSON(http.StatusOK, gin.H{})
 				},
@@ -109,3 +116,103 @@ func TestAuthMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthMiddlewareRevokedTokens(t *testing.T) {
+	username := util.RandomOwner()
+
+	testCases := []struct {
+		name       string
+		validAfter time.Time
+		err        error
+		status     int
+	}{
+		{
+			name:       "IssuedAfterRevocation",
+			validAfter: time.Now().Add(-time.Minute),
+			status:     http.StatusOK,
+		},
+		{
+			name:       "IssuedBeforeRevocation",
+			validAfter: time.Now().Add(time.Minute),
+			status:     http.StatusUnauthorized,
+		},
+		{
+			name:   "UserNotFound",
+			err:    db.ErrRecordNotFound,
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "InternalError",
+			err:    sql.ErrConnDone,
+			status: http.StatusInternalServerError,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				GetUserTokensValidAfter(gomock.Any(), gomock.Eq(username)).
+				Times(1).
+				Return(tc.validAfter, tc.err)
+
+			server := newTestServer(t, store, nil, nil)
+			authPath := "/api/v1/auth"
+			server.router.GET(
+				authPath,
+				authMiddleware(server.tokenMaker, server.store),
+				func(ctx *gin.Context) {
+					ctx.JSON(http.StatusOK, gin.H{})
+				},
+			)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, authPath, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, username, util.BankerRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.status, recorder.Code)
+		})
+	}
+}
+
+func TestMetricsNotServedByAPI(t *testing.T) {
+	server := newTestServer(t, nil, nil, nil)
+
+	// Metrics are served on the admin address only.
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/metrics", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestProbesAPI(t *testing.T) {
+	buf := captureLogs(t)
+	server := newTestServer(t, nil, nil, nil)
+	server.healthChecker.Add("postgres", func(ctx context.Context) error {
+		return errors.New("connection refused")
+	})
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/livez", nil)
+	require.NoError(t, err)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest(http.MethodGet, "/readyz", nil)
+	require.NoError(t, err)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	require.Contains(t, recorder.Body.String(), "connection refused")
+
+	// Probes skip the middleware, so they are not logged.
+	require.Empty(t, recorder.Header().Get(logging.RequestIDHeader))
+	require.Empty(t, buf.String())
+}
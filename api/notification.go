@@ -0,0 +1,173 @@
+package api
+
+import (
+	"net/http"
+
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/token"
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/gin-gonic/gin"
+)
+
+var notificationEvents = []string{
+	util.NotificationTransferSent,
+	util.NotificationTransferReceived,
+	util.NotificationTransferFailed,
+	util.NotificationLowBalance,
+	util.NotificationLargeTransaction,
+}
+
+var notificationChannels = []string{
+	util.ChannelEmail,
+	util.ChannelSMS,
+	util.ChannelPush,
+}
+
+type notificationPreferenceResponse struct {
+	EventType string `json:"event_type"`
+	Channel   string `json:"channel"`
+	Currency  string `json:"currency,omitempty"`
+	Enabled   bool   `json:"enabled"`
+	Threshold int64  `json:"threshold"`
+}
+
+func newNotificationPreferenceResponse(pref db.NotificationPreference) notificationPreferenceResponse {
+	return notificationPreferenceResponse{
+		EventType: pref.EventType,
+		Channel:   pref.Channel,
+		Currency:  pref.Currency,
+		Enabled:   pref.Enabled,
+		Threshold: pref.Threshold,
+	}
+}
+
+// @Summary      List notification preferences
+// @Description  List the effective notification preferences of the authenticated user for every event type and channel, with a row per currency for alerts. Events without a stored preference show their default.
+// @Tags         notifications
+// @Security     BearerAuth
+// @Produce      json
+// @Param        username  path      string  true  "Username"
+// @Success      200       {array}   notificationPreferenceResponse
+// @Failure      400       {object}  api.Problem "INVALID_REQUEST or VALIDATION_FAILED"
+// @Failure      403       {object}  api.Problem "FORBIDDEN: preferences of another user"
+// @Failure      500       {object}  api.Problem "INTERNAL_ERROR"
+// @Router       /api/v1/users/{username}/notification-preferences [get]
+func (server *Server) listNotificationPreferences(ctx *gin.Context) {
+	var reqPath getUserRequest
+	if err := ctx.ShouldBindUri(&reqPath); err != nil {
+		abortWithError(ctx, invalidRequest(err))
+		return
+	}
+
+	if !ownNotificationPreferences(ctx, reqPath.Username) {
+		return
+	}
+
+	prefs, err := server.store.ListNotificationPreferences(ctx, reqPath.Username)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	stored := make(map[string][]db.NotificationPreference, len(prefs))
+	for _, pref := range prefs {
+		key := pref.EventType + "/" + pref.Channel
+		stored[key] = append(stored[key], pref)
+	}
+
+	rsp := make([]notificationPreferenceResponse, 0, len(notificationEvents)*len(notificationChannels))
+	for _, event := range notificationEvents {
+		for _, channel := range notificationChannels {
+			prefs, ok := stored[event+"/"+channel]
+			if !ok {
+				prefs = []db.NotificationPreference{{
+					EventType: event,
+					Channel:   channel,
+					Enabled:   util.IsNotificationEnabledByDefault(event, channel),
+				}}
+			}
+			for _, pref := range prefs {
+				rsp = append(rsp, newNotificationPreferenceResponse(pref))
+			}
+		}
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type updateNotificationPreferenceRequest struct {
+	EventType string `json:"event_type" binding:"required,notification_event"`
+	Channel   string `json:"channel" binding:"required,notification_channel"`
+	Currency  string `json:"currency" binding:"omitempty,currency"`
+	Enabled   *bool  `json:"enabled" binding:"required"`
+	Threshold int64  `json:"threshold" binding:"min=0"`
+}
+
+// @Summary      Update notification preference
+// @Description  Opt in or out of a notification event on a channel. Low-balance and large-transaction alerts are set per currency and need a positive threshold, in the minor unit of that currency, when enabled. Other events take no currency.
+// @Tags         notifications
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        username  path      string                               true  "Username"
+// @Param        body      body      updateNotificationPreferenceRequest  true  "Preference to store"
+// @Success      200       {object}  notificationPreferenceResponse
+// @Failure      400       {object}  api.Problem "INVALID_REQUEST or VALIDATION_FAILED"
+// @Failure      403       {object}  api.Problem "FORBIDDEN: preferences of another user"
+// @Failure      500       {object}  api.Problem "INTERNAL_ERROR"
+// @Router       /api/v1/users/{username}/notification-preferences [put]
+func (server *Server) updateNotificationPreference(ctx *gin.Context) {
+	var reqPath getUserRequest
+	if err := ctx.ShouldBindUri(&reqPath); err != nil {
+		abortWithError(ctx, invalidRequest(err))
+		return
+	}
+
+	var req updateNotificationPreferenceRequest
+	if !bindAndValidateJsonBody(ctx, &req) {
+		return
+	}
+
+	if !ownNotificationPreferences(ctx, reqPath.Username) {
+		return
+	}
+
+	isAlert := util.IsNotificationAlert(req.EventType)
+	if isAlert && req.Currency == "" {
+		abortWithError(ctx, newError(CodeInvalidRequest, "currency is required for %s alerts", req.EventType))
+		return
+	}
+	if !isAlert && req.Currency != "" {
+		abortWithError(ctx, newError(CodeInvalidRequest, "currency only applies to alerts"))
+		return
+	}
+	if isAlert && *req.Enabled && req.Threshold == 0 {
+		abortWithError(ctx, newError(CodeInvalidRequest, "threshold is required to enable %s alerts", req.EventType))
+		return
+	}
+
+	pref, err := server.store.UpsertNotificationPreference(ctx, db.UpsertNotificationPreferenceParams{
+		Username:  reqPath.Username,
+		EventType: req.EventType,
+		Channel:   req.Channel,
+		Currency:  req.Currency,
+		Enabled:   *req.Enabled,
+		Threshold: req.Threshold,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newNotificationPreferenceResponse(pref))
+}
+
+// ownNotificationPreferences only lets users manage their own notification preferences.
+func ownNotificationPreferences(ctx *gin.Context, username string) bool {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.Username != username {
+		abortWithError(ctx, newError(CodeForbidden, "cannot manage notification preferences of another user"))
+		return false
+	}
+	return true
+}
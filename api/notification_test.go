@@ -0,0 +1,351 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/LamThanhNguyen/banking-system/db/mock"
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestListNotificationPreferencesAPI(t *testing.T) {
+	user, _ := randomDistributorUser(t)
+	other, _ := randomDistributorUser(t)
+
+	stored := []db.NotificationPreference{
+		{
+			Username:  user.Username,
+			EventType: util.NotificationLowBalance,
+			Channel:   util.ChannelEmail,
+			Currency:  util.EUR,
+			Enabled:   true,
+			Threshold: 100,
+		},
+		{
+			Username:  user.Username,
+			EventType: util.NotificationLowBalance,
+			Channel:   util.ChannelEmail,
+			Currency:  util.USD,
+			Enabled:   true,
+			Threshold: 5000,
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListNotificationPreferences(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(stored, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp []notificationPreferenceResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				// the stored alert has a row per currency instead of the default
+				require.Len(t, rsp, len(notificationEvents)*len(notificationChannels)+1)
+
+				got := make(map[string]notificationPreferenceResponse, len(rsp))
+				for _, pref := range rsp {
+					got[pref.EventType+"/"+pref.Channel+"/"+pref.Currency] = pref
+				}
+				require.True(t, got[util.NotificationTransferSent+"/"+util.ChannelEmail+"/"].Enabled)
+				require.False(t, got[util.NotificationTransferSent+"/"+util.ChannelSMS+"/"].Enabled)
+				require.False(t, got[util.NotificationLargeTransaction+"/"+util.ChannelEmail+"/"].Enabled)
+				require.NotContains(t, got, util.NotificationLowBalance+"/"+util.ChannelEmail+"/")
+				for _, pref := range stored {
+					require.Equal(t, newNotificationPreferenceResponse(pref), got[pref.EventType+"/"+pref.Channel+"/"+pref.Currency])
+				}
+			},
+		},
+		{
+			name:     "OtherUser",
+			username: other.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListNotificationPreferences(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "InternalError",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListNotificationPreferences(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/api/v1/users/%s/notification-preferences", tc.username)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestUpdateNotificationPreferenceAPI(t *testing.T) {
+	user, _ := randomDistributorUser(t)
+	other, _ := randomDistributorUser(t)
+
+	testCases := []struct {
+		name          string
+		username      string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: user.Username,
+			body: gin.H{
+				"event_type": util.NotificationLargeTransaction,
+				"channel":    util.ChannelEmail,
+				"currency":   util.USD,
+				"enabled":    true,
+				"threshold":  5000,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpsertNotificationPreferenceParams{
+					Username:  user.Username,
+					EventType: util.NotificationLargeTransaction,
+					Channel:   util.ChannelEmail,
+					Currency:  util.USD,
+					Enabled:   true,
+					Threshold: 5000,
+				}
+				store.EXPECT().
+					UpsertNotificationPreference(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.NotificationPreference{
+						Username:  arg.Username,
+						EventType: arg.EventType,
+						Channel:   arg.Channel,
+						Currency:  arg.Currency,
+						Enabled:   arg.Enabled,
+						Threshold: arg.Threshold,
+					}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp notificationPreferenceResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.True(t, rsp.Enabled)
+				require.Equal(t, util.USD, rsp.Currency)
+				require.Equal(t, int64(5000), rsp.Threshold)
+			},
+		},
+		{
+			name:     "OptOut",
+			username: user.Username,
+			body: gin.H{
+				"event_type": util.NotificationTransferReceived,
+				"channel":    util.ChannelEmail,
+				"enabled":    false,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpsertNotificationPreference(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.NotificationPreference{EventType: util.NotificationTransferReceived}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "MissingThreshold",
+			username: user.Username,
+			body: gin.H{
+				"event_type": util.NotificationLowBalance,
+				"channel":    util.ChannelEmail,
+				"currency":   util.USD,
+				"enabled":    true,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpsertNotificationPreference(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "MissingCurrency",
+			username: user.Username,
+			body: gin.H{
+				"event_type": util.NotificationLargeTransaction,
+				"channel":    util.ChannelEmail,
+				"enabled":    true,
+				"threshold":  5000,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpsertNotificationPreference(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "CurrencyOnReceipt",
+			username: user.Username,
+			body: gin.H{
+				"event_type": util.NotificationTransferSent,
+				"channel":    util.ChannelEmail,
+				"currency":   util.USD,
+				"enabled":    true,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpsertNotificationPreference(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "UnsupportedCurrency",
+			username: user.Username,
+			body: gin.H{
+				"event_type": util.NotificationLargeTransaction,
+				"channel":    util.ChannelEmail,
+				"currency":   "XYZ",
+				"enabled":    true,
+				"threshold":  5000,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpsertNotificationPreference(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "UnsupportedChannel",
+			username: user.Username,
+			body: gin.H{
+				"event_type": util.NotificationTransferSent,
+				"channel":    "pigeon",
+				"enabled":    true,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpsertNotificationPreference(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "MissingEnabled",
+			username: user.Username,
+			body: gin.H{
+				"event_type": util.NotificationTransferSent,
+				"channel":    util.ChannelEmail,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpsertNotificationPreference(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "OtherUser",
+			username: other.Username,
+			body: gin.H{
+				"event_type": util.NotificationTransferSent,
+				"channel":    util.ChannelEmail,
+				"enabled":    false,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpsertNotificationPreference(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/api/v1/users/%s/notification-preferences", tc.username)
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/token"
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/LamThanhNguyen/banking-system/worker"
+	"github.com/gin-gonic/gin"
+	"github.com/hibiken/asynq"
+)
+
+const (
+	// verifyPhoneMaxAttempts is the number of wrong codes after which a code is no longer accepted.
+	verifyPhoneMaxAttempts = 5
+	// verifyPhoneResendCooldown is the time before a new code is sent to the same number.
+	verifyPhoneResendCooldown = time.Minute
+	// verifyPhoneMaxCodes codes at most are sent to a user in verifyPhoneCodeWindow.
+	verifyPhoneMaxCodes   = 5
+	verifyPhoneCodeWindow = time.Hour
+)
+
+type updateUserPhoneRequest struct {
+	Phone string `json:"phone" binding:"required,phone"`
+}
+
+// @Summary      Set phone number
+// @Description  Set the phone number of the authenticated user. The number stays unverified until the code sent to it by SMS is submitted. Setting the current unverified number again sends a new code after a one-minute cooldown. At most 5 codes are sent per hour.
+// @Tags         users
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        username  path      string                  true  "Username"
+// @Param        body      body      updateUserPhoneRequest  true  "Phone number in E.164 format"
+// @Success      202       {object}  userResponse "Accepted: a verification code will be sent"
+// @Failure      400       {object}  api.Problem "INVALID_REQUEST or VALIDATION_FAILED"
+// @Failure      403       {object}  api.Problem "FORBIDDEN: another user"
+// @Failure      404       {object}  api.Problem "USER_NOT_FOUND"
+// @Failure      409       {object}  api.Problem "PHONE_ALREADY_VERIFIED"
+// @Failure      429       {object}  api.Problem "RATE_LIMITED"
+// @Failure      500       {object}  api.Problem "INTERNAL_ERROR"
+// @Router       /api/v1/users/{username}/phone [put]
+func (server *Server) updateUserPhone(ctx *gin.Context) {
+	var reqPath getUserRequest
+	if err := ctx.ShouldBindUri(&reqPath); err != nil {
+		abortWithError(ctx, invalidRequest(err))
+		return
+	}
+
+	var req updateUserPhoneRequest
+	if !bindAndValidateJsonBody(ctx, &req) {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.Username != reqPath.Username {
+		abortWithError(ctx, newError(CodeForbidden, "cannot set the phone number of another user"))
+		return
+	}
+
+	now := time.Now()
+	txResult, err := server.store.UpdateUserPhoneTx(ctx, db.UpdateUserPhoneTxParams{
+		Username:     reqPath.Username,
+		Phone:        req.Phone,
+		ResendBefore: now.Add(-verifyPhoneResendCooldown),
+		WindowStart:  now.Add(-verifyPhoneCodeWindow),
+		MaxCodes:     verifyPhoneMaxCodes,
+		AfterUpdate: func(q db.Querier, user db.User) error {
+			return distributeVerifyPhone(ctx, q, user)
+		},
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			err = newError(CodeUserNotFound, "user not found")
+		}
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, newUserResponse(txResult.User))
+}
+
+type verifyUserPhoneRequest struct {
+	Code string `json:"code" binding:"required,otp"`
+}
+
+// @Summary      Verify phone number
+// @Description  Verify the phone number of the authenticated user with the code sent to it by SMS. A code expires after 10 minutes or 5 wrong attempts.
+// @Tags         users
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        username  path      string                  true  "Username"
+// @Param        body      body      verifyUserPhoneRequest  true  "Verification code"
+// @Success      200       {object}  userResponse
+// @Failure      400       {object}  api.Problem "INVALID_REQUEST, VALIDATION_FAILED or INCORRECT_VERIFICATION_CODE"
+// @Failure      403       {object}  api.Problem "FORBIDDEN: another user"
+// @Failure      404       {object}  api.Problem "NOT_FOUND: no active verification code"
+// @Failure      409       {object}  api.Problem "PHONE_ALREADY_VERIFIED"
+// @Failure      500       {object}  api.Problem "INTERNAL_ERROR"
+// @Router       /api/v1/users/{username}/phone/verify [post]
+func (server *Server) verifyUserPhone(ctx *gin.Context) {
+	var reqPath getUserRequest
+	if err := ctx.ShouldBindUri(&reqPath); err != nil {
+		abortWithError(ctx, invalidRequest(err))
+		return
+	}
+
+	var req verifyUserPhoneRequest
+	if !bindAndValidateJsonBody(ctx, &req) {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.Username != reqPath.Username {
+		abortWithError(ctx, newError(CodeForbidden, "cannot verify the phone number of another user"))
+		return
+	}
+
+	txResult, err := server.store.VerifyPhoneTx(ctx, db.VerifyPhoneTxParams{
+		Username:    reqPath.Username,
+		MaxAttempts: verifyPhoneMaxAttempts,
+		CheckCode: func(hashedCode string) bool {
+			return util.CheckPassword(req.Code, hashedCode) == nil
+		},
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			err = newError(CodeNotFound, "no active verification code")
+		}
+		abortWithError(ctx, err)
+		return
+	}
+
+	if !txResult.Verified {
+		abortWithError(ctx, newError(CodeIncorrectVerificationCode, "incorrect verification code"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newUserResponse(txResult.User))
+}
+
+// distributeVerifyPhone schedules the verification code through the outbox of the running transaction.
+func distributeVerifyPhone(ctx context.Context, q db.Querier, user db.User) error {
+	taskPayload := &worker.PayloadSendVerifyPhone{
+		Username: user.Username,
+	}
+	opts := []asynq.Option{
+		asynq.MaxRetry(5),
+		asynq.Queue(worker.QueueCritical),
+	}
+
+	return worker.NewOutboxTaskDistributor(q).DistributeTaskSendVerifyPhone(ctx, taskPayload, opts...)
+}
@@ -0,0 +1,276 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/LamThanhNguyen/banking-system/db/mock"
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/LamThanhNguyen/banking-system/worker"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestUpdateUserPhoneAPI(t *testing.T) {
+	user, _ := randomDistributorUser(t)
+	other, _ := randomDistributorUser(t)
+	phone := "+84901234567"
+
+	testCases := []struct {
+		name          string
+		authUser      db.User
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			authUser: user,
+			body:     gin.H{"phone": phone},
+			buildStubs: func(store *mockdb.MockStore) {
+				updated := user
+				updated.Phone = phone
+
+				store.EXPECT().
+					UpdateUserPhoneTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.UpdateUserPhoneTxParams) (db.UpdateUserPhoneTxResult, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, phone, arg.Phone)
+						require.WithinDuration(t, time.Now().Add(-verifyPhoneResendCooldown), arg.ResendBefore, time.Second)
+						require.WithinDuration(t, time.Now().Add(-verifyPhoneCodeWindow), arg.WindowStart, time.Second)
+						require.Equal(t, int64(verifyPhoneMaxCodes), arg.MaxCodes)
+						return db.UpdateUserPhoneTxResult{User: updated}, arg.AfterUpdate(store, updated)
+					})
+				store.EXPECT().
+					CreateOutbox(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateOutboxParams) (db.Outbox, error) {
+						require.Equal(t, worker.TaskSendVerifyPhone, arg.TaskType)
+						require.Equal(t, worker.QueueCritical, arg.Queue)
+						return db.Outbox{}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+
+				var rsp userResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, phone, rsp.Phone)
+				require.False(t, rsp.IsPhoneVerified)
+			},
+		},
+		{
+			name:     "InvalidPhone",
+			authUser: user,
+			body:     gin.H{"phone": "0901234567"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateUserPhoneTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "OtherUser",
+			authUser: other,
+			body:     gin.H{"phone": phone},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateUserPhoneTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "AlreadyVerified",
+			authUser: user,
+			body:     gin.H{"phone": phone},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateUserPhoneTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UpdateUserPhoneTxResult{}, db.ErrPhoneAlreadyVerified)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "ResendTooSoon",
+			authUser: user,
+			body:     gin.H{"phone": phone},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateUserPhoneTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UpdateUserPhoneTxResult{}, db.ErrVerifyPhoneTooSoon)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+				requireProblem(t, recorder, CodeRateLimited)
+			},
+		},
+		{
+			name:     "CodeLimitReached",
+			authUser: user,
+			body:     gin.H{"phone": "+84907654321"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateUserPhoneTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UpdateUserPhoneTxResult{}, db.ErrVerifyPhoneLimitReached)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+				requireProblem(t, recorder, CodeRateLimited)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/api/v1/users/%s/phone", user.Username)
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.authUser.Username, tc.authUser.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestVerifyUserPhoneAPI(t *testing.T) {
+	user, _ := randomDistributorUser(t)
+	user.Phone = "+84901234567"
+	code := "123456"
+
+	hashedCode, err := util.HashPassword(code)
+	require.NoError(t, err)
+
+	// verifyPhoneTx stands in for VerifyPhoneTx with a single active code.
+	verifyPhoneTx := func(_ context.Context, arg db.VerifyPhoneTxParams) (db.VerifyPhoneTxResult, error) {
+		require.Equal(t, user.Username, arg.Username)
+		require.Equal(t, int32(verifyPhoneMaxAttempts), arg.MaxAttempts)
+
+		result := db.VerifyPhoneTxResult{User: user, Verified: arg.CheckCode(hashedCode)}
+		result.User.IsPhoneVerified = result.Verified
+		return result, nil
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"code": code},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					VerifyPhoneTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(verifyPhoneTx)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp userResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.True(t, rsp.IsPhoneVerified)
+			},
+		},
+		{
+			name: "WrongCode",
+			body: gin.H{"code": "654321"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					VerifyPhoneTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(verifyPhoneTx)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidCode",
+			body: gin.H{"code": "12ab"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					VerifyPhoneTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NoActiveCode",
+			body: gin.H{"code": code},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					VerifyPhoneTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.VerifyPhoneTxResult{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/api/v1/users/%s/phone/verify", user.Username)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
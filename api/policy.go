@@ -0,0 +1,162 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/token"
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/gin-gonic/gin"
+)
+
+type explainPolicyRequest struct {
+	Username string `json:"username" binding:"required,username"`
+	Role     string `json:"role" binding:"omitempty,role"`
+	Object   string `json:"object" binding:"omitempty,max=100"`
+	Action   string `json:"action" binding:"required,max=100"`
+}
+
+type explainPolicyResponse struct {
+	Username     string   `json:"username"`
+	Role         string   `json:"role"`
+	TenantID     string   `json:"tenant_id"`
+	Object       string   `json:"object"`
+	Action       string   `json:"action"`
+	Allowed      bool     `json:"allowed"`
+	MatchedRules []string `json:"matched_rules"`
+}
+
+// @Summary      Explain authorization decision
+// @Description  Dry-run the Casbin enforcer for a subject, object and action within the caller's tenant and return the decision with the matched rule. When role is omitted it is read from the user record. Object defaults to "*".
+// @Tags         policies
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        body  body      explainPolicyRequest  true  "Request to evaluate"
+// @Success      200   {object}  explainPolicyResponse
+// @Failure      400   {object}  api.Problem "INVALID_REQUEST or VALIDATION_FAILED"
+// @Failure      403   {object}  api.Problem "FORBIDDEN"
+// @Failure      404   {object}  api.Problem "USER_NOT_FOUND"
+// @Failure      500   {object}  api.Problem "INTERNAL_ERROR"
+// @Router       /api/v1/policies/explain [post]
+func (server *Server) explainPolicy(ctx *gin.Context) {
+	var req explainPolicyRequest
+	if !bindAndValidateJsonBody(ctx, &req) {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	if req.Role == "" {
+		user, ok := server.tenantUser(ctx, req.Username, authPayload.TenantID)
+		if !ok {
+			return
+		}
+		req.Role = user.Role
+	}
+
+	if req.Object == "" {
+		req.Object = "*"
+	}
+
+	sub := util.Subject{Role: req.Role, Name: req.Username}
+	obj := util.Object{Name: req.Object}
+
+	allowed, explain, err := server.enforcer.EnforceEx(sub, authPayload.TenantID, obj, req.Action)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	rsp := explainPolicyResponse{
+		Username:     req.Username,
+		Role:         req.Role,
+		TenantID:     authPayload.TenantID,
+		Object:       req.Object,
+		Action:       req.Action,
+		Allowed:      allowed,
+		MatchedRules: explain,
+	}
+	if rsp.MatchedRules == nil {
+		rsp.MatchedRules = []string{}
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type userPermissionsResponse struct {
+	Username string   `json:"username"`
+	Role     string   `json:"role"`
+	TenantID string   `json:"tenant_id"`
+	Actions  []string `json:"actions"`
+}
+
+// @Summary      List user permissions
+// @Description  List every action from the policy that the user is allowed to perform on "*" within the caller's tenant. Attribute rules that only apply to specific objects are not included.
+// @Tags         policies
+// @Security     BearerAuth
+// @Produce      json
+// @Param        username  path      string  true  "Username"
+// @Success      200       {object}  userPermissionsResponse
+// @Failure      400       {object}  api.Problem "INVALID_REQUEST or VALIDATION_FAILED"
+// @Failure      403       {object}  api.Problem "FORBIDDEN"
+// @Failure      404       {object}  api.Problem "USER_NOT_FOUND"
+// @Failure      500       {object}  api.Problem "INTERNAL_ERROR"
+// @Router       /api/v1/policies/users/{username} [get]
+func (server *Server) listUserPermissions(ctx *gin.Context) {
+	var reqPath getUserRequest
+	if err := ctx.ShouldBindUri(&reqPath); err != nil {
+		abortWithError(ctx, invalidRequest(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	user, ok := server.tenantUser(ctx, reqPath.Username, authPayload.TenantID)
+	if !ok {
+		return
+	}
+
+	actions, err := server.enforcer.GetAllActions()
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	sub := util.Subject{Role: user.Role, Name: user.Username}
+	obj := util.Object{Name: "*"}
+
+	allowed := make([]string, 0, len(actions))
+	for _, action := range actions {
+		ok, err := server.enforcer.Enforce(sub, user.TenantID, obj, action)
+		if err != nil {
+			abortWithError(ctx, err)
+			return
+		}
+		if ok {
+			allowed = append(allowed, action)
+		}
+	}
+	sort.Strings(allowed)
+
+	ctx.JSON(http.StatusOK, userPermissionsResponse{
+		Username: user.Username,
+		Role:     user.Role,
+		TenantID: user.TenantID,
+		Actions:  allowed,
+	})
+}
+
+// tenantUser loads a user and reports it as not found when it belongs to another tenant.
+func (server *Server) tenantUser(ctx *gin.Context, username string, tenantID string) (db.User, bool) {
+	user, err := server.store.GetUser(ctx, username)
+	if err != nil && !errors.Is(err, db.ErrRecordNotFound) {
+		abortWithError(ctx, err)
+		return user, false
+	}
+	if err != nil || user.TenantID != tenantID {
+		abortWithError(ctx, newError(CodeUserNotFound, "user not found"))
+		return user, false
+	}
+	return user, true
+}
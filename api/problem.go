@@ -0,0 +1,255 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/token"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrorCode is the stable, machine-readable identifier of a problem. Clients
+// should branch on it rather than on the status or the human-readable detail.
+type ErrorCode string
+
+const (
+	CodeInvalidRequest              ErrorCode = "INVALID_REQUEST"
+	CodeValidationFailed            ErrorCode = "VALIDATION_FAILED"
+	CodeUnauthenticated             ErrorCode = "UNAUTHENTICATED"
+	CodeInvalidCredentials          ErrorCode = "INVALID_CREDENTIALS"
+	CodeForbidden                   ErrorCode = "FORBIDDEN"
+	CodeUserDisabled                ErrorCode = "USER_DISABLED"
+	CodeEmailNotVerified            ErrorCode = "EMAIL_NOT_VERIFIED"
+	CodeNotFound                    ErrorCode = "NOT_FOUND"
+	CodeUserNotFound                ErrorCode = "USER_NOT_FOUND"
+	CodeAccountNotFound             ErrorCode = "ACCOUNT_NOT_FOUND"
+	CodeAlreadyExists               ErrorCode = "ALREADY_EXISTS"
+	CodeConflict                    ErrorCode = "CONFLICT"
+	CodeAccountNotOwned             ErrorCode = "ACCOUNT_NOT_OWNED"
+	CodeAccountFrozen               ErrorCode = "ACCOUNT_FROZEN"
+	CodeAccountClosed               ErrorCode = "ACCOUNT_CLOSED"
+	CodeAccountNotEmpty             ErrorCode = "ACCOUNT_NOT_EMPTY"
+	CodeInvalidStatusChange         ErrorCode = "INVALID_STATUS_CHANGE"
+	CodeInvalidReasonCode           ErrorCode = "INVALID_REASON_CODE"
+	CodeCurrencyMismatch            ErrorCode = "CURRENCY_MISMATCH"
+	CodeInsufficientFunds           ErrorCode = "INSUFFICIENT_FUNDS"
+	CodeCrossTenantTransferDisabled ErrorCode = "CROSS_TENANT_TRANSFER_DISABLED"
+	CodeEmailAlreadyVerified        ErrorCode = "EMAIL_ALREADY_VERIFIED"
+	CodePhoneAlreadyVerified        ErrorCode = "PHONE_ALREADY_VERIFIED"
+	CodeIncorrectVerificationCode   ErrorCode = "INCORRECT_VERIFICATION_CODE"
+	CodeRateLimited                 ErrorCode = "RATE_LIMITED"
+	CodeIdempotencyKeyReused        ErrorCode = "IDEMPOTENCY_KEY_REUSED"
+	CodeIdempotencyKeyInProgress    ErrorCode = "IDEMPOTENCY_KEY_IN_PROGRESS"
+	CodeInternal                    ErrorCode = "INTERNAL_ERROR"
+)
+
+type problemType struct {
+	status int
+	title  string
+}
+
+// errorCatalog gives the status and title of every code. A code maps to exactly
+// one status, so the status never has to be chosen at the call site.
+var errorCatalog = map[ErrorCode]problemType{
+	CodeInvalidRequest:              {http.StatusBadRequest, "Invalid request"},
+	CodeValidationFailed:            {http.StatusBadRequest, "Validation failed"},
+	CodeUnauthenticated:             {http.StatusUnauthorized, "Authentication required"},
+	CodeInvalidCredentials:          {http.StatusUnauthorized, "Invalid credentials"},
+	CodeForbidden:                   {http.StatusForbidden, "Forbidden"},
+	CodeUserDisabled:                {http.StatusForbidden, "User is disabled"},
+	CodeEmailNotVerified:            {http.StatusForbidden, "Email address not verified"},
+	CodeNotFound:                    {http.StatusNotFound, "Resource not found"},
+	CodeUserNotFound:                {http.StatusNotFound, "User not found"},
+	CodeAccountNotFound:             {http.StatusNotFound, "Account not found"},
+	CodeAlreadyExists:               {http.StatusConflict, "Resource already exists"},
+	CodeConflict:                    {http.StatusConflict, "Conflict"},
+	CodeAccountNotOwned:             {http.StatusForbidden, "Account not owned"},
+	CodeAccountFrozen:               {http.StatusForbidden, "Account is frozen"},
+	CodeAccountClosed:               {http.StatusForbidden, "Account is closed"},
+	CodeAccountNotEmpty:             {http.StatusUnprocessableEntity, "Account not empty"},
+	CodeInvalidStatusChange:         {http.StatusConflict, "Invalid status change"},
+	CodeInvalidReasonCode:           {http.StatusBadRequest, "Invalid reason code"},
+	CodeCurrencyMismatch:            {http.StatusBadRequest, "Currency mismatch"},
+	CodeInsufficientFunds:           {http.StatusUnprocessableEntity, "Insufficient funds"},
+	CodeCrossTenantTransferDisabled: {http.StatusForbidden, "Cross-tenant transfers disabled"},
+	CodeEmailAlreadyVerified:        {http.StatusConflict, "Email already verified"},
+	CodePhoneAlreadyVerified:        {http.StatusConflict, "Phone number already verified"},
+	CodeIncorrectVerificationCode:   {http.StatusBadRequest, "Incorrect verification code"},
+	CodeRateLimited:                 {http.StatusTooManyRequests, "Too many requests"},
+	CodeIdempotencyKeyReused:        {http.StatusUnprocessableEntity, "Idempotency key reused"},
+	CodeIdempotencyKeyInProgress:    {http.StatusConflict, "Idempotency key in progress"},
+	CodeInternal:                    {http.StatusInternalServerError, "Internal server error"},
+}
+
+const problemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details response.
+type Problem struct {
+	Type       string           `json:"type" example:"/problems/insufficient-funds"`
+	Title      string           `json:"title" example:"Insufficient funds"`
+	Status     int              `json:"status" example:"422"`
+	Detail     string           `json:"detail,omitempty" example:"account [1] has insufficient funds"`
+	Instance   string           `json:"instance,omitempty" example:"/api/v1/transfers"`
+	Code       ErrorCode        `json:"code" example:"INSUFFICIENT_FUNDS"`
+	TraceID    string           `json:"trace_id" example:"4bf92f3577b34da6a3ce929d0e0e4736"`
+	Violations []FieldViolation `json:"violations,omitempty"`
+}
+
+// apiError is an error with a catalog code and a detail that is safe to show to clients.
+type apiError struct {
+	code   ErrorCode
+	detail string
+}
+
+func (e *apiError) Error() string {
+	return e.detail
+}
+
+// newError returns an error that is rendered as a problem with the given code.
+func newError(code ErrorCode, format string, args ...any) error {
+	return &apiError{code: code, detail: fmt.Sprintf(format, args...)}
+}
+
+// invalidRequest wraps an error from binding the request. Validation errors
+// keep their field violations; anything else becomes INVALID_REQUEST.
+func invalidRequest(err error) error {
+	var ve validator.ValidationErrors
+	if errors.As(err, &ve) {
+		return ve
+	}
+	return &apiError{code: CodeInvalidRequest, detail: err.Error()}
+}
+
+// problemCode maps an error to its catalog code and client-safe detail. This is
+// the only place that knows about store and domain errors, so database messages
+// never reach the client.
+func problemCode(err error) (ErrorCode, string) {
+	var apiErr *apiError
+	if errors.As(err, &apiErr) {
+		return apiErr.code, apiErr.detail
+	}
+
+	switch {
+	case errors.Is(err, db.ErrInsufficientFunds), errors.Is(err, db.ErrNegativeBalance):
+		return CodeInsufficientFunds, err.Error()
+	case errors.Is(err, db.ErrAccountFrozen):
+		return CodeAccountFrozen, err.Error()
+	case errors.Is(err, db.ErrAccountClosed):
+		return CodeAccountClosed, err.Error()
+	case errors.Is(err, db.ErrAccountNotEmpty):
+		return CodeAccountNotEmpty, err.Error()
+	case errors.Is(err, db.ErrInvalidAccountTransition):
+		return CodeInvalidStatusChange, err.Error()
+	case errors.Is(err, db.ErrInvalidAccountReason):
+		return CodeInvalidReasonCode, err.Error()
+	case errors.Is(err, db.ErrEmailAlreadyVerified):
+		return CodeEmailAlreadyVerified, err.Error()
+	case errors.Is(err, db.ErrPhoneAlreadyVerified):
+		return CodePhoneAlreadyVerified, err.Error()
+	case errors.Is(err, db.ErrVerificationRateLimited), errors.Is(err, db.ErrVerifyPhoneTooSoon), errors.Is(err, db.ErrVerifyPhoneLimitReached):
+		return CodeRateLimited, err.Error()
+	case errors.Is(err, db.ErrRecordNotFound):
+		return CodeNotFound, "resource not found"
+	case errors.Is(err, token.ErrExpiredToken), errors.Is(err, token.ErrInvalidToken):
+		return CodeUnauthenticated, err.Error()
+	}
+
+	switch db.ErrorCode(err) {
+	case db.UniqueViolation:
+		return CodeAlreadyExists, "resource already exists"
+	case db.ForeignKeyViolation:
+		return CodeConflict, "referenced resource does not exist"
+	}
+
+	return CodeInternal, "internal server error"
+}
+
+// abortWithError writes err as an application/problem+json response and stops the handler chain.
+func abortWithError(ctx *gin.Context, err error) {
+	code, detail := problemCode(err)
+
+	var violations []FieldViolation
+	var ve validator.ValidationErrors
+	if errors.As(err, &ve) {
+		code, detail = CodeValidationFailed, "request has invalid fields"
+		violations = make([]FieldViolation, len(ve))
+		for i, fe := range ve {
+			violations[i] = FieldViolation{Field: fe.Field(), Message: humanMessage(fe)}
+		}
+	}
+
+	pt := errorCatalog[code]
+	traceID := traceIDFromContext(ctx)
+	if pt.status >= http.StatusInternalServerError {
+		log.Ctx(ctx).Error().Err(err).Msg("request failed")
+	}
+	ctx.Set(problemCodeKey, string(code))
+
+	ctx.Header("Content-Type", problemContentType)
+	ctx.AbortWithStatusJSON(pt.status, Problem{
+		Type:       problemTypeURI(code),
+		Title:      pt.title,
+		Status:     pt.status,
+		Detail:     detail,
+		Instance:   ctx.Request.URL.Path,
+		Code:       code,
+		TraceID:    traceID,
+		Violations: violations,
+	})
+}
+
+// problemTypeURI turns INSUFFICIENT_FUNDS into /problems/insufficient-funds.
+func problemTypeURI(code ErrorCode) string {
+	return "/problems/" + strings.ReplaceAll(strings.ToLower(string(code)), "_", "-")
+}
+
+const (
+	traceIDKey     = "trace_id"
+	problemCodeKey = "problem_code"
+	traceIDHeader  = "X-Trace-ID"
+)
+
+// traceparent is the W3C trace context header: version-traceid-parentid-flags.
+var traceparent = regexp.MustCompile(`^[0-9a-f]{2}-([0-9a-f]{32})-[0-9a-f]{16}-[0-9a-f]{2}$`)
+
+// traceMiddleware gives every request a trace ID and returns it in
+// X-Trace-ID. It is the ID of the request's span when tracing is on, and
+// otherwise taken from the traceparent header when a caller sent one.
+func traceMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		traceID := ""
+		if sc := trace.SpanContextFromContext(ctx.Request.Context()); sc.HasTraceID() {
+			traceID = sc.TraceID().String()
+		} else if m := traceparent.FindStringSubmatch(ctx.GetHeader("traceparent")); m != nil {
+			traceID = m[1]
+		} else {
+			traceID = newTraceID()
+		}
+
+		ctx.Set(traceIDKey, traceID)
+		ctx.Header(traceIDHeader, traceID)
+		ctx.Next()
+	}
+}
+
+func traceIDFromContext(ctx *gin.Context) string {
+	if traceID := ctx.GetString(traceIDKey); traceID != "" {
+		return traceID
+	}
+	return newTraceID()
+}
+
+func newTraceID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,187 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/token"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// requireProblem checks that the response is a problem with the given code and returns it.
+func requireProblem(t *testing.T, recorder *httptest.ResponseRecorder, code ErrorCode) Problem {
+	require.Equal(t, problemContentType, recorder.Header().Get("Content-Type"))
+
+	var problem Problem
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &problem))
+	require.Equal(t, code, problem.Code)
+	require.Equal(t, recorder.Code, problem.Status)
+	require.Equal(t, problemTypeURI(code), problem.Type)
+	require.NotEmpty(t, problem.Title)
+	require.NotEmpty(t, problem.TraceID)
+	return problem
+}
+
+func TestProblemCode(t *testing.T) {
+	testCases := []struct {
+		name   string
+		err    error
+		code   ErrorCode
+		detail string
+	}{
+		{
+			name:   "APIError",
+			err:    newError(CodeAccountNotOwned, "account [%d] is not yours", 1),
+			code:   CodeAccountNotOwned,
+			detail: "account [1] is not yours",
+		},
+		{
+			name:   "InsufficientFunds",
+			err:    fmt.Errorf("transfer: %w", db.ErrInsufficientFunds),
+			code:   CodeInsufficientFunds,
+			detail: "transfer: account has insufficient funds",
+		},
+		{
+			name:   "AccountFrozen",
+			err:    db.ErrAccountFrozen,
+			code:   CodeAccountFrozen,
+			detail: db.ErrAccountFrozen.Error(),
+		},
+		{
+			name:   "RecordNotFound",
+			err:    db.ErrRecordNotFound,
+			code:   CodeNotFound,
+			detail: "resource not found",
+		},
+		{
+			name:   "ExpiredToken",
+			err:    token.ErrExpiredToken,
+			code:   CodeUnauthenticated,
+			detail: token.ErrExpiredToken.Error(),
+		},
+		{
+			name: "UniqueViolation",
+			err: &pgconn.PgError{
+				Code:    db.UniqueViolation,
+				Message: `duplicate key value violates unique constraint "owner_currency_key"`,
+			},
+			code:   CodeAlreadyExists,
+			detail: "resource already exists",
+		},
+		{
+			name: "ForeignKeyViolation",
+			err: &pgconn.PgError{
+				Code:    db.ForeignKeyViolation,
+				Message: `insert or update on table "accounts" violates foreign key constraint`,
+			},
+			code:   CodeConflict,
+			detail: "referenced resource does not exist",
+		},
+		{
+			name:   "Unknown",
+			err:    errors.New("pq: connection refused"),
+			code:   CodeInternal,
+			detail: "internal server error",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			code, detail := problemCode(tc.err)
+			require.Equal(t, tc.code, code)
+			require.Equal(t, tc.detail, detail)
+		})
+	}
+}
+
+func TestErrorCatalog(t *testing.T) {
+	for code, pt := range errorCatalog {
+		require.NotEmpty(t, pt.title, code)
+		require.GreaterOrEqual(t, pt.status, http.StatusBadRequest, code)
+	}
+}
+
+func TestAbortWithError(t *testing.T) {
+	router := gin.New()
+	router.Use(traceMiddleware())
+	router.GET("/accounts/:id", func(ctx *gin.Context) {
+		abortWithError(ctx, newError(CodeAccountNotFound, "account [%s] not found", ctx.Param("id")))
+	})
+
+	t.Run("TraceIDFromTraceparent", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, "/accounts/7", nil)
+		request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		router.ServeHTTP(recorder, request)
+
+		require.Equal(t, http.StatusNotFound, recorder.Code)
+		problem := requireProblem(t, recorder, CodeAccountNotFound)
+		require.Equal(t, "account [7] not found", problem.Detail)
+		require.Equal(t, "/accounts/7", problem.Instance)
+		require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", problem.TraceID)
+		require.Equal(t, problem.TraceID, recorder.Header().Get(traceIDHeader))
+	})
+
+	t.Run("GeneratedTraceID", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, "/accounts/7", nil)
+		request.Header.Set("traceparent", "invalid")
+		router.ServeHTTP(recorder, request)
+
+		problem := requireProblem(t, recorder, CodeAccountNotFound)
+		require.Len(t, problem.TraceID, 32)
+		require.Equal(t, problem.TraceID, recorder.Header().Get(traceIDHeader))
+	})
+}
+
+func TestTraceIDFromSpan(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+
+	router := gin.New()
+	router.Use(
+		otelgin.Middleware("test",
+			otelgin.WithTracerProvider(provider),
+			otelgin.WithPropagators(propagation.TraceContext{}),
+		),
+		traceMiddleware(),
+	)
+	router.GET("/accounts/:id", func(ctx *gin.Context) {
+		abortWithError(ctx, newError(CodeAccountNotFound, "account [%s] not found", ctx.Param("id")))
+	})
+
+	t.Run("ContinuedTrace", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, "/accounts/7", nil)
+		request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		router.ServeHTTP(recorder, request)
+
+		problem := requireProblem(t, recorder, CodeAccountNotFound)
+		require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", problem.TraceID)
+	})
+
+	t.Run("NewTrace", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, "/accounts/7", nil)
+		router.ServeHTTP(recorder, request)
+
+		// The trace ID returned to the caller is the one of the exported span.
+		problem := requireProblem(t, recorder, CodeAccountNotFound)
+		ended := spans.Ended()
+		require.Equal(t, ended[len(ended)-1].SpanContext().TraceID().String(), problem.TraceID)
+		require.Equal(t, "GET /accounts/:id", ended[len(ended)-1].Name())
+	})
+}
@@ -6,7 +6,12 @@ import (
 	"time"
 
 	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/health"
+	"github.com/LamThanhNguyen/banking-system/logging"
+	"github.com/LamThanhNguyen/banking-system/mail/templates"
+	"github.com/LamThanhNguyen/banking-system/stream"
 	"github.com/LamThanhNguyen/banking-system/token"
+	"github.com/LamThanhNguyen/banking-system/tracing"
 	"github.com/LamThanhNguyen/banking-system/util"
 	"github.com/LamThanhNguyen/banking-system/worker"
 	"github.com/casbin/casbin/v2"
@@ -14,6 +19,7 @@ import (
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 type Server struct {
@@ -23,6 +29,10 @@ type Server struct {
 	router          *gin.Engine
 	tokenMaker      token.Maker
 	taskDistributor worker.TaskDistributor
+	taskInspector   worker.TaskInspector
+	emailTemplates  *templates.Renderer
+	eventBroker     *stream.Broker
+	healthChecker   *health.Checker
 }
 
 func NewServer(
@@ -30,18 +40,30 @@ func NewServer(
 	store db.Store,
 	enforcer *casbin.Enforcer,
 	taskDistributor worker.TaskDistributor,
+	taskInspector worker.TaskInspector,
+	eventBroker *stream.Broker,
+	healthChecker *health.Checker,
 ) (*Server, error) {
 	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create token maker: %w", err)
 	}
 
+	emailTemplates, err := templates.New(config.EmailSenderName, config.EmailTemplateDir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load email templates: %w", err)
+	}
+
 	return &Server{
 		config:          config,
 		store:           store,
 		enforcer:        enforcer,
 		tokenMaker:      tokenMaker,
 		taskDistributor: taskDistributor,
+		taskInspector:   taskInspector,
+		emailTemplates:  emailTemplates,
+		eventBroker:     eventBroker,
+		healthChecker:   healthChecker,
 	}, nil
 }
 
@@ -54,26 +76,45 @@ func (server *Server) SetupRouter() {
 	}
 
 	router := gin.New()
+	// Handlers pass the gin context to the store and the task distributors;
+	// fall back to the request context so they see its deadline and span.
+	router.ContextWithFallback = true
+
+	// Probes are registered before the middleware, so that the polling of
+	// the kubelet is neither traced, logged nor counted.
+	router.GET("/livez", gin.WrapH(server.healthChecker.LiveHandler()))
+	router.GET("/readyz", gin.WrapH(server.healthChecker.ReadyHandler()))
 
 	corsCfg := cors.Config{
 		AllowOrigins:     server.config.AllowedOrigins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", idempotencyKeyHeader, lastEventIDHeader, logging.RequestIDHeader},
+		ExposeHeaders:    []string{"Content-Length", traceIDHeader, logging.RequestIDHeader, idempotentReplayHeader},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}
 
 	// CORS middleware
 	router.Use(
-		gin.Recovery(),
+		otelgin.Middleware(tracing.ServiceAPI, otelgin.WithGinFilter(func(c *gin.Context) bool {
+			// A span as long as an event stream would never be useful.
+			return c.FullPath() != accountEventStreamPath
+		})),
+		traceMiddleware(),
+		requestIDMiddleware(),
+		gin.CustomRecovery(func(ctx *gin.Context, recovered any) {
+			abortWithError(ctx, fmt.Errorf("panic: %v", recovered))
+		}),
 		HttpLogger(),
+		metricsMiddleware(),
 		cors.New(corsCfg),
-		timeoutMiddleware(requestTimeout),
+		// Event streams stay open for as long as the client is connected.
+		timeoutMiddleware(requestTimeout, accountEventStreamPath),
 	)
 
 	if server.config.Environment == "develop" {
 		router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+		router.GET("/dev/emails/:name", server.previewEmail)
 	}
 
 	apiRoutes := router.Group("/api/v1")
@@ -84,15 +125,147 @@ func (server *Server) SetupRouter() {
 		apiRoutes.POST("/tokens/renew-access", server.renewAccessToken)
 		apiRoutes.GET("/users/verify-email", server.verifyEmail)
 
-		authRoutes := apiRoutes.Group("", authMiddleware(server.tokenMaker))
+		authRoutes := apiRoutes.Group("", authMiddleware(server.tokenMaker, server.store))
 		authRoutes.PATCH(
 			"/users/:username",
 			server.Require("users:update"),
 			server.updateUser,
 		)
+		authRoutes.POST(
+			"/users/:username/verify-email/resend",
+			server.Require("users:resend_verification"),
+			server.resendVerifyEmail,
+		)
+		authRoutes.PUT(
+			"/users/:username/phone",
+			server.Require("users:phone"),
+			server.updateUserPhone,
+		)
+		authRoutes.POST(
+			"/users/:username/phone/verify",
+			server.Require("users:phone"),
+			server.verifyUserPhone,
+		)
+		authRoutes.GET(
+			"/users",
+			server.Require("users:list"),
+			server.listUsers,
+		)
+		authRoutes.GET(
+			"/users/:username/accounts",
+			server.Require("users:accounts"),
+			server.listUserAccounts,
+		)
+		authRoutes.PUT(
+			"/users/:username/role",
+			server.Require("users:update_role"),
+			server.updateUserRole,
+		)
+		authRoutes.POST(
+			"/users/:username/disable",
+			server.Require("users:disable"),
+			server.disableUser,
+		)
+		authRoutes.POST(
+			"/users/:username/enable",
+			server.Require("users:disable"),
+			server.enableUser,
+		)
+		authRoutes.GET(
+			"/users/:username/notification-preferences",
+			server.Require("notifications:manage"),
+			server.listNotificationPreferences,
+		)
+		authRoutes.PUT(
+			"/users/:username/notification-preferences",
+			server.Require("notifications:manage"),
+			server.updateNotificationPreference,
+		)
+		authRoutes.POST(
+			"/tenants",
+			server.Require("tenants:create"),
+			server.createTenant,
+		)
+		authRoutes.POST(
+			"/policies/explain",
+			server.Require("policies:explain"),
+			server.explainPolicy,
+		)
+		authRoutes.GET(
+			"/policies/users/:username",
+			server.Require("policies:explain"),
+			server.listUserPermissions,
+		)
+		authRoutes.POST(
+			"/webhooks",
+			server.Require("webhooks:manage"),
+			server.createWebhookSubscription,
+		)
+		authRoutes.GET(
+			"/webhooks",
+			server.Require("webhooks:manage"),
+			server.listWebhookSubscriptions,
+		)
+		authRoutes.GET(
+			"/webhooks/:id",
+			server.Require("webhooks:manage"),
+			server.getWebhookSubscription,
+		)
+		authRoutes.PATCH(
+			"/webhooks/:id",
+			server.Require("webhooks:manage"),
+			server.updateWebhookSubscription,
+		)
+		authRoutes.DELETE(
+			"/webhooks/:id",
+			server.Require("webhooks:manage"),
+			server.deleteWebhookSubscription,
+		)
+		authRoutes.GET(
+			"/webhooks/:id/deliveries",
+			server.Require("webhooks:manage"),
+			server.listWebhookDeliveries,
+		)
+		authRoutes.POST(
+			"/webhooks/:id/deliveries/:delivery_id/replay",
+			server.Require("webhooks:manage"),
+			server.replayWebhookDelivery,
+		)
+		authRoutes.GET(
+			"/audit-events",
+			server.Require("audit:read"),
+			server.listAuditEvents,
+		)
+		authRoutes.GET(
+			"/queues",
+			server.Require("tasks:read"),
+			server.listTaskQueues,
+		)
+		authRoutes.GET(
+			"/queues/:queue/tasks",
+			server.Require("tasks:read"),
+			server.listTasks,
+		)
+		authRoutes.GET(
+			"/queues/:queue/tasks/:id",
+			server.Require("tasks:read"),
+			server.getTask,
+		)
+		authRoutes.POST(
+			"/queues/:queue/tasks/:id/run",
+			server.Require("tasks:manage"),
+			server.runTask,
+		)
+		authRoutes.DELETE(
+			"/queues/:queue/tasks/:id",
+			server.Require("tasks:manage"),
+			server.deleteTask,
+		)
 		authRoutes.POST(
 			"/accounts",
 			server.Require("accounts:create"),
+			server.RequireVerifiedEmail(),
+			server.Idempotent(),
 			server.createAccount,
 		)
 		authRoutes.GET(
@@ -100,14 +273,36 @@ func (server *Server) SetupRouter() {
 			server.Require("accounts:read"),
 			server.getAccount,
 		)
+		authRoutes.POST(
+			"/accounts/:id/freeze",
+			server.Require("accounts:freeze"),
+			server.freezeAccount,
+		)
+		authRoutes.POST(
+			"/accounts/:id/unfreeze",
+			server.Require("accounts:freeze"),
+			server.unfreezeAccount,
+		)
+		authRoutes.POST(
+			"/accounts/:id/close",
+			server.Require("accounts:close"),
+			server.closeAccount,
+		)
 		authRoutes.GET(
 			"/accounts",
 			server.Require("accounts:list"),
 			server.listAccounts,
 		)
+		authRoutes.GET(
+			"/events",
+			server.Require("accounts:read"),
+			server.streamAccountEvents,
+		)
 		authRoutes.POST(
 			"/transfers",
 			server.Require("transfers:create"),
+			server.RequireVerifiedEmail(),
+			server.Idempotent(),
 			server.createTransfer,
 		)
 	}
@@ -123,16 +318,8 @@ func (server *Server) Start(address string) error {
 	return server.router.Run(address)
 }
 
+// handleHealthCheck only tells that the server answers, like /livez. It is
+// kept for the load balancers that still probe it; use /readyz to route traffic.
 func (server *Server) handleHealthCheck(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "ok"})
 }
-
-// ErrorResponse defines the standard error response for the API.
-type ErrorResponse struct {
-	Error      string           `json:"error"`
-	Violations []FieldViolation `json:"violations,omitempty"`
-}
-
-func errorResponse(err error) gin.H {
-	return gin.H{"error": err.Error()}
-}
@@ -0,0 +1,274 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/LamThanhNguyen/banking-system/worker"
+	"github.com/gin-gonic/gin"
+	"github.com/hibiken/asynq"
+)
+
+type queueResponse struct {
+	Queue          string `json:"queue"`
+	Size           int    `json:"size"`
+	Pending        int    `json:"pending"`
+	Active         int    `json:"active"`
+	Scheduled      int    `json:"scheduled"`
+	Retry          int    `json:"retry"`
+	Archived       int    `json:"archived"`
+	Completed      int    `json:"completed"`
+	ProcessedToday int    `json:"processed_today"`
+	FailedToday    int    `json:"failed_today"`
+	ProcessedTotal int    `json:"processed_total"`
+	FailedTotal    int    `json:"failed_total"`
+	LatencyMs      int64  `json:"latency_ms"`
+	Paused         bool   `json:"paused"`
+}
+
+func newQueueResponse(info *asynq.QueueInfo) queueResponse {
+	return queueResponse{
+		Queue:          info.Queue,
+		Size:           info.Size,
+		Pending:        info.Pending,
+		Active:         info.Active,
+		Scheduled:      info.Scheduled,
+		Retry:          info.Retry,
+		Archived:       info.Archived,
+		Completed:      info.Completed,
+		ProcessedToday: info.Processed,
+		FailedToday:    info.Failed,
+		ProcessedTotal: info.ProcessedTotal,
+		FailedTotal:    info.FailedTotal,
+		LatencyMs:      info.Latency.Milliseconds(),
+		Paused:         info.Paused,
+	}
+}
+
+type taskResponse struct {
+	ID            string          `json:"id"`
+	Queue         string          `json:"queue"`
+	Type          string          `json:"type"`
+	Payload       json.RawMessage `json:"payload" swaggertype:"object"`
+	State         string          `json:"state"`
+	MaxRetry      int             `json:"max_retry"`
+	Retried       int             `json:"retried"`
+	LastError     string          `json:"last_error,omitempty"`
+	LastFailedAt  *time.Time      `json:"last_failed_at,omitempty"`
+	NextProcessAt *time.Time      `json:"next_process_at,omitempty"`
+}
+
+func newTaskResponse(info *asynq.TaskInfo) taskResponse {
+	rsp := taskResponse{
+		ID:        info.ID,
+		Queue:     info.Queue,
+		Type:      info.Type,
+		Payload:   info.Payload,
+		State:     info.State.String(),
+		MaxRetry:  info.MaxRetry,
+		Retried:   info.Retried,
+		LastError: info.LastErr,
+	}
+	if !json.Valid(rsp.Payload) {
+		rsp.Payload, _ = json.Marshal(string(info.Payload))
+	}
+	if !info.LastFailedAt.IsZero() {
+		rsp.LastFailedAt = &info.LastFailedAt
+	}
+	if !info.NextProcessAt.IsZero() {
+		rsp.NextProcessAt = &info.NextProcessAt
+	}
+	return rsp
+}
+
+// @Summary      List task queues
+// @Description  List the background task queues with their depth per state and failure counts.
+// @Tags         tasks
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {array}   queueResponse
+// @Failure      403  {object}  api.Problem "FORBIDDEN"
+// @Failure      500  {object}  api.Problem "INTERNAL_ERROR"
+// @Router       /api/v1/queues [get]
+func (server *Server) listTaskQueues(ctx *gin.Context) {
+	queues, err := server.taskInspector.ListQueues()
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	rsp := make([]queueResponse, len(queues))
+	for i, info := range queues {
+		rsp[i] = newQueueResponse(info)
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type queueRequest struct {
+	Queue string `uri:"queue" binding:"required"`
+}
+
+type listTasksRequest struct {
+	State    string `form:"state" binding:"required,oneof=pending active scheduled retry archived completed"`
+	PageID   int    `form:"page_id" binding:"required,min=1"`
+	PageSize int    `form:"page_size" binding:"required,min=5,max=50"`
+}
+
+// @Summary      List tasks
+// @Description  List the tasks of a queue in one state. Tasks that exhausted their retries are in the archived state.
+// @Tags         tasks
+// @Security     BearerAuth
+// @Produce      json
+// @Param        queue     path      string  true  "Queue name"
+// @Param        state     query     string  true  "Task state (pending, active, scheduled, retry, archived, completed)"
+// @Param        page_id   query     int     true  "Page number (min 1)"
+// @Param        page_size query     int     true  "Page size (min 5, max 50)"
+// @Success      200       {array}   taskResponse
+// @Failure      400       {object}  api.Problem "INVALID_REQUEST or VALIDATION_FAILED"
+// @Failure      403       {object}  api.Problem "FORBIDDEN"
+// @Failure      404       {object}  api.Problem "NOT_FOUND: queue"
+// @Failure      500       {object}  api.Problem "INTERNAL_ERROR"
+// @Router       /api/v1/queues/{queue}/tasks [get]
+func (server *Server) listTasks(ctx *gin.Context) {
+	var reqPath queueRequest
+	if err := ctx.ShouldBindUri(&reqPath); err != nil {
+		abortWithError(ctx, invalidRequest(err))
+		return
+	}
+
+	var req listTasksRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		abortWithError(ctx, invalidRequest(err))
+		return
+	}
+
+	state, err := worker.ParseTaskState(req.State)
+	if err != nil {
+		abortWithError(ctx, invalidRequest(err))
+		return
+	}
+
+	tasks, err := server.taskInspector.ListTasks(reqPath.Queue, state, req.PageID, req.PageSize)
+	if err != nil {
+		taskErrorResponse(ctx, err)
+		return
+	}
+
+	rsp := make([]taskResponse, len(tasks))
+	for i, info := range tasks {
+		rsp[i] = newTaskResponse(info)
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type taskRequest struct {
+	Queue string `uri:"queue" binding:"required"`
+	ID    string `uri:"id" binding:"required"`
+}
+
+// @Summary      Get task
+// @Description  Get a task with its retry count and last error.
+// @Tags         tasks
+// @Security     BearerAuth
+// @Produce      json
+// @Param        queue  path      string  true  "Queue name"
+// @Param        id     path      string  true  "Task ID"
+// @Success      200    {object}  taskResponse
+// @Failure      403    {object}  api.Problem "FORBIDDEN"
+// @Failure      404    {object}  api.Problem "NOT_FOUND: task"
+// @Failure      500    {object}  api.Problem "INTERNAL_ERROR"
+// @Router       /api/v1/queues/{queue}/tasks/{id} [get]
+func (server *Server) getTask(ctx *gin.Context) {
+	var req taskRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		abortWithError(ctx, invalidRequest(err))
+		return
+	}
+
+	info, err := server.taskInspector.GetTask(req.Queue, req.ID)
+	if err != nil {
+		taskErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newTaskResponse(info))
+}
+
+// @Summary      Run task
+// @Description  Move a scheduled, retry or archived task back to pending so that it runs immediately.
+// @Tags         tasks
+// @Security     BearerAuth
+// @Produce      json
+// @Param        queue  path      string  true  "Queue name"
+// @Param        id     path      string  true  "Task ID"
+// @Success      200    {object}  taskResponse
+// @Failure      403    {object}  api.Problem "FORBIDDEN"
+// @Failure      404    {object}  api.Problem "NOT_FOUND: task"
+// @Failure      409    {object}  api.Problem "CONFLICT: task is already pending or active"
+// @Failure      500    {object}  api.Problem "INTERNAL_ERROR"
+// @Router       /api/v1/queues/{queue}/tasks/{id}/run [post]
+func (server *Server) runTask(ctx *gin.Context) {
+	var req taskRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		abortWithError(ctx, invalidRequest(err))
+		return
+	}
+
+	info, err := server.taskInspector.GetTask(req.Queue, req.ID)
+	if err != nil {
+		taskErrorResponse(ctx, err)
+		return
+	}
+
+	if info.State == asynq.TaskStatePending || info.State == asynq.TaskStateActive {
+		abortWithError(ctx, newError(CodeConflict, "task is already %s", info.State))
+		return
+	}
+
+	if err := server.taskInspector.RunTask(req.Queue, req.ID); err != nil {
+		taskErrorResponse(ctx, err)
+		return
+	}
+
+	info.State = asynq.TaskStatePending
+	ctx.JSON(http.StatusOK, newTaskResponse(info))
+}
+
+// @Summary      Delete task
+// @Description  Delete a task that is not currently running.
+// @Tags         tasks
+// @Security     BearerAuth
+// @Param        queue  path      string  true  "Queue name"
+// @Param        id     path      string  true  "Task ID"
+// @Success      204
+// @Failure      403    {object}  api.Problem "FORBIDDEN"
+// @Failure      404    {object}  api.Problem "NOT_FOUND: task"
+// @Failure      500    {object}  api.Problem "INTERNAL_ERROR"
+// @Router       /api/v1/queues/{queue}/tasks/{id} [delete]
+func (server *Server) deleteTask(ctx *gin.Context) {
+	var req taskRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		abortWithError(ctx, invalidRequest(err))
+		return
+	}
+
+	if err := server.taskInspector.DeleteTask(req.Queue, req.ID); err != nil {
+		taskErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func taskErrorResponse(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, asynq.ErrQueueNotFound):
+		abortWithError(ctx, newError(CodeNotFound, "queue not found"))
+	case errors.Is(err, asynq.ErrTaskNotFound):
+		abortWithError(ctx, newError(CodeNotFound, "task not found"))
+	default:
+		abortWithError(ctx, err)
+	}
+}
@@ -0,0 +1,399 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/LamThanhNguyen/banking-system/token"
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/LamThanhNguyen/banking-system/worker"
+	mockwk "github.com/LamThanhNguyen/banking-system/worker/mock"
+	"github.com/casbin/casbin/v2"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func randomArchivedTask() *asynq.TaskInfo {
+	return &asynq.TaskInfo{
+		ID:           "task-1",
+		Queue:        worker.QueueCritical,
+		Type:         worker.TaskSendVerifyEmail,
+		Payload:      []byte(`{"username":"alice"}`),
+		State:        asynq.TaskStateArchived,
+		MaxRetry:     10,
+		Retried:      10,
+		LastErr:      "failed to send verify email: dial tcp: i/o timeout",
+		LastFailedAt: time.Now().Truncate(time.Second),
+	}
+}
+
+func TestListTaskQueuesAPI(t *testing.T) {
+	banker, _ := randomBankerUser(t)
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(inspector *mockwk.MockTaskInspector)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(inspector *mockwk.MockTaskInspector) {
+				inspector.EXPECT().
+					ListQueues().
+					Times(1).
+					Return([]*asynq.QueueInfo{
+						{Queue: worker.QueueCritical, Size: 3, Archived: 2, Retry: 1, FailedTotal: 7},
+					}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp []queueResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Len(t, rsp, 1)
+				require.Equal(t, worker.QueueCritical, rsp[0].Queue)
+				require.Equal(t, 2, rsp[0].Archived)
+				require.Equal(t, 7, rsp[0].FailedTotal)
+			},
+		},
+		{
+			name: "InternalError",
+			buildStubs: func(inspector *mockwk.MockTaskInspector) {
+				inspector.EXPECT().
+					ListQueues().
+					Times(1).
+					Return(nil, errors.New("redis unavailable"))
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			inspector := mockwk.NewMockTaskInspector(ctrl)
+			tc.buildStubs(inspector)
+
+			server := newTestServer(t, nil, nil, nil)
+			server.taskInspector = inspector
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/api/v1/queues", nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, banker.Username, banker.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestTaskAdminTenantScope(t *testing.T) {
+	banker, _ := randomBankerUser(t)
+
+	// Task permissions are seeded for the bankers of the default tenant only.
+	enforcer, err := casbin.NewEnforcer("../model.conf")
+	require.NoError(t, err)
+	for _, act := range []string{"tasks:read", "tasks:manage"} {
+		_, err = enforcer.AddPolicy(util.BankerRole, util.DefaultTenant, "*", act)
+		require.NoError(t, err)
+	}
+
+	testCases := []struct {
+		name       string
+		tenantID   string
+		buildStubs func(inspector *mockwk.MockTaskInspector)
+		status     int
+	}{
+		{
+			name:     "DefaultTenant",
+			tenantID: util.DefaultTenant,
+			buildStubs: func(inspector *mockwk.MockTaskInspector) {
+				inspector.EXPECT().
+					ListQueues().
+					Times(1).
+					Return(nil, nil)
+			},
+			status: http.StatusOK,
+		},
+		{
+			name:     "PartnerTenant",
+			tenantID: "partner-bank",
+			buildStubs: func(inspector *mockwk.MockTaskInspector) {
+				inspector.EXPECT().
+					ListQueues().
+					Times(0)
+			},
+			status: http.StatusForbidden,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			inspector := mockwk.NewMockTaskInspector(ctrl)
+			tc.buildStubs(inspector)
+
+			server := newTestServer(t, nil, enforcer, nil)
+			server.taskInspector = inspector
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/api/v1/queues", nil)
+			require.NoError(t, err)
+
+			accessToken, _, err := server.tokenMaker.CreateToken(banker.Username, banker.Role, tc.tenantID, time.Minute, token.TokenTypeAccessToken)
+			require.NoError(t, err)
+			request.Header.Set(authorizationHeaderKey, authorizationTypeBearer+" "+accessToken)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.status, recorder.Code)
+		})
+	}
+}
+
+func TestListTasksAPI(t *testing.T) {
+	banker, _ := randomBankerUser(t)
+	task := randomArchivedTask()
+
+	testCases := []struct {
+		name          string
+		query         string
+		buildStubs    func(inspector *mockwk.MockTaskInspector)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: "state=archived&page_id=2&page_size=10",
+			buildStubs: func(inspector *mockwk.MockTaskInspector) {
+				inspector.EXPECT().
+					ListTasks(gomock.Eq(worker.QueueCritical), gomock.Eq(asynq.TaskStateArchived), gomock.Eq(2), gomock.Eq(10)).
+					Times(1).
+					Return([]*asynq.TaskInfo{task}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp []taskResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Len(t, rsp, 1)
+				require.Equal(t, task.ID, rsp[0].ID)
+				require.Equal(t, "archived", rsp[0].State)
+				require.Equal(t, task.LastErr, rsp[0].LastError)
+				require.JSONEq(t, string(task.Payload), string(rsp[0].Payload))
+				require.NotNil(t, rsp[0].LastFailedAt)
+			},
+		},
+		{
+			name:  "InvalidState",
+			query: "state=dead&page_id=1&page_size=10",
+			buildStubs: func(inspector *mockwk.MockTaskInspector) {
+				inspector.EXPECT().
+					ListTasks(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "QueueNotFound",
+			query: "state=retry&page_id=1&page_size=10",
+			buildStubs: func(inspector *mockwk.MockTaskInspector) {
+				inspector.EXPECT().
+					ListTasks(gomock.Any(), gomock.Eq(asynq.TaskStateRetry), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, fmt.Errorf("asynq: %w", asynq.ErrQueueNotFound))
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			inspector := mockwk.NewMockTaskInspector(ctrl)
+			tc.buildStubs(inspector)
+
+			server := newTestServer(t, nil, nil, nil)
+			server.taskInspector = inspector
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/api/v1/queues/%s/tasks?%s", worker.QueueCritical, tc.query)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, banker.Username, banker.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestRunTaskAPI(t *testing.T) {
+	banker, _ := randomBankerUser(t)
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(inspector *mockwk.MockTaskInspector)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(inspector *mockwk.MockTaskInspector) {
+				task := randomArchivedTask()
+				inspector.EXPECT().
+					GetTask(gomock.Eq(task.Queue), gomock.Eq(task.ID)).
+					Times(1).
+					Return(task, nil)
+				inspector.EXPECT().
+					RunTask(gomock.Eq(task.Queue), gomock.Eq(task.ID)).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp taskResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, "pending", rsp.State)
+			},
+		},
+		{
+			name: "AlreadyPending",
+			buildStubs: func(inspector *mockwk.MockTaskInspector) {
+				task := randomArchivedTask()
+				task.State = asynq.TaskStatePending
+				inspector.EXPECT().
+					GetTask(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(task, nil)
+				inspector.EXPECT().
+					RunTask(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			buildStubs: func(inspector *mockwk.MockTaskInspector) {
+				inspector.EXPECT().
+					GetTask(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, fmt.Errorf("asynq: %w", asynq.ErrTaskNotFound))
+				inspector.EXPECT().
+					RunTask(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			inspector := mockwk.NewMockTaskInspector(ctrl)
+			tc.buildStubs(inspector)
+
+			server := newTestServer(t, nil, nil, nil)
+			server.taskInspector = inspector
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/api/v1/queues/%s/tasks/%s/run", worker.QueueCritical, "task-1")
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, banker.Username, banker.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestDeleteTaskAPI(t *testing.T) {
+	banker, _ := randomBankerUser(t)
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(inspector *mockwk.MockTaskInspector)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(inspector *mockwk.MockTaskInspector) {
+				inspector.EXPECT().
+					DeleteTask(gomock.Eq(worker.QueueCritical), gomock.Eq("task-1")).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			buildStubs: func(inspector *mockwk.MockTaskInspector) {
+				inspector.EXPECT().
+					DeleteTask(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(fmt.Errorf("asynq: %w", asynq.ErrTaskNotFound))
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			inspector := mockwk.NewMockTaskInspector(ctrl)
+			tc.buildStubs(inspector)
+
+			server := newTestServer(t, nil, nil, nil)
+			server.taskInspector = inspector
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/api/v1/queues/%s/tasks/%s", worker.QueueCritical, "task-1")
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, banker.Username, banker.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/gin-gonic/gin"
+)
+
+type tenantAdminRequest struct {
+	Username string `json:"username" binding:"required,username"`
+	Password string `json:"password" binding:"required,min=8,max=50"`
+	FullName string `json:"full_name" binding:"required,fullname"`
+	Email    string `json:"email" binding:"required,email,max=50"`
+}
+
+type createTenantRequest struct {
+	ID                        string             `json:"id" binding:"required,tenant_id"`
+	Name                      string             `json:"name" binding:"required,max=100"`
+	AllowCrossTenantTransfers bool               `json:"allow_cross_tenant_transfers"`
+	Admin                     tenantAdminRequest `json:"admin" binding:"required"`
+}
+
+type tenantResponse struct {
+	ID                        string       `json:"id"`
+	Name                      string       `json:"name"`
+	AllowCrossTenantTransfers bool         `json:"allow_cross_tenant_transfers"`
+	CreatedAt                 time.Time    `json:"created_at"`
+	Admin                     userResponse `json:"admin"`
+}
+
+// @Summary      Provision tenant
+// @Description  Create a new tenant (partner bank) together with its first banker. Only bankers of the default tenant may provision tenants.
+// @Tags         tenants
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        body  body      createTenantRequest  true  "Tenant info"
+// @Success      201   {object}  tenantResponse
+// @Failure      400   {object}  api.Problem "INVALID_REQUEST or VALIDATION_FAILED"
+// @Failure      403   {object}  api.Problem "FORBIDDEN"
+// @Failure      409   {object}  api.Problem "ALREADY_EXISTS: tenant, username or email"
+// @Failure      500   {object}  api.Problem "INTERNAL_ERROR"
+// @Router       /api/v1/tenants [post]
+func (server *Server) createTenant(ctx *gin.Context) {
+	var req createTenantRequest
+	if !bindAndValidateJsonBody(ctx, &req) {
+		return
+	}
+
+	hashedPassword, err := util.HashPassword(req.Admin.Password)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	arg := db.CreateTenantTxParams{
+		CreateTenantParams: db.CreateTenantParams{
+			ID:                        req.ID,
+			Name:                      req.Name,
+			AllowCrossTenantTransfers: req.AllowCrossTenantTransfers,
+		},
+		Admin: db.CreateUserParams{
+			Username:       req.Admin.Username,
+			HashedPassword: hashedPassword,
+			FullName:       req.Admin.FullName,
+			Email:          req.Admin.Email,
+		},
+		AdminRole: util.BankerRole,
+	}
+
+	result, err := server.store.CreateTenantTx(ctx, arg)
+	if err != nil {
+		if db.ErrorCode(err) == db.UniqueViolation {
+			err = newError(CodeAlreadyExists, "tenant or admin user already exists")
+		}
+		abortWithError(ctx, err)
+		return
+	}
+
+	rsp := tenantResponse{
+		ID:                        result.Tenant.ID,
+		Name:                      result.Tenant.Name,
+		AllowCrossTenantTransfers: result.Tenant.AllowCrossTenantTransfers,
+		CreatedAt:                 result.Tenant.CreatedAt,
+		Admin:                     newUserResponse(result.Admin),
+	}
+	ctx.JSON(http.StatusCreated, rsp)
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/LamThanhNguyen/banking-system/db/mock"
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+type eqCreateTenantTxParamsMatcher struct {
+	arg      db.CreateTenantTxParams
+	password string
+}
+
+func (e eqCreateTenantTxParamsMatcher) Matches(x interface{}) bool {
+	txArg, ok := x.(db.CreateTenantTxParams)
+	if !ok {
+		return false
+	}
+
+	if err := util.CheckPassword(e.password, txArg.Admin.HashedPassword); err != nil {
+		return false
+	}
+	txArg.Admin.HashedPassword = ""
+
+	return e.arg == txArg
+}
+
+func (e eqCreateTenantTxParamsMatcher) String() string {
+	return fmt.Sprintf("matches arg %v and password %v", e.arg, e.password)
+}
+
+func TestCreateTenantAPI(t *testing.T) {
+	banker, _ := randomBankerUser(t)
+	admin, password := randomBankerUser(t)
+	admin.TenantID = "partner-bank"
+
+	tenant := db.Tenant{
+		ID:                        admin.TenantID,
+		Name:                      "Partner Bank",
+		AllowCrossTenantTransfers: true,
+	}
+
+	body := gin.H{
+		"id":                           tenant.ID,
+		"name":                         tenant.Name,
+		"allow_cross_tenant_transfers": tenant.AllowCrossTenantTransfers,
+		"admin": gin.H{
+			"username":  admin.Username,
+			"password":  password,
+			"full_name": admin.FullName,
+			"email":     admin.Email,
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateTenantTxParams{
+					CreateTenantParams: db.CreateTenantParams{
+						ID:                        tenant.ID,
+						Name:                      tenant.Name,
+						AllowCrossTenantTransfers: tenant.AllowCrossTenantTransfers,
+					},
+					Admin: db.CreateUserParams{
+						Username: admin.Username,
+						FullName: admin.FullName,
+						Email:    admin.Email,
+					},
+					AdminRole: util.BankerRole,
+				}
+				store.EXPECT().
+					CreateTenantTx(gomock.Any(), eqCreateTenantTxParamsMatcher{arg, password}).
+					Times(1).
+					Return(db.CreateTenantTxResult{Tenant: tenant, Admin: admin}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var got tenantResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, tenant.ID, got.ID)
+				require.Equal(t, admin.Username, got.Admin.Username)
+				require.Equal(t, tenant.ID, got.Admin.TenantID)
+			},
+		},
+		{
+			name: "InvalidTenantID",
+			body: gin.H{
+				"id":    "Partner Bank",
+				"name":  tenant.Name,
+				"admin": body["admin"],
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateTenantTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "DuplicateTenant",
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateTenantTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CreateTenantTxResult{}, db.ErrUniqueViolation)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateTenantTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CreateTenantTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := "/api/v1/tenants"
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, banker.Username, banker.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
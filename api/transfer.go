@@ -2,12 +2,16 @@ package api
 
 import (
 	"errors"
-	"fmt"
 	"net/http"
+	"strconv"
 
 	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/metrics"
 	"github.com/LamThanhNguyen/banking-system/token"
+	"github.com/LamThanhNguyen/banking-system/worker"
 	"github.com/gin-gonic/gin"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
 )
 
 type transferRequest struct {
@@ -19,38 +23,63 @@ type transferRequest struct {
 
 // @Summary      Transfer funds
 // @Description  Transfer funds from one account to another. Only the owner of the source account can initiate a transfer.
+// @Description  Send an Idempotency-Key to retry safely: a retry with the same key and body returns the first response.
 // @Tags         transfers
 // @Security     BearerAuth
 // @Accept       json
 // @Produce      json
-// @Param        body  body      transferRequest  true  "Transfer details"
+// @Param        body             body      transferRequest  true   "Transfer details"
+// @Param        Idempotency-Key  header    string           false  "Key that makes retries of this request safe"
 // @Success      200   {object}  db.TransferTxResult
-// @Failure      400   {object}  api.ErrorResponse "Invalid request or currency mismatch"
-// @Failure      401   {object}  api.ErrorResponse "Unauthorized: from account doesn't belong to the user"
-// @Failure      404   {object}  api.ErrorResponse "Account not found"
-// @Failure      500   {object}  api.ErrorResponse "Internal server error"
+// @Failure      400   {object}  api.Problem "INVALID_REQUEST, VALIDATION_FAILED or CURRENCY_MISMATCH"
+// @Failure      401   {object}  api.Problem "UNAUTHENTICATED"
+// @Failure      403   {object}  api.Problem "FORBIDDEN, EMAIL_NOT_VERIFIED, ACCOUNT_NOT_OWNED, ACCOUNT_FROZEN or CROSS_TENANT_TRANSFER_DISABLED"
+// @Failure      404   {object}  api.Problem "ACCOUNT_NOT_FOUND"
+// @Failure      409   {object}  api.Problem "IDEMPOTENCY_KEY_IN_PROGRESS"
+// @Failure      422   {object}  api.Problem "INSUFFICIENT_FUNDS or IDEMPOTENCY_KEY_REUSED"
+// @Failure      500   {object}  api.Problem "INTERNAL_ERROR"
 // @Router       /api/v1/transfers [post]
 func (server *Server) createTransfer(ctx *gin.Context) {
 	var req transferRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		abortWithError(ctx, invalidRequest(err))
 		return
 	}
 
-	fromAccount, valid := server.validAccount(ctx, req.FromAccountID, req.Currency)
-	if !valid {
-		return
+	transfer := map[string]any{
+		"to_account_id": req.ToAccountID,
+		"amount":        req.Amount,
+		"currency":      req.Currency,
+	}
+	audit := db.AppendAuditEventTxParams{
+		Action:     db.AuditActionTransferCreate,
+		TargetType: db.AuditTargetAccount,
+		TargetID:   strconv.FormatInt(req.FromAccountID, 10),
+		After:      transfer,
 	}
+	defer server.recordAudit(ctx, &audit)
 
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	fromAccount, err := server.validAccount(ctx, req.FromAccountID, authPayload.TenantID, req.Currency)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
 	if fromAccount.Owner != authPayload.Username {
-		err := errors.New("from account doesn't belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		abortWithError(ctx, newError(CodeAccountNotOwned, "from account doesn't belong to the authenticated user"))
 		return
 	}
 
-	_, valid = server.validAccount(ctx, req.ToAccountID, req.Currency)
-	if !valid {
+	toTenantID, err := server.recipientTenant(ctx, req.ToAccountID, authPayload.TenantID)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	if _, err := server.validAccount(ctx, req.ToAccountID, toTenantID, req.Currency); err != nil {
+		abortWithError(ctx, err)
 		return
 	}
 
@@ -58,33 +87,104 @@ func (server *Server) createTransfer(ctx *gin.Context) {
 		FromAccountID: req.FromAccountID,
 		ToAccountID:   req.ToAccountID,
 		Amount:        req.Amount,
+		TenantID:      authPayload.TenantID,
+		AfterTransfer: func(q db.Querier, result db.TransferTxResult) error {
+			err := worker.DistributeTransferNotifications(ctx, worker.NewOutboxTaskDistributor(q), result, req.Currency)
+			if err != nil {
+				return err
+			}
+			err = worker.DispatchTransferWebhooks(ctx, q, result, req.Currency)
+			if err != nil {
+				return err
+			}
+
+			transfer["transfer_id"] = result.Transfer.ID
+			return appendAudit(ctx, q, &audit)
+		},
 	}
 
 	result, err := server.store.TransferTx(ctx, arg)
+	metrics.ObserveTransfer(req.Currency, req.Amount, err)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		server.notifyTransferFailed(ctx, authPayload.Username, req)
+		if errors.Is(err, db.ErrInsufficientFunds) {
+			err = newError(CodeInsufficientFunds, "account [%d] has insufficient funds", req.FromAccountID)
+		}
+		abortWithError(ctx, err)
 		return
 	}
 
 	ctx.JSON(http.StatusOK, result)
 }
 
-func (server *Server) validAccount(ctx *gin.Context, accountID int64, currency string) (db.Account, bool) {
-	account, err := server.store.GetAccount(ctx, accountID)
+// notifyTransferFailed tells the sender that a transfer did not go through.
+// The transaction was rolled back, so the task is enqueued directly and a failure is only logged.
+func (server *Server) notifyTransferFailed(ctx *gin.Context, username string, req transferRequest) {
+	payload := &worker.PayloadSendTransferFailedNotification{
+		Username:      username,
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Currency:      req.Currency,
+	}
+
+	err := server.taskDistributor.DistributeTaskSendTransferFailedNotification(ctx, payload, asynq.MaxRetry(10))
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to distribute transfer failed notification")
+	}
+}
+
+func (server *Server) validAccount(ctx *gin.Context, accountID int64, tenantID string, currency string) (db.Account, error) {
+	account, err := server.store.GetAccount(ctx, db.GetAccountParams{
+		ID:       accountID,
+		TenantID: tenantID,
+	})
 	if err != nil {
 		if errors.Is(err, db.ErrRecordNotFound) {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return account, false
+			return account, newError(CodeAccountNotFound, "account [%d] not found", accountID)
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return account, false
+		return account, err
 	}
 
 	if account.Currency != currency {
-		err := fmt.Errorf("account [%d] currency mismatch: %s vs %s", accountID, account.Currency, currency)
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return account, false
+		return account, newError(CodeCurrencyMismatch, "account [%d] currency mismatch: %s vs %s", accountID, account.Currency, currency)
+	}
+
+	switch account.Status {
+	case db.AccountStatusFrozen:
+		return account, newError(CodeAccountFrozen, "account [%d] is frozen", accountID)
+	case db.AccountStatusClosed:
+		return account, newError(CodeAccountClosed, "account [%d] is closed", accountID)
+	}
+
+	return account, nil
+}
+
+// recipientTenant returns the tenant of the destination account. Transfers to another
+// tenant are only allowed when both tenants have enabled cross-tenant transfers.
+func (server *Server) recipientTenant(ctx *gin.Context, accountID int64, tenantID string) (string, error) {
+	toTenantID, err := server.store.GetAccountTenant(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return "", newError(CodeAccountNotFound, "account [%d] not found", accountID)
+		}
+		return "", err
+	}
+
+	if toTenantID == tenantID {
+		return toTenantID, nil
+	}
+
+	for _, id := range []string{tenantID, toTenantID} {
+		tenant, err := server.store.GetTenant(ctx, id)
+		if err != nil {
+			return "", err
+		}
+
+		if !tenant.AllowCrossTenantTransfers {
+			return "", newError(CodeCrossTenantTransferDisabled, "cross-tenant transfers are not enabled for tenant %s", tenant.ID)
+		}
 	}
 
-	return account, true
+	return toTenantID, nil
 }
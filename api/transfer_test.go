@@ -2,10 +2,14 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strconv"
 	"testing"
 	"time"
 
@@ -13,11 +17,35 @@ import (
 	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
 	"github.com/LamThanhNguyen/banking-system/token"
 	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/LamThanhNguyen/banking-system/worker"
+	mockwk "github.com/LamThanhNguyen/banking-system/worker/mock"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 )
 
+type eqTransferTxParamsMatcher struct {
+	arg db.TransferTxParams
+}
+
+func (e eqTransferTxParamsMatcher) Matches(x interface{}) bool {
+	txArg, ok := x.(db.TransferTxParams)
+	if !ok || txArg.AfterTransfer == nil {
+		return false
+	}
+	txArg.AfterTransfer = nil
+
+	return reflect.DeepEqual(e.arg, txArg)
+}
+
+func (e eqTransferTxParamsMatcher) String() string {
+	return fmt.Sprintf("matches arg %v with an AfterTransfer hook", e.arg)
+}
+
+func EqTransferTxParams(arg db.TransferTxParams) gomock.Matcher {
+	return eqTransferTxParamsMatcher{arg}
+}
+
 func TestTransferAPI(t *testing.T) {
 	amount := int64(10)
 
@@ -37,7 +65,7 @@ func TestTransferAPI(t *testing.T) {
 		name          string
 		body          gin.H
 		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
-		buildStubs    func(store *mockdb.MockStore)
+		buildStubs    func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor)
 		checkResponse func(recorder *httptest.ResponseRecorder)
 	}{
 		{
@@ -51,13 +79,17 @@ func TestTransferAPI(t *testing.T) {
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, user1.Role, time.Minute)
 			},
-			buildStubs: func(store *mockdb.MockStore) {
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(account1.ID)).
+					GetAccount(gomock.Any(), gomock.Eq(db.GetAccountParams{ID: account1.ID, TenantID: util.DefaultTenant})).
 					Times(1).
 					Return(account1, nil)
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(account2.ID)).
+					GetAccountTenant(gomock.Any(), gomock.Eq(account2.ID)).
+					Times(1).
+					Return(util.DefaultTenant, nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(db.GetAccountParams{ID: account2.ID, TenantID: util.DefaultTenant})).
 					Times(1).
 					Return(account2, nil)
 
@@ -65,10 +97,38 @@ func TestTransferAPI(t *testing.T) {
 					FromAccountID: account1.ID,
 					ToAccountID:   account2.ID,
 					Amount:        amount,
+					TenantID:      util.DefaultTenant,
+				}
+				result := db.TransferTxResult{
+					Transfer:    db.Transfer{ID: 1, FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: amount},
+					FromAccount: account1,
+					ToAccount:   account2,
 				}
 				store.EXPECT().
-					TransferTx(gomock.Any(), gomock.Eq(arg)).
-					Times(1)
+					TransferTx(gomock.Any(), EqTransferTxParams(arg)).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.TransferTxParams) (db.TransferTxResult, error) {
+						return result, arg.AfterTransfer(store, result)
+					})
+				store.EXPECT().
+					CreateOutbox(gomock.Any(), gomock.Any()).
+					Times(2).
+					DoAndReturn(func(_ context.Context, arg db.CreateOutboxParams) (db.Outbox, error) {
+						require.Equal(t, worker.TaskSendTransferNotification, arg.TaskType)
+						return db.Outbox{ID: 1, TaskType: arg.TaskType, Payload: arg.Payload}, nil
+					})
+				store.EXPECT().
+					ListWebhookSubscriptionsForEvent(gomock.Any(), gomock.Any()).
+					Times(2).
+					Return(nil, nil)
+				expectAppendedAuditEvent(t, store, db.AuditActionTransferCreate).
+					Do(func(_ context.Context, arg db.CreateAuditEventParams) {
+						require.Equal(t, strconv.FormatInt(account1.ID, 10), arg.TargetID)
+
+						var after map[string]any
+						require.NoError(t, json.Unmarshal(arg.After, &after))
+						require.EqualValues(t, 1, after["transfer_id"])
+					})
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
@@ -85,20 +145,22 @@ func TestTransferAPI(t *testing.T) {
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user2.Username, user2.Role, time.Minute)
 			},
-			buildStubs: func(store *mockdb.MockStore) {
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(account1.ID)).
+					GetAccount(gomock.Any(), gomock.Eq(db.GetAccountParams{ID: account1.ID, TenantID: util.DefaultTenant})).
 					Times(1).
 					Return(account1, nil)
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(account2.ID)).
+					GetAccount(gomock.Any(), gomock.Eq(db.GetAccountParams{ID: account2.ID, TenantID: util.DefaultTenant})).
 					Times(0)
 				store.EXPECT().
 					TransferTx(gomock.Any(), gomock.Any()).
 					Times(0)
+				expectAuditEvent(t, store, db.AuditActionTransferCreate, db.AuditOutcomeDenied, CodeAccountNotOwned)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblem(t, recorder, CodeAccountNotOwned)
 			},
 		},
 		{
@@ -111,7 +173,7 @@ func TestTransferAPI(t *testing.T) {
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 			},
-			buildStubs: func(store *mockdb.MockStore) {
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
 				store.EXPECT().
 					GetAccount(gomock.Any(), gomock.Any()).
 					Times(0)
@@ -134,13 +196,13 @@ func TestTransferAPI(t *testing.T) {
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, user1.Role, time.Minute)
 			},
-			buildStubs: func(store *mockdb.MockStore) {
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(account1.ID)).
+					GetAccount(gomock.Any(), gomock.Eq(db.GetAccountParams{ID: account1.ID, TenantID: util.DefaultTenant})).
 					Times(1).
 					Return(db.Account{}, db.ErrRecordNotFound)
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(account2.ID)).
+					GetAccount(gomock.Any(), gomock.Eq(db.GetAccountParams{ID: account2.ID, TenantID: util.DefaultTenant})).
 					Times(0)
 				store.EXPECT().
 					TransferTx(gomock.Any(), gomock.Any()).
@@ -161,13 +223,17 @@ func TestTransferAPI(t *testing.T) {
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, user1.Role, time.Minute)
 			},
-			buildStubs: func(store *mockdb.MockStore) {
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(account1.ID)).
+					GetAccount(gomock.Any(), gomock.Eq(db.GetAccountParams{ID: account1.ID, TenantID: util.DefaultTenant})).
 					Times(1).
 					Return(account1, nil)
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(account2.ID)).
+					GetAccountTenant(gomock.Any(), gomock.Eq(account2.ID)).
+					Times(1).
+					Return(util.DefaultTenant, nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(db.GetAccountParams{ID: account2.ID, TenantID: util.DefaultTenant})).
 					Times(1).
 					Return(db.Account{}, db.ErrRecordNotFound)
 				store.EXPECT().
@@ -189,13 +255,13 @@ func TestTransferAPI(t *testing.T) {
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user3.Username, user3.Role, time.Minute)
 			},
-			buildStubs: func(store *mockdb.MockStore) {
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(account3.ID)).
+					GetAccount(gomock.Any(), gomock.Eq(db.GetAccountParams{ID: account3.ID, TenantID: util.DefaultTenant})).
 					Times(1).
 					Return(account3, nil)
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(account2.ID)).
+					GetAccount(gomock.Any(), gomock.Eq(db.GetAccountParams{ID: account2.ID, TenantID: util.DefaultTenant})).
 					Times(0)
 				store.EXPECT().
 					TransferTx(gomock.Any(), gomock.Any()).
@@ -203,6 +269,7 @@ func TestTransferAPI(t *testing.T) {
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblem(t, recorder, CodeCurrencyMismatch)
 			},
 		},
 		{
@@ -216,13 +283,17 @@ func TestTransferAPI(t *testing.T) {
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, user1.Role, time.Minute)
 			},
-			buildStubs: func(store *mockdb.MockStore) {
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(account1.ID)).
+					GetAccount(gomock.Any(), gomock.Eq(db.GetAccountParams{ID: account1.ID, TenantID: util.DefaultTenant})).
 					Times(1).
 					Return(account1, nil)
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(account3.ID)).
+					GetAccountTenant(gomock.Any(), gomock.Eq(account3.ID)).
+					Times(1).
+					Return(util.DefaultTenant, nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(db.GetAccountParams{ID: account3.ID, TenantID: util.DefaultTenant})).
 					Times(1).
 					Return(account3, nil)
 				store.EXPECT().
@@ -231,6 +302,85 @@ func TestTransferAPI(t *testing.T) {
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblem(t, recorder, CodeCurrencyMismatch)
+			},
+		},
+		{
+			name: "CrossTenantNotEnabled",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        util.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, user1.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(db.GetAccountParams{ID: account1.ID, TenantID: util.DefaultTenant})).
+					Times(1).
+					Return(account1, nil)
+				store.EXPECT().
+					GetAccountTenant(gomock.Any(), gomock.Eq(account2.ID)).
+					Times(1).
+					Return("partner", nil)
+				store.EXPECT().
+					GetTenant(gomock.Any(), gomock.Eq(util.DefaultTenant)).
+					Times(1).
+					Return(db.Tenant{ID: util.DefaultTenant, AllowCrossTenantTransfers: true}, nil)
+				store.EXPECT().
+					GetTenant(gomock.Any(), gomock.Eq("partner")).
+					Times(1).
+					Return(db.Tenant{ID: "partner", AllowCrossTenantTransfers: false}, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblem(t, recorder, CodeCrossTenantTransferDisabled)
+			},
+		},
+		{
+			name: "CrossTenantEnabled",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        util.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, user1.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				partnerAccount := account2
+				partnerAccount.TenantID = "partner"
+
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(db.GetAccountParams{ID: account1.ID, TenantID: util.DefaultTenant})).
+					Times(1).
+					Return(account1, nil)
+				store.EXPECT().
+					GetAccountTenant(gomock.Any(), gomock.Eq(account2.ID)).
+					Times(1).
+					Return("partner", nil)
+				store.EXPECT().
+					GetTenant(gomock.Any(), gomock.Any()).
+					Times(2).
+					DoAndReturn(func(_ any, id string) (db.Tenant, error) {
+						return db.Tenant{ID: id, AllowCrossTenantTransfers: true}, nil
+					})
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(db.GetAccountParams{ID: account2.ID, TenantID: "partner"})).
+					Times(1).
+					Return(partnerAccount, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
 			},
 		},
 		{
@@ -244,7 +394,7 @@ func TestTransferAPI(t *testing.T) {
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, user1.Role, time.Minute)
 			},
-			buildStubs: func(store *mockdb.MockStore) {
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
 				store.EXPECT().
 					GetAccount(gomock.Any(), gomock.Any()).
 					Times(0)
@@ -267,7 +417,7 @@ func TestTransferAPI(t *testing.T) {
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, user1.Role, time.Minute)
 			},
-			buildStubs: func(store *mockdb.MockStore) {
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
 				store.EXPECT().
 					GetAccount(gomock.Any(), gomock.Any()).
 					Times(0)
@@ -290,7 +440,7 @@ func TestTransferAPI(t *testing.T) {
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, user1.Role, time.Minute)
 			},
-			buildStubs: func(store *mockdb.MockStore) {
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
 				store.EXPECT().
 					GetAccount(gomock.Any(), gomock.Any()).
 					Times(1).
@@ -303,6 +453,73 @@ func TestTransferAPI(t *testing.T) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
+		{
+			name: "ToAccountFrozen",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        util.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, user1.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				frozen := account2
+				frozen.Status = db.AccountStatusFrozen
+
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(db.GetAccountParams{ID: account1.ID, TenantID: util.DefaultTenant})).
+					Times(1).
+					Return(account1, nil)
+				store.EXPECT().
+					GetAccountTenant(gomock.Any(), gomock.Eq(account2.ID)).
+					Times(1).
+					Return(util.DefaultTenant, nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(db.GetAccountParams{ID: account2.ID, TenantID: util.DefaultTenant})).
+					Times(1).
+					Return(frozen, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblem(t, recorder, CodeAccountFrozen)
+			},
+		},
+		{
+			name: "FromAccountClosed",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        util.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, user1.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				closed := account1
+				closed.Status = db.AccountStatusClosed
+
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(db.GetAccountParams{ID: account1.ID, TenantID: util.DefaultTenant})).
+					Times(1).
+					Return(closed, nil)
+				store.EXPECT().
+					GetAccountTenant(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblem(t, recorder, CodeAccountClosed)
+			},
+		},
 		{
 			name: "TransferTxError",
 			body: gin.H{
@@ -314,24 +531,133 @@ func TestTransferAPI(t *testing.T) {
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, user1.Role, time.Minute)
 			},
-			buildStubs: func(store *mockdb.MockStore) {
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(account1.ID)).
+					GetAccount(gomock.Any(), gomock.Eq(db.GetAccountParams{ID: account1.ID, TenantID: util.DefaultTenant})).
 					Times(1).
 					Return(account1, nil)
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(account2.ID)).
+					GetAccountTenant(gomock.Any(), gomock.Eq(account2.ID)).
+					Times(1).
+					Return(util.DefaultTenant, nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(db.GetAccountParams{ID: account2.ID, TenantID: util.DefaultTenant})).
 					Times(1).
 					Return(account2, nil)
 				store.EXPECT().
 					TransferTx(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(db.TransferTxResult{}, sql.ErrTxDone)
+				distributor.EXPECT().
+					DistributeTaskSendTransferFailedNotification(gomock.Any(), gomock.Eq(&worker.PayloadSendTransferFailedNotification{
+						Username:      user1.Username,
+						FromAccountID: account1.ID,
+						ToAccountID:   account2.ID,
+						Amount:        amount,
+						Currency:      util.USD,
+					}), gomock.Any()).
+					Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				problem := requireProblem(t, recorder, CodeInternal)
+				require.Equal(t, "internal server error", problem.Detail)
+			},
+		},
+		{
+			name: "AuditEventError",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        util.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, user1.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(db.GetAccountParams{ID: account1.ID, TenantID: util.DefaultTenant})).
+					Times(1).
+					Return(account1, nil)
+				store.EXPECT().
+					GetAccountTenant(gomock.Any(), gomock.Eq(account2.ID)).
+					Times(1).
+					Return(util.DefaultTenant, nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(db.GetAccountParams{ID: account2.ID, TenantID: util.DefaultTenant})).
+					Times(1).
+					Return(account2, nil)
+
+				// The transfer is rolled back if its audit event cannot be recorded.
+				result := db.TransferTxResult{
+					Transfer:    db.Transfer{ID: 1, FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: amount},
+					FromAccount: account1,
+					ToAccount:   account2,
+				}
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.TransferTxParams) (db.TransferTxResult, error) {
+						return db.TransferTxResult{}, arg.AfterTransfer(store, result)
+					})
+				store.EXPECT().
+					CreateOutbox(gomock.Any(), gomock.Any()).
+					Times(2)
+				store.EXPECT().
+					ListWebhookSubscriptionsForEvent(gomock.Any(), gomock.Any()).
+					Times(2).
+					Return(nil, nil)
+				store.EXPECT().
+					LockAuditEvents(gomock.Any(), gomock.Eq(util.DefaultTenant)).
+					Times(1).
+					Return(sql.ErrConnDone)
+				expectAuditEvent(t, store, db.AuditActionTransferCreate, db.AuditOutcomeFailure, CodeInternal)
+				distributor.EXPECT().
+					DistributeTaskSendTransferFailedNotification(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
+		{
+			name: "InsufficientFunds",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        util.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, user1.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(db.GetAccountParams{ID: account1.ID, TenantID: util.DefaultTenant})).
+					Times(1).
+					Return(account1, nil)
+				store.EXPECT().
+					GetAccountTenant(gomock.Any(), gomock.Eq(account2.ID)).
+					Times(1).
+					Return(util.DefaultTenant, nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(db.GetAccountParams{ID: account2.ID, TenantID: util.DefaultTenant})).
+					Times(1).
+					Return(account2, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+				distributor.EXPECT().
+					DistributeTaskSendTransferFailedNotification(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireProblem(t, recorder, CodeInsufficientFunds)
+			},
+		},
 	}
 
 	for i := range testCases {
@@ -342,9 +668,11 @@ func TestTransferAPI(t *testing.T) {
 			defer ctrl.Finish()
 
 			store := mockdb.NewMockStore(ctrl)
-			tc.buildStubs(store)
+			distributor := mockwk.NewMockTaskDistributor(ctrl)
+			tc.buildStubs(store, distributor)
+			store.EXPECT().AppendAuditEventTx(gomock.Any(), gomock.Any()).AnyTimes()
 
-			server := newTestServer(t, store, nil, nil)
+			server := newTestServer(t, store, nil, distributor)
 			recorder := httptest.NewRecorder()
 
 			data, err := json.Marshal(tc.body)
@@ -1,12 +1,15 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/metrics"
 	"github.com/LamThanhNguyen/banking-system/token"
 	"github.com/LamThanhNguyen/banking-system/util"
 	"github.com/LamThanhNguyen/banking-system/worker"
@@ -33,6 +36,10 @@ type userResponse struct {
 	Username          string    `json:"username"`
 	FullName          string    `json:"full_name"`
 	Email             string    `json:"email"`
+	TenantID          string    `json:"tenant_id"`
+	Locale            string    `json:"locale"`
+	Phone             string    `json:"phone,omitempty"`
+	IsPhoneVerified   bool      `json:"is_phone_verified"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
 }
@@ -42,21 +49,25 @@ func newUserResponse(user db.User) userResponse {
 		Username:          user.Username,
 		FullName:          user.FullName,
 		Email:             user.Email,
+		TenantID:          user.TenantID,
+		Locale:            user.Locale,
+		Phone:             user.Phone,
+		IsPhoneVerified:   user.IsPhoneVerified,
 		PasswordChangedAt: user.PasswordChangedAt,
 		CreatedAt:         user.CreatedAt,
 	}
 }
 
 // @Summary      Create a new user
-// @Description  Register a new user and send email verification. Username and email must be unique.
+// @Description  Register a new user of the default tenant and send email verification. Username and email must be unique.
 // @Tags         users
 // @Accept       json
 // @Produce      json
 // @Param        body  body      createUserRequest  true  "User registration info"
 // @Success      201   {object}  userResponse
-// @Failure      400   {object}  api.ErrorResponse "Invalid request or validation error"
-// @Failure      409   {object}  api.ErrorResponse "Conflict: email or username already exists"
-// @Failure      500   {object}  api.ErrorResponse "Internal server error"
+// @Failure      400   {object}  api.Problem "INVALID_REQUEST or VALIDATION_FAILED"
+// @Failure      409   {object}  api.Problem "ALREADY_EXISTS: email or username"
+// @Failure      500   {object}  api.Problem "INTERNAL_ERROR"
 // @Router       /api/v1/users [post]
 func (server *Server) createUser(ctx *gin.Context) {
 	var req createUserRequest
@@ -67,7 +78,7 @@ func (server *Server) createUser(ctx *gin.Context) {
 
 	hashedPassword, err := util.HashPassword(req.Password)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, err)
 		return
 	}
 
@@ -77,28 +88,21 @@ func (server *Server) createUser(ctx *gin.Context) {
 			HashedPassword: hashedPassword,
 			FullName:       req.FullName,
 			Email:          req.Email,
+			// The caller is not authenticated, so it cannot choose a tenant.
+			TenantID: util.DefaultTenant,
 		},
-		AfterCreate: func(user db.User) error {
-			taskPayload := &worker.PayloadSendVerifyEmail{
-				Username: user.Username,
-			}
-			opts := []asynq.Option{
-				asynq.MaxRetry(10),
-				asynq.ProcessIn(10 * time.Second),
-				asynq.Queue(worker.QueueCritical),
-			}
-
-			return server.taskDistributor.DistributeTaskSendVerifyEmail(ctx, taskPayload, opts...)
+		AfterCreate: func(q db.Querier, user db.User) error {
+			return distributeVerifyEmail(ctx, q, user)
 		},
 	}
 
 	txResult, err := server.store.CreateUserTx(ctx, arg)
 	if err != nil {
-		if db.ErrorCode(err) == db.UniqueViolation {
-			ctx.JSON(http.StatusConflict, errorResponse(err))
-			return
+		switch db.ErrorCode(err) {
+		case db.UniqueViolation:
+			err = newError(CodeAlreadyExists, "username or email already exists")
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, err)
 		return
 	}
 
@@ -127,10 +131,11 @@ type loginUserResponse struct {
 // @Produce      json
 // @Param        body  body      loginUserRequest  true  "Login credentials"
 // @Success      200   {object}  loginUserResponse
-// @Failure      400   {object}  api.ErrorResponse "Invalid request or validation error"
-// @Failure      401   {object}  api.ErrorResponse "Unauthorized: incorrect credentials"
-// @Failure      404   {object}  api.ErrorResponse "User not found"
-// @Failure      500   {object}  api.ErrorResponse "Internal server error"
+// @Failure      400   {object}  api.Problem "INVALID_REQUEST or VALIDATION_FAILED"
+// @Failure      401   {object}  api.Problem "INVALID_CREDENTIALS"
+// @Failure      403   {object}  api.Problem "USER_DISABLED"
+// @Failure      404   {object}  api.Problem "USER_NOT_FOUND"
+// @Failure      500   {object}  api.Problem "INTERNAL_ERROR"
 // @Router       /api/v1/users/login [post]
 func (server *Server) loginUser(ctx *gin.Context) {
 	var req loginUserRequest
@@ -138,46 +143,62 @@ func (server *Server) loginUser(ctx *gin.Context) {
 		return
 	}
 
+	// Unknown usernames have no tenant, so they are recorded in the default one.
+	audit := db.AppendAuditEventTxParams{
+		TenantID:   util.DefaultTenant,
+		Actor:      req.Username,
+		Action:     db.AuditActionLogin,
+		TargetType: db.AuditTargetUser,
+		TargetID:   req.Username,
+	}
+	defer server.recordAudit(ctx, &audit)
+
 	user, err := server.store.GetUser(ctx, req.Username)
 	if err != nil {
 		if errors.Is(err, db.ErrRecordNotFound) {
-			err := fmt.Errorf("user not found")
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
+			metrics.ObserveLoginFailure(metrics.LoginUserNotFound)
+			err = newError(CodeUserNotFound, "user not found")
 		}
-		err := fmt.Errorf("failed to find user")
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, err)
 		return
 	}
+	audit.TenantID = user.TenantID
+	audit.ActorRole = user.Role
 
 	err = util.CheckPassword(req.Password, user.HashedPassword)
 	if err != nil {
-		err := fmt.Errorf("incorrect password")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		metrics.ObserveLoginFailure(metrics.LoginInvalidCredentials)
+		abortWithError(ctx, newError(CodeInvalidCredentials, "incorrect password"))
+		return
+	}
+
+	if user.IsDisabled {
+		metrics.ObserveLoginFailure(metrics.LoginUserDisabled)
+		abortWithError(ctx, newError(CodeUserDisabled, "user is disabled"))
 		return
 	}
 
 	accessToken, accessPayload, err := server.tokenMaker.CreateToken(
 		user.Username,
 		user.Role,
+		user.TenantID,
 		server.config.AccessTokenDurationParsed,
 		token.TokenTypeAccessToken,
 	)
 	if err != nil {
-		err := fmt.Errorf("failed to create access token")
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, fmt.Errorf("failed to create access token: %w", err))
 		return
 	}
 
 	refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(
 		user.Username,
 		user.Role,
+		user.TenantID,
 		server.config.RefreshTokenDurationParsed,
 		token.TokenTypeRefreshToken,
 	)
 	if err != nil {
-		err := fmt.Errorf("failed to create refresh token")
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, fmt.Errorf("failed to create refresh token: %w", err))
 		return
 	}
 
@@ -191,10 +212,12 @@ func (server *Server) loginUser(ctx *gin.Context) {
 		ExpiresAt:    refreshPayload.ExpiredAt,
 	})
 	if err != nil {
-		err := fmt.Errorf("failed to create session")
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, err)
+		return
 	}
 
+	audit.After = map[string]any{"session_id": session.ID}
+
 	rsp := loginUserResponse{
 		SessionID:             session.ID,
 		AccessToken:           accessToken,
@@ -214,27 +237,28 @@ type updateUserRequest struct {
 	Password *string `json:"password,omitempty" binding:"omitempty,min=8,max=50"` // built-in tags
 	FullName *string `json:"full_name,omitempty" binding:"omitempty,fullname"`    // custom tag
 	Email    *string `json:"email,omitempty" binding:"omitempty,email,max=50"`    // built-in
+	Locale   *string `json:"locale,omitempty" binding:"omitempty,locale"`         // custom tag
 }
 
 // @Summary      Update user
-// @Description  Banker can update any user. Depositor can update only their own account.
+// @Description  Banker can update any user. Depositor can update only their own account. Changing the email marks it unverified and sends a new verification email.
 // @Tags         users
 // @Security     BearerAuth
 // @Param        username   path      string               true  "Username"
 // @Param        body       body      updateUserRequest    true  "Fields to update"
 // @Success      200        {object}  userResponse
-// @Failure      400        {object}  api.ErrorResponse "Invalid request or validation error"
-// @Failure      403        {object}  api.ErrorResponse "Forbidden: not allowed to update this user"
-// @Failure      404        {object}  api.ErrorResponse "User not found"
-// @Failure      409        {object}  api.ErrorResponse "Conflict: email or username already exists"
-// @Failure      500        {object}  api.ErrorResponse "Internal server error"
+// @Failure      400        {object}  api.Problem "INVALID_REQUEST or VALIDATION_FAILED"
+// @Failure      403        {object}  api.Problem "FORBIDDEN: not allowed to update this user"
+// @Failure      404        {object}  api.Problem "USER_NOT_FOUND"
+// @Failure      409        {object}  api.Problem "ALREADY_EXISTS: email or username"
+// @Failure      500        {object}  api.Problem "INTERNAL_ERROR"
 // @Router       /api/v1/users/{username} [patch]
 func (server *Server) updateUser(ctx *gin.Context) {
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 
 	var reqPath getUserRequest
 	if err := ctx.ShouldBindUri(&reqPath); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		abortWithError(ctx, invalidRequest(err))
 		return
 	}
 
@@ -243,8 +267,15 @@ func (server *Server) updateUser(ctx *gin.Context) {
 		return
 	}
 
-	if reqBody.Password == nil && reqBody.FullName == nil && reqBody.Email == nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("no fields to update")))
+	audit := db.AppendAuditEventTxParams{
+		Action:     db.AuditActionUserUpdate,
+		TargetType: db.AuditTargetUser,
+		TargetID:   reqPath.Username,
+	}
+	defer server.recordAudit(ctx, &audit)
+
+	if reqBody.Password == nil && reqBody.FullName == nil && reqBody.Email == nil && reqBody.Locale == nil {
+		abortWithError(ctx, newError(CodeInvalidRequest, "no fields to update"))
 		return
 	}
 
@@ -256,17 +287,18 @@ func (server *Server) updateUser(ctx *gin.Context) {
 		Name: reqPath.Username,
 	}
 
-	ok, err := server.enforcer.Enforce(sub, obj, "users:update")
+	ok, err := server.enforcer.Enforce(sub, authPayload.TenantID, obj, "users:update")
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, err)
 		return
 	}
 	if !ok {
-		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("forbidden")))
+		server.logDenial(ctx, sub, authPayload.TenantID, obj, "users:update")
+		abortWithError(ctx, newError(CodeForbidden, "forbidden"))
 		return
 	}
 
-	var fullName, email pgtype.Text
+	var fullName, email, locale pgtype.Text
 	if reqBody.FullName != nil {
 		fullName = pgtype.Text{
 			String: *reqBody.FullName, Valid: true,
@@ -277,17 +309,24 @@ func (server *Server) updateUser(ctx *gin.Context) {
 			String: *reqBody.Email, Valid: true,
 		}
 	}
+	if reqBody.Locale != nil {
+		locale = pgtype.Text{
+			String: *reqBody.Locale, Valid: true,
+		}
+	}
 
 	arg := db.UpdateUserParams{
 		Username: reqPath.Username,
+		TenantID: pgtype.Text{String: authPayload.TenantID, Valid: true},
 		FullName: fullName,
 		Email:    email,
+		Locale:   locale,
 	}
 
 	if reqBody.Password != nil {
 		hashedPassword, err := util.HashPassword(*reqBody.Password)
 		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			abortWithError(ctx, err)
 			return
 		}
 
@@ -304,21 +343,28 @@ func (server *Server) updateUser(ctx *gin.Context) {
 		arg.PasswordChangedAt = pgtype.Timestamptz{}
 	}
 
-	user, err := server.store.UpdateUser(ctx, arg)
+	txResult, err := server.store.UpdateUserTx(ctx, db.UpdateUserTxParams{
+		UpdateUserParams: arg,
+		AfterEmailChange: func(q db.Querier, user db.User) error {
+			return distributeVerifyEmail(ctx, q, user)
+		},
+		AfterUpdate: func(q db.Querier, result db.UpdateUserTxResult) error {
+			audit.Before, audit.After = db.AuditUserChanges(result.Before, result.User)
+			return appendAudit(ctx, q, &audit)
+		},
+	})
 	if err != nil {
 		switch {
 		case errors.Is(err, db.ErrRecordNotFound):
-			err := fmt.Errorf("user not found")
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			err = newError(CodeUserNotFound, "user not found")
 		case db.ErrorCode(err) == db.UniqueViolation:
-			ctx.JSON(http.StatusConflict, errorResponse(err))
-		default:
-			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			err = newError(CodeAlreadyExists, "email already exists")
 		}
+		abortWithError(ctx, err)
 		return
 	}
 
-	rsp := newUserResponse(user)
+	rsp := newUserResponse(txResult.User)
 	ctx.JSON(http.StatusOK, rsp)
 }
 
@@ -335,27 +381,15 @@ type verifyEmailRequest struct {
 // @Param        email_id    query     int    true   "Email ID"
 // @Param        secret_code query     string true   "Secret verification code"
 // @Success      204        "No Content: email verified successfully"
-// @Failure      400        {object}  api.ErrorResponse "Invalid request or validation error"
-// @Failure      404        {object}  api.ErrorResponse "Email or code not found"
-// @Failure      500        {object}  api.ErrorResponse "Internal server error"
+// @Failure      400        {object}  api.Problem "INVALID_REQUEST or VALIDATION_FAILED"
+// @Failure      404        {object}  api.Problem "NOT_FOUND: email or code"
+// @Failure      500        {object}  api.Problem "INTERNAL_ERROR"
 // @Router       /api/v1/users/verify-email [get]
 func (server *Server) verifyEmail(ctx *gin.Context) {
 	var req verifyEmailRequest
 
 	if err := ctx.ShouldBindQuery(&req); err != nil {
-		var ve validator.ValidationErrors
-		if errors.As(err, &ve) {
-			violations := make([]FieldViolation, 0, len(ve))
-			for _, fe := range ve {
-				violations = append(violations, FieldViolation{
-					Field:   fe.Field(),
-					Message: humanMessage(fe),
-				})
-			}
-			ctx.JSON(http.StatusBadRequest, gin.H{"violations": violations})
-			return
-		}
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		abortWithError(ctx, invalidRequest(err))
 		return
 	}
 
@@ -363,30 +397,82 @@ func (server *Server) verifyEmail(ctx *gin.Context) {
 		EmailId:    req.EmailId,
 		SecretCode: req.SecretCode,
 	}); err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			err = newError(CodeNotFound, "verification email not found or code is invalid")
+		}
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent) // 204
+}
+
+// @Summary      Resend verification email
+// @Description  Send a new verification email to the authenticated user and revoke the codes sent before. Requests are rate limited by VERIFY_EMAIL_COOLDOWN.
+// @Tags         users
+// @Security     BearerAuth
+// @Produce      json
+// @Param        username  path  string  true  "Username"
+// @Success      202       "Accepted: a new verification email will be sent"
+// @Failure      400       {object}  api.Problem "INVALID_REQUEST or VALIDATION_FAILED"
+// @Failure      403       {object}  api.Problem "FORBIDDEN: another user"
+// @Failure      404       {object}  api.Problem "USER_NOT_FOUND"
+// @Failure      409       {object}  api.Problem "EMAIL_ALREADY_VERIFIED"
+// @Failure      429       {object}  api.Problem "RATE_LIMITED: requested too recently"
+// @Failure      500       {object}  api.Problem "INTERNAL_ERROR"
+// @Router       /api/v1/users/{username}/verify-email/resend [post]
+func (server *Server) resendVerifyEmail(ctx *gin.Context) {
+	var reqPath getUserRequest
+	if err := ctx.ShouldBindUri(&reqPath); err != nil {
+		abortWithError(ctx, invalidRequest(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.Username != reqPath.Username {
+		abortWithError(ctx, newError(CodeForbidden, "cannot resend the verification email of another user"))
+		return
+	}
+
+	cooldown := server.config.VerifyEmailCooldownParsed
+	_, err := server.store.ResendVerifyEmailTx(ctx, db.ResendVerifyEmailTxParams{
+		Username:        reqPath.Username,
+		RequestedBefore: time.Now().Add(-cooldown),
+		AfterRequest: func(q db.Querier, user db.User) error {
+			return distributeVerifyEmail(ctx, q, user)
+		},
+	})
+	if err != nil {
 		switch {
 		case errors.Is(err, db.ErrRecordNotFound):
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-		default:
-			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			err = newError(CodeUserNotFound, "user not found")
+		case errors.Is(err, db.ErrVerificationRateLimited):
+			ctx.Header("Retry-After", strconv.Itoa(int(cooldown.Seconds())))
 		}
+		abortWithError(ctx, err)
 		return
 	}
 
-	ctx.Status(http.StatusNoContent) // 204
+	ctx.Status(http.StatusAccepted)
+}
+
+// distributeVerifyEmail schedules the verification email through the outbox of the running transaction.
+func distributeVerifyEmail(ctx context.Context, q db.Querier, user db.User) error {
+	taskPayload := &worker.PayloadSendVerifyEmail{
+		Username: user.Username,
+	}
+	opts := []asynq.Option{
+		asynq.MaxRetry(10),
+		asynq.ProcessIn(10 * time.Second),
+		asynq.Queue(worker.QueueCritical),
+	}
+
+	return worker.NewOutboxTaskDistributor(q).DistributeTaskSendVerifyEmail(ctx, taskPayload, opts...)
 }
 
 func bindAndValidateJsonBody(ctx *gin.Context, v interface{}) bool {
 	if err := ctx.ShouldBindJSON(v); err != nil {
-		var ve validator.ValidationErrors
-		if errors.As(err, &ve) {
-			violations := make([]FieldViolation, len(ve))
-			for i, fe := range ve {
-				violations[i] = FieldViolation{Field: fe.Field(), Message: humanMessage(fe)}
-			}
-			ctx.JSON(http.StatusBadRequest, gin.H{"violations": violations})
-			return false
-		}
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		abortWithError(ctx, invalidRequest(err))
 		return false
 	}
 	return true
@@ -406,8 +492,32 @@ func humanMessage(fe validator.FieldError) string {
 		return "must contain only letters or spaces"
 	case "email":
 		return "is not a valid email address"
+	case "tenant_id":
+		return "must contain only lowercase letters, digits or hyphen"
+	case "role":
+		return "is not a supported role"
+	case "locale":
+		return "is not a supported locale"
+	case "notification_event":
+		return "is not a supported notification event"
+	case "notification_channel":
+		return "is not a supported notification channel"
+	case "webhook_event":
+		return "is not a supported webhook event"
+	case "webhook_url":
+		return "must be an absolute http or https URL with a public host"
+	case "phone":
+		return "must be a phone number in E.164 format"
+	case "otp":
+		return "must be a 6-digit code"
 	case "email_id":
 		return "must be a positive integer"
+	case "currency":
+		return "is not a supported currency"
+	case "oneof":
+		return "must be one of " + fe.Param()
+	case "gt":
+		return "must be greater than " + fe.Param()
 	default:
 		return fe.Error() // fallback
 	}
@@ -0,0 +1,269 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/token"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type adminUserResponse struct {
+	Username          string    `json:"username"`
+	FullName          string    `json:"full_name"`
+	Email             string    `json:"email"`
+	Role              string    `json:"role"`
+	IsEmailVerified   bool      `json:"is_email_verified"`
+	IsDisabled        bool      `json:"is_disabled"`
+	PasswordChangedAt time.Time `json:"password_changed_at"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+func newAdminUserResponse(user db.User) adminUserResponse {
+	return adminUserResponse{
+		Username:          user.Username,
+		FullName:          user.FullName,
+		Email:             user.Email,
+		Role:              user.Role,
+		IsEmailVerified:   user.IsEmailVerified,
+		IsDisabled:        user.IsDisabled,
+		PasswordChangedAt: user.PasswordChangedAt,
+		CreatedAt:         user.CreatedAt,
+	}
+}
+
+type listUsersRequest struct {
+	Search   string `form:"search" binding:"omitempty,max=50"`
+	Role     string `form:"role" binding:"omitempty,role"`
+	PageID   int32  `form:"page_id" binding:"required,min=1"`
+	PageSize int32  `form:"page_size" binding:"required,min=5,max=50"`
+}
+
+// @Summary      List users
+// @Description  Search and list users (paginated). Matches username, full name or email.
+// @Tags         admin
+// @Security     BearerAuth
+// @Produce      json
+// @Param        search    query     string  false  "Search term"
+// @Param        role      query     string  false  "Filter by role"
+// @Param        page_id   query     int     true   "Page number (min 1)"
+// @Param        page_size query     int     true   "Page size (min 5, max 50)"
+// @Success      200       {array}   adminUserResponse
+// @Failure      400       {object}  api.Problem "INVALID_REQUEST or VALIDATION_FAILED"
+// @Failure      403       {object}  api.Problem "FORBIDDEN"
+// @Failure      500       {object}  api.Problem "INTERNAL_ERROR"
+// @Router       /api/v1/users [get]
+func (server *Server) listUsers(ctx *gin.Context) {
+	var req listUsersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		abortWithError(ctx, invalidRequest(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	arg := db.ListUsersParams{
+		TenantID: authPayload.TenantID,
+		Search:   pgtype.Text{String: req.Search, Valid: req.Search != ""},
+		Role:     pgtype.Text{String: req.Role, Valid: req.Role != ""},
+		Limit:    req.PageSize,
+		Offset:   (req.PageID - 1) * req.PageSize,
+	}
+
+	users, err := server.store.ListUsers(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	rsp := make([]adminUserResponse, len(users))
+	for i, user := range users {
+		rsp[i] = newAdminUserResponse(user)
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type listUserAccountsRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+// @Summary      List a user's accounts
+// @Description  List all accounts owned by the given user (paginated)
+// @Tags         admin
+// @Security     BearerAuth
+// @Produce      json
+// @Param        username  path      string  true  "Username"
+// @Param        page_id   query     int     true  "Page number (min 1)"
+// @Param        page_size query     int     true  "Page size (min 5, max 10)"
+// @Success      200       {array}   db.Account
+// @Failure      400       {object}  api.Problem "INVALID_REQUEST or VALIDATION_FAILED"
+// @Failure      403       {object}  api.Problem "FORBIDDEN"
+// @Failure      404       {object}  api.Problem "USER_NOT_FOUND"
+// @Failure      500       {object}  api.Problem "INTERNAL_ERROR"
+// @Router       /api/v1/users/{username}/accounts [get]
+func (server *Server) listUserAccounts(ctx *gin.Context) {
+	var reqPath getUserRequest
+	if err := ctx.ShouldBindUri(&reqPath); err != nil {
+		abortWithError(ctx, invalidRequest(err))
+		return
+	}
+
+	var req listUserAccountsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		abortWithError(ctx, invalidRequest(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if _, ok := server.tenantUser(ctx, reqPath.Username, authPayload.TenantID); !ok {
+		return
+	}
+
+	accounts, err := server.store.ListAccounts(ctx, db.ListAccountsParams{
+		Owner:    reqPath.Username,
+		TenantID: authPayload.TenantID,
+		Limit:    req.PageSize,
+		Offset:   (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, accounts)
+}
+
+type updateUserRoleRequest struct {
+	Role string `json:"role" binding:"required,role"`
+}
+
+// @Summary      Change user role
+// @Description  Change the role of a user. All of the user's sessions are blocked so tokens carrying the old role cannot be renewed.
+// @Tags         admin
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        username  path      string                 true  "Username"
+// @Param        body      body      updateUserRoleRequest  true  "New role"
+// @Success      200       {object}  adminUserResponse
+// @Failure      400       {object}  api.Problem "INVALID_REQUEST or VALIDATION_FAILED"
+// @Failure      403       {object}  api.Problem "FORBIDDEN"
+// @Failure      404       {object}  api.Problem "USER_NOT_FOUND"
+// @Failure      500       {object}  api.Problem "INTERNAL_ERROR"
+// @Router       /api/v1/users/{username}/role [put]
+func (server *Server) updateUserRole(ctx *gin.Context) {
+	var reqPath getUserRequest
+	if err := ctx.ShouldBindUri(&reqPath); err != nil {
+		abortWithError(ctx, invalidRequest(err))
+		return
+	}
+
+	var req updateUserRoleRequest
+	if !bindAndValidateJsonBody(ctx, &req) {
+		return
+	}
+
+	audit := db.AppendAuditEventTxParams{
+		Action:     db.AuditActionUserUpdateRole,
+		TargetType: db.AuditTargetUser,
+		TargetID:   reqPath.Username,
+	}
+	defer server.recordAudit(ctx, &audit)
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.Username == reqPath.Username {
+		abortWithError(ctx, newError(CodeForbidden, "cannot change your own role"))
+		return
+	}
+
+	server.updateUserAccess(ctx, db.UpdateUserAccessTxParams{
+		Username: reqPath.Username,
+		TenantID: authPayload.TenantID,
+		Role:     pgtype.Text{String: req.Role, Valid: true},
+	}, &audit)
+}
+
+// @Summary      Disable user
+// @Description  Disable a user. A disabled user cannot log in and all of their sessions are blocked.
+// @Tags         admin
+// @Security     BearerAuth
+// @Produce      json
+// @Param        username  path      string  true  "Username"
+// @Success      200       {object}  adminUserResponse
+// @Failure      400       {object}  api.Problem "INVALID_REQUEST or VALIDATION_FAILED"
+// @Failure      403       {object}  api.Problem "FORBIDDEN"
+// @Failure      404       {object}  api.Problem "USER_NOT_FOUND"
+// @Failure      500       {object}  api.Problem "INTERNAL_ERROR"
+// @Router       /api/v1/users/{username}/disable [post]
+func (server *Server) disableUser(ctx *gin.Context) {
+	server.setUserDisabled(ctx, true)
+}
+
+// @Summary      Enable user
+// @Description  Re-enable a previously disabled user. The user has to log in again.
+// @Tags         admin
+// @Security     BearerAuth
+// @Produce      json
+// @Param        username  path      string  true  "Username"
+// @Success      200       {object}  adminUserResponse
+// @Failure      400       {object}  api.Problem "INVALID_REQUEST or VALIDATION_FAILED"
+// @Failure      403       {object}  api.Problem "FORBIDDEN"
+// @Failure      404       {object}  api.Problem "USER_NOT_FOUND"
+// @Failure      500       {object}  api.Problem "INTERNAL_ERROR"
+// @Router       /api/v1/users/{username}/enable [post]
+func (server *Server) enableUser(ctx *gin.Context) {
+	server.setUserDisabled(ctx, false)
+}
+
+func (server *Server) setUserDisabled(ctx *gin.Context, disabled bool) {
+	var reqPath getUserRequest
+	if err := ctx.ShouldBindUri(&reqPath); err != nil {
+		abortWithError(ctx, invalidRequest(err))
+		return
+	}
+
+	action := db.AuditActionUserEnable
+	if disabled {
+		action = db.AuditActionUserDisable
+	}
+	audit := db.AppendAuditEventTxParams{
+		Action:     action,
+		TargetType: db.AuditTargetUser,
+		TargetID:   reqPath.Username,
+	}
+	defer server.recordAudit(ctx, &audit)
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.Username == reqPath.Username {
+		abortWithError(ctx, newError(CodeForbidden, "cannot disable or enable yourself"))
+		return
+	}
+
+	server.updateUserAccess(ctx, db.UpdateUserAccessTxParams{
+		Username:   reqPath.Username,
+		TenantID:   authPayload.TenantID,
+		IsDisabled: pgtype.Bool{Bool: disabled, Valid: true},
+	}, &audit)
+}
+
+// updateUserAccess applies arg and records the changes in audit, within the
+// same transaction.
+func (server *Server) updateUserAccess(ctx *gin.Context, arg db.UpdateUserAccessTxParams, audit *db.AppendAuditEventTxParams) {
+	arg.AfterUpdate = func(q db.Querier, result db.UpdateUserAccessTxResult) error {
+		audit.Before, audit.After = db.AuditUserChanges(result.Before, result.User)
+		return appendAudit(ctx, q, audit)
+	}
+	result, err := server.store.UpdateUserAccessTx(ctx, arg)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			err = newError(CodeUserNotFound, "user not found")
+		}
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAdminUserResponse(result.User))
+}
@@ -0,0 +1,490 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	mockdb "github.com/LamThanhNguyen/banking-system/db/mock"
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/token"
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+type eqUpdateUserAccessTxParamsMatcher struct {
+	arg db.UpdateUserAccessTxParams
+}
+
+func (e eqUpdateUserAccessTxParamsMatcher) Matches(x interface{}) bool {
+	txArg, ok := x.(db.UpdateUserAccessTxParams)
+	if !ok || txArg.AfterUpdate == nil {
+		return false
+	}
+	txArg.AfterUpdate = nil
+
+	return reflect.DeepEqual(e.arg, txArg)
+}
+
+func (e eqUpdateUserAccessTxParamsMatcher) String() string {
+	return fmt.Sprintf("matches arg %v with an AfterUpdate hook", e.arg)
+}
+
+func EqUpdateUserAccessTxParams(arg db.UpdateUserAccessTxParams) gomock.Matcher {
+	return eqUpdateUserAccessTxParamsMatcher{arg}
+}
+
+// updateUserAccess stubs UpdateUserAccessTx to return result once AfterUpdate
+// ran on the mock store.
+func updateUserAccess(store *mockdb.MockStore, result db.UpdateUserAccessTxResult) func(ctx context.Context, arg db.UpdateUserAccessTxParams) (db.UpdateUserAccessTxResult, error) {
+	return func(ctx context.Context, arg db.UpdateUserAccessTxParams) (db.UpdateUserAccessTxResult, error) {
+		return result, arg.AfterUpdate(store, result)
+	}
+}
+
+func TestListUsersAPI(t *testing.T) {
+	banker, _ := randomBankerUser(t)
+
+	n := 5
+	users := make([]db.User, n)
+	for i := 0; i < n; i++ {
+		users[i], _ = randomDistributorUser(t)
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: fmt.Sprintf("page_id=1&page_size=%d&search=abc&role=%s", n, util.DepositorRole),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, banker.Username, banker.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListUsersParams{
+					TenantID: util.DefaultTenant,
+					Search:   pgtype.Text{String: "abc", Valid: true},
+					Role:     pgtype.Text{String: util.DepositorRole, Valid: true},
+					Limit:    int32(n),
+					Offset:   0,
+				}
+				store.EXPECT().
+					ListUsers(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(users, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAdminUsers(t, recorder.Body, users)
+			},
+		},
+		{
+			name:  "NoAuthorization",
+			query: fmt.Sprintf("page_id=1&page_size=%d", n),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListUsers(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidRole",
+			query: fmt.Sprintf("page_id=1&page_size=%d&role=superuser", n),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, banker.Username, banker.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListUsers(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InternalError",
+			query: fmt.Sprintf("page_id=1&page_size=%d", n),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, banker.Username, banker.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListUsers(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.User{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			recorder := httptest.NewRecorder()
+
+			url := "/api/v1/users?" + tc.query
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestListUserAccountsAPI(t *testing.T) {
+	banker, _ := randomBankerUser(t)
+	user, _ := randomDistributorUser(t)
+	accounts := []db.Account{randomAccount(user.Username)}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				arg := db.ListAccountsParams{
+					Owner:    user.Username,
+					TenantID: util.DefaultTenant,
+					Limit:    5,
+					Offset:   0,
+				}
+				store.EXPECT().
+					ListAccounts(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(accounts, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAccounts(t, recorder.Body, accounts)
+			},
+		},
+		{
+			name: "OtherTenant",
+			buildStubs: func(store *mockdb.MockStore) {
+				other := user
+				other.TenantID = "partner"
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(other, nil)
+				store.EXPECT().
+					ListAccounts(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "UserNotFound",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.User{}, db.ErrRecordNotFound)
+				store.EXPECT().
+					ListAccounts(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/api/v1/users/%s/accounts?page_id=1&page_size=5", user.Username)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, banker.Username, banker.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestUpdateUserRoleAPI(t *testing.T) {
+	banker, _ := randomBankerUser(t)
+	user, _ := randomDistributorUser(t)
+
+	promoted := user
+	promoted.Role = util.BankerRole
+
+	testCases := []struct {
+		name          string
+		username      string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: user.Username,
+			body:     gin.H{"role": util.BankerRole},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateUserAccessTxParams{
+					Username: user.Username,
+					TenantID: util.DefaultTenant,
+					Role:     pgtype.Text{String: util.BankerRole, Valid: true},
+				}
+				store.EXPECT().
+					UpdateUserAccessTx(gomock.Any(), EqUpdateUserAccessTxParams(arg)).
+					Times(1).
+					DoAndReturn(updateUserAccess(store, db.UpdateUserAccessTxResult{Before: user, User: promoted}))
+				expectAppendedAuditEvent(t, store, db.AuditActionUserUpdateRole).
+					Do(func(_ context.Context, arg db.CreateAuditEventParams) {
+						require.JSONEq(t, `{"role": "depositor"}`, string(arg.Before))
+						require.JSONEq(t, `{"role": "banker"}`, string(arg.After))
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got adminUserResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, util.BankerRole, got.Role)
+			},
+		},
+		{
+			name:     "InvalidRole",
+			username: user.Username,
+			body:     gin.H{"role": "superuser"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateUserAccessTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "OwnRole",
+			username: banker.Username,
+			body:     gin.H{"role": util.DepositorRole},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateUserAccessTx(gomock.Any(), gomock.Any()).
+					Times(0)
+				expectAuditEvent(t, store, db.AuditActionUserUpdateRole, db.AuditOutcomeDenied, CodeForbidden)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "UserNotFound",
+			username: user.Username,
+			body:     gin.H{"role": util.BankerRole},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateUserAccessTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UpdateUserAccessTxResult{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			store.EXPECT().AppendAuditEventTx(gomock.Any(), gomock.Any()).AnyTimes()
+
+			server := newTestServer(t, store, nil, nil)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/api/v1/users/%s/role", tc.username)
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, banker.Username, banker.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestDisableUserAPI(t *testing.T) {
+	banker, _ := randomBankerUser(t)
+	user, _ := randomDistributorUser(t)
+
+	testCases := []struct {
+		name          string
+		action        string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "Disable",
+			action: "disable",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateUserAccessTxParams{
+					Username:   user.Username,
+					TenantID:   util.DefaultTenant,
+					IsDisabled: pgtype.Bool{Bool: true, Valid: true},
+				}
+				disabled := user
+				disabled.IsDisabled = true
+				store.EXPECT().
+					UpdateUserAccessTx(gomock.Any(), EqUpdateUserAccessTxParams(arg)).
+					Times(1).
+					DoAndReturn(updateUserAccess(store, db.UpdateUserAccessTxResult{Before: user, User: disabled}))
+				expectAppendedAuditEvent(t, store, db.AuditActionUserDisable)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "Enable",
+			action: "enable",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateUserAccessTxParams{
+					Username:   user.Username,
+					TenantID:   util.DefaultTenant,
+					IsDisabled: pgtype.Bool{Bool: false, Valid: true},
+				}
+				disabled := user
+				disabled.IsDisabled = true
+				store.EXPECT().
+					UpdateUserAccessTx(gomock.Any(), EqUpdateUserAccessTxParams(arg)).
+					Times(1).
+					DoAndReturn(updateUserAccess(store, db.UpdateUserAccessTxResult{Before: disabled, User: user}))
+				expectAppendedAuditEvent(t, store, db.AuditActionUserEnable)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "InternalError",
+			action: "disable",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateUserAccessTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UpdateUserAccessTxResult{}, sql.ErrConnDone)
+				expectAuditEvent(t, store, db.AuditActionUserDisable, db.AuditOutcomeFailure, CodeInternal)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:   "EnableInternalError",
+			action: "enable",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateUserAccessTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UpdateUserAccessTxResult{}, sql.ErrConnDone)
+				expectAuditEvent(t, store, db.AuditActionUserEnable, db.AuditOutcomeFailure, CodeInternal)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			store.EXPECT().AppendAuditEventTx(gomock.Any(), gomock.Any()).AnyTimes()
+
+			server := newTestServer(t, store, nil, nil)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/api/v1/users/%s/%s", user.Username, tc.action)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, banker.Username, banker.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func requireBodyMatchAdminUsers(t *testing.T, body *bytes.Buffer, users []db.User) {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var gotUsers []adminUserResponse
+	err = json.Unmarshal(data, &gotUsers)
+	require.NoError(t, err)
+	require.Len(t, gotUsers, len(users))
+	for i, user := range users {
+		require.Equal(t, user.Username, gotUsers[i].Username)
+		require.Equal(t, user.Role, gotUsers[i].Role)
+	}
+}
@@ -69,6 +69,7 @@ func TestCreateUserAPI(t *testing.T) {
 					Username: user.Username,
 					FullName: user.FullName,
 					Email:    user.Email,
+					TenantID: util.DefaultTenant,
 				}
 				store.EXPECT().
 					CreateUserTx(gomock.Any(), EqCreateUserTxParams(arg, password)).
@@ -80,6 +81,31 @@ func TestCreateUserAPI(t *testing.T) {
 				requireBodyMatchUser(t, recorder.Body, user)
 			},
 		},
+		{
+			name: "IgnoresTenantID",
+			body: gin.H{
+				"username":  user.Username,
+				"password":  password,
+				"full_name": user.FullName,
+				"email":     user.Email,
+				"tenant_id": "partner-bank",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateUserParams{
+					Username: user.Username,
+					FullName: user.FullName,
+					Email:    user.Email,
+					TenantID: util.DefaultTenant,
+				}
+				store.EXPECT().
+					CreateUserTx(gomock.Any(), EqCreateUserTxParams(arg, password)).
+					Times(1).
+					Return(db.CreateUserTxResult{User: user}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
 		{
 			name: "InternalError",
 			body: gin.H{
@@ -131,6 +157,8 @@ func TestCreateUserAPI(t *testing.T) {
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				problem := requireProblem(t, recorder, CodeValidationFailed)
+				require.Equal(t, []FieldViolation{{Field: "Username", Message: "must contain only lowercase letters, digits or underscore"}}, problem.Violations)
 			},
 		},
 		{
@@ -219,6 +247,7 @@ func TestLoginUserAPI(t *testing.T) {
 				store.EXPECT().
 					CreateSession(gomock.Any(), gomock.Any()).
 					Times(1)
+				expectAuditEvent(t, store, db.AuditActionLogin, db.AuditOutcomeSuccess, "")
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
@@ -235,6 +264,7 @@ func TestLoginUserAPI(t *testing.T) {
 					GetUser(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(db.User{}, db.ErrRecordNotFound)
+				expectAuditEvent(t, store, db.AuditActionLogin, db.AuditOutcomeFailure, CodeUserNotFound)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusNotFound, recorder.Code)
@@ -251,11 +281,34 @@ func TestLoginUserAPI(t *testing.T) {
 					GetUser(gomock.Any(), gomock.Eq(user.Username)).
 					Times(1).
 					Return(user, nil)
+				expectAuditEvent(t, store, db.AuditActionLogin, db.AuditOutcomeFailure, CodeInvalidCredentials)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
 		},
+		{
+			name: "UserDisabled",
+			body: gin.H{
+				"username": user.Username,
+				"password": password,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				disabled := user
+				disabled.IsDisabled = true
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(disabled, nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+				expectAuditEvent(t, store, db.AuditActionLogin, db.AuditOutcomeDenied, CodeUserDisabled)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
 		{
 			name: "InternalError",
 			body: gin.H{
@@ -267,6 +320,7 @@ func TestLoginUserAPI(t *testing.T) {
 					GetUser(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(db.User{}, sql.ErrConnDone)
+				expectAuditEvent(t, store, db.AuditActionLogin, db.AuditOutcomeFailure, CodeInternal)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
@@ -282,6 +336,9 @@ func TestLoginUserAPI(t *testing.T) {
 				store.EXPECT().
 					GetUser(gomock.Any(), gomock.Any()).
 					Times(0)
+				store.EXPECT().
+					AppendAuditEventTx(gomock.Any(), gomock.Any()).
+					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
@@ -326,6 +383,7 @@ func randomDistributorUser(t *testing.T) (user db.User, password string) {
 		FullName:       util.RandomOwner(),
 		Email:          util.RandomEmail(),
 		Role:           util.DepositorRole,
+		TenantID:       util.DefaultTenant,
 	}
 	return
 }
@@ -341,6 +399,7 @@ func randomBankerUser(t *testing.T) (user db.User, password string) {
 		FullName:       util.RandomOwner(),
 		Email:          util.RandomEmail(),
 		Role:           util.BankerRole,
+		TenantID:       util.DefaultTenant,
 	}
 	return
 }
@@ -27,6 +27,60 @@ func init() {
 			panic(err)
 		}
 
+		if err := v.RegisterValidation("tenant_id", func(fl validator.FieldLevel) bool {
+			return val.ValidateTenantID(fl.Field().String()) == nil
+		}); err != nil {
+			panic(err)
+		}
+
+		if err := v.RegisterValidation("role", func(fl validator.FieldLevel) bool {
+			return util.IsSupportedRole(fl.Field().String())
+		}); err != nil {
+			panic(err)
+		}
+
+		if err := v.RegisterValidation("locale", func(fl validator.FieldLevel) bool {
+			return util.IsSupportedLocale(fl.Field().String())
+		}); err != nil {
+			panic(err)
+		}
+
+		if err := v.RegisterValidation("notification_event", func(fl validator.FieldLevel) bool {
+			return util.IsSupportedNotificationEvent(fl.Field().String())
+		}); err != nil {
+			panic(err)
+		}
+
+		if err := v.RegisterValidation("notification_channel", func(fl validator.FieldLevel) bool {
+			return util.IsSupportedNotificationChannel(fl.Field().String())
+		}); err != nil {
+			panic(err)
+		}
+
+		if err := v.RegisterValidation("webhook_event", func(fl validator.FieldLevel) bool {
+			return util.IsSupportedWebhookEvent(fl.Field().String())
+		}); err != nil {
+			panic(err)
+		}
+
+		if err := v.RegisterValidation("webhook_url", func(fl validator.FieldLevel) bool {
+			return val.ValidateWebhookURL(fl.Field().String()) == nil
+		}); err != nil {
+			panic(err)
+		}
+
+		if err := v.RegisterValidation("phone", func(fl validator.FieldLevel) bool {
+			return val.ValidatePhone(fl.Field().String()) == nil
+		}); err != nil {
+			panic(err)
+		}
+
+		if err := v.RegisterValidation("otp", func(fl validator.FieldLevel) bool {
+			return val.ValidateOTP(fl.Field().String()) == nil
+		}); err != nil {
+			panic(err)
+		}
+
 		if err := v.RegisterValidation("email_id", func(fl validator.FieldLevel) bool {
 			return val.ValidateEmailId(fl.Field().Int()) == nil
 		}); err != nil {
@@ -0,0 +1,203 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "github.com/LamThanhNguyen/banking-system/db/mock"
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/worker"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestResendVerifyEmailAPI(t *testing.T) {
+	user, _ := randomDistributorUser(t)
+	other, _ := randomDistributorUser(t)
+
+	testCases := []struct {
+		name          string
+		authUser      db.User
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			authUser: user,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ResendVerifyEmailTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.ResendVerifyEmailTxParams) (db.ResendVerifyEmailTxResult, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.WithinDuration(t, time.Now().Add(-time.Minute), arg.RequestedBefore, time.Second)
+						return db.ResendVerifyEmailTxResult{User: user}, arg.AfterRequest(store, user)
+					})
+				store.EXPECT().
+					CreateOutbox(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateOutboxParams) (db.Outbox, error) {
+						require.Equal(t, worker.TaskSendVerifyEmail, arg.TaskType)
+						require.Equal(t, worker.QueueCritical, arg.Queue)
+						return db.Outbox{}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+			},
+		},
+		{
+			name:     "OtherUser",
+			authUser: other,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ResendVerifyEmailTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "AlreadyVerified",
+			authUser: user,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ResendVerifyEmailTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ResendVerifyEmailTxResult{}, db.ErrEmailAlreadyVerified)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "RateLimited",
+			authUser: user,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ResendVerifyEmailTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ResendVerifyEmailTxResult{}, db.ErrVerificationRateLimited)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+				require.Equal(t, "60", recorder.Header().Get("Retry-After"))
+			},
+		},
+		{
+			name:     "NotFound",
+			authUser: user,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ResendVerifyEmailTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ResendVerifyEmailTxResult{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			server.config.VerifyEmailCooldownParsed = time.Minute
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/api/v1/users/%s/verify-email/resend", user.Username)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.authUser.Username, tc.authUser.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestRequireVerifiedEmailMiddleware(t *testing.T) {
+	user, _ := randomDistributorUser(t)
+
+	testCases := []struct {
+		name          string
+		verified      bool
+		buildStubs    func(store *mockdb.MockStore, user db.User)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "Verified",
+			verified: true,
+			buildStubs: func(store *mockdb.MockStore, user db.User) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CreateAccountTxResult{Account: db.Account{Owner: user.Username}}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "Unverified",
+			verified: false,
+			buildStubs: func(store *mockdb.MockStore, user db.User) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			authUser := user
+			authUser.IsEmailVerified = tc.verified
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store, authUser)
+
+			server := newTestServer(t, store, nil, nil)
+			server.config.RequireVerifiedEmail = true
+			recorder := httptest.NewRecorder()
+
+			body := `{"currency":"USD"}`
+			request, err := http.NewRequest(http.MethodPost, "/api/v1/accounts", strings.NewReader(body))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, authUser.Username, authUser.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
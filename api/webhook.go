@@ -0,0 +1,402 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/token"
+	"github.com/LamThanhNguyen/banking-system/worker"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type webhookSubscriptionResponse struct {
+	ID                  int64     `json:"id"`
+	URL                 string    `json:"url"`
+	Events              []string  `json:"events"`
+	IsDisabled          bool      `json:"is_disabled"`
+	ConsecutiveFailures int32     `json:"consecutive_failures"`
+	CreatedAt           time.Time `json:"created_at"`
+	// Secret is only returned when the subscription is created.
+	Secret string `json:"secret,omitempty"`
+}
+
+func newWebhookSubscriptionResponse(subscription db.WebhookSubscription) webhookSubscriptionResponse {
+	return webhookSubscriptionResponse{
+		ID:                  subscription.ID,
+		URL:                 subscription.Url,
+		Events:              subscription.Events,
+		IsDisabled:          subscription.IsDisabled,
+		ConsecutiveFailures: subscription.ConsecutiveFailures,
+		CreatedAt:           subscription.CreatedAt,
+	}
+}
+
+type webhookDeliveryResponse struct {
+	ID             int64           `json:"id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload" swaggertype:"object"`
+	Status         string          `json:"status"`
+	Attempts       int32           `json:"attempts"`
+	ResponseStatus int32           `json:"response_status,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+func newWebhookDeliveryResponse(delivery db.WebhookDelivery) webhookDeliveryResponse {
+	rsp := webhookDeliveryResponse{
+		ID:             delivery.ID,
+		EventType:      delivery.EventType,
+		Payload:        delivery.Payload,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		ResponseStatus: delivery.ResponseStatus,
+		LastError:      delivery.LastError,
+		CreatedAt:      delivery.CreatedAt,
+	}
+	if delivery.DeliveredAt.Valid {
+		rsp.DeliveredAt = &delivery.DeliveredAt.Time
+	}
+	return rsp
+}
+
+// checkWebhookScheme requires https outside develop, so that deliveries do
+// not carry account events in clear text.
+func (server *Server) checkWebhookScheme(rawURL string) error {
+	if server.config.Environment == "develop" || strings.HasPrefix(strings.ToLower(rawURL), "https://") {
+		return nil
+	}
+	return newError(CodeValidationFailed, "url must use https")
+}
+
+type createWebhookSubscriptionRequest struct {
+	URL    string   `json:"url" binding:"required,webhook_url"`
+	Events []string `json:"events" binding:"required,min=1,dive,webhook_event"`
+	// Secret signs the deliveries; a random one is generated when empty.
+	Secret string `json:"secret" binding:"omitempty,min=16,max=128"`
+}
+
+// newWebhookSecret returns a random secret to sign the deliveries with.
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// @Summary      Create webhook subscription
+// @Description  Subscribe a URL to account and transfer events of the authenticated user. Every delivery is signed with the secret in the X-Signature header ("sha256=" + hex HMAC-SHA256 of the body). The secret is only returned in this response.
+// @Description  The URL must use https outside develop and point to a public address; deliveries do not follow redirects.
+// @Tags         webhooks
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      createWebhookSubscriptionRequest  true  "Subscription"
+// @Success      201      {object}  webhookSubscriptionResponse
+// @Failure      400      {object}  api.Problem "INVALID_REQUEST or VALIDATION_FAILED"
+// @Failure      403      {object}  api.Problem "FORBIDDEN"
+// @Failure      500      {object}  api.Problem "INTERNAL_ERROR"
+// @Router       /api/v1/webhooks [post]
+func (server *Server) createWebhookSubscription(ctx *gin.Context) {
+	var req createWebhookSubscriptionRequest
+	if !bindAndValidateJsonBody(ctx, &req) {
+		return
+	}
+	if err := server.checkWebhookScheme(req.URL); err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		var err error
+		secret, err = newWebhookSecret()
+		if err != nil {
+			abortWithError(ctx, err)
+			return
+		}
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	subscription, err := server.store.CreateWebhookSubscription(ctx, db.CreateWebhookSubscriptionParams{
+		Username: authPayload.Username,
+		Url:      req.URL,
+		Events:   req.Events,
+		Secret:   secret,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	rsp := newWebhookSubscriptionResponse(subscription)
+	rsp.Secret = subscription.Secret
+	ctx.JSON(http.StatusCreated, rsp)
+}
+
+// @Summary      List webhook subscriptions
+// @Description  List the webhook subscriptions of the authenticated user.
+// @Tags         webhooks
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {array}   webhookSubscriptionResponse
+// @Failure      403  {object}  api.Problem "FORBIDDEN"
+// @Failure      500  {object}  api.Problem "INTERNAL_ERROR"
+// @Router       /api/v1/webhooks [get]
+func (server *Server) listWebhookSubscriptions(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	subscriptions, err := server.store.ListWebhookSubscriptions(ctx, authPayload.Username)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	rsp := make([]webhookSubscriptionResponse, len(subscriptions))
+	for i, subscription := range subscriptions {
+		rsp[i] = newWebhookSubscriptionResponse(subscription)
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type webhookSubscriptionRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// ownWebhookSubscription loads the subscription of the request path and checks
+// that it belongs to the authenticated user. It writes the error response and
+// returns false otherwise.
+func (server *Server) ownWebhookSubscription(ctx *gin.Context) (db.WebhookSubscription, bool) {
+	var req webhookSubscriptionRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		abortWithError(ctx, invalidRequest(err))
+		return db.WebhookSubscription{}, false
+	}
+
+	subscription, err := server.store.GetWebhookSubscription(ctx, req.ID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			err = newError(CodeNotFound, "webhook subscription not found")
+		}
+		abortWithError(ctx, err)
+		return db.WebhookSubscription{}, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if subscription.Username != authPayload.Username {
+		abortWithError(ctx, newError(CodeForbidden, "webhook subscription doesn't belong to the authenticated user"))
+		return db.WebhookSubscription{}, false
+	}
+
+	return subscription, true
+}
+
+// @Summary      Get webhook subscription
+// @Description  Get a webhook subscription of the authenticated user.
+// @Tags         webhooks
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id   path      int  true  "Subscription ID"
+// @Success      200  {object}  webhookSubscriptionResponse
+// @Failure      403  {object}  api.Problem "FORBIDDEN"
+// @Failure      404  {object}  api.Problem "NOT_FOUND: subscription"
+// @Failure      500  {object}  api.Problem "INTERNAL_ERROR"
+// @Router       /api/v1/webhooks/{id} [get]
+func (server *Server) getWebhookSubscription(ctx *gin.Context) {
+	subscription, ok := server.ownWebhookSubscription(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newWebhookSubscriptionResponse(subscription))
+}
+
+type updateWebhookSubscriptionRequest struct {
+	URL        *string  `json:"url" binding:"omitempty,webhook_url"`
+	Events     []string `json:"events" binding:"omitempty,min=1,dive,webhook_event"`
+	IsDisabled *bool    `json:"is_disabled"`
+}
+
+// @Summary      Update webhook subscription
+// @Description  Change the URL or events of a webhook subscription, or disable it. Re-enabling a subscription resets its failure count.
+// @Tags         webhooks
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id       path      int                               true  "Subscription ID"
+// @Param        request  body      updateWebhookSubscriptionRequest  true  "Fields to update"
+// @Success      200      {object}  webhookSubscriptionResponse
+// @Failure      400      {object}  api.Problem "INVALID_REQUEST or VALIDATION_FAILED"
+// @Failure      403      {object}  api.Problem "FORBIDDEN"
+// @Failure      404      {object}  api.Problem "NOT_FOUND: subscription"
+// @Failure      500      {object}  api.Problem "INTERNAL_ERROR"
+// @Router       /api/v1/webhooks/{id} [patch]
+func (server *Server) updateWebhookSubscription(ctx *gin.Context) {
+	var req updateWebhookSubscriptionRequest
+	if !bindAndValidateJsonBody(ctx, &req) {
+		return
+	}
+	if req.URL != nil {
+		if err := server.checkWebhookScheme(*req.URL); err != nil {
+			abortWithError(ctx, err)
+			return
+		}
+	}
+
+	subscription, ok := server.ownWebhookSubscription(ctx)
+	if !ok {
+		return
+	}
+
+	arg := db.UpdateWebhookSubscriptionParams{
+		ID:     subscription.ID,
+		Events: req.Events,
+	}
+	if req.URL != nil {
+		arg.Url = pgtype.Text{String: *req.URL, Valid: true}
+	}
+	if req.IsDisabled != nil {
+		arg.IsDisabled = pgtype.Bool{Bool: *req.IsDisabled, Valid: true}
+	}
+
+	subscription, err := server.store.UpdateWebhookSubscription(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newWebhookSubscriptionResponse(subscription))
+}
+
+// @Summary      Delete webhook subscription
+// @Description  Delete a webhook subscription with its delivery log.
+// @Tags         webhooks
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Subscription ID"
+// @Success      204
+// @Failure      403  {object}  api.Problem "FORBIDDEN"
+// @Failure      404  {object}  api.Problem "NOT_FOUND: subscription"
+// @Failure      500  {object}  api.Problem "INTERNAL_ERROR"
+// @Router       /api/v1/webhooks/{id} [delete]
+func (server *Server) deleteWebhookSubscription(ctx *gin.Context) {
+	subscription, ok := server.ownWebhookSubscription(ctx)
+	if !ok {
+		return
+	}
+
+	if err := server.store.DeleteWebhookSubscription(ctx, subscription.ID); err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+type listWebhookDeliveriesRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=50"`
+}
+
+// @Summary      List webhook deliveries
+// @Description  List the deliveries of a webhook subscription, newest first, with their status, attempts and last error.
+// @Tags         webhooks
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id        path      int  true  "Subscription ID"
+// @Param        page_id   query     int  true  "Page number (min 1)"
+// @Param        page_size query     int  true  "Page size (min 5, max 50)"
+// @Success      200       {array}   webhookDeliveryResponse
+// @Failure      400       {object}  api.Problem "INVALID_REQUEST or VALIDATION_FAILED"
+// @Failure      403       {object}  api.Problem "FORBIDDEN"
+// @Failure      404       {object}  api.Problem "NOT_FOUND: subscription"
+// @Failure      500       {object}  api.Problem "INTERNAL_ERROR"
+// @Router       /api/v1/webhooks/{id}/deliveries [get]
+func (server *Server) listWebhookDeliveries(ctx *gin.Context) {
+	var req listWebhookDeliveriesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		abortWithError(ctx, invalidRequest(err))
+		return
+	}
+
+	subscription, ok := server.ownWebhookSubscription(ctx)
+	if !ok {
+		return
+	}
+
+	deliveries, err := server.store.ListWebhookDeliveries(ctx, db.ListWebhookDeliveriesParams{
+		SubscriptionID: subscription.ID,
+		Limit:          req.PageSize,
+		Offset:         (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	rsp := make([]webhookDeliveryResponse, len(deliveries))
+	for i, delivery := range deliveries {
+		rsp[i] = newWebhookDeliveryResponse(delivery)
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type replayWebhookDeliveryRequest struct {
+	ID         int64 `uri:"id" binding:"required,min=1"`
+	DeliveryID int64 `uri:"delivery_id" binding:"required,min=1"`
+}
+
+// @Summary      Replay webhook delivery
+// @Description  Deliver the event of an earlier delivery again. The replay is recorded as a new delivery with the same event ID.
+// @Tags         webhooks
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id           path      int  true  "Subscription ID"
+// @Param        delivery_id  path      int  true  "Delivery ID"
+// @Success      202          {object}  webhookDeliveryResponse
+// @Failure      403          {object}  api.Problem "FORBIDDEN"
+// @Failure      404          {object}  api.Problem "NOT_FOUND: subscription or delivery"
+// @Failure      409          {object}  api.Problem "CONFLICT: subscription is disabled"
+// @Failure      500          {object}  api.Problem "INTERNAL_ERROR"
+// @Router       /api/v1/webhooks/{id}/deliveries/{delivery_id}/replay [post]
+func (server *Server) replayWebhookDelivery(ctx *gin.Context) {
+	var req replayWebhookDeliveryRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		abortWithError(ctx, invalidRequest(err))
+		return
+	}
+
+	subscription, ok := server.ownWebhookSubscription(ctx)
+	if !ok {
+		return
+	}
+
+	if subscription.IsDisabled {
+		abortWithError(ctx, newError(CodeConflict, "webhook subscription is disabled"))
+		return
+	}
+
+	txResult, err := server.store.ReplayWebhookDeliveryTx(ctx, db.ReplayWebhookDeliveryTxParams{
+		SubscriptionID: subscription.ID,
+		DeliveryID:     req.DeliveryID,
+		AfterCreate: func(q db.Querier, delivery db.WebhookDelivery) error {
+			return worker.DistributeWebhookDelivery(ctx, q, delivery)
+		},
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			err = newError(CodeNotFound, "webhook delivery not found")
+		}
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, newWebhookDeliveryResponse(txResult.Delivery))
+}
@@ -0,0 +1,419 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/LamThanhNguyen/banking-system/db/mock"
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/LamThanhNguyen/banking-system/worker"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func randomWebhookSubscription(owner string) db.WebhookSubscription {
+	return db.WebhookSubscription{
+		ID:        util.RandomInt(1, 1000),
+		Username:  owner,
+		Url:       "https://partner.example.com/hooks",
+		Events:    []string{util.WebhookTransferCreated},
+		Secret:    util.RandomString(32),
+		CreatedAt: time.Now().Truncate(time.Second),
+	}
+}
+
+func TestCreateWebhookSubscriptionAPI(t *testing.T) {
+	user, _ := randomDistributorUser(t)
+	subscription := randomWebhookSubscription(user.Username)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"url":    subscription.Url,
+				"events": subscription.Events,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateWebhookSubscription(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateWebhookSubscriptionParams) (db.WebhookSubscription, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, subscription.Url, arg.Url)
+						require.Equal(t, subscription.Events, arg.Events)
+						// 32 random bytes, hex encoded
+						secret, err := hex.DecodeString(arg.Secret)
+						require.NoError(t, err)
+						require.Len(t, secret, 32)
+						return subscription, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var rsp webhookSubscriptionResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, subscription.ID, rsp.ID)
+				require.Equal(t, subscription.Secret, rsp.Secret)
+			},
+		},
+		{
+			name: "InvalidURL",
+			body: gin.H{
+				"url":    "ftp://partner.example.com",
+				"events": subscription.Events,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateWebhookSubscription(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "LocalURL",
+			body: gin.H{
+				"url":    "https://169.254.169.254/latest/meta-data",
+				"events": subscription.Events,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateWebhookSubscription(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblem(t, recorder, CodeValidationFailed)
+			},
+		},
+		{
+			name: "PlainHTTP",
+			body: gin.H{
+				"url":    "http://partner.example.com/hooks",
+				"events": subscription.Events,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateWebhookSubscription(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblem(t, recorder, CodeValidationFailed)
+			},
+		},
+		{
+			name: "UnsupportedEvent",
+			body: gin.H{
+				"url":    subscription.Url,
+				"events": []string{"user.deleted"},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateWebhookSubscription(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/api/v1/webhooks", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestUpdateWebhookSubscriptionAPI(t *testing.T) {
+	user, _ := randomDistributorUser(t)
+	other, _ := randomDistributorUser(t)
+	subscription := randomWebhookSubscription(user.Username)
+	subscription.IsDisabled = true
+	subscription.ConsecutiveFailures = 20
+
+	testCases := []struct {
+		name          string
+		authUser      db.User
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "Reenable",
+			authUser: user,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetWebhookSubscription(gomock.Any(), gomock.Eq(subscription.ID)).
+					Times(1).
+					Return(subscription, nil)
+
+				updated := subscription
+				updated.IsDisabled = false
+				updated.ConsecutiveFailures = 0
+				store.EXPECT().
+					UpdateWebhookSubscription(gomock.Any(), gomock.Eq(db.UpdateWebhookSubscriptionParams{
+						ID:         subscription.ID,
+						IsDisabled: pgtype.Bool{Bool: false, Valid: true},
+					})).
+					Times(1).
+					Return(updated, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp webhookSubscriptionResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.False(t, rsp.IsDisabled)
+				require.Zero(t, rsp.ConsecutiveFailures)
+				require.Empty(t, rsp.Secret)
+			},
+		},
+		{
+			name:     "OtherUser",
+			authUser: other,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetWebhookSubscription(gomock.Any(), gomock.Eq(subscription.ID)).
+					Times(1).
+					Return(subscription, nil)
+				store.EXPECT().
+					UpdateWebhookSubscription(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "NotFound",
+			authUser: user,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetWebhookSubscription(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.WebhookSubscription{}, db.ErrRecordNotFound)
+				store.EXPECT().
+					UpdateWebhookSubscription(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/api/v1/webhooks/%d", subscription.ID)
+			request, err := http.NewRequest(http.MethodPatch, url, bytes.NewReader([]byte(`{"is_disabled":false}`)))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.authUser.Username, tc.authUser.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestReplayWebhookDeliveryAPI(t *testing.T) {
+	user, _ := randomDistributorUser(t)
+	subscription := randomWebhookSubscription(user.Username)
+	replayed := db.WebhookDelivery{
+		ID:             99,
+		SubscriptionID: subscription.ID,
+		EventType:      util.WebhookTransferCreated,
+		Payload:        []byte(`{"id":"evt"}`),
+		Status:         "pending",
+	}
+
+	testCases := []struct {
+		name          string
+		disabled      bool
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReplayWebhookDeliveryTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.ReplayWebhookDeliveryTxParams) (db.ReplayWebhookDeliveryTxResult, error) {
+						require.Equal(t, subscription.ID, arg.SubscriptionID)
+						require.Equal(t, int64(5), arg.DeliveryID)
+						return db.ReplayWebhookDeliveryTxResult{Delivery: replayed}, arg.AfterCreate(store, replayed)
+					})
+				store.EXPECT().
+					CreateOutbox(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateOutboxParams) (db.Outbox, error) {
+						require.Equal(t, worker.TaskDeliverWebhook, arg.TaskType)
+						return db.Outbox{}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+
+				var rsp webhookDeliveryResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, replayed.ID, rsp.ID)
+				require.JSONEq(t, string(replayed.Payload), string(rsp.Payload))
+			},
+		},
+		{
+			name: "DeliveryNotFound",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReplayWebhookDeliveryTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReplayWebhookDeliveryTxResult{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "SubscriptionDisabled",
+			disabled: true,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReplayWebhookDeliveryTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sub := subscription
+			sub.IsDisabled = tc.disabled
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				GetWebhookSubscription(gomock.Any(), gomock.Eq(sub.ID)).
+				Times(1).
+				Return(sub, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/api/v1/webhooks/%d/deliveries/5/replay", sub.ID)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestListWebhookDeliveriesAPI(t *testing.T) {
+	user, _ := randomDistributorUser(t)
+	subscription := randomWebhookSubscription(user.Username)
+	deliveries := []db.WebhookDelivery{
+		{
+			ID:             2,
+			SubscriptionID: subscription.ID,
+			EventType:      util.WebhookTransferCreated,
+			Payload:        []byte(`{"id":"evt"}`),
+			Status:         "failed",
+			Attempts:       3,
+			ResponseStatus: http.StatusBadGateway,
+			LastError:      "unexpected status 502",
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetWebhookSubscription(gomock.Any(), gomock.Eq(subscription.ID)).
+		Times(1).
+		Return(subscription, nil)
+	store.EXPECT().
+		ListWebhookDeliveries(gomock.Any(), gomock.Eq(db.ListWebhookDeliveriesParams{
+			SubscriptionID: subscription.ID,
+			Limit:          5,
+			Offset:         5,
+		})).
+		Times(1).
+		Return(deliveries, nil)
+
+	server := newTestServer(t, store, nil, nil)
+	recorder := httptest.NewRecorder()
+
+	url := fmt.Sprintf("/api/v1/webhooks/%d/deliveries?page_id=2&page_size=5", subscription.ID)
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var rsp []webhookDeliveryResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+	require.Len(t, rsp, 1)
+	require.Equal(t, "failed", rsp[0].Status)
+	require.Equal(t, deliveries[0].LastError, rsp[0].LastError)
+	require.Nil(t, rsp[0].DeliveredAt)
+}
@@ -0,0 +1,137 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// CreateAccount opens an account for the logged in user.
+func (c *Client) CreateAccount(ctx context.Context, req CreateAccountRequest) (Account, error) {
+	key := req.IdempotencyKey
+	if key == "" {
+		key = NewIdempotencyKey()
+	}
+
+	var account Account
+	err := c.do(ctx, request{
+		method:         http.MethodPost,
+		path:           "/api/v1/accounts",
+		body:           req,
+		auth:           true,
+		idempotencyKey: key,
+	}, &account)
+	return account, err
+}
+
+// GetAccount returns an account of the logged in user.
+func (c *Client) GetAccount(ctx context.Context, id int64) (Account, error) {
+	var account Account
+	err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   "/api/v1/accounts/" + strconv.FormatInt(id, 10),
+		auth:   true,
+	}, &account)
+	return account, err
+}
+
+// ListAccounts lists the accounts of the logged in user.
+func (c *Client) ListAccounts(ctx context.Context, pageID int32, pageSize int32) ([]Account, error) {
+	var accounts []Account
+	err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   "/api/v1/accounts",
+		query:  pageQuery(pageID, pageSize),
+		auth:   true,
+	}, &accounts)
+	return accounts, err
+}
+
+// CloseAccount closes an account of the logged in user. Its balance must be
+// zero; a closed account cannot be reopened.
+func (c *Client) CloseAccount(ctx context.Context, id int64) (Account, error) {
+	var account Account
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/api/v1/accounts/" + strconv.FormatInt(id, 10) + "/close",
+		auth:   true,
+	}, &account)
+	return account, err
+}
+
+// CreateTransfer moves money from an account of the logged in user. A retry
+// after a lost response returns the result of the first attempt.
+func (c *Client) CreateTransfer(ctx context.Context, req CreateTransferRequest) (TransferResult, error) {
+	key := req.IdempotencyKey
+	if key == "" {
+		key = NewIdempotencyKey()
+	}
+
+	var result TransferResult
+	err := c.do(ctx, request{
+		method:         http.MethodPost,
+		path:           "/api/v1/transfers",
+		body:           req,
+		auth:           true,
+		idempotencyKey: key,
+	}, &result)
+	return result, err
+}
+
+// StreamAccountEvents calls fn with the balance events of the logged in
+// user's accounts until ctx is cancelled, fn returns an error, or the stream
+// ends. With a lastEventID above zero, the events after it are sent first;
+// otherwise only new events are. It returns the ID of the last event passed to
+// fn, to resume from when called again.
+func (c *Client) StreamAccountEvents(ctx context.Context, lastEventID int64, fn func(AccountEvent) error) (int64, error) {
+	req := request{method: http.MethodGet, path: "/api/v1/events", auth: true, accept: "text/event-stream"}
+	if lastEventID > 0 {
+		req.query = url.Values{"last_event_id": {strconv.FormatInt(lastEventID, 10)}}
+	}
+
+	rsp, err := c.send(ctx, req)
+	if err != nil {
+		return lastEventID, err
+	}
+	defer rsp.Body.Close()
+
+	scanner := bufio.NewScanner(rsp.Body)
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			// A blank line ends an event; comments and retry hints have no data.
+			if data.Len() == 0 {
+				continue
+			}
+
+			var event AccountEvent
+			if err := json.Unmarshal([]byte(data.String()), &event); err != nil {
+				return lastEventID, fmt.Errorf("cannot decode account event: %w", err)
+			}
+			data.Reset()
+
+			if err := fn(event); err != nil {
+				return lastEventID, err
+			}
+			lastEventID = event.ID
+
+		case strings.HasPrefix(line, "data:"):
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+
+	if ctx.Err() != nil {
+		return lastEventID, ctx.Err()
+	}
+	if err := scanner.Err(); err != nil {
+		return lastEventID, err
+	}
+	return lastEventID, nil
+}
@@ -0,0 +1,180 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+func pageQuery(pageID int32, pageSize int32) url.Values {
+	return url.Values{
+		"page_id":   {strconv.FormatInt(int64(pageID), 10)},
+		"page_size": {strconv.FormatInt(int64(pageSize), 10)},
+	}
+}
+
+// ListUsers lists the users of the banker's tenant.
+func (c *Client) ListUsers(ctx context.Context, req ListUsersRequest) ([]AdminUser, error) {
+	query := pageQuery(req.PageID, req.PageSize)
+	if req.Search != "" {
+		query.Set("search", req.Search)
+	}
+	if req.Role != "" {
+		query.Set("role", req.Role)
+	}
+
+	var users []AdminUser
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/users", query: query, auth: true}, &users)
+	return users, err
+}
+
+func (c *Client) ListUserAccounts(ctx context.Context, username string, pageID int32, pageSize int32) ([]Account, error) {
+	var accounts []Account
+	err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   userPath(username, "accounts"),
+		query:  pageQuery(pageID, pageSize),
+		auth:   true,
+	}, &accounts)
+	return accounts, err
+}
+
+func (c *Client) UpdateUserRole(ctx context.Context, username string, role string) (AdminUser, error) {
+	var user AdminUser
+	err := c.do(ctx, request{
+		method: http.MethodPut,
+		path:   userPath(username, "role"),
+		body:   map[string]string{"role": role},
+		auth:   true,
+	}, &user)
+	return user, err
+}
+
+// DisableUser blocks the user from logging in and revokes their sessions.
+func (c *Client) DisableUser(ctx context.Context, username string) (AdminUser, error) {
+	var user AdminUser
+	err := c.do(ctx, request{method: http.MethodPost, path: userPath(username, "disable"), auth: true}, &user)
+	return user, err
+}
+
+func (c *Client) EnableUser(ctx context.Context, username string) (AdminUser, error) {
+	var user AdminUser
+	err := c.do(ctx, request{method: http.MethodPost, path: userPath(username, "enable"), auth: true}, &user)
+	return user, err
+}
+
+// FreezeAccount freezes an account of the caller's tenant. Transfers from and
+// to it are rejected until it is unfrozen.
+func (c *Client) FreezeAccount(ctx context.Context, id int64, reason string) (Account, error) {
+	return c.changeAccountStatus(ctx, id, "freeze", reason)
+}
+
+// UnfreezeAccount makes a frozen account active again.
+func (c *Client) UnfreezeAccount(ctx context.Context, id int64, reason string) (Account, error) {
+	return c.changeAccountStatus(ctx, id, "unfreeze", reason)
+}
+
+func (c *Client) changeAccountStatus(ctx context.Context, id int64, action string, reason string) (Account, error) {
+	var account Account
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/api/v1/accounts/" + strconv.FormatInt(id, 10) + "/" + action,
+		body:   map[string]string{"reason": reason},
+		auth:   true,
+	}, &account)
+	return account, err
+}
+
+// CreateTenant creates a tenant with its first banker.
+func (c *Client) CreateTenant(ctx context.Context, req CreateTenantRequest) (Tenant, error) {
+	var tenant Tenant
+	err := c.do(ctx, request{method: http.MethodPost, path: "/api/v1/tenants", body: req, auth: true}, &tenant)
+	return tenant, err
+}
+
+// ExplainPolicy reports whether a user is allowed an action and which rules decided it.
+func (c *Client) ExplainPolicy(ctx context.Context, req ExplainPolicyRequest) (PolicyExplanation, error) {
+	var rsp PolicyExplanation
+	err := c.do(ctx, request{method: http.MethodPost, path: "/api/v1/policies/explain", body: req, auth: true}, &rsp)
+	return rsp, err
+}
+
+func (c *Client) ListUserPermissions(ctx context.Context, username string) (UserPermissions, error) {
+	var rsp UserPermissions
+	err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   "/api/v1/policies/users/" + url.PathEscape(username),
+		auth:   true,
+	}, &rsp)
+	return rsp, err
+}
+
+func taskPath(queue string, id string) string {
+	return "/api/v1/queues/" + url.PathEscape(queue) + "/tasks/" + url.PathEscape(id)
+}
+
+func (c *Client) ListQueues(ctx context.Context) ([]Queue, error) {
+	var queues []Queue
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/queues", auth: true}, &queues)
+	return queues, err
+}
+
+// ListTasks lists the tasks of queue in state, such as "archived".
+func (c *Client) ListTasks(ctx context.Context, queue string, state string, pageID int32, pageSize int32) ([]Task, error) {
+	query := pageQuery(pageID, pageSize)
+	query.Set("state", state)
+
+	var tasks []Task
+	err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   "/api/v1/queues/" + url.PathEscape(queue) + "/tasks",
+		query:  query,
+		auth:   true,
+	}, &tasks)
+	return tasks, err
+}
+
+func (c *Client) GetTask(ctx context.Context, queue string, id string) (Task, error) {
+	var task Task
+	err := c.do(ctx, request{method: http.MethodGet, path: taskPath(queue, id), auth: true}, &task)
+	return task, err
+}
+
+// RunTask runs a scheduled, retrying or archived task now.
+func (c *Client) RunTask(ctx context.Context, queue string, id string) (Task, error) {
+	var task Task
+	err := c.do(ctx, request{method: http.MethodPost, path: taskPath(queue, id) + "/run", auth: true}, &task)
+	return task, err
+}
+
+func (c *Client) DeleteTask(ctx context.Context, queue string, id string) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: taskPath(queue, id), auth: true}, nil)
+}
+
+// ListAuditEvents searches the audit log of the banker's tenant, newest first.
+func (c *Client) ListAuditEvents(ctx context.Context, req ListAuditEventsRequest) ([]AuditEvent, error) {
+	query := pageQuery(req.PageID, req.PageSize)
+	for key, value := range map[string]string{
+		"actor":       req.Actor,
+		"action":      req.Action,
+		"target_type": req.TargetType,
+		"target_id":   req.TargetID,
+		"outcome":     req.Outcome,
+	} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	if !req.From.IsZero() {
+		query.Set("from", req.From.Format(time.RFC3339))
+	}
+	if !req.To.IsZero() {
+		query.Set("to", req.To.Format(time.RFC3339))
+	}
+
+	var events []AuditEvent
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/audit-events", query: query, auth: true}, &events)
+	return events, err
+}
@@ -0,0 +1,356 @@
+// Package client is a typed client for the banking system HTTP API.
+//
+// A Client keeps the session of the user that logged in with it, renews the
+// access token with the refresh token before it expires or when the API
+// rejects it, and retries requests that are safe to repeat. Money-moving
+// requests carry an Idempotency-Key, so that a retry after a lost response
+// returns the original result instead of moving the money twice. Errors
+// returned by the API are *Error values that match the Err variables with
+// errors.Is.
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMaxRetries   = 3
+	defaultRetryBackoff = 200 * time.Millisecond
+	maxRetryBackoff     = 5 * time.Second
+	// refreshBefore is how long before expiry the access token is renewed.
+	refreshBefore = 30 * time.Second
+
+	idempotencyKeyHeader = "Idempotency-Key"
+)
+
+// ErrNoSession is returned by calls that need a user when the client has not
+// logged in and was not given a session.
+var ErrNoSession = errors.New("client has no session: call Login first")
+
+// Session holds the tokens of a logged in user.
+type Session struct {
+	AccessToken           string    `json:"access_token"`
+	AccessTokenExpiresAt  time.Time `json:"access_token_expires_at"`
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
+}
+
+// Client calls the banking system API. It is safe for concurrent use.
+type Client struct {
+	baseURL      *url.URL
+	httpClient   *http.Client
+	maxRetries   int
+	retryBackoff time.Duration
+	onSession    func(Session)
+	now          func() time.Time
+
+	mu      sync.Mutex
+	session Session
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sends the requests with httpClient instead of http.DefaultClient.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithRetries sets how many times a failed request that is safe to repeat is
+// retried, and the delay before the first retry, which doubles on each retry.
+// Zero retries disables retrying.
+func WithRetries(maxRetries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.retryBackoff = backoff
+	}
+}
+
+// WithSession starts the client with the tokens of an earlier login.
+func WithSession(session Session) Option {
+	return func(c *Client) {
+		c.session = session
+	}
+}
+
+// WithSessionHook calls fn whenever the client logs in or renews its access
+// token, for example to persist the session.
+func WithSessionHook(fn func(Session)) Option {
+	return func(c *Client) {
+		c.onSession = fn
+	}
+}
+
+// New returns a client of the API served at baseURL, such as "https://bank.example.com".
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid base URL %q: scheme and host are required", baseURL)
+	}
+
+	c := &Client{
+		baseURL:      u,
+		httpClient:   http.DefaultClient,
+		maxRetries:   defaultMaxRetries,
+		retryBackoff: defaultRetryBackoff,
+		now:          time.Now,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// Session returns the current tokens of the client.
+func (c *Client) Session() Session {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.session
+}
+
+func (c *Client) setSession(session Session) {
+	c.mu.Lock()
+	c.session = session
+	c.mu.Unlock()
+
+	if c.onSession != nil {
+		c.onSession(session)
+	}
+}
+
+// request describes one API call.
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	body   any
+	// auth sends the access token of the session.
+	auth bool
+	// idempotencyKey makes a non-idempotent request safe to retry.
+	idempotencyKey string
+	// accept is the media type asked for, application/json when empty.
+	accept string
+}
+
+// retryable reports whether the request can be sent again after a failure
+// without repeating its effect.
+func (r request) retryable() bool {
+	switch r.method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return r.idempotencyKey != ""
+}
+
+// do sends the request and decodes a successful response into out, if not nil.
+func (c *Client) do(ctx context.Context, req request, out any) error {
+	rsp, err := c.send(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+
+	if out == nil {
+		_, _ = io.Copy(io.Discard, rsp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(rsp.Body).Decode(out); err != nil {
+		return fmt.Errorf("cannot decode response of %s %s: %w", req.method, req.path, err)
+	}
+	return nil
+}
+
+// send sends the request until it succeeds, fails with an error that retrying
+// cannot fix, or runs out of retries. The caller must close the body of the
+// returned response, which always has a 2xx status.
+func (c *Client) send(ctx context.Context, req request) (*http.Response, error) {
+	var body []byte
+	if req.body != nil {
+		var err error
+		body, err = json.Marshal(req.body)
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode request: %w", err)
+		}
+	}
+
+	renewed := false
+	for attempt := 0; ; attempt++ {
+		rsp, err := c.sendOnce(ctx, req, body)
+		if err == nil && rsp.StatusCode < http.StatusBadRequest {
+			return rsp, nil
+		}
+
+		var wait time.Duration
+		if err == nil {
+			apiErr := decodeError(rsp)
+			rsp.Body.Close()
+
+			// The access token can be revoked or expire early; renew it once.
+			if apiErr.Code == CodeUnauthenticated && req.auth && !renewed {
+				renewed = true
+				if c.renewAccessToken(ctx, true) == nil {
+					attempt--
+					continue
+				}
+			}
+
+			if !retryableError(apiErr) {
+				return nil, apiErr
+			}
+			err, wait = apiErr, apiErr.RetryAfter
+		} else if ctx.Err() != nil {
+			return nil, err
+		}
+
+		if !req.retryable() || attempt >= c.maxRetries {
+			return nil, err
+		}
+		if wait == 0 {
+			wait = min(c.retryBackoff<<attempt, maxRetryBackoff)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (c *Client) sendOnce(ctx context.Context, req request, body []byte) (*http.Response, error) {
+	u := c.baseURL.JoinPath(req.path)
+	u.RawQuery = req.query.Encode()
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, u.String(), reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	accept := req.accept
+	if accept == "" {
+		accept = "application/json"
+	}
+	httpReq.Header.Set("Accept", accept)
+	if req.idempotencyKey != "" {
+		httpReq.Header.Set(idempotencyKeyHeader, req.idempotencyKey)
+	}
+
+	if req.auth {
+		accessToken, err := c.accessToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+		httpReq.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	return c.httpClient.Do(httpReq)
+}
+
+// retryableError reports whether the request may succeed when sent again.
+func retryableError(err *Error) bool {
+	switch err.Status {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return err.Code == CodeIdempotencyKeyInProgress
+}
+
+// accessToken returns the access token of the session, renewing it first when
+// it is about to expire.
+func (c *Client) accessToken(ctx context.Context) (string, error) {
+	session := c.Session()
+	if session.AccessToken == "" && session.RefreshToken == "" {
+		return "", ErrNoSession
+	}
+
+	if c.now().Add(refreshBefore).Before(session.AccessTokenExpiresAt) {
+		return session.AccessToken, nil
+	}
+	if err := c.renewAccessToken(ctx, false); err != nil {
+		return "", err
+	}
+	return c.Session().AccessToken, nil
+}
+
+// RenewAccessToken gets a new access token with the refresh token of the
+// session. The client does this by itself, so it is rarely needed.
+func (c *Client) RenewAccessToken(ctx context.Context) (Session, error) {
+	if err := c.renewAccessToken(ctx, true); err != nil {
+		return Session{}, err
+	}
+	return c.Session(), nil
+}
+
+// renewAccessToken renews the access token unless it is still fresh or force
+// is set. Concurrent calls are serialized, so the token is renewed once.
+func (c *Client) renewAccessToken(ctx context.Context, force bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	session := c.session
+	if session.RefreshToken == "" {
+		return ErrNoSession
+	}
+	if !force && c.now().Add(refreshBefore).Before(session.AccessTokenExpiresAt) {
+		// Another call renewed it while this one waited for the lock.
+		return nil
+	}
+
+	var rsp struct {
+		AccessToken          string    `json:"access_token"`
+		AccessTokenExpiresAt time.Time `json:"access_token_expires_at"`
+	}
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/api/v1/tokens/renew-access",
+		body:   map[string]string{"refresh_token": session.RefreshToken},
+	}, &rsp)
+	if err != nil {
+		return fmt.Errorf("cannot renew access token: %w", err)
+	}
+
+	session.AccessToken = rsp.AccessToken
+	session.AccessTokenExpiresAt = rsp.AccessTokenExpiresAt
+	c.session = session
+
+	if c.onSession != nil {
+		c.onSession(session)
+	}
+	return nil
+}
+
+// NewIdempotencyKey returns a random key for requests that accept an
+// Idempotency-Key. Keep it with the request and send the same key when the
+// request is retried, even from another process.
+func NewIdempotencyKey() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
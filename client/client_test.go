@@ -0,0 +1,355 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeProblem(w http.ResponseWriter, status int, code ErrorCode) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.Header().Set("X-Trace-ID", "trace-1")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(Error{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Code:   code,
+	})
+}
+
+func newTestClient(t *testing.T, handler http.Handler, opts ...Option) *Client {
+	httpServer := httptest.NewServer(handler)
+	t.Cleanup(httpServer.Close)
+
+	opts = append([]Option{WithRetries(3, time.Millisecond)}, opts...)
+	c, err := New(httpServer.URL, opts...)
+	require.NoError(t, err)
+	return c
+}
+
+func TestNew(t *testing.T) {
+	_, err := New("localhost:8080")
+	require.Error(t, err)
+
+	c, err := New("http://localhost:8080/")
+	require.NoError(t, err)
+	require.Equal(t, "http://localhost:8080", c.baseURL.String())
+}
+
+func TestLogin(t *testing.T) {
+	expiresAt := time.Now().Add(time.Minute).UTC().Truncate(time.Second)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/users/login", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, LoginResponse{
+			AccessToken:           "access",
+			AccessTokenExpiresAt:  expiresAt,
+			RefreshToken:          "refresh",
+			RefreshTokenExpiresAt: expiresAt,
+		})
+	})
+	mux.HandleFunc("GET /api/v1/accounts/1", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer access", r.Header.Get("Authorization"))
+		writeJSON(w, http.StatusOK, Account{ID: 1})
+	})
+
+	var saved Session
+	c := newTestClient(t, mux, WithSessionHook(func(session Session) {
+		saved = session
+	}))
+
+	_, err := c.GetAccount(context.Background(), 1)
+	require.ErrorIs(t, err, ErrNoSession)
+
+	_, err = c.Login(context.Background(), "alice", "secret")
+	require.NoError(t, err)
+	require.Equal(t, "access", saved.AccessToken)
+	require.Equal(t, saved, c.Session())
+
+	account, err := c.GetAccount(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), account.ID)
+}
+
+func TestRenewExpiredAccessToken(t *testing.T) {
+	var renewals int
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/tokens/renew-access", func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, "refresh", req["refresh_token"])
+
+		renewals++
+		writeJSON(w, http.StatusOK, map[string]any{
+			"access_token":            "renewed",
+			"access_token_expires_at": time.Now().Add(time.Minute),
+		})
+	})
+	mux.HandleFunc("GET /api/v1/accounts", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer renewed", r.Header.Get("Authorization"))
+		writeJSON(w, http.StatusOK, []Account{{ID: 1}})
+	})
+
+	c := newTestClient(t, mux, WithSession(Session{
+		AccessToken:          "expired",
+		AccessTokenExpiresAt: time.Now().Add(-time.Second),
+		RefreshToken:         "refresh",
+	}))
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			accounts, err := c.ListAccounts(context.Background(), 1, 5)
+			require.NoError(t, err)
+			require.Len(t, accounts, 1)
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, 1, renewals)
+	require.Equal(t, "renewed", c.Session().AccessToken)
+}
+
+func TestRenewRejectedAccessToken(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/tokens/renew-access", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{
+			"access_token":            "renewed",
+			"access_token_expires_at": time.Now().Add(time.Minute),
+		})
+	})
+	mux.HandleFunc("GET /api/v1/accounts/1", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer renewed" {
+			writeProblem(w, http.StatusUnauthorized, CodeUnauthenticated)
+			return
+		}
+		writeJSON(w, http.StatusOK, Account{ID: 1})
+	})
+
+	c := newTestClient(t, mux, WithSession(Session{
+		AccessToken:          "revoked",
+		AccessTokenExpiresAt: time.Now().Add(time.Minute),
+		RefreshToken:         "refresh",
+	}))
+
+	account, err := c.GetAccount(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), account.ID)
+}
+
+func TestRenewAccessTokenFailure(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/tokens/renew-access", func(w http.ResponseWriter, r *http.Request) {
+		writeProblem(w, http.StatusUnauthorized, CodeUnauthenticated)
+	})
+	mux.HandleFunc("GET /api/v1/accounts/1", func(w http.ResponseWriter, r *http.Request) {
+		writeProblem(w, http.StatusUnauthorized, CodeUnauthenticated)
+	})
+
+	c := newTestClient(t, mux, WithSession(Session{
+		AccessToken:          "revoked",
+		AccessTokenExpiresAt: time.Now().Add(time.Minute),
+		RefreshToken:         "revoked",
+	}))
+
+	_, err := c.GetAccount(context.Background(), 1)
+	require.ErrorIs(t, err, ErrUnauthenticated)
+}
+
+func TestProblemError(t *testing.T) {
+	var attempts int
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/transfers", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		writeProblem(w, http.StatusUnprocessableEntity, CodeInsufficientFunds)
+	})
+
+	c := newTestClient(t, mux, WithSession(Session{
+		AccessToken:          "access",
+		AccessTokenExpiresAt: time.Now().Add(time.Minute),
+	}))
+
+	_, err := c.CreateTransfer(context.Background(), CreateTransferRequest{
+		FromAccountID: 1,
+		ToAccountID:   2,
+		Amount:        10,
+		Currency:      "USD",
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+	require.NotErrorIs(t, err, ErrAccountFrozen)
+	require.Equal(t, 1, attempts)
+
+	var apiErr *Error
+	require.True(t, errors.As(err, &apiErr))
+	require.Equal(t, http.StatusUnprocessableEntity, apiErr.Status)
+	require.Equal(t, "trace-1", apiErr.TraceID)
+}
+
+func TestErrorWithoutProblem(t *testing.T) {
+	var attempts int
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/users", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		http.Error(w, "bad gateway", http.StatusBadGateway)
+	})
+
+	c := newTestClient(t, mux)
+
+	// A request without an idempotency key is not retried.
+	_, err := c.CreateUser(context.Background(), CreateUserRequest{Username: "alice"})
+	require.ErrorIs(t, err, ErrInternal)
+	require.Equal(t, 1, attempts)
+}
+
+// dropFirstResponse sends every request, but loses the response of the first.
+type dropFirstResponse struct {
+	mu      sync.Mutex
+	dropped bool
+}
+
+func (d *dropFirstResponse) RoundTrip(req *http.Request) (*http.Response, error) {
+	rsp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.dropped {
+		d.dropped = true
+		rsp.Body.Close()
+		return nil, fmt.Errorf("connection reset")
+	}
+	return rsp, nil
+}
+
+func TestIdempotentRetry(t *testing.T) {
+	var keys []string
+	results := map[string]TransferResult{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/transfers", func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotencyKeyHeader)
+		keys = append(keys, key)
+
+		result, ok := results[key]
+		if !ok {
+			result = TransferResult{Transfer: Transfer{ID: int64(len(results) + 1)}}
+			results[key] = result
+		}
+		writeJSON(w, http.StatusOK, result)
+	})
+
+	c := newTestClient(t, mux,
+		WithHTTPClient(&http.Client{Transport: &dropFirstResponse{}}),
+		WithSession(Session{
+			AccessToken:          "access",
+			AccessTokenExpiresAt: time.Now().Add(time.Minute),
+		}),
+	)
+
+	result, err := c.CreateTransfer(context.Background(), CreateTransferRequest{
+		FromAccountID: 1,
+		ToAccountID:   2,
+		Amount:        10,
+		Currency:      "USD",
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), result.Transfer.ID)
+
+	// The retry carried the key of the lost attempt, so only one transfer was made.
+	require.Len(t, keys, 2)
+	require.NotEmpty(t, keys[0])
+	require.Equal(t, keys[0], keys[1])
+	require.Len(t, results, 1)
+}
+
+func TestRetryIdempotencyKeyInProgress(t *testing.T) {
+	var attempts int
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/transfers", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "key-1", r.Header.Get(idempotencyKeyHeader))
+
+		attempts++
+		if attempts < 3 {
+			writeProblem(w, http.StatusConflict, CodeIdempotencyKeyInProgress)
+			return
+		}
+		writeJSON(w, http.StatusOK, TransferResult{Transfer: Transfer{ID: 1}})
+	})
+
+	c := newTestClient(t, mux, WithSession(Session{
+		AccessToken:          "access",
+		AccessTokenExpiresAt: time.Now().Add(time.Minute),
+	}))
+
+	result, err := c.CreateTransfer(context.Background(), CreateTransferRequest{IdempotencyKey: "key-1"})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), result.Transfer.ID)
+	require.Equal(t, 3, attempts)
+}
+
+func TestStreamAccountEvents(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/events", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "text/event-stream", r.Header.Get("Accept"))
+		require.Equal(t, "5", r.URL.Query().Get("last_event_id"))
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "retry: 3000\n\n")
+		fmt.Fprint(w, "id: 6\nevent: transfer.sent\ndata: {\"id\":6,\"account_id\":1,\"type\":\"transfer.sent\",\"data\":{\"amount\":-10,\"balance\":90}}\n\n")
+		fmt.Fprint(w, ": heartbeat\n\n")
+		fmt.Fprint(w, "id: 7\nevent: balance.adjusted\ndata: {\"id\":7,\"account_id\":1,\"type\":\"balance.adjusted\",\"data\":{\"amount\":5,\"balance\":95}}\n\n")
+	})
+
+	c := newTestClient(t, mux, WithSession(Session{
+		AccessToken:          "access",
+		AccessTokenExpiresAt: time.Now().Add(time.Minute),
+	}))
+
+	var events []AccountEvent
+	lastEventID, err := c.StreamAccountEvents(context.Background(), 5, func(event AccountEvent) error {
+		events = append(events, event)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(7), lastEventID)
+
+	require.Len(t, events, 2)
+	require.Equal(t, "transfer.sent", events[0].Type)
+	require.Equal(t, int64(90), events[0].Data.Balance)
+	require.Equal(t, "balance.adjusted", events[1].Type)
+	require.Equal(t, int64(95), events[1].Data.Balance)
+
+	// An error of fn stops the stream at the last event handled.
+	errStop := errors.New("stop")
+	lastEventID, err = c.StreamAccountEvents(context.Background(), 5, func(event AccountEvent) error {
+		if event.ID == 7 {
+			return errStop
+		}
+		return nil
+	})
+	require.ErrorIs(t, err, errStop)
+	require.Equal(t, int64(6), lastEventID)
+}
@@ -0,0 +1,161 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ErrorCode is the machine-readable code of an API error.
+type ErrorCode string
+
+// Error codes returned by the API
+const (
+	CodeInvalidRequest              ErrorCode = "INVALID_REQUEST"
+	CodeValidationFailed            ErrorCode = "VALIDATION_FAILED"
+	CodeUnauthenticated             ErrorCode = "UNAUTHENTICATED"
+	CodeInvalidCredentials          ErrorCode = "INVALID_CREDENTIALS"
+	CodeForbidden                   ErrorCode = "FORBIDDEN"
+	CodeUserDisabled                ErrorCode = "USER_DISABLED"
+	CodeEmailNotVerified            ErrorCode = "EMAIL_NOT_VERIFIED"
+	CodeNotFound                    ErrorCode = "NOT_FOUND"
+	CodeUserNotFound                ErrorCode = "USER_NOT_FOUND"
+	CodeAccountNotFound             ErrorCode = "ACCOUNT_NOT_FOUND"
+	CodeAlreadyExists               ErrorCode = "ALREADY_EXISTS"
+	CodeConflict                    ErrorCode = "CONFLICT"
+	CodeAccountNotOwned             ErrorCode = "ACCOUNT_NOT_OWNED"
+	CodeAccountFrozen               ErrorCode = "ACCOUNT_FROZEN"
+	CodeAccountClosed               ErrorCode = "ACCOUNT_CLOSED"
+	CodeAccountNotEmpty             ErrorCode = "ACCOUNT_NOT_EMPTY"
+	CodeInvalidStatusChange         ErrorCode = "INVALID_STATUS_CHANGE"
+	CodeInvalidReasonCode           ErrorCode = "INVALID_REASON_CODE"
+	CodeCurrencyMismatch            ErrorCode = "CURRENCY_MISMATCH"
+	CodeInsufficientFunds           ErrorCode = "INSUFFICIENT_FUNDS"
+	CodeCrossTenantTransferDisabled ErrorCode = "CROSS_TENANT_TRANSFER_DISABLED"
+	CodeEmailAlreadyVerified        ErrorCode = "EMAIL_ALREADY_VERIFIED"
+	CodePhoneAlreadyVerified        ErrorCode = "PHONE_ALREADY_VERIFIED"
+	CodeIncorrectVerificationCode   ErrorCode = "INCORRECT_VERIFICATION_CODE"
+	CodeRateLimited                 ErrorCode = "RATE_LIMITED"
+	CodeIdempotencyKeyReused        ErrorCode = "IDEMPOTENCY_KEY_REUSED"
+	CodeIdempotencyKeyInProgress    ErrorCode = "IDEMPOTENCY_KEY_IN_PROGRESS"
+	CodeInternal                    ErrorCode = "INTERNAL_ERROR"
+)
+
+// Errors to match with errors.Is, for example
+//
+//	if errors.Is(err, client.ErrInsufficientFunds) { ... }
+var (
+	ErrInvalidRequest              = &Error{Code: CodeInvalidRequest}
+	ErrValidationFailed            = &Error{Code: CodeValidationFailed}
+	ErrUnauthenticated             = &Error{Code: CodeUnauthenticated}
+	ErrInvalidCredentials          = &Error{Code: CodeInvalidCredentials}
+	ErrForbidden                   = &Error{Code: CodeForbidden}
+	ErrUserDisabled                = &Error{Code: CodeUserDisabled}
+	ErrEmailNotVerified            = &Error{Code: CodeEmailNotVerified}
+	ErrNotFound                    = &Error{Code: CodeNotFound}
+	ErrUserNotFound                = &Error{Code: CodeUserNotFound}
+	ErrAccountNotFound             = &Error{Code: CodeAccountNotFound}
+	ErrAlreadyExists               = &Error{Code: CodeAlreadyExists}
+	ErrConflict                    = &Error{Code: CodeConflict}
+	ErrAccountNotOwned             = &Error{Code: CodeAccountNotOwned}
+	ErrAccountFrozen               = &Error{Code: CodeAccountFrozen}
+	ErrAccountClosed               = &Error{Code: CodeAccountClosed}
+	ErrAccountNotEmpty             = &Error{Code: CodeAccountNotEmpty}
+	ErrInvalidStatusChange         = &Error{Code: CodeInvalidStatusChange}
+	ErrInvalidReasonCode           = &Error{Code: CodeInvalidReasonCode}
+	ErrCurrencyMismatch            = &Error{Code: CodeCurrencyMismatch}
+	ErrInsufficientFunds           = &Error{Code: CodeInsufficientFunds}
+	ErrCrossTenantTransferDisabled = &Error{Code: CodeCrossTenantTransferDisabled}
+	ErrEmailAlreadyVerified        = &Error{Code: CodeEmailAlreadyVerified}
+	ErrPhoneAlreadyVerified        = &Error{Code: CodePhoneAlreadyVerified}
+	ErrIncorrectVerificationCode   = &Error{Code: CodeIncorrectVerificationCode}
+	ErrRateLimited                 = &Error{Code: CodeRateLimited}
+	ErrIdempotencyKeyReused        = &Error{Code: CodeIdempotencyKeyReused}
+	ErrIdempotencyKeyInProgress    = &Error{Code: CodeIdempotencyKeyInProgress}
+	ErrInternal                    = &Error{Code: CodeInternal}
+)
+
+// FieldViolation is an invalid field of a VALIDATION_FAILED request.
+type FieldViolation struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error is an RFC 7807 problem returned by the API.
+type Error struct {
+	Type       string           `json:"type"`
+	Title      string           `json:"title"`
+	Status     int              `json:"status"`
+	Detail     string           `json:"detail"`
+	Instance   string           `json:"instance"`
+	Code       ErrorCode        `json:"code"`
+	TraceID    string           `json:"trace_id"`
+	Violations []FieldViolation `json:"violations"`
+
+	// RetryAfter is the delay the server asked for before retrying, if any.
+	RetryAfter time.Duration `json:"-"`
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("%s (%d)", e.Code, e.Status)
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	if e.TraceID != "" {
+		msg += " [trace_id " + e.TraceID + "]"
+	}
+	return msg
+}
+
+// Is reports whether target is an *Error with the same code, so that the
+// Err variables match any error of their code.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// decodeError reads the problem of an error response. Responses that are not
+// problems, such as those of a proxy in front of the API, get a code from
+// their status.
+func decodeError(rsp *http.Response) *Error {
+	apiErr := &Error{}
+	body, err := io.ReadAll(io.LimitReader(rsp.Body, 1<<20))
+	if err != nil || json.Unmarshal(body, apiErr) != nil || apiErr.Code == "" {
+		apiErr = &Error{
+			Title:  http.StatusText(rsp.StatusCode),
+			Code:   codeFromStatus(rsp.StatusCode),
+			Detail: string(body),
+		}
+	}
+
+	apiErr.Status = rsp.StatusCode
+	if apiErr.TraceID == "" {
+		apiErr.TraceID = rsp.Header.Get("X-Trace-ID")
+	}
+	if seconds, err := strconv.Atoi(rsp.Header.Get("Retry-After")); err == nil {
+		apiErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+	return apiErr
+}
+
+func codeFromStatus(status int) ErrorCode {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeInvalidRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthenticated
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	default:
+		return CodeInternal
+	}
+}
@@ -0,0 +1,292 @@
+package client
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// User is a user as seen by themselves.
+type User struct {
+	Username          string    `json:"username"`
+	FullName          string    `json:"full_name"`
+	Email             string    `json:"email"`
+	TenantID          string    `json:"tenant_id"`
+	Locale            string    `json:"locale"`
+	Phone             string    `json:"phone,omitempty"`
+	IsPhoneVerified   bool      `json:"is_phone_verified"`
+	PasswordChangedAt time.Time `json:"password_changed_at"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// AdminUser is a user as seen by a banker.
+type AdminUser struct {
+	Username          string    `json:"username"`
+	FullName          string    `json:"full_name"`
+	Email             string    `json:"email"`
+	Role              string    `json:"role"`
+	IsEmailVerified   bool      `json:"is_email_verified"`
+	IsDisabled        bool      `json:"is_disabled"`
+	PasswordChangedAt time.Time `json:"password_changed_at"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+type CreateUserRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	FullName string `json:"full_name"`
+	Email    string `json:"email"`
+}
+
+// UpdateUserRequest changes the fields that are not nil.
+type UpdateUserRequest struct {
+	Password *string `json:"password,omitempty"`
+	FullName *string `json:"full_name,omitempty"`
+	Email    *string `json:"email,omitempty"`
+	Locale   *string `json:"locale,omitempty"`
+}
+
+type LoginResponse struct {
+	SessionID             string    `json:"session_id"`
+	AccessToken           string    `json:"access_token"`
+	AccessTokenExpiresAt  time.Time `json:"access_token_expires_at"`
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
+	User                  User      `json:"user"`
+}
+
+type ListUsersRequest struct {
+	Search   string
+	Role     string
+	PageID   int32
+	PageSize int32
+}
+
+// Account statuses
+const (
+	AccountStatusActive = "active"
+	AccountStatusFrozen = "frozen"
+	AccountStatusClosed = "closed"
+)
+
+type Account struct {
+	ID              int64     `json:"id"`
+	Owner           string    `json:"owner"`
+	Balance         int64     `json:"balance"`
+	Currency        string    `json:"currency"`
+	CreatedAt       time.Time `json:"created_at"`
+	TenantID        string    `json:"tenant_id"`
+	Status          string    `json:"status"`
+	StatusReason    string    `json:"status_reason"`
+	StatusChangedAt time.Time `json:"status_changed_at"`
+}
+
+type Transfer struct {
+	ID            int64     `json:"id"`
+	FromAccountID int64     `json:"from_account_id"`
+	ToAccountID   int64     `json:"to_account_id"`
+	Amount        int64     `json:"amount"`
+	CreatedAt     time.Time `json:"created_at"`
+	TenantID      string    `json:"tenant_id"`
+}
+
+type Entry struct {
+	ID        int64     `json:"id"`
+	AccountID int64     `json:"account_id"`
+	Amount    int64     `json:"amount"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CreateAccountRequest struct {
+	Currency string `json:"currency"`
+	// IdempotencyKey makes the request safe to retry; one is generated when empty.
+	IdempotencyKey string `json:"-"`
+}
+
+type CreateTransferRequest struct {
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	Currency      string `json:"currency"`
+	// IdempotencyKey makes the request safe to retry; one is generated when
+	// empty. Set it to retry a transfer whose outcome is unknown, for example
+	// after a crash, without moving the money twice.
+	IdempotencyKey string `json:"-"`
+}
+
+type TransferResult struct {
+	Transfer    Transfer `json:"transfer"`
+	FromAccount Account  `json:"from_account"`
+	ToAccount   Account  `json:"to_account"`
+	FromEntry   Entry    `json:"from_entry"`
+	ToEntry     Entry    `json:"to_entry"`
+}
+
+// AccountEvent is a committed change to the balance of an account.
+type AccountEvent struct {
+	ID        int64     `json:"id"`
+	AccountID int64     `json:"account_id"`
+	Type      string    `json:"type"`
+	Data      EventData `json:"data"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// EventData is the change of an AccountEvent and the balance right after it.
+type EventData struct {
+	AccountID    int64  `json:"account_id"`
+	Amount       int64  `json:"amount"`
+	Balance      int64  `json:"balance"`
+	Currency     string `json:"currency"`
+	EntryID      int64  `json:"entry_id"`
+	TransferID   int64  `json:"transfer_id,omitempty"`
+	AdjustmentID int64  `json:"adjustment_id,omitempty"`
+}
+
+type NotificationPreference struct {
+	EventType string `json:"event_type"`
+	Channel   string `json:"channel"`
+	Currency  string `json:"currency,omitempty"`
+	Enabled   bool   `json:"enabled"`
+	Threshold int64  `json:"threshold"`
+}
+
+type TenantAdmin struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	FullName string `json:"full_name"`
+	Email    string `json:"email"`
+}
+
+type CreateTenantRequest struct {
+	ID                        string      `json:"id"`
+	Name                      string      `json:"name"`
+	AllowCrossTenantTransfers bool        `json:"allow_cross_tenant_transfers"`
+	Admin                     TenantAdmin `json:"admin"`
+}
+
+type Tenant struct {
+	ID                        string    `json:"id"`
+	Name                      string    `json:"name"`
+	AllowCrossTenantTransfers bool      `json:"allow_cross_tenant_transfers"`
+	CreatedAt                 time.Time `json:"created_at"`
+	Admin                     User      `json:"admin"`
+}
+
+type ExplainPolicyRequest struct {
+	Username string `json:"username"`
+	Role     string `json:"role,omitempty"`
+	Object   string `json:"object,omitempty"`
+	Action   string `json:"action"`
+}
+
+type PolicyExplanation struct {
+	Username     string   `json:"username"`
+	Role         string   `json:"role"`
+	TenantID     string   `json:"tenant_id"`
+	Object       string   `json:"object"`
+	Action       string   `json:"action"`
+	Allowed      bool     `json:"allowed"`
+	MatchedRules []string `json:"matched_rules"`
+}
+
+type UserPermissions struct {
+	Username string   `json:"username"`
+	Role     string   `json:"role"`
+	TenantID string   `json:"tenant_id"`
+	Actions  []string `json:"actions"`
+}
+
+type CreateWebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	// Secret signs the deliveries; a random one is generated when empty.
+	Secret string `json:"secret,omitempty"`
+}
+
+// UpdateWebhookRequest changes the fields that are set.
+type UpdateWebhookRequest struct {
+	URL        *string  `json:"url,omitempty"`
+	Events     []string `json:"events,omitempty"`
+	IsDisabled *bool    `json:"is_disabled,omitempty"`
+}
+
+type WebhookSubscription struct {
+	ID                  int64     `json:"id"`
+	URL                 string    `json:"url"`
+	Events              []string  `json:"events"`
+	IsDisabled          bool      `json:"is_disabled"`
+	ConsecutiveFailures int32     `json:"consecutive_failures"`
+	CreatedAt           time.Time `json:"created_at"`
+	// Secret is only returned when the subscription is created.
+	Secret string `json:"secret,omitempty"`
+}
+
+type WebhookDelivery struct {
+	ID             int64           `json:"id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int32           `json:"attempts"`
+	ResponseStatus int32           `json:"response_status,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+type Queue struct {
+	Queue          string `json:"queue"`
+	Size           int    `json:"size"`
+	Pending        int    `json:"pending"`
+	Active         int    `json:"active"`
+	Scheduled      int    `json:"scheduled"`
+	Retry          int    `json:"retry"`
+	Archived       int    `json:"archived"`
+	Completed      int    `json:"completed"`
+	ProcessedToday int    `json:"processed_today"`
+	FailedToday    int    `json:"failed_today"`
+	ProcessedTotal int    `json:"processed_total"`
+	FailedTotal    int    `json:"failed_total"`
+	LatencyMs      int64  `json:"latency_ms"`
+	Paused         bool   `json:"paused"`
+}
+
+type Task struct {
+	ID            string          `json:"id"`
+	Queue         string          `json:"queue"`
+	Type          string          `json:"type"`
+	Payload       json.RawMessage `json:"payload"`
+	State         string          `json:"state"`
+	MaxRetry      int             `json:"max_retry"`
+	Retried       int             `json:"retried"`
+	LastError     string          `json:"last_error,omitempty"`
+	LastFailedAt  *time.Time      `json:"last_failed_at,omitempty"`
+	NextProcessAt *time.Time      `json:"next_process_at,omitempty"`
+}
+
+type ListAuditEventsRequest struct {
+	Actor      string
+	Action     string
+	TargetType string
+	TargetID   string
+	Outcome    string
+	From       time.Time
+	To         time.Time
+	PageID     int32
+	PageSize   int32
+}
+
+type AuditEvent struct {
+	ID         int64           `json:"id"`
+	Actor      string          `json:"actor"`
+	ActorRole  string          `json:"actor_role"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetID   string          `json:"target_id"`
+	Outcome    string          `json:"outcome"`
+	Reason     string          `json:"reason,omitempty"`
+	RequestID  string          `json:"request_id,omitempty"`
+	ClientIP   string          `json:"client_ip,omitempty"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	Hash       string          `json:"hash"`
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+func userPath(username string, elem ...string) string {
+	p := "/api/v1/users/" + url.PathEscape(username)
+	for _, e := range elem {
+		p += "/" + e
+	}
+	return p
+}
+
+// Health checks that the API is up.
+func (c *Client) Health(ctx context.Context) error {
+	return c.do(ctx, request{method: http.MethodGet, path: "/api/v1/health"}, nil)
+}
+
+// CreateUser registers a user. It does not log in.
+func (c *Client) CreateUser(ctx context.Context, req CreateUserRequest) (User, error) {
+	var user User
+	err := c.do(ctx, request{method: http.MethodPost, path: "/api/v1/users", body: req}, &user)
+	return user, err
+}
+
+// Login logs in and keeps the session for the calls that need a user.
+func (c *Client) Login(ctx context.Context, username string, password string) (LoginResponse, error) {
+	var rsp LoginResponse
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/api/v1/users/login",
+		body:   map[string]string{"username": username, "password": password},
+	}, &rsp)
+	if err != nil {
+		return LoginResponse{}, err
+	}
+
+	c.setSession(Session{
+		AccessToken:           rsp.AccessToken,
+		AccessTokenExpiresAt:  rsp.AccessTokenExpiresAt,
+		RefreshToken:          rsp.RefreshToken,
+		RefreshTokenExpiresAt: rsp.RefreshTokenExpiresAt,
+	})
+	return rsp, nil
+}
+
+// VerifyEmail confirms an email address with the code sent to it.
+func (c *Client) VerifyEmail(ctx context.Context, emailID int64, secretCode string) error {
+	return c.do(ctx, request{
+		method: http.MethodGet,
+		path:   "/api/v1/users/verify-email",
+		query: url.Values{
+			"email_id":    {strconv.FormatInt(emailID, 10)},
+			"secret_code": {secretCode},
+		},
+	}, nil)
+}
+
+func (c *Client) UpdateUser(ctx context.Context, username string, req UpdateUserRequest) (User, error) {
+	var user User
+	err := c.do(ctx, request{method: http.MethodPatch, path: userPath(username), body: req, auth: true}, &user)
+	return user, err
+}
+
+// ResendVerifyEmail sends a new verification email to the logged in user.
+func (c *Client) ResendVerifyEmail(ctx context.Context, username string) error {
+	return c.do(ctx, request{method: http.MethodPost, path: userPath(username, "verify-email", "resend"), auth: true}, nil)
+}
+
+// UpdateUserPhone sets the phone number of the user and sends it a verification code.
+func (c *Client) UpdateUserPhone(ctx context.Context, username string, phone string) (User, error) {
+	var user User
+	err := c.do(ctx, request{
+		method: http.MethodPut,
+		path:   userPath(username, "phone"),
+		body:   map[string]string{"phone": phone},
+		auth:   true,
+	}, &user)
+	return user, err
+}
+
+func (c *Client) VerifyUserPhone(ctx context.Context, username string, code string) (User, error) {
+	var user User
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   userPath(username, "phone", "verify"),
+		body:   map[string]string{"code": code},
+		auth:   true,
+	}, &user)
+	return user, err
+}
+
+func (c *Client) ListNotificationPreferences(ctx context.Context, username string) ([]NotificationPreference, error) {
+	var prefs []NotificationPreference
+	err := c.do(ctx, request{method: http.MethodGet, path: userPath(username, "notification-preferences"), auth: true}, &prefs)
+	return prefs, err
+}
+
+func (c *Client) UpdateNotificationPreference(ctx context.Context, username string, pref NotificationPreference) (NotificationPreference, error) {
+	var rsp NotificationPreference
+	err := c.do(ctx, request{
+		method: http.MethodPut,
+		path:   userPath(username, "notification-preferences"),
+		body:   pref,
+		auth:   true,
+	}, &rsp)
+	return rsp, err
+}
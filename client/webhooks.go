@@ -0,0 +1,63 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+func webhookPath(id int64) string {
+	return "/api/v1/webhooks/" + strconv.FormatInt(id, 10)
+}
+
+// CreateWebhook subscribes a URL to events. The returned subscription holds
+// the signing secret, which is not returned again.
+func (c *Client) CreateWebhook(ctx context.Context, req CreateWebhookRequest) (WebhookSubscription, error) {
+	var subscription WebhookSubscription
+	err := c.do(ctx, request{method: http.MethodPost, path: "/api/v1/webhooks", body: req, auth: true}, &subscription)
+	return subscription, err
+}
+
+func (c *Client) ListWebhooks(ctx context.Context) ([]WebhookSubscription, error) {
+	var subscriptions []WebhookSubscription
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/webhooks", auth: true}, &subscriptions)
+	return subscriptions, err
+}
+
+func (c *Client) GetWebhook(ctx context.Context, id int64) (WebhookSubscription, error) {
+	var subscription WebhookSubscription
+	err := c.do(ctx, request{method: http.MethodGet, path: webhookPath(id), auth: true}, &subscription)
+	return subscription, err
+}
+
+func (c *Client) UpdateWebhook(ctx context.Context, id int64, req UpdateWebhookRequest) (WebhookSubscription, error) {
+	var subscription WebhookSubscription
+	err := c.do(ctx, request{method: http.MethodPatch, path: webhookPath(id), body: req, auth: true}, &subscription)
+	return subscription, err
+}
+
+func (c *Client) DeleteWebhook(ctx context.Context, id int64) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: webhookPath(id), auth: true}, nil)
+}
+
+func (c *Client) ListWebhookDeliveries(ctx context.Context, id int64, pageID int32, pageSize int32) ([]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+	err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   webhookPath(id) + "/deliveries",
+		query:  pageQuery(pageID, pageSize),
+		auth:   true,
+	}, &deliveries)
+	return deliveries, err
+}
+
+// ReplayWebhookDelivery sends a delivery again as a new delivery.
+func (c *Client) ReplayWebhookDelivery(ctx context.Context, id int64, deliveryID int64) (WebhookDelivery, error) {
+	var delivery WebhookDelivery
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   webhookPath(id) + "/deliveries/" + strconv.FormatInt(deliveryID, 10) + "/replay",
+		auth:   true,
+	}, &delivery)
+	return delivery, err
+}
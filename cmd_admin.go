@@ -0,0 +1,276 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/LamThanhNguyen/banking-system/admin"
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/util"
+)
+
+// adminAction is an action of the admin command. setup registers its flags and
+// returns the function that runs once they are parsed.
+type adminAction struct {
+	name  string
+	usage string
+	setup func(flags *flag.FlagSet) func(ctx context.Context, admin *admin.Admin, format string) error
+}
+
+var adminActions = []adminAction{
+	{"create-user", "create a user with a role", adminCreateUser},
+	{"reset-password", "set a new password and block the user's sessions", adminResetPassword},
+	{"block-sessions", "block every session of a user", adminBlockSessions},
+	{"freeze-account", "freeze an account", adminChangeAccountStatus(db.AccountStatusActive, db.AccountStatusFrozen)},
+	{"unfreeze-account", "unfreeze an account", adminChangeAccountStatus(db.AccountStatusFrozen, db.AccountStatusActive)},
+	{"close-account", "close an account with a zero balance", adminChangeAccountStatus(db.AccountStatusActive, db.AccountStatusClosed)},
+	{"adjust-balance", "post a manual credit or debit with a reason", adminAdjustBalance},
+	{"export-policies", "print every authorization policy", adminExportPolicies},
+	{"import-policies", "add the policies of a CSV or JSON file", adminImportPolicies},
+}
+
+// runAdminCommand runs an operational task against the database. Changes are
+// logged with the operator given by -actor.
+func runAdminCommand(ctx context.Context, args []string) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "help" {
+		adminUsage()
+		return flag.ErrHelp
+	}
+
+	name, args := args[0], args[1:]
+	index := -1
+	for i, action := range adminActions {
+		if action.name == name {
+			index = i
+		}
+	}
+	if index < 0 {
+		adminUsage()
+		return fmt.Errorf("unknown admin action %q", name)
+	}
+
+	flags := newFlagSet("admin "+name, "[flags]")
+	format := flags.String("o", admin.FormatTable, "output format: table or json, export-policies also supports csv")
+	actor := flags.String("actor", currentUsername(), "operator recorded with the change")
+	run := adminActions[index].setup(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *actor == "" {
+		return errors.New("-actor is required")
+	}
+	switch *format {
+	case admin.FormatTable, admin.FormatJSON:
+	case admin.FormatCSV:
+		if name != "export-policies" {
+			return fmt.Errorf("csv output is only supported by export-policies")
+		}
+	default:
+		return fmt.Errorf("unsupported output format %q", *format)
+	}
+
+	runtimeCfg, err := loadRuntimeConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	connPool, err := newConnPool(ctx, runtimeCfg.DBSource)
+	if err != nil {
+		return err
+	}
+	defer connPool.Close()
+
+	casbin_enforcer, err := newCasbinEnforcer(ctx, connPool)
+	if err != nil {
+		return err
+	}
+
+	return run(ctx, admin.New(db.NewStore(connPool), casbin_enforcer, *actor), *format)
+}
+
+func adminUsage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s admin <action> [flags]\n\nActions:\n", filepath.Base(os.Args[0]))
+	for _, action := range adminActions {
+		fmt.Fprintf(os.Stderr, "  %-17s %s\n", action.name, action.usage)
+	}
+}
+
+func currentUsername() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+func adminCreateUser(flags *flag.FlagSet) func(ctx context.Context, a *admin.Admin, format string) error {
+	var arg admin.CreateUserParams
+	flags.StringVar(&arg.Username, "username", "", "username (required)")
+	flags.StringVar(&arg.FullName, "full-name", "", "full name (required)")
+	flags.StringVar(&arg.Email, "email", "", "email address (required)")
+	flags.StringVar(&arg.Password, "password", "", "initial password (required)")
+	flags.StringVar(&arg.Role, "role", util.DepositorRole, "role: depositor or banker")
+	flags.StringVar(&arg.TenantID, "tenant", util.DefaultTenant, "tenant of the user")
+	flags.BoolVar(&arg.EmailVerified, "email-verified", false, "mark the email verified instead of sending a verification email")
+
+	return func(ctx context.Context, a *admin.Admin, format string) error {
+		if arg.Username == "" || arg.FullName == "" || arg.Email == "" || arg.Password == "" {
+			return errors.New("-username, -full-name, -email and -password are required")
+		}
+
+		user, err := a.CreateUser(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return admin.Print(os.Stdout, format, user, admin.UserTable(user))
+	}
+}
+
+func adminResetPassword(flags *flag.FlagSet) func(ctx context.Context, a *admin.Admin, format string) error {
+	username := flags.String("username", "", "username (required)")
+	password := flags.String("password", "", "new password, a random one is generated and printed when empty")
+
+	return func(ctx context.Context, a *admin.Admin, format string) error {
+		if *username == "" {
+			return errors.New("-username is required")
+		}
+
+		user, newPassword, err := a.ResetPassword(ctx, *username, *password)
+		if err != nil {
+			return err
+		}
+
+		rsp := struct {
+			admin.User
+			Password string `json:"password,omitempty"`
+		}{User: user}
+		table := admin.UserTable(user)
+		if *password == "" {
+			rsp.Password = newPassword
+			table.Header = append(table.Header, "PASSWORD")
+			table.Rows[0] = append(table.Rows[0], newPassword)
+		}
+		return admin.Print(os.Stdout, format, rsp, table)
+	}
+}
+
+func adminBlockSessions(flags *flag.FlagSet) func(ctx context.Context, a *admin.Admin, format string) error {
+	username := flags.String("username", "", "username (required)")
+
+	return func(ctx context.Context, a *admin.Admin, format string) error {
+		if *username == "" {
+			return errors.New("-username is required")
+		}
+
+		user, err := a.BlockSessions(ctx, *username)
+		if err != nil {
+			return err
+		}
+		return admin.Print(os.Stdout, format, user, admin.UserTable(user))
+	}
+}
+
+func adminChangeAccountStatus(from string, to string) func(flags *flag.FlagSet) func(ctx context.Context, a *admin.Admin, format string) error {
+	return func(flags *flag.FlagSet) func(ctx context.Context, a *admin.Admin, format string) error {
+		accountID := flags.Int64("account", 0, "account ID (required)")
+		reason := flags.String("reason", "", "reason code: "+strings.Join(db.AccountReasons(from, to), ", ")+" (required)")
+		note := flags.String("note", "", "free text recorded in the audit log, such as a case number")
+
+		return func(ctx context.Context, a *admin.Admin, format string) error {
+			if *accountID <= 0 {
+				return errors.New("-account is required")
+			}
+
+			account, err := a.ChangeAccountStatus(ctx, *accountID, to, *reason, *note)
+			if err != nil {
+				return err
+			}
+			return admin.Print(os.Stdout, format, account, admin.AccountTable(account))
+		}
+	}
+}
+
+func adminAdjustBalance(flags *flag.FlagSet) func(ctx context.Context, a *admin.Admin, format string) error {
+	accountID := flags.Int64("account", 0, "account ID (required)")
+	amount := flags.Int64("amount", 0, "amount to credit, negative to debit (required)")
+	reason := flags.String("reason", "", "reason stored with the adjustment (required)")
+
+	return func(ctx context.Context, a *admin.Admin, format string) error {
+		if *accountID <= 0 {
+			return errors.New("-account is required")
+		}
+
+		result, err := a.AdjustBalance(ctx, *accountID, *amount, *reason)
+		if err != nil {
+			return err
+		}
+		return admin.Print(os.Stdout, format, result, admin.AdjustmentTable(result))
+	}
+}
+
+func adminExportPolicies(flags *flag.FlagSet) func(ctx context.Context, a *admin.Admin, format string) error {
+	return func(ctx context.Context, a *admin.Admin, format string) error {
+		policies, err := a.ExportPolicies()
+		if err != nil {
+			return err
+		}
+		if format == admin.FormatCSV {
+			return admin.WritePoliciesCSV(os.Stdout, policies)
+		}
+		return admin.Print(os.Stdout, format, policies, admin.PolicyTable(policies...))
+	}
+}
+
+func adminImportPolicies(flags *flag.FlagSet) func(ctx context.Context, a *admin.Admin, format string) error {
+	file := flags.String("file", "", "CSV (Casbin policy file) or JSON file to import, - for stdin (required)")
+	inputFormat := flags.String("format", "", "input format: csv or json (default from the file extension)")
+	replace := flags.Bool("replace", false, "remove the policies that are not in the file")
+
+	return func(ctx context.Context, a *admin.Admin, format string) error {
+		if *file == "" {
+			return errors.New("-file is required")
+		}
+		if *inputFormat == "" {
+			*inputFormat = admin.FormatCSV
+			if strings.EqualFold(filepath.Ext(*file), ".json") {
+				*inputFormat = admin.FormatJSON
+			}
+		}
+
+		r := os.Stdin
+		if *file != "-" {
+			f, err := os.Open(*file)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			r = f
+		}
+
+		policies, err := admin.ReadPolicies(r, *inputFormat)
+		if err != nil {
+			return err
+		}
+
+		added, removed, err := a.ImportPolicies(ctx, policies, *replace)
+		if err != nil {
+			return err
+		}
+
+		rsp := struct {
+			Added   int `json:"added"`
+			Removed int `json:"removed"`
+		}{added, removed}
+		table := admin.Table{
+			Header: []string{"ADDED", "REMOVED"},
+			Rows:   [][]string{{strconv.Itoa(added), strconv.Itoa(removed)}},
+		}
+		return admin.Print(os.Stdout, format, rsp, table)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/LamThanhNguyen/banking-system/admin"
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+)
+
+// runAuditCommand verifies the hash chains of the audit log. It fails at the
+// first event that was changed, inserted or removed, so it can run as a
+// scheduled job that alerts on a non-zero exit.
+func runAuditCommand(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New("missing audit action: verify")
+	}
+	if args[0] != "verify" {
+		return fmt.Errorf("unknown audit action %q: use verify", args[0])
+	}
+
+	flags := newFlagSet("audit verify", "[flags]")
+	format := flags.String("o", admin.FormatTable, "output format: table or json")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	runtimeCfg, err := loadRuntimeConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	connPool, err := newConnPool(ctx, runtimeCfg.DBSource)
+	if err != nil {
+		return err
+	}
+	defer connPool.Close()
+
+	result, err := db.VerifyAuditChain(ctx, db.New(connPool))
+	if err != nil {
+		return fmt.Errorf("cannot verify audit log: %w", err)
+	}
+
+	table := admin.Table{
+		Header: []string{"TENANT", "VERIFIED", "LAST ID", "LAST HASH", "BROKEN AT", "REASON"},
+	}
+	for _, chain := range result.Chains {
+		table.Rows = append(table.Rows, []string{
+			chain.TenantID, strconv.FormatInt(chain.Verified, 10), strconv.FormatInt(chain.LastID, 10), chain.LastHash, "", "",
+		})
+	}
+	if result.Break != nil {
+		table.Rows = append(table.Rows, []string{"", "", "", "", strconv.FormatInt(result.Break.ID, 10), result.Break.Reason})
+	}
+	if err := admin.Print(os.Stdout, *format, result, table); err != nil {
+		return err
+	}
+
+	if result.Break != nil {
+		return errors.New("audit log was tampered with")
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/rs/zerolog/log"
+)
+
+// runMigrateCommand applies (up), rolls back (down) or lists (status) the
+// database migrations. It is meant to run once per release as its own job.
+func runMigrateCommand(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New("missing migrate action: up, down or status")
+	}
+
+	action, args := args[0], args[1:]
+	flags := newFlagSet("migrate "+action, "[flags]")
+	url := flags.String("url", "", "migration source URL (default MIGRATION_URL)")
+
+	var steps *int
+	var all *bool
+	switch action {
+	case "up":
+		steps = flags.Int("steps", 0, "number of migrations to apply, 0 applies all pending")
+	case "down":
+		steps = flags.Int("steps", 1, "number of migrations to roll back")
+		all = flags.Bool("all", false, "roll back every migration")
+	case "status":
+	default:
+		return fmt.Errorf("unknown migrate action %q: use up, down or status", action)
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	runtimeCfg, err := loadRuntimeConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if *url == "" {
+		*url = runtimeCfg.MigrationURL
+	}
+
+	switch action {
+	case "up":
+		return migrateUp(*url, runtimeCfg.DBSource, *steps)
+	case "down":
+		return migrateDown(*url, runtimeCfg.DBSource, *steps, *all)
+	default:
+		return migrateStatus(*url, runtimeCfg.DBSource)
+	}
+}
+
+// migrateUp applies steps pending migrations, or all of them when steps is 0.
+func migrateUp(migrationURL string, dbSource string, steps int) error {
+	migration, err := migrate.New(migrationURL, dbSource)
+	if err != nil {
+		return fmt.Errorf("cannot create new migrate instance: %w", err)
+	}
+	defer migration.Close()
+
+	if steps > 0 {
+		err = migration.Steps(steps)
+	} else {
+		err = migration.Up()
+	}
+	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("failed to run migrate up: %w", err)
+	}
+
+	log.Info().Msg("db migrated successfully")
+	return nil
+}
+
+func migrateDown(migrationURL string, dbSource string, steps int, all bool) error {
+	if !all && steps <= 0 {
+		return errors.New("steps must be positive, use -all to roll back every migration")
+	}
+
+	migration, err := migrate.New(migrationURL, dbSource)
+	if err != nil {
+		return fmt.Errorf("cannot create new migrate instance: %w", err)
+	}
+	defer migration.Close()
+
+	if all {
+		err = migration.Down()
+	} else {
+		err = migration.Steps(-steps)
+	}
+	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("failed to run migrate down: %w", err)
+	}
+
+	log.Info().Msg("db rolled back successfully")
+	return nil
+}
+
+// migrateStatus prints every available migration and whether it is applied.
+func migrateStatus(migrationURL string, dbSource string) error {
+	migration, err := migrate.New(migrationURL, dbSource)
+	if err != nil {
+		return fmt.Errorf("cannot create new migrate instance: %w", err)
+	}
+	defer migration.Close()
+
+	current, dirty, err := migration.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		current, err = 0, nil
+	}
+	if err != nil {
+		return fmt.Errorf("cannot read migration version: %w", err)
+	}
+
+	src, err := source.Open(migrationURL)
+	if err != nil {
+		return fmt.Errorf("cannot open migration source: %w", err)
+	}
+	defer src.Close()
+
+	pending := 0
+	version, err := src.First()
+	for err == nil {
+		r, identifier, readErr := src.ReadUp(version)
+		if readErr != nil {
+			return fmt.Errorf("cannot read migration %d: %w", version, readErr)
+		}
+		r.Close()
+
+		state := "applied"
+		switch {
+		case version == current && dirty:
+			state = "dirty"
+		case version > current:
+			state = "pending"
+			pending++
+		}
+		fmt.Printf("%06d  %-8s %s\n", version, state, identifier)
+
+		version, err = src.Next(version)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("cannot list migrations: %w", err)
+	}
+
+	fmt.Printf("\nversion %d, dirty %t, %d pending\n", current, dirty, pending)
+	return nil
+}
+
+// latestMigration returns the version of the last migration of the source,
+// which the database must have reached for the binary to be ready.
+func latestMigration(migrationURL string) (uint, error) {
+	src, err := source.Open(migrationURL)
+	if err != nil {
+		return 0, fmt.Errorf("cannot open migration source: %w", err)
+	}
+	defer src.Close()
+
+	latest, err := src.First()
+	for err == nil {
+		var next uint
+		next, err = src.Next(latest)
+		if err == nil {
+			latest = next
+		}
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return 0, fmt.Errorf("cannot list migrations: %w", err)
+	}
+	return latest, nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/casbin/casbin/v2"
+	"github.com/rs/zerolog/log"
+)
+
+// runSeedPoliciesCommand adds the default policies that are missing. Existing
+// policies, including ones added by bankers at runtime, are left untouched.
+func runSeedPoliciesCommand(ctx context.Context, args []string) error {
+	flags := newFlagSet("seed-policies", "[flags]")
+	dryRun := flags.Bool("dry-run", false, "print the missing policies without adding them")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	runtimeCfg, err := loadRuntimeConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	connPool, err := newConnPool(ctx, runtimeCfg.DBSource)
+	if err != nil {
+		return err
+	}
+	defer connPool.Close()
+
+	casbin_enforcer, err := newCasbinEnforcer(ctx, connPool)
+	if err != nil {
+		return err
+	}
+
+	missing, err := seedPolicies(casbin_enforcer, *dryRun)
+	if err != nil {
+		return fmt.Errorf("cannot seed policies: %w", err)
+	}
+	for _, policy := range missing {
+		fmt.Println(strings.Join(policy, ", "))
+	}
+	return nil
+}
+
+// defaultPolicies returns the permissions every deployment starts with.
+func defaultPolicies() [][]string {
+	var policies [][]string
+	add := func(sub, act string) {
+		policies = append(policies, []string{sub, "*", "*", act})
+	}
+	addInTenant := func(sub, dom, act string) {
+		policies = append(policies, []string{sub, dom, "*", act})
+	}
+
+	// banker
+	add("banker", "accounts:create")
+	add("banker", "accounts:read")
+	add("banker", "accounts:list")
+	add("banker", "accounts:freeze")
+	add("banker", "accounts:close")
+	add("banker", "users:update")
+	add("banker", "transfers:create")
+	add("banker", "users:list")
+	add("banker", "users:accounts")
+	add("banker", "users:update_role")
+	add("banker", "users:disable")
+	add("banker", "policies:explain")
+	add("banker", "notifications:manage")
+	add("banker", "users:resend_verification")
+	add("banker", "users:phone")
+	add("banker", "webhooks:manage")
+	add("banker", "audit:read")
+
+	// platform bankers; queued tasks of every tenant hold personal data
+	addInTenant("banker", util.DefaultTenant, "tenants:create")
+	addInTenant("banker", util.DefaultTenant, "tasks:read")
+	addInTenant("banker", util.DefaultTenant, "tasks:manage")
+
+	// depositer
+	add("depositor", "accounts:create")
+	add("depositor", "accounts:read")
+	add("depositor", "accounts:list")
+	add("depositor", "accounts:close")
+	add("depositor", "users:update")
+	add("depositor", "transfers:create")
+	add("depositor", "notifications:manage")
+	add("depositor", "users:resend_verification")
+	add("depositor", "users:phone")
+	add("depositor", "webhooks:manage")
+	return policies
+}
+
+// seedPolicies adds the default policies that are missing and returns them.
+// With dryRun it only returns them.
+func seedPolicies(casbin_enforcer *casbin.Enforcer, dryRun bool) ([][]string, error) {
+	var missing [][]string
+	for _, policy := range defaultPolicies() {
+		ok, err := casbin_enforcer.HasPolicy(policy)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			missing = append(missing, policy)
+		}
+	}
+
+	if !dryRun && len(missing) > 0 {
+		if _, err := casbin_enforcer.AddPolicies(missing); err != nil {
+			return nil, err
+		}
+	}
+
+	log.Info().Int("missing", len(missing)).Bool("dry_run", dryRun).Msg("seeded policies")
+	return missing, nil
+}
@@ -0,0 +1,391 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/LamThanhNguyen/banking-system/api"
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/gapi"
+	"github.com/LamThanhNguyen/banking-system/health"
+	"github.com/LamThanhNguyen/banking-system/metrics"
+	"github.com/LamThanhNguyen/banking-system/stream"
+	"github.com/LamThanhNguyen/banking-system/tracing"
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/LamThanhNguyen/banking-system/worker"
+	"github.com/casbin/casbin/v2"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+)
+
+// runServeCommand runs the HTTP API server. Tasks are distributed to Redis
+// for separately deployed workers unless -worker runs them in this process.
+func runServeCommand(ctx context.Context, args []string) error {
+	flags := newFlagSet("serve", "[flags]")
+	addr := flags.String("addr", "", "HTTP listen address (default HTTP_SERVER_ADDRESS)")
+	withWorker := flags.Bool("worker", false, "also run the task processor, scheduler and outbox relay in this process")
+	withMigrate := flags.Bool("migrate", false, "apply pending database migrations before serving")
+	withSeed := flags.Bool("seed-policies", false, "add the default authorization policies before serving")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	runtimeCfg, err := loadRuntimeConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if *addr != "" {
+		runtimeCfg.HTTPServerAddress = *addr
+	}
+
+	shutdownTracing, err := tracing.Setup(ctx, runtimeCfg, tracing.ServiceAPI)
+	if err != nil {
+		return err
+	}
+	defer flushTracing(shutdownTracing)
+
+	if *withMigrate {
+		if err := migrateUp(runtimeCfg.MigrationURL, runtimeCfg.DBSource, 0); err != nil {
+			return err
+		}
+	}
+
+	connPool, err := newConnPool(ctx, runtimeCfg.DBSource)
+	if err != nil {
+		return err
+	}
+	defer connPool.Close()
+
+	casbin_enforcer, err := newCasbinEnforcer(ctx, connPool)
+	if err != nil {
+		return err
+	}
+	if *withSeed {
+		if _, err := seedPolicies(casbin_enforcer, false); err != nil {
+			return fmt.Errorf("cannot seed policies: %w", err)
+		}
+	}
+
+	store := db.NewStore(connPool)
+
+	waitGroup, ctx := errgroup.WithContext(ctx)
+	// Everything below runs on serveCtx, which stays live for the drain delay
+	// after shutdown begins while readiness already fails.
+	serveCtx, stopServing := context.WithCancel(context.WithoutCancel(ctx))
+	healthChecker := newHealthChecker(serveCtx, waitGroup, runtimeCfg, connPool)
+	drainOnShutdown(ctx, waitGroup, healthChecker, runtimeCfg.ShutdownDrainDelayParsed, stopServing)
+	ctx = serveCtx
+
+	var taskDistributor worker.TaskDistributor
+	var taskInspector worker.TaskInspector
+
+	switch runtimeCfg.TaskQueue {
+	case util.TaskQueueMemory:
+		// The queue only exists in this process, so it has to run the tasks too.
+		log.Warn().Msg("tasks are queued in memory and lost on exit")
+		queue := worker.NewMemoryQueue()
+		taskDistributor = worker.NewMemoryTaskDistributor(queue)
+		taskInspector = queue
+
+		if err := runMemoryWorker(ctx, waitGroup, runtimeCfg, store, queue, healthChecker); err != nil {
+			return err
+		}
+
+	default:
+		redisOpt := asynq.RedisClientOpt{
+			Addr: runtimeCfg.RedisAddress,
+		}
+		taskDistributor = worker.NewRedisTaskDistributor(redisOpt)
+		taskInspector = worker.NewRedisTaskInspector(redisOpt)
+
+		if *withWorker {
+			if err := runRedisWorker(ctx, waitGroup, runtimeCfg, store, redisOpt, healthChecker, true, true); err != nil {
+				return err
+			}
+		}
+	}
+
+	prometheus.MustRegister(
+		worker.NewQueueCollector(taskInspector),
+		metrics.NewPoolCollector(connPool),
+	)
+	if runtimeCfg.MetricsServerAddress != "" {
+		runMetricsServer(ctx, waitGroup, runtimeCfg.MetricsServerAddress, healthChecker)
+	}
+
+	// Account events committed by any replica reach this one through Postgres NOTIFY.
+	eventBroker := stream.NewBroker()
+	waitGroup.Go(func() error {
+		return stream.Listen(ctx, connPool, eventBroker)
+	})
+
+	if err := runServer(ctx, waitGroup, runtimeCfg, store, casbin_enforcer, taskDistributor, taskInspector, eventBroker, healthChecker); err != nil {
+		return err
+	}
+
+	if runtimeCfg.GRPCServerAddress != "" {
+		grpcServer, err := gapi.NewServer(runtimeCfg, store, casbin_enforcer, taskDistributor)
+		if err != nil {
+			return fmt.Errorf("cannot create gRPC server: %w", err)
+		}
+		if err := runGrpcServer(ctx, waitGroup, runtimeCfg.GRPCServerAddress, grpcServer); err != nil {
+			return err
+		}
+		if runtimeCfg.GatewayServerAddress != "" {
+			if err := runGatewayServer(ctx, waitGroup, runtimeCfg); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := waitGroup.Wait(); err != nil {
+		return fmt.Errorf("err from wait group: %w", err)
+	}
+
+	log.Info().Msg("application shutdown complete")
+	return nil
+}
+
+func runServer(
+	ctx context.Context,
+	waitGroup *errgroup.Group,
+	config util.RuntimeConfig,
+	store db.Store,
+	enforcer *casbin.Enforcer,
+	taskDistributor worker.TaskDistributor,
+	taskInspector worker.TaskInspector,
+	eventBroker *stream.Broker,
+	healthChecker *health.Checker,
+) error {
+	server, err := api.NewServer(config, store, enforcer, taskDistributor, taskInspector, eventBroker, healthChecker)
+	if err != nil {
+		return fmt.Errorf("cannot create server: %w", err)
+	}
+
+	server.SetupRouter() // initialize routes
+
+	// Setup HTTP server
+	httpServer := &http.Server{
+		Addr:    config.HTTPServerAddress,
+		Handler: server.Router(), // use the Gin router
+	}
+
+	// Start HTTP server in goroutine
+	waitGroup.Go(func() error {
+		log.Info().Msgf("start HTTP server at %s", httpServer.Addr)
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error().Err(err).Msg("HTTP server failed to serve")
+			return err
+		}
+		return nil
+	})
+
+	// Graceful shutdown on context cancel
+	waitGroup.Go(func() error {
+		<-ctx.Done()
+		log.Info().Msg("graceful shutdown HTTP server")
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			log.Error().Err(err).Msg("failed to shutdown HTTP server")
+			return err
+		}
+
+		log.Info().Msg("HTTP server is stopped")
+		return nil
+	})
+	return nil
+}
+
+// runGrpcServer serves the gRPC API until the context is cancelled.
+func runGrpcServer(
+	ctx context.Context,
+	waitGroup *errgroup.Group,
+	address string,
+	server *gapi.Server,
+) error {
+	grpcServer := server.GRPCServer()
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("cannot create gRPC listener: %w", err)
+	}
+
+	waitGroup.Go(func() error {
+		log.Info().Msgf("start gRPC server at %s", listener.Addr().String())
+		if err := grpcServer.Serve(listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			log.Error().Err(err).Msg("gRPC server failed to serve")
+			return err
+		}
+		return nil
+	})
+
+	waitGroup.Go(func() error {
+		<-ctx.Done()
+		log.Info().Msg("graceful shutdown gRPC server")
+
+		grpcServer.GracefulStop()
+		log.Info().Msg("gRPC server is stopped")
+		return nil
+	})
+	return nil
+}
+
+// runGatewayServer serves the REST gateway, which forwards to the gRPC server.
+func runGatewayServer(
+	ctx context.Context,
+	waitGroup *errgroup.Group,
+	config util.RuntimeConfig,
+) error {
+	handler, err := gapi.NewGatewayHandler(ctx, config.GRPCServerAddress)
+	if err != nil {
+		return err
+	}
+
+	httpServer := &http.Server{
+		Addr:    config.GatewayServerAddress,
+		Handler: handler,
+	}
+
+	waitGroup.Go(func() error {
+		log.Info().Msgf("start gRPC gateway server at %s", httpServer.Addr)
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error().Err(err).Msg("gRPC gateway server failed to serve")
+			return err
+		}
+		return nil
+	})
+
+	waitGroup.Go(func() error {
+		<-ctx.Done()
+		log.Info().Msg("graceful shutdown gRPC gateway server")
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			log.Error().Err(err).Msg("failed to shutdown gRPC gateway server")
+			return err
+		}
+
+		log.Info().Msg("gRPC gateway server is stopped")
+		return nil
+	})
+	return nil
+}
+
+// runMetricsServer serves the Prometheus metrics and the probes on an admin
+// address, apart from the public API. It is the only HTTP server of the
+// worker command.
+func runMetricsServer(
+	ctx context.Context,
+	waitGroup *errgroup.Group,
+	address string,
+	healthChecker *health.Checker,
+) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.Handle("/livez", healthChecker.LiveHandler())
+	mux.Handle("/readyz", healthChecker.ReadyHandler())
+
+	httpServer := &http.Server{
+		Addr:              address,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	waitGroup.Go(func() error {
+		log.Info().Msgf("start metrics server at %s", httpServer.Addr)
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error().Err(err).Msg("metrics server failed to serve")
+			return err
+		}
+		return nil
+	})
+
+	waitGroup.Go(func() error {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			log.Error().Err(err).Msg("failed to shutdown metrics server")
+			return err
+		}
+
+		log.Info().Msg("metrics server is stopped")
+		return nil
+	})
+}
+
+// drainOnShutdown makes readiness fail as soon as ctx is cancelled and only
+// calls stop after delay, so load balancers take the process out of rotation
+// while its servers still accept requests.
+func drainOnShutdown(
+	ctx context.Context,
+	waitGroup *errgroup.Group,
+	checker *health.Checker,
+	delay time.Duration,
+	stop context.CancelFunc,
+) {
+	waitGroup.Go(func() error {
+		<-ctx.Done()
+		checker.Shutdown()
+		log.Info().Dur("delay", delay).Msg("readiness failing, draining before shutdown")
+
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		<-timer.C
+		stop()
+		return nil
+	})
+}
+
+// newHealthChecker returns the readiness checks of Postgres, the migration
+// version and, unless tasks are queued in memory, Redis. Readiness fails as
+// soon as ctx is cancelled.
+func newHealthChecker(
+	ctx context.Context,
+	waitGroup *errgroup.Group,
+	config util.RuntimeConfig,
+	connPool *pgxpool.Pool,
+) *health.Checker {
+	const checkTimeout = 2 * time.Second
+	checker := health.NewChecker(checkTimeout)
+
+	checker.Add("postgres", health.Ping(connPool))
+	if version, err := latestMigration(config.MigrationURL); err != nil {
+		log.Warn().Err(err).Msg("readiness does not check the migration version")
+	} else {
+		checker.Add("migrations", health.MigrationVersion(connPool, version))
+	}
+
+	var redisClient *redis.Client
+	if config.TaskQueue != util.TaskQueueMemory {
+		redisClient = redis.NewClient(&redis.Options{Addr: config.RedisAddress})
+		checker.Add("redis", health.Redis(redisClient))
+	}
+
+	waitGroup.Go(func() error {
+		<-ctx.Done()
+		checker.Shutdown()
+		if redisClient != nil {
+			return redisClient.Close()
+		}
+		return nil
+	})
+	return checker
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/LamThanhNguyen/banking-system/health"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+)
+
+func freeAddress(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	return listener.Addr().String()
+}
+
+func readyStatus(url string) int {
+	resp, err := http.Get(url)
+	if err != nil {
+		return 0
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestDrainOnShutdown(t *testing.T) {
+	const delay = 500 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	waitGroup, ctx := errgroup.WithContext(ctx)
+	serveCtx, stopServing := context.WithCancel(context.WithoutCancel(ctx))
+	checker := health.NewChecker(time.Second)
+	drainOnShutdown(ctx, waitGroup, checker, delay, stopServing)
+
+	address := freeAddress(t)
+	runMetricsServer(serveCtx, waitGroup, address, checker)
+	url := "http://" + address + "/readyz"
+
+	require.Eventually(t, func() bool {
+		return readyStatus(url) == http.StatusOK
+	}, time.Second, 10*time.Millisecond)
+
+	start := time.Now()
+	cancel()
+
+	// Readiness fails while the server still answers.
+	require.Eventually(t, func() bool {
+		return readyStatus(url) == http.StatusServiceUnavailable
+	}, delay/2, 10*time.Millisecond)
+	require.NoError(t, serveCtx.Err())
+
+	require.NoError(t, waitGroup.Wait())
+	require.GreaterOrEqual(t, time.Since(start), delay)
+	require.Zero(t, readyStatus(url))
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/health"
+	"github.com/LamThanhNguyen/banking-system/mail"
+	"github.com/LamThanhNguyen/banking-system/mail/templates"
+	"github.com/LamThanhNguyen/banking-system/metrics"
+	"github.com/LamThanhNguyen/banking-system/notify"
+	"github.com/LamThanhNguyen/banking-system/tracing"
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/LamThanhNguyen/banking-system/worker"
+	"github.com/hibiken/asynq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/sync/errgroup"
+)
+
+// runWorkerCommand runs the asynq task processor together with the
+// maintenance scheduler and the outbox relay, without the HTTP server.
+func runWorkerCommand(ctx context.Context, args []string) error {
+	flags := newFlagSet("worker", "[flags]")
+	withScheduler := flags.Bool("scheduler", true, "run the maintenance scheduler")
+	withRelay := flags.Bool("outbox-relay", true, "run the outbox relay")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	runtimeCfg, err := loadRuntimeConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if runtimeCfg.TaskQueue == util.TaskQueueMemory {
+		return errors.New("TASK_QUEUE=memory has no separate worker, run 'serve' instead")
+	}
+
+	shutdownTracing, err := tracing.Setup(ctx, runtimeCfg, tracing.ServiceWorker)
+	if err != nil {
+		return err
+	}
+	defer flushTracing(shutdownTracing)
+
+	connPool, err := newConnPool(ctx, runtimeCfg.DBSource)
+	if err != nil {
+		return err
+	}
+	defer connPool.Close()
+
+	store := db.NewStore(connPool)
+	redisOpt := asynq.RedisClientOpt{
+		Addr: runtimeCfg.RedisAddress,
+	}
+
+	waitGroup, ctx := errgroup.WithContext(ctx)
+	healthChecker := newHealthChecker(ctx, waitGroup, runtimeCfg, connPool)
+	if err := runRedisWorker(ctx, waitGroup, runtimeCfg, store, redisOpt, healthChecker, *withScheduler, *withRelay); err != nil {
+		return err
+	}
+	prometheus.MustRegister(metrics.NewPoolCollector(connPool))
+	if runtimeCfg.MetricsServerAddress != "" {
+		runMetricsServer(ctx, waitGroup, runtimeCfg.MetricsServerAddress, healthChecker)
+	}
+
+	if err := waitGroup.Wait(); err != nil {
+		return fmt.Errorf("err from wait group: %w", err)
+	}
+
+	log.Info().Msg("worker shutdown complete")
+	return nil
+}
+
+func runRedisWorker(
+	ctx context.Context,
+	waitGroup *errgroup.Group,
+	config util.RuntimeConfig,
+	store db.Store,
+	redisOpt asynq.RedisClientOpt,
+	healthChecker *health.Checker,
+	withScheduler bool,
+	withRelay bool,
+) error {
+	err := runTaskProcessor(ctx, waitGroup, config, healthChecker, func(notifiers notify.Notifiers, renderer *templates.Renderer) worker.TaskProcessor {
+		return worker.NewRedisTaskProcessor(redisOpt, store, notifiers, renderer, config)
+	})
+	if err != nil {
+		return err
+	}
+
+	if withScheduler {
+		scheduler, err := worker.NewScheduler(redisOpt, config)
+		if err != nil {
+			return fmt.Errorf("cannot create scheduler: %w", err)
+		}
+		runScheduler(ctx, waitGroup, scheduler)
+	}
+	if withRelay {
+		runOutboxRelay(ctx, waitGroup, asynq.NewClient(redisOpt), store)
+	}
+	return nil
+}
+
+func runMemoryWorker(
+	ctx context.Context,
+	waitGroup *errgroup.Group,
+	config util.RuntimeConfig,
+	store db.Store,
+	queue *worker.MemoryQueue,
+	healthChecker *health.Checker,
+) error {
+	err := runTaskProcessor(ctx, waitGroup, config, healthChecker, func(notifiers notify.Notifiers, renderer *templates.Renderer) worker.TaskProcessor {
+		return worker.NewMemoryTaskProcessor(queue, store, notifiers, renderer, config)
+	})
+	if err != nil {
+		return err
+	}
+
+	scheduler, err := worker.NewMemoryScheduler(queue, config)
+	if err != nil {
+		return fmt.Errorf("cannot create scheduler: %w", err)
+	}
+	runScheduler(ctx, waitGroup, scheduler)
+	runOutboxRelay(ctx, waitGroup, queue, store)
+	return nil
+}
+
+func runTaskProcessor(
+	ctx context.Context,
+	waitGroup *errgroup.Group,
+	config util.RuntimeConfig,
+	healthChecker *health.Checker,
+	newProcessor func(notifiers notify.Notifiers, renderer *templates.Renderer) worker.TaskProcessor,
+) error {
+	mailer, err := mail.NewEmailSender(config.Config)
+	if err != nil {
+		return fmt.Errorf("cannot create email sender: %w", err)
+	}
+	notifiers, err := notify.NewNotifiers(config.Config, mailer)
+	if err != nil {
+		return fmt.Errorf("cannot create notifiers: %w", err)
+	}
+	renderer, err := templates.New(config.EmailSenderName, config.EmailTemplateDir)
+	if err != nil {
+		return fmt.Errorf("cannot load email templates: %w", err)
+	}
+	taskProcessor := newProcessor(notifiers, renderer)
+
+	if err := taskProcessor.Start(); err != nil {
+		return fmt.Errorf("failed to start task processor: %w", err)
+	}
+	log.Info().Msg("task processor started")
+	healthChecker.Add("task_processor", func(ctx context.Context) error {
+		return taskProcessor.Ping()
+	})
+
+	waitGroup.Go(func() error {
+		<-ctx.Done()
+		log.Info().Msg("graceful shutdown task processor")
+
+		taskProcessor.Shutdown()
+		log.Info().Msg("task processor is stopped")
+
+		return nil
+	})
+	return nil
+}
+
+// taskScheduler is implemented by worker.Scheduler and worker.MemoryScheduler.
+type taskScheduler interface {
+	Run(ctx context.Context) error
+}
+
+func runScheduler(ctx context.Context, waitGroup *errgroup.Group, scheduler taskScheduler) {
+	log.Info().Msg("scheduler started")
+
+	waitGroup.Go(func() error {
+		err := scheduler.Run(ctx)
+		log.Info().Msg("scheduler is stopped")
+		return err
+	})
+}
+
+func runOutboxRelay(
+	ctx context.Context,
+	waitGroup *errgroup.Group,
+	client worker.TaskEnqueuer,
+	store db.Store,
+) {
+	relay := worker.NewOutboxRelay(client, store, time.Second)
+	log.Info().Msg("outbox relay started")
+
+	waitGroup.Go(func() error {
+		err := relay.Run(ctx)
+		log.Info().Msg("outbox relay is stopped")
+		return err
+	})
+}
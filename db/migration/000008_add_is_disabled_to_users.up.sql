@@ -0,0 +1 @@
+ALTER TABLE "users" ADD COLUMN "is_disabled" bool NOT NULL DEFAULT false;
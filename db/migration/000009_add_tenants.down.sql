@@ -0,0 +1,12 @@
+ALTER TABLE casbin_rule DROP CONSTRAINT IF EXISTS uq_casbin_rule;
+DELETE FROM casbin_rule WHERE ptype = 'p' AND v1 <> '*';
+UPDATE casbin_rule SET v1 = v2, v2 = v3, v3 = NULL WHERE ptype = 'p';
+DELETE FROM casbin_rule WHERE ptype = 'g' AND v2 <> 'default';
+UPDATE casbin_rule SET v2 = NULL WHERE ptype = 'g';
+ALTER TABLE casbin_rule ADD CONSTRAINT uq_casbin_rule UNIQUE (ptype, v0, v1);
+
+ALTER TABLE "transfers" DROP COLUMN "tenant_id";
+ALTER TABLE "accounts" DROP COLUMN "tenant_id";
+ALTER TABLE "users" DROP COLUMN "tenant_id";
+
+DROP TABLE IF EXISTS "tenants";
@@ -0,0 +1,28 @@
+CREATE TABLE "tenants" (
+  "id" varchar PRIMARY KEY,
+  "name" varchar NOT NULL,
+  "allow_cross_tenant_transfers" bool NOT NULL DEFAULT false,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+INSERT INTO "tenants" ("id", "name") VALUES ('default', 'Default');
+
+ALTER TABLE "users" ADD COLUMN "tenant_id" varchar NOT NULL DEFAULT 'default';
+ALTER TABLE "accounts" ADD COLUMN "tenant_id" varchar NOT NULL DEFAULT 'default';
+ALTER TABLE "transfers" ADD COLUMN "tenant_id" varchar NOT NULL DEFAULT 'default';
+
+ALTER TABLE "users" ADD FOREIGN KEY ("tenant_id") REFERENCES "tenants" ("id");
+ALTER TABLE "accounts" ADD FOREIGN KEY ("tenant_id") REFERENCES "tenants" ("id");
+ALTER TABLE "transfers" ADD FOREIGN KEY ("tenant_id") REFERENCES "tenants" ("id");
+
+CREATE INDEX ON "users" ("tenant_id");
+CREATE INDEX ON "accounts" ("tenant_id", "owner");
+CREATE INDEX ON "transfers" ("tenant_id");
+
+COMMENT ON COLUMN "transfers"."tenant_id" IS 'tenant of the source account';
+
+-- casbin rules gain a domain column: p = sub, dom, obj, act and g = user, role, dom
+ALTER TABLE casbin_rule DROP CONSTRAINT IF EXISTS uq_casbin_rule;
+UPDATE casbin_rule SET v3 = v2, v2 = v1, v1 = '*' WHERE ptype = 'p';
+UPDATE casbin_rule SET v2 = 'default' WHERE ptype = 'g';
+ALTER TABLE casbin_rule ADD CONSTRAINT uq_casbin_rule UNIQUE NULLS NOT DISTINCT (ptype, v0, v1, v2, v3);
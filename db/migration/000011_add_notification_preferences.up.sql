@@ -0,0 +1,14 @@
+CREATE TABLE "notification_preferences" (
+  "username" varchar NOT NULL,
+  "event_type" varchar NOT NULL,
+  "channel" varchar NOT NULL,
+  "currency" varchar NOT NULL DEFAULT '',
+  "enabled" bool NOT NULL DEFAULT true,
+  "threshold" bigint NOT NULL DEFAULT 0,
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("username", "event_type", "channel", "currency")
+);
+
+ALTER TABLE "notification_preferences" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+COMMENT ON COLUMN "notification_preferences"."currency" IS 'currency of an alert threshold, empty for other events';
@@ -0,0 +1 @@
+ALTER TABLE "users" ADD COLUMN "verification_requested_at" timestamptz NOT NULL DEFAULT('0001-01-01 00:00:00Z');
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS "verify_phones";
+
+ALTER TABLE "users" DROP COLUMN "is_phone_verified";
+ALTER TABLE "users" DROP COLUMN "phone";
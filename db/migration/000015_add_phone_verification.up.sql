@@ -0,0 +1,17 @@
+ALTER TABLE "users" ADD COLUMN "phone" varchar NOT NULL DEFAULT '';
+ALTER TABLE "users" ADD COLUMN "is_phone_verified" bool NOT NULL DEFAULT false;
+
+CREATE TABLE "verify_phones" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "phone" varchar NOT NULL,
+  "hashed_code" varchar NOT NULL,
+  "attempts" int NOT NULL DEFAULT 0,
+  "is_used" bool NOT NULL DEFAULT false,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "expired_at" timestamptz NOT NULL DEFAULT (now() + interval '10 minutes')
+);
+
+ALTER TABLE "verify_phones" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+CREATE INDEX ON "verify_phones" ("username");
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "adjustments";
+
+ALTER TABLE "accounts" DROP COLUMN "is_frozen";
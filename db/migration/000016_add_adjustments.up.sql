@@ -0,0 +1,21 @@
+ALTER TABLE "accounts" ADD COLUMN "is_frozen" bool NOT NULL DEFAULT false;
+
+CREATE TABLE "adjustments" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "entry_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "reason" varchar NOT NULL,
+  "created_by" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "adjustments" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "adjustments" ADD FOREIGN KEY ("entry_id") REFERENCES "entries" ("id");
+
+CREATE INDEX ON "adjustments" ("account_id");
+
+COMMENT ON COLUMN "adjustments"."amount" IS 'can be negative or positive';
+
+COMMENT ON COLUMN "adjustments"."created_by" IS 'operator who posted the adjustment';
@@ -0,0 +1,15 @@
+CREATE TABLE "account_events" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "owner" varchar NOT NULL,
+  "tenant_id" varchar NOT NULL,
+  "event_type" varchar NOT NULL,
+  "payload" jsonb NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "account_events" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+CREATE INDEX ON "account_events" ("tenant_id", "owner", "id");
+
+COMMENT ON COLUMN "account_events"."owner" IS 'owner of the account, who receives the event';
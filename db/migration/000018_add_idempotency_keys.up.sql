@@ -0,0 +1,15 @@
+CREATE TABLE "idempotency_keys" (
+  "tenant_id" varchar NOT NULL,
+  "username" varchar NOT NULL,
+  "key" varchar NOT NULL,
+  "request_hash" varchar NOT NULL,
+  "response_status" int,
+  "response_content_type" varchar NOT NULL DEFAULT '',
+  "response_body" bytea,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("tenant_id", "username", "key")
+);
+
+COMMENT ON COLUMN "idempotency_keys"."request_hash" IS 'sha256 of the method, path and body of the first request';
+
+COMMENT ON COLUMN "idempotency_keys"."response_status" IS 'null while the first request is in progress';
@@ -0,0 +1 @@
+ALTER TABLE "outbox" ADD COLUMN "headers" jsonb NOT NULL DEFAULT '{}';
@@ -0,0 +1,47 @@
+CREATE TABLE "audit_events" (
+  "id" bigserial PRIMARY KEY,
+  "tenant_id" varchar NOT NULL,
+  "actor" varchar NOT NULL,
+  "actor_role" varchar NOT NULL,
+  "action" varchar NOT NULL,
+  "target_type" varchar NOT NULL,
+  "target_id" varchar NOT NULL,
+  "outcome" varchar NOT NULL,
+  "reason" varchar NOT NULL,
+  "request_id" varchar NOT NULL,
+  "client_ip" varchar NOT NULL,
+  "before" jsonb,
+  "after" jsonb,
+  "created_at" timestamptz NOT NULL,
+  "prev_hash" bytea NOT NULL,
+  "hash" bytea UNIQUE NOT NULL
+);
+
+CREATE INDEX ON "audit_events" ("tenant_id", "id");
+
+CREATE INDEX ON "audit_events" ("tenant_id", "created_at");
+
+CREATE INDEX ON "audit_events" ("tenant_id", "actor", "created_at");
+
+CREATE INDEX ON "audit_events" ("tenant_id", "target_type", "target_id", "created_at");
+
+COMMENT ON COLUMN "audit_events"."actor" IS 'username of the caller, or the attempted username of a failed login';
+
+COMMENT ON COLUMN "audit_events"."outcome" IS 'success, failure or denied';
+
+COMMENT ON COLUMN "audit_events"."reason" IS 'problem code of a failure';
+
+COMMENT ON COLUMN "audit_events"."prev_hash" IS 'hash of the previous event of the tenant, 32 zero bytes for its first one';
+
+COMMENT ON COLUMN "audit_events"."hash" IS 'sha256 of prev_hash and the other columns, see db.AuditEventHash';
+
+-- The log is append-only: rows can be inserted, never changed or removed.
+CREATE FUNCTION "reject_audit_event_change"() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "audit_events_append_only"
+BEFORE UPDATE OR DELETE OR TRUNCATE ON "audit_events"
+FOR EACH STATEMENT EXECUTE FUNCTION "reject_audit_event_change"();
@@ -0,0 +1,15 @@
+DROP INDEX IF EXISTS "accounts_owner_currency_open_key";
+
+ALTER TABLE "accounts" ADD COLUMN "is_frozen" bool NOT NULL DEFAULT false;
+
+-- Closed accounts stay unusable as frozen ones.
+UPDATE "accounts" SET "is_frozen" = true WHERE "status" <> 'active';
+
+-- Fails if a user opened a new account in the currency of a closed one.
+ALTER TABLE "accounts" ADD CONSTRAINT "owner_currency_key" UNIQUE ("owner", "currency");
+
+ALTER TABLE "accounts" DROP COLUMN "status_changed_at";
+
+ALTER TABLE "accounts" DROP COLUMN "status_reason";
+
+ALTER TABLE "accounts" DROP COLUMN "status";
@@ -0,0 +1,20 @@
+ALTER TABLE "accounts" ADD COLUMN "status" varchar NOT NULL DEFAULT 'active';
+
+ALTER TABLE "accounts" ADD COLUMN "status_reason" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "accounts" ADD COLUMN "status_changed_at" timestamptz NOT NULL DEFAULT (now());
+
+-- The free-text reason of earlier freezes is only kept in the logs, so they
+-- stay frozen as under review until an operator unfreezes them.
+UPDATE "accounts" SET "status" = 'frozen', "status_reason" = 'compliance_review' WHERE "is_frozen";
+
+ALTER TABLE "accounts" DROP COLUMN "is_frozen";
+
+-- A closed account no longer blocks opening a new one in the same currency.
+ALTER TABLE "accounts" DROP CONSTRAINT "owner_currency_key";
+
+CREATE UNIQUE INDEX "accounts_owner_currency_open_key" ON "accounts" ("owner", "currency") WHERE "status" <> 'closed';
+
+COMMENT ON COLUMN "accounts"."status" IS 'active, frozen or closed';
+
+COMMENT ON COLUMN "accounts"."status_reason" IS 'reason code of the last status change';
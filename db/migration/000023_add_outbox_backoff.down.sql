@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS "outbox_published_at_idx";
+DROP INDEX IF EXISTS "outbox_next_attempt_at_idx";
+CREATE INDEX ON "outbox" ("id") WHERE "published_at" IS NULL;
+
+ALTER TABLE "outbox" DROP COLUMN "dead_at";
+ALTER TABLE "outbox" DROP COLUMN "next_attempt_at";
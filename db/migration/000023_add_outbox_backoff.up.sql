@@ -0,0 +1,9 @@
+ALTER TABLE "outbox" ADD COLUMN "next_attempt_at" timestamptz NOT NULL DEFAULT (now());
+ALTER TABLE "outbox" ADD COLUMN "dead_at" timestamptz;
+
+COMMENT ON COLUMN "outbox"."next_attempt_at" IS 'the relay skips the row until this time after a failed publish';
+COMMENT ON COLUMN "outbox"."dead_at" IS 'set when the row ran out of attempts; the relay no longer publishes it';
+
+DROP INDEX IF EXISTS "outbox_id_idx";
+CREATE INDEX ON "outbox" ("next_attempt_at") WHERE "published_at" IS NULL AND "dead_at" IS NULL;
+CREATE INDEX ON "outbox" ("published_at") WHERE "published_at" IS NOT NULL;
@@ -0,0 +1,14 @@
+CREATE TABLE "transfer_notifications" (
+  "transfer_id" bigint NOT NULL,
+  "username" varchar NOT NULL,
+  "template" varchar NOT NULL,
+  "channel" varchar NOT NULL,
+  "sent_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("transfer_id", "username", "template", "channel")
+);
+
+ALTER TABLE "transfer_notifications" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id") ON DELETE CASCADE;
+
+CREATE INDEX ON "transfer_notifications" ("sent_at");
+
+COMMENT ON TABLE "transfer_notifications" IS 'notifications already sent for a transfer, skipped when the task is retried';
@@ -2,39 +2,73 @@
 INSERT INTO accounts (
   owner,
   balance,
-  currency
+  currency,
+  tenant_id
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4
 ) RETURNING *;
 
 -- name: GetAccount :one
 SELECT * FROM accounts
-WHERE id = $1 LIMIT 1;
+WHERE id = $1 AND tenant_id = $2 LIMIT 1;
 
 -- name: GetAccountForUpdate :one
 SELECT * FROM accounts
-WHERE id = $1 LIMIT 1
+WHERE id = $1 AND tenant_id = $2 LIMIT 1
 FOR NO KEY UPDATE;
 
+-- name: GetAccountTenant :one
+SELECT tenant_id FROM accounts
+WHERE id = $1 LIMIT 1;
+
 -- name: ListAccounts :many
 SELECT * FROM accounts
-WHERE owner = $1
+WHERE owner = $1 AND tenant_id = $2
 ORDER BY id
-LIMIT $2
-OFFSET $3;
+LIMIT $3
+OFFSET $4;
 
 -- name: UpdateAccount :one
 UPDATE accounts
 SET balance = $2
-WHERE id = $1
+WHERE id = $1 AND tenant_id = $3
 RETURNING *;
 
 -- name: AddAccountBalance :one
+-- Only called inside store transactions whose callers have checked the tenant
+-- of the account. The recipient of a transfer may belong to another tenant.
 UPDATE accounts
 SET balance = balance + sqlc.arg(amount)
 WHERE id = sqlc.arg(id)
 RETURNING *;
 
--- name: DeleteAccount :exec
-DELETE FROM accounts
-WHERE id = $1;
+-- name: ListBalanceMismatches :many
+SELECT
+  a.id,
+  a.owner,
+  a.currency,
+  a.balance,
+  COALESCE(SUM(e.amount), 0)::bigint AS entries_total
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id
+GROUP BY a.id
+HAVING a.balance <> COALESCE(SUM(e.amount), 0)
+ORDER BY a.id;
+
+-- name: GetAccountByID :one
+SELECT * FROM accounts
+WHERE id = $1 LIMIT 1;
+
+-- name: GetAccountByIDForUpdate :one
+SELECT * FROM accounts
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: UpdateAccountStatus :one
+UPDATE accounts
+SET
+  status = sqlc.arg(status),
+  status_reason = sqlc.arg(status_reason),
+  status_changed_at = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
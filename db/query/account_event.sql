@@ -0,0 +1,26 @@
+-- name: LockAccountEvents :exec
+-- Serializes the events of an owner until commit, so that their IDs follow
+-- the order in which they commit.
+SELECT pg_advisory_xact_lock(hashtext('account_events'), hashtext(sqlc.arg(tenant_id)::text || '/' || sqlc.arg(owner)::text));
+
+-- name: CreateAccountEvent :one
+INSERT INTO account_events (
+  account_id,
+  owner,
+  tenant_id,
+  event_type,
+  payload
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: ListAccountEventsAfter :many
+SELECT * FROM account_events
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND owner = sqlc.arg(owner)
+  AND id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg(limit_count);
+
+-- name: NotifyAccountEvent :exec
+SELECT pg_notify(sqlc.arg(channel)::text, sqlc.arg(payload)::text);
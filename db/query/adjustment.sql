@@ -0,0 +1,17 @@
+-- name: CreateAdjustment :one
+INSERT INTO adjustments (
+  account_id,
+  entry_id,
+  amount,
+  reason,
+  created_by
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: ListAdjustments :many
+SELECT * FROM adjustments
+WHERE account_id = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
@@ -0,0 +1,51 @@
+-- name: LockAuditEvents :exec
+-- Serializes the appends to the audit chain of a tenant until commit.
+SELECT pg_advisory_xact_lock(hashtext('audit_events'), hashtext(sqlc.arg(tenant_id)::text));
+
+-- name: GetLastAuditEvent :one
+SELECT * FROM audit_events
+WHERE tenant_id = $1
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: CreateAuditEvent :one
+INSERT INTO audit_events (
+  tenant_id,
+  actor,
+  actor_role,
+  action,
+  target_type,
+  target_id,
+  outcome,
+  reason,
+  request_id,
+  client_ip,
+  before,
+  after,
+  created_at,
+  prev_hash,
+  hash
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
+) RETURNING *;
+
+-- name: ListAuditEvents :many
+SELECT * FROM audit_events
+WHERE
+  tenant_id = sqlc.arg(tenant_id)
+  AND (sqlc.narg(actor)::varchar IS NULL OR actor = sqlc.narg(actor))
+  AND (sqlc.narg(action)::varchar IS NULL OR action = sqlc.narg(action))
+  AND (sqlc.narg(target_type)::varchar IS NULL OR target_type = sqlc.narg(target_type))
+  AND (sqlc.narg(target_id)::varchar IS NULL OR target_id = sqlc.narg(target_id))
+  AND (sqlc.narg(outcome)::varchar IS NULL OR outcome = sqlc.narg(outcome))
+  AND (sqlc.narg(created_from)::timestamptz IS NULL OR created_at >= sqlc.narg(created_from))
+  AND (sqlc.narg(created_to)::timestamptz IS NULL OR created_at < sqlc.narg(created_to))
+ORDER BY id DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: ListAuditEventsAfter :many
+SELECT * FROM audit_events
+WHERE id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg(limit_count);
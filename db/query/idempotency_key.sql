@@ -0,0 +1,40 @@
+-- name: CreateIdempotencyKey :one
+-- Claims the key for a new request. Keys older than expired_before are claimed
+-- again; a live key returns no row.
+INSERT INTO idempotency_keys (
+  tenant_id,
+  username,
+  key,
+  request_hash
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (tenant_id, username, key) DO UPDATE
+SET
+  request_hash = EXCLUDED.request_hash,
+  response_status = NULL,
+  response_content_type = '',
+  response_body = NULL,
+  created_at = now()
+WHERE idempotency_keys.created_at < sqlc.arg(expired_before)
+RETURNING *;
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys
+WHERE tenant_id = $1 AND username = $2 AND key = $3;
+
+-- name: SaveIdempotencyKeyResponse :exec
+UPDATE idempotency_keys
+SET
+  response_status = $4,
+  response_content_type = $5,
+  response_body = $6
+WHERE tenant_id = $1 AND username = $2 AND key = $3;
+
+-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE tenant_id = $1 AND username = $2 AND key = $3;
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys
+WHERE created_at < sqlc.arg(expired_before);
@@ -0,0 +1,22 @@
+-- name: ListNotificationPreferences :many
+SELECT * FROM notification_preferences
+WHERE username = $1
+ORDER BY event_type, channel, currency;
+
+-- name: UpsertNotificationPreference :one
+INSERT INTO notification_preferences (
+    username,
+    event_type,
+    channel,
+    currency,
+    enabled,
+    threshold
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (username, event_type, channel, currency) DO UPDATE
+SET
+    enabled = EXCLUDED.enabled,
+    threshold = EXCLUDED.threshold,
+    updated_at = now()
+RETURNING *;
@@ -0,0 +1,41 @@
+-- name: CreateOutbox :one
+INSERT INTO outbox (
+    task_type,
+    payload,
+    queue,
+    max_retry,
+    process_at,
+    headers
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: ListPendingOutbox :many
+SELECT * FROM outbox
+WHERE published_at IS NULL
+  AND dead_at IS NULL
+  AND next_attempt_at <= now()
+ORDER BY id
+LIMIT $1
+FOR UPDATE SKIP LOCKED;
+
+-- name: MarkOutboxPublished :exec
+UPDATE outbox
+SET
+    published_at = now(),
+    attempts = attempts + 1
+WHERE id = $1;
+
+-- name: MarkOutboxFailed :one
+UPDATE outbox
+SET
+    attempts = attempts + 1,
+    last_error = sqlc.arg(last_error),
+    next_attempt_at = sqlc.arg(next_attempt_at),
+    dead_at = CASE WHEN attempts + 1 >= sqlc.arg(max_attempts)::int THEN now() END
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: DeleteOutboxPublishedBefore :execrows
+DELETE FROM outbox
+WHERE published_at < sqlc.arg(published_before)::timestamptz;
@@ -14,3 +14,12 @@ INSERT INTO sessions (
 -- name: GetSession :one
 SELECT * FROM sessions
 WHERE id = $1 LIMIT 1;
+
+-- name: BlockUserSessions :exec
+UPDATE sessions
+SET is_blocked = TRUE
+WHERE username = $1 AND is_blocked = FALSE;
+
+-- name: DeleteExpiredSessions :execrows
+DELETE FROM sessions
+WHERE expires_at < sqlc.arg(expired_before);
@@ -0,0 +1,12 @@
+-- name: CreateTenant :one
+INSERT INTO tenants (
+  id,
+  name,
+  allow_cross_tenant_transfers
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
+
+-- name: GetTenant :one
+SELECT * FROM tenants
+WHERE id = $1 LIMIT 1;
@@ -2,20 +2,22 @@
 INSERT INTO transfers (
   from_account_id,
   to_account_id,
-  amount
+  amount,
+  tenant_id
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4
 ) RETURNING *;
 
 -- name: GetTransfer :one
 SELECT * FROM transfers
-WHERE id = $1 LIMIT 1;
+WHERE id = $1 AND tenant_id = $2 LIMIT 1;
 
 -- name: ListTransfers :many
 SELECT * FROM transfers
 WHERE 
-    from_account_id = $1 OR
-    to_account_id = $2
+    tenant_id = $1 AND
+    (from_account_id = $2 OR
+    to_account_id = $3)
 ORDER BY id
-LIMIT $3
-OFFSET $4;
+LIMIT $4
+OFFSET $5;
@@ -0,0 +1,17 @@
+-- name: ListSentTransferNotifications :many
+SELECT * FROM transfer_notifications
+WHERE transfer_id = $1 AND username = $2;
+
+-- name: CreateTransferNotification :exec
+INSERT INTO transfer_notifications (
+    transfer_id,
+    username,
+    template,
+    channel
+) VALUES (
+    $1, $2, $3, $4
+) ON CONFLICT DO NOTHING;
+
+-- name: DeleteTransferNotificationsSentBefore :execrows
+DELETE FROM transfer_notifications
+WHERE sent_at < sqlc.arg(sent_before);
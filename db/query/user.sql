@@ -3,15 +3,47 @@ INSERT INTO users (
   username,
   hashed_password,
   full_name,
-  email
+  email,
+  tenant_id
 ) VALUES (
-  $1, $2, $3, $4
+  $1, $2, $3, $4, $5
 ) RETURNING *;
 
 -- name: GetUser :one
+-- Usernames are unique across tenants, so this is not filtered by tenant.
+-- Callers look up the authenticated user or check user.tenant_id themselves.
 SELECT * FROM users
 WHERE username = $1 LIMIT 1;
 
+-- name: GetUserForUpdate :one
+SELECT * FROM users
+WHERE username = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: GetUserTokensValidAfter :one
+SELECT tokens_valid_after FROM users
+WHERE username = $1 LIMIT 1;
+
+-- name: RevokeUserTokens :exec
+UPDATE users
+SET
+  tokens_valid_after = sqlc.arg(tokens_valid_after)
+WHERE
+  username = sqlc.arg(username);
+
+-- name: ListUsers :many
+SELECT * FROM users
+WHERE
+  tenant_id = sqlc.arg(tenant_id)
+  AND (sqlc.narg(search)::varchar IS NULL
+    OR username ILIKE '%' || sqlc.narg(search) || '%'
+    OR full_name ILIKE '%' || sqlc.narg(search) || '%'
+    OR email ILIKE '%' || sqlc.narg(search) || '%')
+  AND (sqlc.narg(role)::varchar IS NULL OR role = sqlc.narg(role))
+ORDER BY username
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
 -- name: UpdateUser :one
 UPDATE users
 SET
@@ -19,7 +51,30 @@ SET
   password_changed_at = COALESCE(sqlc.narg(password_changed_at), password_changed_at),
   full_name = COALESCE(sqlc.narg(full_name), full_name),
   email = COALESCE(sqlc.narg(email), email),
-  is_email_verified = COALESCE(sqlc.narg(is_email_verified), is_email_verified)
+  is_email_verified = COALESCE(sqlc.narg(is_email_verified), is_email_verified),
+  role = COALESCE(sqlc.narg(role), role),
+  is_disabled = COALESCE(sqlc.narg(is_disabled), is_disabled),
+  locale = COALESCE(sqlc.narg(locale), locale)
+WHERE
+  username = sqlc.arg(username)
+  AND tenant_id = COALESCE(sqlc.narg(tenant_id), tenant_id)
+RETURNING *;
+
+-- name: UpdateUserPhone :one
+UPDATE users
+SET
+  phone = sqlc.arg(phone),
+  is_phone_verified = sqlc.arg(is_phone_verified)
+WHERE
+  username = sqlc.arg(username)
+RETURNING *;
+
+-- name: MarkVerificationRequested :one
+UPDATE users
+SET
+  verification_requested_at = now()
 WHERE
   username = sqlc.arg(username)
+  AND is_email_verified = FALSE
+  AND verification_requested_at < sqlc.arg(requested_before)
 RETURNING *;
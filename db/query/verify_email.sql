@@ -17,3 +17,16 @@ WHERE
     AND is_used = FALSE
     AND expired_at > now()
 RETURNING *;
+
+-- name: RevokeVerifyEmails :exec
+UPDATE verify_emails
+SET
+    expired_at = now()
+WHERE
+    username = @username
+    AND is_used = FALSE
+    AND expired_at > now();
+
+-- name: DeleteExpiredVerifyEmails :execrows
+DELETE FROM verify_emails
+WHERE expired_at < sqlc.arg(expired_before);
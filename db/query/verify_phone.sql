@@ -0,0 +1,54 @@
+-- name: CreateVerifyPhone :one
+INSERT INTO verify_phones (
+    username,
+    phone,
+    hashed_code
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: GetActiveVerifyPhoneForUpdate :one
+SELECT * FROM verify_phones
+WHERE
+    username = @username
+    AND is_used = FALSE
+    AND expired_at > now()
+    AND attempts < sqlc.arg(max_attempts)::int
+ORDER BY id DESC
+LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: IncrementVerifyPhoneAttempts :one
+UPDATE verify_phones
+SET
+    attempts = attempts + 1
+WHERE
+    id = @id
+RETURNING *;
+
+-- name: MarkVerifyPhoneUsed :one
+UPDATE verify_phones
+SET
+    is_used = TRUE
+WHERE
+    id = @id
+RETURNING *;
+
+-- name: CountVerifyPhonesSince :one
+SELECT count(*) FROM verify_phones
+WHERE
+    username = @username
+    AND created_at > sqlc.arg(created_after);
+
+-- name: RevokeVerifyPhones :exec
+UPDATE verify_phones
+SET
+    expired_at = now()
+WHERE
+    username = @username
+    AND is_used = FALSE
+    AND expired_at > now();
+
+-- name: DeleteExpiredVerifyPhones :execrows
+DELETE FROM verify_phones
+WHERE expired_at < sqlc.arg(expired_before);
@@ -0,0 +1,98 @@
+-- name: CreateWebhookSubscription :one
+INSERT INTO webhook_subscriptions (
+    username,
+    url,
+    events,
+    secret
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetWebhookSubscription :one
+SELECT * FROM webhook_subscriptions
+WHERE id = $1 LIMIT 1;
+
+-- name: ListWebhookSubscriptions :many
+SELECT * FROM webhook_subscriptions
+WHERE username = $1
+ORDER BY id;
+
+-- name: ListWebhookSubscriptionsForEvent :many
+SELECT * FROM webhook_subscriptions
+WHERE
+    username = sqlc.arg(username)
+    AND is_disabled = FALSE
+    AND sqlc.arg(event_type)::varchar = ANY(events)
+ORDER BY id;
+
+-- name: UpdateWebhookSubscription :one
+UPDATE webhook_subscriptions
+SET
+    url = COALESCE(sqlc.narg(url), url),
+    events = COALESCE(sqlc.narg(events)::varchar[], events),
+    is_disabled = COALESCE(sqlc.narg(is_disabled), is_disabled),
+    consecutive_failures = CASE
+        WHEN sqlc.narg(is_disabled) = FALSE THEN 0
+        ELSE consecutive_failures
+    END
+WHERE
+    id = sqlc.arg(id)
+RETURNING *;
+
+-- name: DeleteWebhookSubscription :exec
+DELETE FROM webhook_subscriptions
+WHERE id = $1;
+
+-- name: RecordWebhookSuccess :exec
+UPDATE webhook_subscriptions
+SET consecutive_failures = 0
+WHERE id = $1;
+
+-- name: RecordWebhookFailure :one
+UPDATE webhook_subscriptions
+SET
+    consecutive_failures = consecutive_failures + 1,
+    is_disabled = is_disabled OR consecutive_failures + 1 >= sqlc.arg(max_failures)::int
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries (
+    subscription_id,
+    event_type,
+    payload
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: GetWebhookDelivery :one
+SELECT * FROM webhook_deliveries
+WHERE id = $1 LIMIT 1;
+
+-- name: ListWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE subscription_id = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: MarkWebhookDeliverySucceeded :one
+UPDATE webhook_deliveries
+SET
+    status = 'succeeded',
+    attempts = attempts + 1,
+    response_status = sqlc.arg(response_status),
+    last_error = '',
+    delivered_at = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: MarkWebhookDeliveryFailed :one
+UPDATE webhook_deliveries
+SET
+    status = 'failed',
+    attempts = attempts + 1,
+    response_status = sqlc.arg(response_status),
+    last_error = sqlc.arg(last_error)
+WHERE id = sqlc.arg(id)
+RETURNING *;
@@ -13,7 +13,7 @@ const addAccountBalance = `-- name: AddAccountBalance :one
 UPDATE accounts
 SET balance = balance + $1
 WHERE id = $2
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, tenant_id, status, status_reason, status_changed_at
 `
 
 type AddAccountBalanceParams struct {
@@ -21,6 +21,8 @@ type AddAccountBalanceParams struct {
 	ID     int64 `json:"id"`
 }
 
+// Only called inside store transactions whose callers have checked the tenant
+// of the account. The recipient of a transfer may belong to another tenant.
 func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error) {
 	row := q.db.QueryRow(ctx, addAccountBalance, arg.Amount, arg.ID)
 	var i Account
@@ -30,6 +32,10 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.TenantID,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
 	)
 	return i, err
 }
@@ -38,20 +44,27 @@ const createAccount = `-- name: CreateAccount :one
 INSERT INTO accounts (
   owner,
   balance,
-  currency
+  currency,
+  tenant_id
 ) VALUES (
-  $1, $2, $3
-) RETURNING id, owner, balance, currency, created_at
+  $1, $2, $3, $4
+) RETURNING id, owner, balance, currency, created_at, tenant_id, status, status_reason, status_changed_at
 `
 
 type CreateAccountParams struct {
 	Owner    string `json:"owner"`
 	Balance  int64  `json:"balance"`
 	Currency string `json:"currency"`
+	TenantID string `json:"tenant_id"`
 }
 
 func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
-	row := q.db.QueryRow(ctx, createAccount, arg.Owner, arg.Balance, arg.Currency)
+	row := q.db.QueryRow(ctx, createAccount,
+		arg.Owner,
+		arg.Balance,
+		arg.Currency,
+		arg.TenantID,
+	)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -59,27 +72,48 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.TenantID,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
 	)
 	return i, err
 }
 
-const deleteAccount = `-- name: DeleteAccount :exec
-DELETE FROM accounts
-WHERE id = $1
+const getAccount = `-- name: GetAccount :one
+SELECT id, owner, balance, currency, created_at, tenant_id, status, status_reason, status_changed_at FROM accounts
+WHERE id = $1 AND tenant_id = $2 LIMIT 1
 `
 
-func (q *Queries) DeleteAccount(ctx context.Context, id int64) error {
-	_, err := q.db.Exec(ctx, deleteAccount, id)
-	return err
+type GetAccountParams struct {
+	ID       int64  `json:"id"`
+	TenantID string `json:"tenant_id"`
 }
 
-const getAccount = `-- name: GetAccount :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+func (q *Queries) GetAccount(ctx context.Context, arg GetAccountParams) (Account, error) {
+	row := q.db.QueryRow(ctx, getAccount, arg.ID, arg.TenantID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.TenantID,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+	)
+	return i, err
+}
+
+const getAccountByID = `-- name: GetAccountByID :one
+SELECT id, owner, balance, currency, created_at, tenant_id, status, status_reason, status_changed_at FROM accounts
 WHERE id = $1 LIMIT 1
 `
 
-func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
-	row := q.db.QueryRow(ctx, getAccount, id)
+func (q *Queries) GetAccountByID(ctx context.Context, id int64) (Account, error) {
+	row := q.db.QueryRow(ctx, getAccountByID, id)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -87,18 +121,22 @@ func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.TenantID,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
 	)
 	return i, err
 }
 
-const getAccountForUpdate = `-- name: GetAccountForUpdate :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+const getAccountByIDForUpdate = `-- name: GetAccountByIDForUpdate :one
+SELECT id, owner, balance, currency, created_at, tenant_id, status, status_reason, status_changed_at FROM accounts
 WHERE id = $1 LIMIT 1
 FOR NO KEY UPDATE
 `
 
-func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, error) {
-	row := q.db.QueryRow(ctx, getAccountForUpdate, id)
+func (q *Queries) GetAccountByIDForUpdate(ctx context.Context, id int64) (Account, error) {
+	row := q.db.QueryRow(ctx, getAccountByIDForUpdate, id)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -106,26 +144,76 @@ func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, e
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.TenantID,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
 	)
 	return i, err
 }
 
+const getAccountForUpdate = `-- name: GetAccountForUpdate :one
+SELECT id, owner, balance, currency, created_at, tenant_id, status, status_reason, status_changed_at FROM accounts
+WHERE id = $1 AND tenant_id = $2 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+type GetAccountForUpdateParams struct {
+	ID       int64  `json:"id"`
+	TenantID string `json:"tenant_id"`
+}
+
+func (q *Queries) GetAccountForUpdate(ctx context.Context, arg GetAccountForUpdateParams) (Account, error) {
+	row := q.db.QueryRow(ctx, getAccountForUpdate, arg.ID, arg.TenantID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.TenantID,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+	)
+	return i, err
+}
+
+const getAccountTenant = `-- name: GetAccountTenant :one
+SELECT tenant_id FROM accounts
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetAccountTenant(ctx context.Context, id int64) (string, error) {
+	row := q.db.QueryRow(ctx, getAccountTenant, id)
+	var tenant_id string
+	err := row.Scan(&tenant_id)
+	return tenant_id, err
+}
+
 const listAccounts = `-- name: ListAccounts :many
-SELECT id, owner, balance, currency, created_at FROM accounts
-WHERE owner = $1
+SELECT id, owner, balance, currency, created_at, tenant_id, status, status_reason, status_changed_at FROM accounts
+WHERE owner = $1 AND tenant_id = $2
 ORDER BY id
-LIMIT $2
-OFFSET $3
+LIMIT $3
+OFFSET $4
 `
 
 type ListAccountsParams struct {
-	Owner  string `json:"owner"`
-	Limit  int32  `json:"limit"`
-	Offset int32  `json:"offset"`
+	Owner    string `json:"owner"`
+	TenantID string `json:"tenant_id"`
+	Limit    int32  `json:"limit"`
+	Offset   int32  `json:"offset"`
 }
 
 func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error) {
-	rows, err := q.db.Query(ctx, listAccounts, arg.Owner, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, listAccounts,
+		arg.Owner,
+		arg.TenantID,
+		arg.Limit,
+		arg.Offset,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -139,6 +227,58 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 			&i.Balance,
 			&i.Currency,
 			&i.CreatedAt,
+			&i.TenantID,
+			&i.Status,
+			&i.StatusReason,
+			&i.StatusChangedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBalanceMismatches = `-- name: ListBalanceMismatches :many
+SELECT
+  a.id,
+  a.owner,
+  a.currency,
+  a.balance,
+  COALESCE(SUM(e.amount), 0)::bigint AS entries_total
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id
+GROUP BY a.id
+HAVING a.balance <> COALESCE(SUM(e.amount), 0)
+ORDER BY a.id
+`
+
+type ListBalanceMismatchesRow struct {
+	ID           int64  `json:"id"`
+	Owner        string `json:"owner"`
+	Currency     string `json:"currency"`
+	Balance      int64  `json:"balance"`
+	EntriesTotal int64  `json:"entries_total"`
+}
+
+func (q *Queries) ListBalanceMismatches(ctx context.Context) ([]ListBalanceMismatchesRow, error) {
+	rows, err := q.db.Query(ctx, listBalanceMismatches)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListBalanceMismatchesRow{}
+	for rows.Next() {
+		var i ListBalanceMismatchesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Currency,
+			&i.Balance,
+			&i.EntriesTotal,
 		); err != nil {
 			return nil, err
 		}
@@ -153,17 +293,51 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 const updateAccount = `-- name: UpdateAccount :one
 UPDATE accounts
 SET balance = $2
-WHERE id = $1
-RETURNING id, owner, balance, currency, created_at
+WHERE id = $1 AND tenant_id = $3
+RETURNING id, owner, balance, currency, created_at, tenant_id, status, status_reason, status_changed_at
 `
 
 type UpdateAccountParams struct {
-	ID      int64 `json:"id"`
-	Balance int64 `json:"balance"`
+	ID       int64  `json:"id"`
+	Balance  int64  `json:"balance"`
+	TenantID string `json:"tenant_id"`
 }
 
 func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error) {
-	row := q.db.QueryRow(ctx, updateAccount, arg.ID, arg.Balance)
+	row := q.db.QueryRow(ctx, updateAccount, arg.ID, arg.Balance, arg.TenantID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.TenantID,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+	)
+	return i, err
+}
+
+const updateAccountStatus = `-- name: UpdateAccountStatus :one
+UPDATE accounts
+SET
+  status = $1,
+  status_reason = $2,
+  status_changed_at = now()
+WHERE id = $3
+RETURNING id, owner, balance, currency, created_at, tenant_id, status, status_reason, status_changed_at
+`
+
+type UpdateAccountStatusParams struct {
+	Status       string `json:"status"`
+	StatusReason string `json:"status_reason"`
+	ID           int64  `json:"id"`
+}
+
+func (q *Queries) UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error) {
+	row := q.db.QueryRow(ctx, updateAccountStatus, arg.Status, arg.StatusReason, arg.ID)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -171,6 +345,10 @@ func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.TenantID,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
 	)
 	return i, err
 }
@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// AccountEventsChannel is the Postgres NOTIFY channel that account events are
+// published on. NOTIFY is delivered on commit, so listeners never see an event
+// of a rolled back transaction.
+const AccountEventsChannel = "account_events"
+
+// Account event types
+const (
+	AccountEventTransferSent     = "transfer.sent"
+	AccountEventTransferReceived = "transfer.received"
+	AccountEventBalanceAdjusted  = "balance.adjusted"
+	AccountEventStatusChanged    = "status.changed"
+)
+
+// AccountEventPayload is the payload of an account event: the change and the
+// balance right after it. Status changes carry the new status and its reason
+// instead of an amount.
+type AccountEventPayload struct {
+	AccountID    int64  `json:"account_id"`
+	Amount       int64  `json:"amount"`
+	Balance      int64  `json:"balance"`
+	Currency     string `json:"currency"`
+	EntryID      int64  `json:"entry_id,omitempty"`
+	TransferID   int64  `json:"transfer_id,omitempty"`
+	AdjustmentID int64  `json:"adjustment_id,omitempty"`
+	Status       string `json:"status,omitempty"`
+	Reason       string `json:"reason,omitempty"`
+}
+
+// recordAccountEvent stores an event for the owner of account and notifies the
+// listeners on AccountEventsChannel once the transaction commits. The owner's
+// events are locked first, so an event never commits after one with a higher
+// ID: streams resume after the last ID they sent and would skip it.
+func recordAccountEvent(ctx context.Context, q Querier, account Account, eventType string, payload AccountEventPayload) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal account event payload: %w", err)
+	}
+
+	if err := lockAccountEventOwners(ctx, q, account); err != nil {
+		return err
+	}
+
+	event, err := q.CreateAccountEvent(ctx, CreateAccountEventParams{
+		AccountID: account.ID,
+		Owner:     account.Owner,
+		TenantID:  account.TenantID,
+		EventType: eventType,
+		Payload:   data,
+	})
+	if err != nil {
+		return err
+	}
+
+	notification, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal account event: %w", err)
+	}
+
+	return q.NotifyAccountEvent(ctx, NotifyAccountEventParams{
+		Channel: AccountEventsChannel,
+		Payload: string(notification),
+	})
+}
+
+// lockAccountEventOwners locks the events of the owners of accounts until commit.
+// The owners are locked in order, so transactions recording events for the
+// same owners cannot deadlock.
+func lockAccountEventOwners(ctx context.Context, q Querier, accounts ...Account) error {
+	owners := make([]LockAccountEventsParams, 0, len(accounts))
+	for _, account := range accounts {
+		owners = append(owners, LockAccountEventsParams{TenantID: account.TenantID, Owner: account.Owner})
+	}
+	sort.Slice(owners, func(i, j int) bool {
+		if owners[i].TenantID != owners[j].TenantID {
+			return owners[i].TenantID < owners[j].TenantID
+		}
+		return owners[i].Owner < owners[j].Owner
+	})
+
+	for _, owner := range owners {
+		if err := q.LockAccountEvents(ctx, owner); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: account_event.sql
+
+package db
+
+import (
+	"context"
+)
+
+const createAccountEvent = `-- name: CreateAccountEvent :one
+INSERT INTO account_events (
+  account_id,
+  owner,
+  tenant_id,
+  event_type,
+  payload
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING id, account_id, owner, tenant_id, event_type, payload, created_at
+`
+
+type CreateAccountEventParams struct {
+	AccountID int64  `json:"account_id"`
+	Owner     string `json:"owner"`
+	TenantID  string `json:"tenant_id"`
+	EventType string `json:"event_type"`
+	Payload   []byte `json:"payload"`
+}
+
+func (q *Queries) CreateAccountEvent(ctx context.Context, arg CreateAccountEventParams) (AccountEvent, error) {
+	row := q.db.QueryRow(ctx, createAccountEvent,
+		arg.AccountID,
+		arg.Owner,
+		arg.TenantID,
+		arg.EventType,
+		arg.Payload,
+	)
+	var i AccountEvent
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Owner,
+		&i.TenantID,
+		&i.EventType,
+		&i.Payload,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listAccountEventsAfter = `-- name: ListAccountEventsAfter :many
+SELECT id, account_id, owner, tenant_id, event_type, payload, created_at FROM account_events
+WHERE tenant_id = $1
+  AND owner = $2
+  AND id > $3
+ORDER BY id
+LIMIT $4
+`
+
+type ListAccountEventsAfterParams struct {
+	TenantID   string `json:"tenant_id"`
+	Owner      string `json:"owner"`
+	AfterID    int64  `json:"after_id"`
+	LimitCount int32  `json:"limit_count"`
+}
+
+func (q *Queries) ListAccountEventsAfter(ctx context.Context, arg ListAccountEventsAfterParams) ([]AccountEvent, error) {
+	rows, err := q.db.Query(ctx, listAccountEventsAfter,
+		arg.TenantID,
+		arg.Owner,
+		arg.AfterID,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AccountEvent{}
+	for rows.Next() {
+		var i AccountEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Owner,
+			&i.TenantID,
+			&i.EventType,
+			&i.Payload,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockAccountEvents = `-- name: LockAccountEvents :exec
+SELECT pg_advisory_xact_lock(hashtext('account_events'), hashtext($1::text || '/' || $2::text))
+`
+
+type LockAccountEventsParams struct {
+	TenantID string `json:"tenant_id"`
+	Owner    string `json:"owner"`
+}
+
+// Serializes the events of an owner until commit, so that their IDs follow
+// the order in which they commit.
+func (q *Queries) LockAccountEvents(ctx context.Context, arg LockAccountEventsParams) error {
+	_, err := q.db.Exec(ctx, lockAccountEvents, arg.TenantID, arg.Owner)
+	return err
+}
+
+const notifyAccountEvent = `-- name: NotifyAccountEvent :exec
+SELECT pg_notify($1::text, $2::text)
+`
+
+type NotifyAccountEventParams struct {
+	Channel string `json:"channel"`
+	Payload string `json:"payload"`
+}
+
+func (q *Queries) NotifyAccountEvent(ctx context.Context, arg NotifyAccountEventParams) error {
+	_, err := q.db.Exec(ctx, notifyAccountEvent, arg.Channel, arg.Payload)
+	return err
+}
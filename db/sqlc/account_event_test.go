@@ -0,0 +1,131 @@
+package db
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// eventTable stands in for account_events: IDs come from a sequence when a
+// row is inserted, and advisory locks are held until the transaction commits.
+type eventTable struct {
+	mu        sync.Mutex
+	nextID    int64
+	locks     map[LockAccountEventsParams]*sync.Mutex
+	committed []int64
+}
+
+func (table *eventTable) lock(key LockAccountEventsParams) *sync.Mutex {
+	table.mu.Lock()
+	defer table.mu.Unlock()
+
+	if table.locks == nil {
+		table.locks = make(map[LockAccountEventsParams]*sync.Mutex)
+	}
+	if table.locks[key] == nil {
+		table.locks[key] = &sync.Mutex{}
+	}
+	return table.locks[key]
+}
+
+// eventTx is a transaction on an eventTable.
+type eventTx struct {
+	Querier
+	table    *eventTable
+	held     []*sync.Mutex
+	inserted []int64
+}
+
+func (tx *eventTx) LockAccountEvents(_ context.Context, arg LockAccountEventsParams) error {
+	lock := tx.table.lock(arg)
+	lock.Lock()
+	tx.held = append(tx.held, lock)
+	return nil
+}
+
+func (tx *eventTx) CreateAccountEvent(_ context.Context, arg CreateAccountEventParams) (AccountEvent, error) {
+	tx.table.mu.Lock()
+	defer tx.table.mu.Unlock()
+
+	tx.table.nextID++
+	tx.inserted = append(tx.inserted, tx.table.nextID)
+	return AccountEvent{ID: tx.table.nextID, Owner: arg.Owner, TenantID: arg.TenantID}, nil
+}
+
+func (tx *eventTx) NotifyAccountEvent(context.Context, NotifyAccountEventParams) error {
+	return nil
+}
+
+func (tx *eventTx) commit() {
+	tx.table.mu.Lock()
+	tx.table.committed = append(tx.table.committed, tx.inserted...)
+	tx.table.mu.Unlock()
+
+	for _, lock := range tx.held {
+		lock.Unlock()
+	}
+}
+
+func TestRecordAccountEventCommitsInIDOrder(t *testing.T) {
+	ctx := context.Background()
+	account := Account{ID: 1, Owner: "alice", TenantID: "default"}
+	table := &eventTable{}
+
+	txA := &eventTx{table: table}
+	require.NoError(t, recordAccountEvent(ctx, txA, account, AccountEventTransferSent, AccountEventPayload{}))
+
+	// B tries to commit an event of the same owner before A does.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		txB := &eventTx{table: table}
+		require.NoError(t, recordAccountEvent(ctx, txB, account, AccountEventTransferReceived, AccountEventPayload{}))
+		txB.commit()
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("an event of the same owner committed while another was pending")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	txA.commit()
+	<-done
+	require.Equal(t, []int64{1, 2}, table.committed)
+}
+
+func TestLockAccountEventOwnersInOrder(t *testing.T) {
+	ctx := context.Background()
+	alice := Account{ID: 1, Owner: "alice", TenantID: "default"}
+	bob := Account{ID: 2, Owner: "bob", TenantID: "default"}
+	table := &eventTable{}
+
+	// Transfers in opposite directions lock the two owners in the same order,
+	// so they cannot deadlock.
+	var wg sync.WaitGroup
+	for _, accounts := range [][2]Account{{alice, bob}, {bob, alice}} {
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func(from, to Account) {
+				defer wg.Done()
+				tx := &eventTx{table: table}
+				require.NoError(t, lockAccountEventOwners(ctx, tx, from, to))
+				tx.commit()
+			}(accounts[0], accounts[1])
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("transactions locking the same owners deadlocked")
+	}
+}
@@ -0,0 +1,85 @@
+package db
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Account statuses
+const (
+	AccountStatusActive = "active"
+	AccountStatusFrozen = "frozen"
+	AccountStatusClosed = "closed"
+)
+
+// Reason codes of account status changes
+const (
+	AccountReasonSuspectedFraud   = "suspected_fraud"
+	AccountReasonLegalOrder       = "legal_order"
+	AccountReasonComplianceReview = "compliance_review"
+	AccountReasonCustomerRequest  = "customer_request"
+	AccountReasonReviewCleared    = "review_cleared"
+)
+
+type accountTransition struct {
+	from string
+	to   string
+}
+
+// accountTransitions lists the allowed status changes and the reason codes
+// each one accepts. Closed is final, and a frozen account has to be
+// unfrozen before it can be closed.
+var accountTransitions = map[accountTransition][]string{
+	{AccountStatusActive, AccountStatusFrozen}: {
+		AccountReasonSuspectedFraud,
+		AccountReasonLegalOrder,
+		AccountReasonComplianceReview,
+		AccountReasonCustomerRequest,
+	},
+	{AccountStatusFrozen, AccountStatusActive}: {
+		AccountReasonReviewCleared,
+		AccountReasonCustomerRequest,
+	},
+	{AccountStatusActive, AccountStatusClosed}: {
+		AccountReasonCustomerRequest,
+	},
+}
+
+// AccountReasons returns the reason codes accepted to move an account from
+// one status to another, none if the change is not allowed.
+func AccountReasons(from string, to string) []string {
+	return accountTransitions[accountTransition{from, to}]
+}
+
+// CheckAccountTransition reports whether account can move to status with
+// reason. Closing also needs a zero balance. Holds are not modelled yet, so
+// the balance is the only money that can be left on an account.
+func CheckAccountTransition(account Account, status string, reason string) error {
+	if account.Status == AccountStatusClosed {
+		return ErrAccountClosed
+	}
+
+	reasons, ok := accountTransitions[accountTransition{account.Status, status}]
+	if !ok {
+		return fmt.Errorf("%w from %s to %s", ErrInvalidAccountTransition, account.Status, status)
+	}
+	if !slices.Contains(reasons, reason) {
+		return fmt.Errorf("%w %q for %s to %s", ErrInvalidAccountReason, reason, account.Status, status)
+	}
+
+	if status == AccountStatusClosed && account.Balance != 0 {
+		return ErrAccountNotEmpty
+	}
+	return nil
+}
+
+// CheckAccountUsable reports whether money can move in or out of account.
+func CheckAccountUsable(account Account) error {
+	switch account.Status {
+	case AccountStatusFrozen:
+		return ErrAccountFrozen
+	case AccountStatusClosed:
+		return ErrAccountClosed
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckAccountTransition(t *testing.T) {
+	testCases := []struct {
+		name    string
+		account Account
+		status  string
+		reason  string
+		err     error
+	}{
+		{
+			name:    "Freeze",
+			account: Account{Status: AccountStatusActive, Balance: 100},
+			status:  AccountStatusFrozen,
+			reason:  AccountReasonSuspectedFraud,
+		},
+		{
+			name:    "FreezeWithUnfreezeReason",
+			account: Account{Status: AccountStatusActive},
+			status:  AccountStatusFrozen,
+			reason:  AccountReasonReviewCleared,
+			err:     ErrInvalidAccountReason,
+		},
+		{
+			name:    "FreezeFrozen",
+			account: Account{Status: AccountStatusFrozen},
+			status:  AccountStatusFrozen,
+			reason:  AccountReasonLegalOrder,
+			err:     ErrInvalidAccountTransition,
+		},
+		{
+			name:    "Unfreeze",
+			account: Account{Status: AccountStatusFrozen, StatusReason: AccountReasonComplianceReview},
+			status:  AccountStatusActive,
+			reason:  AccountReasonReviewCleared,
+		},
+		{
+			name:    "UnfreezeActive",
+			account: Account{Status: AccountStatusActive},
+			status:  AccountStatusActive,
+			reason:  AccountReasonReviewCleared,
+			err:     ErrInvalidAccountTransition,
+		},
+		{
+			name:    "Close",
+			account: Account{Status: AccountStatusActive},
+			status:  AccountStatusClosed,
+			reason:  AccountReasonCustomerRequest,
+		},
+		{
+			name:    "CloseNotEmpty",
+			account: Account{Status: AccountStatusActive, Balance: 1},
+			status:  AccountStatusClosed,
+			reason:  AccountReasonCustomerRequest,
+			err:     ErrAccountNotEmpty,
+		},
+		{
+			name:    "CloseFrozen",
+			account: Account{Status: AccountStatusFrozen},
+			status:  AccountStatusClosed,
+			reason:  AccountReasonCustomerRequest,
+			err:     ErrInvalidAccountTransition,
+		},
+		{
+			name:    "ReopenClosed",
+			account: Account{Status: AccountStatusClosed},
+			status:  AccountStatusActive,
+			reason:  AccountReasonCustomerRequest,
+			err:     ErrAccountClosed,
+		},
+		{
+			name:    "UnknownStatus",
+			account: Account{Status: AccountStatusActive},
+			status:  "dormant",
+			reason:  AccountReasonCustomerRequest,
+			err:     ErrInvalidAccountTransition,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			err := CheckAccountTransition(tc.account, tc.status, tc.reason)
+			if tc.err == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+}
+
+func TestCheckAccountUsable(t *testing.T) {
+	require.NoError(t, CheckAccountUsable(Account{Status: AccountStatusActive}))
+	require.ErrorIs(t, CheckAccountUsable(Account{Status: AccountStatusFrozen}), ErrAccountFrozen)
+	require.ErrorIs(t, CheckAccountUsable(Account{Status: AccountStatusClosed}), ErrAccountClosed)
+}
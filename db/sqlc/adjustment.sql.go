@@ -0,0 +1,93 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: adjustment.sql
+
+package db
+
+import (
+	"context"
+)
+
+const createAdjustment = `-- name: CreateAdjustment :one
+INSERT INTO adjustments (
+  account_id,
+  entry_id,
+  amount,
+  reason,
+  created_by
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING id, account_id, entry_id, amount, reason, created_by, created_at
+`
+
+type CreateAdjustmentParams struct {
+	AccountID int64  `json:"account_id"`
+	EntryID   int64  `json:"entry_id"`
+	Amount    int64  `json:"amount"`
+	Reason    string `json:"reason"`
+	CreatedBy string `json:"created_by"`
+}
+
+func (q *Queries) CreateAdjustment(ctx context.Context, arg CreateAdjustmentParams) (Adjustment, error) {
+	row := q.db.QueryRow(ctx, createAdjustment,
+		arg.AccountID,
+		arg.EntryID,
+		arg.Amount,
+		arg.Reason,
+		arg.CreatedBy,
+	)
+	var i Adjustment
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.EntryID,
+		&i.Amount,
+		&i.Reason,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listAdjustments = `-- name: ListAdjustments :many
+SELECT id, account_id, entry_id, amount, reason, created_by, created_at FROM adjustments
+WHERE account_id = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3
+`
+
+type ListAdjustmentsParams struct {
+	AccountID int64 `json:"account_id"`
+	Limit     int32 `json:"limit"`
+	Offset    int32 `json:"offset"`
+}
+
+func (q *Queries) ListAdjustments(ctx context.Context, arg ListAdjustmentsParams) ([]Adjustment, error) {
+	rows, err := q.db.Query(ctx, listAdjustments, arg.AccountID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Adjustment{}
+	for rows.Next() {
+		var i Adjustment
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.EntryID,
+			&i.Amount,
+			&i.Reason,
+			&i.CreatedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,234 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Audit event actions
+const (
+	AuditActionLogin           = "user.login"
+	AuditActionUserCreate      = "user.create"
+	AuditActionUserUpdate      = "user.update"
+	AuditActionUserUpdateRole  = "user.update_role"
+	AuditActionUserDisable     = "user.disable"
+	AuditActionUserEnable      = "user.enable"
+	AuditActionPasswordReset   = "user.reset_password"
+	AuditActionSessionsBlock   = "user.block_sessions"
+	AuditActionTransferCreate  = "transfer.create"
+	AuditActionAccountFreeze   = "account.freeze"
+	AuditActionAccountUnfreeze = "account.unfreeze"
+	AuditActionAccountClose    = "account.close"
+	AuditActionBalanceAdjust   = "account.adjust_balance"
+	AuditActionPolicyImport    = "policies.import"
+)
+
+// Types of the target of an audit event
+const (
+	AuditTargetUser     = "user"
+	AuditTargetAccount  = "account"
+	AuditTargetPolicies = "policies"
+)
+
+// Outcomes of an audited action
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+	AuditOutcomeDenied  = "denied"
+)
+
+const auditEventsVerifyBatchSize = 500
+
+// genesisAuditHash is the prev_hash of the first event of a chain.
+var genesisAuditHash = make([]byte, sha256.Size)
+
+// auditEventContent is what the hash of an audit event covers: every column
+// but the ID, which is assigned on insert, and the hash itself.
+type auditEventContent struct {
+	TenantID   string          `json:"tenant_id"`
+	Actor      string          `json:"actor"`
+	ActorRole  string          `json:"actor_role"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetID   string          `json:"target_id"`
+	Outcome    string          `json:"outcome"`
+	Reason     string          `json:"reason"`
+	RequestID  string          `json:"request_id"`
+	ClientIP   string          `json:"client_ip"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	CreatedAt  string          `json:"created_at"`
+	PrevHash   string          `json:"prev_hash"`
+}
+
+// AuditEventHash returns the sha256 of the content of event, which includes
+// the hash of the previous event. Before and After are hashed in a canonical
+// form, because jsonb does not keep the formatting and key order of the JSON
+// it was given.
+func AuditEventHash(event AuditEvent) ([]byte, error) {
+	before, err := canonicalJSON(event.Before)
+	if err != nil {
+		return nil, fmt.Errorf("invalid before of audit event: %w", err)
+	}
+	after, err := canonicalJSON(event.After)
+	if err != nil {
+		return nil, fmt.Errorf("invalid after of audit event: %w", err)
+	}
+
+	content, err := json.Marshal(auditEventContent{
+		TenantID:   event.TenantID,
+		Actor:      event.Actor,
+		ActorRole:  event.ActorRole,
+		Action:     event.Action,
+		TargetType: event.TargetType,
+		TargetID:   event.TargetID,
+		Outcome:    event.Outcome,
+		Reason:     event.Reason,
+		RequestID:  event.RequestID,
+		ClientIP:   event.ClientIp,
+		Before:     before,
+		After:      after,
+		CreatedAt:  event.CreatedAt.UTC().Format(time.RFC3339Nano),
+		PrevHash:   hex.EncodeToString(event.PrevHash),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(content)
+	return sum[:], nil
+}
+
+// canonicalJSON decodes and encodes data again, which sorts the keys of its
+// objects. Empty data stands for SQL NULL.
+func canonicalJSON(data []byte) (json.RawMessage, error) {
+	if len(data) == 0 {
+		return json.RawMessage("null"), nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+// AuditChainBreak is the first audit event whose hash does not match.
+type AuditChainBreak struct {
+	ID     int64  `json:"id"`
+	Reason string `json:"reason"`
+}
+
+// AuditChain is the last verified event of the chain of a tenant.
+type AuditChain struct {
+	TenantID string `json:"tenant_id"`
+	Verified int64  `json:"verified"`
+	LastID   int64  `json:"last_id"`
+	LastHash string `json:"last_hash"`
+}
+
+// VerifyAuditChainResult is the outcome of VerifyAuditChain. Chains are
+// sorted by TenantID.
+type VerifyAuditChainResult struct {
+	Verified int64            `json:"verified"`
+	Chains   []AuditChain     `json:"chains"`
+	Break    *AuditChainBreak `json:"break,omitempty"`
+}
+
+// VerifyAuditChain reads every audit event in order and checks that it links
+// to the previous event of its chain and that its content matches its hash.
+// It stops at the first event that does not. Removing the latest events of a
+// chain cannot be detected from the chain alone, so the LastID and LastHash of
+// every chain should be kept elsewhere and compared with the next run.
+func VerifyAuditChain(ctx context.Context, q Querier) (result VerifyAuditChainResult, err error) {
+	chains := make(map[string]*AuditChain)
+	prevHashes := make(map[string][]byte) // by tenant
+	defer func() {
+		for _, chain := range chains {
+			result.Chains = append(result.Chains, *chain)
+		}
+		sort.Slice(result.Chains, func(i, j int) bool {
+			return result.Chains[i].TenantID < result.Chains[j].TenantID
+		})
+	}()
+
+	var lastID int64
+	for {
+		events, err := q.ListAuditEventsAfter(ctx, ListAuditEventsAfterParams{
+			AfterID:    lastID,
+			LimitCount: auditEventsVerifyBatchSize,
+		})
+		if err != nil {
+			return result, err
+		}
+
+		for _, event := range events {
+			chain, ok := chains[event.TenantID]
+			if !ok {
+				chain = &AuditChain{TenantID: event.TenantID}
+				chains[event.TenantID] = chain
+				prevHashes[event.TenantID] = genesisAuditHash
+			}
+
+			if !bytes.Equal(event.PrevHash, prevHashes[event.TenantID]) {
+				result.Break = &AuditChainBreak{ID: event.ID, Reason: "prev_hash does not match the hash of the previous event"}
+				return result, nil
+			}
+
+			hash, err := AuditEventHash(event)
+			if err != nil {
+				result.Break = &AuditChainBreak{ID: event.ID, Reason: err.Error()}
+				return result, nil
+			}
+			if !bytes.Equal(event.Hash, hash) {
+				result.Break = &AuditChainBreak{ID: event.ID, Reason: "content does not match the hash"}
+				return result, nil
+			}
+
+			prevHashes[event.TenantID] = event.Hash
+			chain.Verified++
+			chain.LastID = event.ID
+			chain.LastHash = hex.EncodeToString(event.Hash)
+			result.Verified++
+			lastID = event.ID
+		}
+
+		if len(events) < auditEventsVerifyBatchSize {
+			return result, nil
+		}
+	}
+}
+
+// AuditUserChanges returns the fields of a user that differ between before
+// and after, as the Before and After of an audit event. Passwords are never
+// recorded, only the time they changed.
+func AuditUserChanges(before User, after User) (map[string]any, map[string]any) {
+	changedBefore := make(map[string]any)
+	changedAfter := make(map[string]any)
+	add := func(field string, old any, new any) {
+		if old != new {
+			changedBefore[field] = old
+			changedAfter[field] = new
+		}
+	}
+
+	add("full_name", before.FullName, after.FullName)
+	add("email", before.Email, after.Email)
+	add("is_email_verified", before.IsEmailVerified, after.IsEmailVerified)
+	add("locale", before.Locale, after.Locale)
+	add("role", before.Role, after.Role)
+	add("is_disabled", before.IsDisabled, after.IsDisabled)
+	if !before.PasswordChangedAt.Equal(after.PasswordChangedAt) {
+		changedBefore["password_changed_at"] = before.PasswordChangedAt
+		changedAfter["password_changed_at"] = after.PasswordChangedAt
+	}
+	return changedBefore, changedAfter
+}
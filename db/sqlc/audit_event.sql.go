@@ -0,0 +1,260 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: audit_event.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createAuditEvent = `-- name: CreateAuditEvent :one
+INSERT INTO audit_events (
+  tenant_id,
+  actor,
+  actor_role,
+  action,
+  target_type,
+  target_id,
+  outcome,
+  reason,
+  request_id,
+  client_ip,
+  before,
+  after,
+  created_at,
+  prev_hash,
+  hash
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
+) RETURNING id, tenant_id, actor, actor_role, action, target_type, target_id, outcome, reason, request_id, client_ip, before, after, created_at, prev_hash, hash
+`
+
+type CreateAuditEventParams struct {
+	TenantID   string    `json:"tenant_id"`
+	Actor      string    `json:"actor"`
+	ActorRole  string    `json:"actor_role"`
+	Action     string    `json:"action"`
+	TargetType string    `json:"target_type"`
+	TargetID   string    `json:"target_id"`
+	Outcome    string    `json:"outcome"`
+	Reason     string    `json:"reason"`
+	RequestID  string    `json:"request_id"`
+	ClientIp   string    `json:"client_ip"`
+	Before     []byte    `json:"before"`
+	After      []byte    `json:"after"`
+	CreatedAt  time.Time `json:"created_at"`
+	PrevHash   []byte    `json:"prev_hash"`
+	Hash       []byte    `json:"hash"`
+}
+
+func (q *Queries) CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) (AuditEvent, error) {
+	row := q.db.QueryRow(ctx, createAuditEvent,
+		arg.TenantID,
+		arg.Actor,
+		arg.ActorRole,
+		arg.Action,
+		arg.TargetType,
+		arg.TargetID,
+		arg.Outcome,
+		arg.Reason,
+		arg.RequestID,
+		arg.ClientIp,
+		arg.Before,
+		arg.After,
+		arg.CreatedAt,
+		arg.PrevHash,
+		arg.Hash,
+	)
+	var i AuditEvent
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Actor,
+		&i.ActorRole,
+		&i.Action,
+		&i.TargetType,
+		&i.TargetID,
+		&i.Outcome,
+		&i.Reason,
+		&i.RequestID,
+		&i.ClientIp,
+		&i.Before,
+		&i.After,
+		&i.CreatedAt,
+		&i.PrevHash,
+		&i.Hash,
+	)
+	return i, err
+}
+
+const getLastAuditEvent = `-- name: GetLastAuditEvent :one
+SELECT id, tenant_id, actor, actor_role, action, target_type, target_id, outcome, reason, request_id, client_ip, before, after, created_at, prev_hash, hash FROM audit_events
+WHERE tenant_id = $1
+ORDER BY id DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLastAuditEvent(ctx context.Context, tenantID string) (AuditEvent, error) {
+	row := q.db.QueryRow(ctx, getLastAuditEvent, tenantID)
+	var i AuditEvent
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Actor,
+		&i.ActorRole,
+		&i.Action,
+		&i.TargetType,
+		&i.TargetID,
+		&i.Outcome,
+		&i.Reason,
+		&i.RequestID,
+		&i.ClientIp,
+		&i.Before,
+		&i.After,
+		&i.CreatedAt,
+		&i.PrevHash,
+		&i.Hash,
+	)
+	return i, err
+}
+
+const listAuditEvents = `-- name: ListAuditEvents :many
+SELECT id, tenant_id, actor, actor_role, action, target_type, target_id, outcome, reason, request_id, client_ip, before, after, created_at, prev_hash, hash FROM audit_events
+WHERE
+  tenant_id = $1
+  AND ($2::varchar IS NULL OR actor = $2)
+  AND ($3::varchar IS NULL OR action = $3)
+  AND ($4::varchar IS NULL OR target_type = $4)
+  AND ($5::varchar IS NULL OR target_id = $5)
+  AND ($6::varchar IS NULL OR outcome = $6)
+  AND ($7::timestamptz IS NULL OR created_at >= $7)
+  AND ($8::timestamptz IS NULL OR created_at < $8)
+ORDER BY id DESC
+LIMIT $10
+OFFSET $9
+`
+
+type ListAuditEventsParams struct {
+	TenantID    string             `json:"tenant_id"`
+	Actor       pgtype.Text        `json:"actor"`
+	Action      pgtype.Text        `json:"action"`
+	TargetType  pgtype.Text        `json:"target_type"`
+	TargetID    pgtype.Text        `json:"target_id"`
+	Outcome     pgtype.Text        `json:"outcome"`
+	CreatedFrom pgtype.Timestamptz `json:"created_from"`
+	CreatedTo   pgtype.Timestamptz `json:"created_to"`
+	Offset      int32              `json:"offset"`
+	Limit       int32              `json:"limit"`
+}
+
+func (q *Queries) ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) ([]AuditEvent, error) {
+	rows, err := q.db.Query(ctx, listAuditEvents,
+		arg.TenantID,
+		arg.Actor,
+		arg.Action,
+		arg.TargetType,
+		arg.TargetID,
+		arg.Outcome,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AuditEvent{}
+	for rows.Next() {
+		var i AuditEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.Actor,
+			&i.ActorRole,
+			&i.Action,
+			&i.TargetType,
+			&i.TargetID,
+			&i.Outcome,
+			&i.Reason,
+			&i.RequestID,
+			&i.ClientIp,
+			&i.Before,
+			&i.After,
+			&i.CreatedAt,
+			&i.PrevHash,
+			&i.Hash,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAuditEventsAfter = `-- name: ListAuditEventsAfter :many
+SELECT id, tenant_id, actor, actor_role, action, target_type, target_id, outcome, reason, request_id, client_ip, before, after, created_at, prev_hash, hash FROM audit_events
+WHERE id > $1
+ORDER BY id
+LIMIT $2
+`
+
+type ListAuditEventsAfterParams struct {
+	AfterID    int64 `json:"after_id"`
+	LimitCount int32 `json:"limit_count"`
+}
+
+func (q *Queries) ListAuditEventsAfter(ctx context.Context, arg ListAuditEventsAfterParams) ([]AuditEvent, error) {
+	rows, err := q.db.Query(ctx, listAuditEventsAfter, arg.AfterID, arg.LimitCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AuditEvent{}
+	for rows.Next() {
+		var i AuditEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.Actor,
+			&i.ActorRole,
+			&i.Action,
+			&i.TargetType,
+			&i.TargetID,
+			&i.Outcome,
+			&i.Reason,
+			&i.RequestID,
+			&i.ClientIp,
+			&i.Before,
+			&i.After,
+			&i.CreatedAt,
+			&i.PrevHash,
+			&i.Hash,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockAuditEvents = `-- name: LockAuditEvents :exec
+SELECT pg_advisory_xact_lock(hashtext('audit_events'), hashtext($1::text))
+`
+
+// Serializes the appends to the audit chain of a tenant until commit.
+func (q *Queries) LockAuditEvents(ctx context.Context, tenantID string) error {
+	_, err := q.db.Exec(ctx, lockAuditEvents, tenantID)
+	return err
+}
@@ -0,0 +1,186 @@
+package db
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// auditEventsQuerier serves ListAuditEventsAfter from memory.
+type auditEventsQuerier struct {
+	Querier
+	events []AuditEvent
+}
+
+func (q auditEventsQuerier) ListAuditEventsAfter(ctx context.Context, arg ListAuditEventsAfterParams) ([]AuditEvent, error) {
+	var events []AuditEvent
+	for _, event := range q.events {
+		if event.ID > arg.AfterID && len(events) < int(arg.LimitCount) {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// newAuditChain returns the chain of n events of the default tenant.
+func newAuditChain(t *testing.T, n int) []AuditEvent {
+	events := make([]AuditEvent, n)
+	prevHash := genesisAuditHash
+	for i := range events {
+		event := AuditEvent{
+			ID:         int64(i + 1),
+			TenantID:   "default",
+			Actor:      "banker",
+			ActorRole:  "banker",
+			Action:     AuditActionUserUpdateRole,
+			TargetType: AuditTargetUser,
+			TargetID:   "alice",
+			Outcome:    AuditOutcomeSuccess,
+			RequestID:  "req-42",
+			ClientIp:   "10.0.0.1",
+			Before:     []byte(`{"role":"depositor"}`),
+			After:      []byte(`{"role":"banker","tenant_id":"default"}`),
+			CreatedAt:  time.Date(2026, 10, 19, 8, 0, i, 123456000, time.UTC),
+			PrevHash:   prevHash,
+		}
+
+		hash, err := AuditEventHash(event)
+		require.NoError(t, err)
+		event.Hash = hash
+		events[i] = event
+		prevHash = hash
+	}
+	return events
+}
+
+func TestVerifyAuditChain(t *testing.T) {
+	testCases := []struct {
+		name        string
+		tamper      func(events []AuditEvent) []AuditEvent
+		verified    int64
+		brokenID    int64
+		breakReason string
+	}{
+		{
+			name:     "Intact",
+			tamper:   func(events []AuditEvent) []AuditEvent { return events },
+			verified: 3,
+		},
+		{
+			name: "ReformattedByJSONB",
+			tamper: func(events []AuditEvent) []AuditEvent {
+				// jsonb returns the keys in its own order, with spaces.
+				events[1].After = []byte(`{"tenant_id": "default", "role": "banker"}`)
+				events[1].CreatedAt = events[1].CreatedAt.In(time.FixedZone("ICT", 7*60*60))
+				return events
+			},
+			verified: 3,
+		},
+		{
+			name: "ChangedContent",
+			tamper: func(events []AuditEvent) []AuditEvent {
+				events[1].Outcome = AuditOutcomeFailure
+				return events
+			},
+			verified:    1,
+			brokenID:    2,
+			breakReason: "content does not match the hash",
+		},
+		{
+			name: "RemovedEvent",
+			tamper: func(events []AuditEvent) []AuditEvent {
+				return append(events[:1], events[2:]...)
+			},
+			verified:    1,
+			brokenID:    3,
+			breakReason: "prev_hash does not match the hash of the previous event",
+		},
+		{
+			name: "RehashedEvent",
+			tamper: func(events []AuditEvent) []AuditEvent {
+				// Rehashing a changed event breaks the link of the next one.
+				events[0].Actor = "mallory"
+				events[0].Hash, _ = AuditEventHash(events[0])
+				return events
+			},
+			verified:    1,
+			brokenID:    2,
+			breakReason: "prev_hash does not match the hash of the previous event",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			events := tc.tamper(newAuditChain(t, 3))
+
+			result, err := VerifyAuditChain(context.Background(), auditEventsQuerier{events: events})
+			require.NoError(t, err)
+			require.Equal(t, tc.verified, result.Verified)
+			if tc.brokenID == 0 {
+				require.Nil(t, result.Break)
+				require.Len(t, result.Chains, 1)
+				require.Equal(t, int64(3), result.Chains[0].LastID)
+				return
+			}
+			require.NotNil(t, result.Break)
+			require.Equal(t, tc.brokenID, result.Break.ID)
+			require.Equal(t, tc.breakReason, result.Break.Reason)
+		})
+	}
+}
+
+func TestVerifyEmptyAuditChain(t *testing.T) {
+	result, err := VerifyAuditChain(context.Background(), auditEventsQuerier{})
+	require.NoError(t, err)
+	require.Zero(t, result.Verified)
+	require.Nil(t, result.Break)
+}
+
+func TestVerifyTenantAuditChains(t *testing.T) {
+	events := newAuditChain(t, 2)
+	prevHashes := map[string][]byte{"default": events[1].Hash}
+	for i, tenantID := range []string{"default", "partner-bank", "default"} {
+		prevHash, ok := prevHashes[tenantID]
+		if !ok {
+			prevHash = genesisAuditHash
+		}
+		event := AuditEvent{
+			ID:         int64(len(events) + 1),
+			TenantID:   tenantID,
+			Actor:      "alice",
+			ActorRole:  "depositor",
+			Action:     AuditActionTransferCreate,
+			TargetType: AuditTargetAccount,
+			TargetID:   "1",
+			Outcome:    AuditOutcomeSuccess,
+			CreatedAt:  time.Date(2026, 10, 19, 9, 0, i, 0, time.UTC),
+			PrevHash:   prevHash,
+		}
+		hash, err := AuditEventHash(event)
+		require.NoError(t, err)
+		event.Hash = hash
+		events = append(events, event)
+		prevHashes[tenantID] = hash
+	}
+
+	result, err := VerifyAuditChain(context.Background(), auditEventsQuerier{events: events})
+	require.NoError(t, err)
+	require.Nil(t, result.Break)
+	require.Equal(t, int64(5), result.Verified)
+	require.Equal(t, []AuditChain{
+		{TenantID: "default", Verified: 4, LastID: 5, LastHash: hex.EncodeToString(events[4].Hash)},
+		{TenantID: "partner-bank", Verified: 1, LastID: 4, LastHash: hex.EncodeToString(events[3].Hash)},
+	}, result.Chains)
+
+	// Moving an event to another tenant breaks both chains.
+	events[3].TenantID = "default"
+	result, err = VerifyAuditChain(context.Background(), auditEventsQuerier{events: events})
+	require.NoError(t, err)
+	require.NotNil(t, result.Break)
+	require.Equal(t, int64(4), result.Break.ID)
+}
@@ -14,6 +14,21 @@ const (
 
 var ErrRecordNotFound = pgx.ErrNoRows
 
+var (
+	ErrEmailAlreadyVerified     = errors.New("email is already verified")
+	ErrVerificationRateLimited  = errors.New("verification email was requested too recently")
+	ErrPhoneAlreadyVerified     = errors.New("phone number is already verified")
+	ErrVerifyPhoneTooSoon       = errors.New("verification code was sent too recently")
+	ErrVerifyPhoneLimitReached  = errors.New("too many verification codes were requested")
+	ErrAccountFrozen            = errors.New("account is frozen")
+	ErrAccountClosed            = errors.New("account is closed")
+	ErrAccountNotEmpty          = errors.New("account balance must be zero to close it")
+	ErrInvalidAccountTransition = errors.New("cannot change account status")
+	ErrInvalidAccountReason     = errors.New("invalid reason code")
+	ErrInsufficientFunds        = errors.New("account has insufficient funds")
+	ErrNegativeBalance          = errors.New("balance cannot become negative")
+)
+
 var ErrUniqueViolation = &pgconn.PgError{
 	Code: UniqueViolation,
 }
@@ -0,0 +1,151 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: idempotency_key.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createIdempotencyKey = `-- name: CreateIdempotencyKey :one
+INSERT INTO idempotency_keys (
+  tenant_id,
+  username,
+  key,
+  request_hash
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (tenant_id, username, key) DO UPDATE
+SET
+  request_hash = EXCLUDED.request_hash,
+  response_status = NULL,
+  response_content_type = '',
+  response_body = NULL,
+  created_at = now()
+WHERE idempotency_keys.created_at < $5
+RETURNING tenant_id, username, key, request_hash, response_status, response_content_type, response_body, created_at
+`
+
+type CreateIdempotencyKeyParams struct {
+	TenantID      string    `json:"tenant_id"`
+	Username      string    `json:"username"`
+	Key           string    `json:"key"`
+	RequestHash   string    `json:"request_hash"`
+	ExpiredBefore time.Time `json:"expired_before"`
+}
+
+// Claims the key for a new request. Keys older than expired_before are claimed
+// again; a live key returns no row.
+func (q *Queries) CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error) {
+	row := q.db.QueryRow(ctx, createIdempotencyKey,
+		arg.TenantID,
+		arg.Username,
+		arg.Key,
+		arg.RequestHash,
+		arg.ExpiredBefore,
+	)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.TenantID,
+		&i.Username,
+		&i.Key,
+		&i.RequestHash,
+		&i.ResponseStatus,
+		&i.ResponseContentType,
+		&i.ResponseBody,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteExpiredIdempotencyKeys = `-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys
+WHERE created_at < $1
+`
+
+func (q *Queries) DeleteExpiredIdempotencyKeys(ctx context.Context, expiredBefore time.Time) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredIdempotencyKeys, expiredBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteIdempotencyKey = `-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE tenant_id = $1 AND username = $2 AND key = $3
+`
+
+type DeleteIdempotencyKeyParams struct {
+	TenantID string `json:"tenant_id"`
+	Username string `json:"username"`
+	Key      string `json:"key"`
+}
+
+func (q *Queries) DeleteIdempotencyKey(ctx context.Context, arg DeleteIdempotencyKeyParams) error {
+	_, err := q.db.Exec(ctx, deleteIdempotencyKey, arg.TenantID, arg.Username, arg.Key)
+	return err
+}
+
+const getIdempotencyKey = `-- name: GetIdempotencyKey :one
+SELECT tenant_id, username, key, request_hash, response_status, response_content_type, response_body, created_at FROM idempotency_keys
+WHERE tenant_id = $1 AND username = $2 AND key = $3
+`
+
+type GetIdempotencyKeyParams struct {
+	TenantID string `json:"tenant_id"`
+	Username string `json:"username"`
+	Key      string `json:"key"`
+}
+
+func (q *Queries) GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error) {
+	row := q.db.QueryRow(ctx, getIdempotencyKey, arg.TenantID, arg.Username, arg.Key)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.TenantID,
+		&i.Username,
+		&i.Key,
+		&i.RequestHash,
+		&i.ResponseStatus,
+		&i.ResponseContentType,
+		&i.ResponseBody,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const saveIdempotencyKeyResponse = `-- name: SaveIdempotencyKeyResponse :exec
+UPDATE idempotency_keys
+SET
+  response_status = $4,
+  response_content_type = $5,
+  response_body = $6
+WHERE tenant_id = $1 AND username = $2 AND key = $3
+`
+
+type SaveIdempotencyKeyResponseParams struct {
+	TenantID            string      `json:"tenant_id"`
+	Username            string      `json:"username"`
+	Key                 string      `json:"key"`
+	ResponseStatus      pgtype.Int4 `json:"response_status"`
+	ResponseContentType string      `json:"response_content_type"`
+	ResponseBody        []byte      `json:"response_body"`
+}
+
+func (q *Queries) SaveIdempotencyKeyResponse(ctx context.Context, arg SaveIdempotencyKeyResponseParams) error {
+	_, err := q.db.Exec(ctx, saveIdempotencyKeyResponse,
+		arg.TenantID,
+		arg.Username,
+		arg.Key,
+		arg.ResponseStatus,
+		arg.ResponseContentType,
+		arg.ResponseBody,
+	)
+	return err
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Account struct {
@@ -16,6 +17,59 @@ type Account struct {
 	Balance   int64     `json:"balance"`
 	Currency  string    `json:"currency"`
 	CreatedAt time.Time `json:"created_at"`
+	TenantID  string    `json:"tenant_id"`
+	// active, frozen or closed
+	Status string `json:"status"`
+	// reason code of the last status change
+	StatusReason    string    `json:"status_reason"`
+	StatusChangedAt time.Time `json:"status_changed_at"`
+}
+
+type AccountEvent struct {
+	ID        int64 `json:"id"`
+	AccountID int64 `json:"account_id"`
+	// owner of the account, who receives the event
+	Owner     string    `json:"owner"`
+	TenantID  string    `json:"tenant_id"`
+	EventType string    `json:"event_type"`
+	Payload   []byte    `json:"payload"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Adjustment struct {
+	ID        int64 `json:"id"`
+	AccountID int64 `json:"account_id"`
+	EntryID   int64 `json:"entry_id"`
+	// can be negative or positive
+	Amount int64  `json:"amount"`
+	Reason string `json:"reason"`
+	// operator who posted the adjustment
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type AuditEvent struct {
+	ID       int64  `json:"id"`
+	TenantID string `json:"tenant_id"`
+	// username of the caller, or the attempted username of a failed login
+	Actor      string `json:"actor"`
+	ActorRole  string `json:"actor_role"`
+	Action     string `json:"action"`
+	TargetType string `json:"target_type"`
+	TargetID   string `json:"target_id"`
+	// success, failure or denied
+	Outcome string `json:"outcome"`
+	// problem code of a failure
+	Reason    string    `json:"reason"`
+	RequestID string    `json:"request_id"`
+	ClientIp  string    `json:"client_ip"`
+	Before    []byte    `json:"before"`
+	After     []byte    `json:"after"`
+	CreatedAt time.Time `json:"created_at"`
+	// hash of the previous event of the tenant, 32 zero bytes for its first one
+	PrevHash []byte `json:"prev_hash"`
+	// sha256 of prev_hash and the other columns, see db.AuditEventHash
+	Hash []byte `json:"hash"`
 }
 
 type Entry struct {
@@ -26,6 +80,48 @@ type Entry struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+type IdempotencyKey struct {
+	TenantID string `json:"tenant_id"`
+	Username string `json:"username"`
+	Key      string `json:"key"`
+	// sha256 of the method, path and body of the first request
+	RequestHash string `json:"request_hash"`
+	// null while the first request is in progress
+	ResponseStatus      pgtype.Int4 `json:"response_status"`
+	ResponseContentType string      `json:"response_content_type"`
+	ResponseBody        []byte      `json:"response_body"`
+	CreatedAt           time.Time   `json:"created_at"`
+}
+
+type NotificationPreference struct {
+	Username  string `json:"username"`
+	EventType string `json:"event_type"`
+	Channel   string `json:"channel"`
+	// currency of an alert threshold, empty for other events
+	Currency  string    `json:"currency"`
+	Enabled   bool      `json:"enabled"`
+	Threshold int64     `json:"threshold"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type Outbox struct {
+	ID          int64              `json:"id"`
+	TaskType    string             `json:"task_type"`
+	Payload     []byte             `json:"payload"`
+	Queue       string             `json:"queue"`
+	MaxRetry    int32              `json:"max_retry"`
+	ProcessAt   time.Time          `json:"process_at"`
+	Attempts    int32              `json:"attempts"`
+	LastError   string             `json:"last_error"`
+	PublishedAt pgtype.Timestamptz `json:"published_at"`
+	CreatedAt   time.Time          `json:"created_at"`
+	Headers     []byte             `json:"headers"`
+	// the relay skips the row until this time after a failed publish
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	// set when the row ran out of attempts; the relay no longer publishes it
+	DeadAt pgtype.Timestamptz `json:"dead_at"`
+}
+
 type Session struct {
 	ID           uuid.UUID `json:"id"`
 	Username     string    `json:"username"`
@@ -37,6 +133,13 @@ type Session struct {
 	CreatedAt    time.Time `json:"created_at"`
 }
 
+type Tenant struct {
+	ID                        string    `json:"id"`
+	Name                      string    `json:"name"`
+	AllowCrossTenantTransfers bool      `json:"allow_cross_tenant_transfers"`
+	CreatedAt                 time.Time `json:"created_at"`
+}
+
 type Transfer struct {
 	ID            int64 `json:"id"`
 	FromAccountID int64 `json:"from_account_id"`
@@ -44,17 +147,36 @@ type Transfer struct {
 	// must be positive
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// tenant of the source account
+	TenantID string `json:"tenant_id"`
+}
+
+// notifications already sent for a transfer, skipped when the task is retried
+type TransferNotification struct {
+	TransferID int64     `json:"transfer_id"`
+	Username   string    `json:"username"`
+	Template   string    `json:"template"`
+	Channel    string    `json:"channel"`
+	SentAt     time.Time `json:"sent_at"`
 }
 
 type User struct {
-	Username          string    `json:"username"`
-	HashedPassword    string    `json:"hashed_password"`
-	FullName          string    `json:"full_name"`
-	Email             string    `json:"email"`
-	PasswordChangedAt time.Time `json:"password_changed_at"`
-	CreatedAt         time.Time `json:"created_at"`
-	IsEmailVerified   bool      `json:"is_email_verified"`
-	Role              string    `json:"role"`
+	Username                string    `json:"username"`
+	HashedPassword          string    `json:"hashed_password"`
+	FullName                string    `json:"full_name"`
+	Email                   string    `json:"email"`
+	PasswordChangedAt       time.Time `json:"password_changed_at"`
+	CreatedAt               time.Time `json:"created_at"`
+	IsEmailVerified         bool      `json:"is_email_verified"`
+	Role                    string    `json:"role"`
+	IsDisabled              bool      `json:"is_disabled"`
+	TenantID                string    `json:"tenant_id"`
+	Locale                  string    `json:"locale"`
+	VerificationRequestedAt time.Time `json:"verification_requested_at"`
+	Phone                   string    `json:"phone"`
+	IsPhoneVerified         bool      `json:"is_phone_verified"`
+	// access tokens issued before this time are rejected
+	TokensValidAfter time.Time `json:"tokens_valid_after"`
 }
 
 type VerifyEmail struct {
@@ -66,3 +188,38 @@ type VerifyEmail struct {
 	CreatedAt  time.Time `json:"created_at"`
 	ExpiredAt  time.Time `json:"expired_at"`
 }
+
+type VerifyPhone struct {
+	ID         int64     `json:"id"`
+	Username   string    `json:"username"`
+	Phone      string    `json:"phone"`
+	HashedCode string    `json:"hashed_code"`
+	Attempts   int32     `json:"attempts"`
+	IsUsed     bool      `json:"is_used"`
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiredAt  time.Time `json:"expired_at"`
+}
+
+type WebhookDelivery struct {
+	ID             int64              `json:"id"`
+	SubscriptionID int64              `json:"subscription_id"`
+	EventType      string             `json:"event_type"`
+	Payload        []byte             `json:"payload"`
+	Status         string             `json:"status"`
+	Attempts       int32              `json:"attempts"`
+	ResponseStatus int32              `json:"response_status"`
+	LastError      string             `json:"last_error"`
+	DeliveredAt    pgtype.Timestamptz `json:"delivered_at"`
+	CreatedAt      time.Time          `json:"created_at"`
+}
+
+type WebhookSubscription struct {
+	ID                  int64     `json:"id"`
+	Username            string    `json:"username"`
+	Url                 string    `json:"url"`
+	Events              []string  `json:"events"`
+	Secret              string    `json:"secret"`
+	IsDisabled          bool      `json:"is_disabled"`
+	ConsecutiveFailures int32     `json:"consecutive_failures"`
+	CreatedAt           time.Time `json:"created_at"`
+}
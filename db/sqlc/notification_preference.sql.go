@@ -0,0 +1,94 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: notification_preference.sql
+
+package db
+
+import (
+	"context"
+)
+
+const listNotificationPreferences = `-- name: ListNotificationPreferences :many
+SELECT username, event_type, channel, currency, enabled, threshold, updated_at FROM notification_preferences
+WHERE username = $1
+ORDER BY event_type, channel, currency
+`
+
+func (q *Queries) ListNotificationPreferences(ctx context.Context, username string) ([]NotificationPreference, error) {
+	rows, err := q.db.Query(ctx, listNotificationPreferences, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []NotificationPreference{}
+	for rows.Next() {
+		var i NotificationPreference
+		if err := rows.Scan(
+			&i.Username,
+			&i.EventType,
+			&i.Channel,
+			&i.Currency,
+			&i.Enabled,
+			&i.Threshold,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertNotificationPreference = `-- name: UpsertNotificationPreference :one
+INSERT INTO notification_preferences (
+    username,
+    event_type,
+    channel,
+    currency,
+    enabled,
+    threshold
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (username, event_type, channel, currency) DO UPDATE
+SET
+    enabled = EXCLUDED.enabled,
+    threshold = EXCLUDED.threshold,
+    updated_at = now()
+RETURNING username, event_type, channel, currency, enabled, threshold, updated_at
+`
+
+type UpsertNotificationPreferenceParams struct {
+	Username  string `json:"username"`
+	EventType string `json:"event_type"`
+	Channel   string `json:"channel"`
+	Currency  string `json:"currency"`
+	Enabled   bool   `json:"enabled"`
+	Threshold int64  `json:"threshold"`
+}
+
+func (q *Queries) UpsertNotificationPreference(ctx context.Context, arg UpsertNotificationPreferenceParams) (NotificationPreference, error) {
+	row := q.db.QueryRow(ctx, upsertNotificationPreference,
+		arg.Username,
+		arg.EventType,
+		arg.Channel,
+		arg.Currency,
+		arg.Enabled,
+		arg.Threshold,
+	)
+	var i NotificationPreference
+	err := row.Scan(
+		&i.Username,
+		&i.EventType,
+		&i.Channel,
+		&i.Currency,
+		&i.Enabled,
+		&i.Threshold,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
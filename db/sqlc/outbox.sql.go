@@ -0,0 +1,175 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: outbox.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const createOutbox = `-- name: CreateOutbox :one
+INSERT INTO outbox (
+    task_type,
+    payload,
+    queue,
+    max_retry,
+    process_at,
+    headers
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING id, task_type, payload, queue, max_retry, process_at, attempts, last_error, published_at, created_at, headers, next_attempt_at, dead_at
+`
+
+type CreateOutboxParams struct {
+	TaskType  string    `json:"task_type"`
+	Payload   []byte    `json:"payload"`
+	Queue     string    `json:"queue"`
+	MaxRetry  int32     `json:"max_retry"`
+	ProcessAt time.Time `json:"process_at"`
+	Headers   []byte    `json:"headers"`
+}
+
+func (q *Queries) CreateOutbox(ctx context.Context, arg CreateOutboxParams) (Outbox, error) {
+	row := q.db.QueryRow(ctx, createOutbox,
+		arg.TaskType,
+		arg.Payload,
+		arg.Queue,
+		arg.MaxRetry,
+		arg.ProcessAt,
+		arg.Headers,
+	)
+	var i Outbox
+	err := row.Scan(
+		&i.ID,
+		&i.TaskType,
+		&i.Payload,
+		&i.Queue,
+		&i.MaxRetry,
+		&i.ProcessAt,
+		&i.Attempts,
+		&i.LastError,
+		&i.PublishedAt,
+		&i.CreatedAt,
+		&i.Headers,
+		&i.NextAttemptAt,
+		&i.DeadAt,
+	)
+	return i, err
+}
+
+const deleteOutboxPublishedBefore = `-- name: DeleteOutboxPublishedBefore :execrows
+DELETE FROM outbox
+WHERE published_at < $1::timestamptz
+`
+
+func (q *Queries) DeleteOutboxPublishedBefore(ctx context.Context, publishedBefore time.Time) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteOutboxPublishedBefore, publishedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const listPendingOutbox = `-- name: ListPendingOutbox :many
+SELECT id, task_type, payload, queue, max_retry, process_at, attempts, last_error, published_at, created_at, headers, next_attempt_at, dead_at FROM outbox
+WHERE published_at IS NULL
+  AND dead_at IS NULL
+  AND next_attempt_at <= now()
+ORDER BY id
+LIMIT $1
+FOR UPDATE SKIP LOCKED
+`
+
+func (q *Queries) ListPendingOutbox(ctx context.Context, limit int32) ([]Outbox, error) {
+	rows, err := q.db.Query(ctx, listPendingOutbox, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Outbox{}
+	for rows.Next() {
+		var i Outbox
+		if err := rows.Scan(
+			&i.ID,
+			&i.TaskType,
+			&i.Payload,
+			&i.Queue,
+			&i.MaxRetry,
+			&i.ProcessAt,
+			&i.Attempts,
+			&i.LastError,
+			&i.PublishedAt,
+			&i.CreatedAt,
+			&i.Headers,
+			&i.NextAttemptAt,
+			&i.DeadAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markOutboxFailed = `-- name: MarkOutboxFailed :one
+UPDATE outbox
+SET
+    attempts = attempts + 1,
+    last_error = $1,
+    next_attempt_at = $2,
+    dead_at = CASE WHEN attempts + 1 >= $3::int THEN now() END
+WHERE id = $4
+RETURNING id, task_type, payload, queue, max_retry, process_at, attempts, last_error, published_at, created_at, headers, next_attempt_at, dead_at
+`
+
+type MarkOutboxFailedParams struct {
+	LastError     string    `json:"last_error"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	MaxAttempts   int32     `json:"max_attempts"`
+	ID            int64     `json:"id"`
+}
+
+func (q *Queries) MarkOutboxFailed(ctx context.Context, arg MarkOutboxFailedParams) (Outbox, error) {
+	row := q.db.QueryRow(ctx, markOutboxFailed,
+		arg.LastError,
+		arg.NextAttemptAt,
+		arg.MaxAttempts,
+		arg.ID,
+	)
+	var i Outbox
+	err := row.Scan(
+		&i.ID,
+		&i.TaskType,
+		&i.Payload,
+		&i.Queue,
+		&i.MaxRetry,
+		&i.ProcessAt,
+		&i.Attempts,
+		&i.LastError,
+		&i.PublishedAt,
+		&i.CreatedAt,
+		&i.Headers,
+		&i.NextAttemptAt,
+		&i.DeadAt,
+	)
+	return i, err
+}
+
+const markOutboxPublished = `-- name: MarkOutboxPublished :exec
+UPDATE outbox
+SET
+    published_at = now(),
+    attempts = attempts + 1
+WHERE id = $1
+`
+
+func (q *Queries) MarkOutboxPublished(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, markOutboxPublished, id)
+	return err
+}
@@ -6,31 +6,103 @@ package db
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type Querier interface {
+	// Only called inside store transactions whose callers have checked the tenant
+	// of the account. The recipient of a transfer may belong to another tenant.
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	BlockUserSessions(ctx context.Context, username string) error
+	CountVerifyPhonesSince(ctx context.Context, arg CountVerifyPhonesSinceParams) (int64, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateAccountEvent(ctx context.Context, arg CreateAccountEventParams) (AccountEvent, error)
+	CreateAdjustment(ctx context.Context, arg CreateAdjustmentParams) (Adjustment, error)
+	CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) (AuditEvent, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	// Claims the key for a new request. Keys older than expired_before are claimed
+	// again; a live key returns no row.
+	CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error)
+	CreateOutbox(ctx context.Context, arg CreateOutboxParams) (Outbox, error)
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
+	CreateTenant(ctx context.Context, arg CreateTenantParams) (Tenant, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
+	CreateTransferNotification(ctx context.Context, arg CreateTransferNotificationParams) error
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
 	CreateVerifyEmail(ctx context.Context, arg CreateVerifyEmailParams) (VerifyEmail, error)
-	DeleteAccount(ctx context.Context, id int64) error
-	GetAccount(ctx context.Context, id int64) (Account, error)
-	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	CreateVerifyPhone(ctx context.Context, arg CreateVerifyPhoneParams) (VerifyPhone, error)
+	CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) (WebhookDelivery, error)
+	CreateWebhookSubscription(ctx context.Context, arg CreateWebhookSubscriptionParams) (WebhookSubscription, error)
+	DeleteExpiredIdempotencyKeys(ctx context.Context, expiredBefore time.Time) (int64, error)
+	DeleteExpiredSessions(ctx context.Context, expiredBefore time.Time) (int64, error)
+	DeleteExpiredVerifyEmails(ctx context.Context, expiredBefore time.Time) (int64, error)
+	DeleteExpiredVerifyPhones(ctx context.Context, expiredBefore time.Time) (int64, error)
+	DeleteIdempotencyKey(ctx context.Context, arg DeleteIdempotencyKeyParams) error
+	DeleteOutboxPublishedBefore(ctx context.Context, publishedBefore time.Time) (int64, error)
+	DeleteTransferNotificationsSentBefore(ctx context.Context, sentBefore time.Time) (int64, error)
+	DeleteWebhookSubscription(ctx context.Context, id int64) error
+	GetAccount(ctx context.Context, arg GetAccountParams) (Account, error)
+	GetAccountByID(ctx context.Context, id int64) (Account, error)
+	GetAccountByIDForUpdate(ctx context.Context, id int64) (Account, error)
+	GetAccountForUpdate(ctx context.Context, arg GetAccountForUpdateParams) (Account, error)
+	GetAccountTenant(ctx context.Context, id int64) (string, error)
+	GetActiveVerifyPhoneForUpdate(ctx context.Context, arg GetActiveVerifyPhoneForUpdateParams) (VerifyPhone, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
+	GetLastAuditEvent(ctx context.Context, tenantID string) (AuditEvent, error)
 	GetSession(ctx context.Context, id uuid.UUID) (Session, error)
-	GetTransfer(ctx context.Context, id int64) (Transfer, error)
+	GetTenant(ctx context.Context, id string) (Tenant, error)
+	GetTransfer(ctx context.Context, arg GetTransferParams) (Transfer, error)
+	// Usernames are unique across tenants, so this is not filtered by tenant.
+	// Callers look up the authenticated user or check user.tenant_id themselves.
 	GetUser(ctx context.Context, username string) (User, error)
+	GetUserForUpdate(ctx context.Context, username string) (User, error)
+	GetUserTokensValidAfter(ctx context.Context, username string) (time.Time, error)
+	GetWebhookDelivery(ctx context.Context, id int64) (WebhookDelivery, error)
+	GetWebhookSubscription(ctx context.Context, id int64) (WebhookSubscription, error)
+	IncrementVerifyPhoneAttempts(ctx context.Context, id int64) (VerifyPhone, error)
+	ListAccountEventsAfter(ctx context.Context, arg ListAccountEventsAfterParams) ([]AccountEvent, error)
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	ListAdjustments(ctx context.Context, arg ListAdjustmentsParams) ([]Adjustment, error)
+	ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) ([]AuditEvent, error)
+	ListAuditEventsAfter(ctx context.Context, arg ListAuditEventsAfterParams) ([]AuditEvent, error)
+	ListBalanceMismatches(ctx context.Context) ([]ListBalanceMismatchesRow, error)
 	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	ListNotificationPreferences(ctx context.Context, username string) ([]NotificationPreference, error)
+	ListPendingOutbox(ctx context.Context, limit int32) ([]Outbox, error)
+	ListSentTransferNotifications(ctx context.Context, arg ListSentTransferNotificationsParams) ([]TransferNotification, error)
 	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
+	ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error)
+	ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	ListWebhookSubscriptions(ctx context.Context, username string) ([]WebhookSubscription, error)
+	ListWebhookSubscriptionsForEvent(ctx context.Context, arg ListWebhookSubscriptionsForEventParams) ([]WebhookSubscription, error)
+	// Serializes the events of an owner until commit, so that their IDs follow
+	// the order in which they commit.
+	LockAccountEvents(ctx context.Context, arg LockAccountEventsParams) error
+	// Serializes the appends to the audit chain of a tenant until commit.
+	LockAuditEvents(ctx context.Context, tenantID string) error
+	MarkOutboxFailed(ctx context.Context, arg MarkOutboxFailedParams) (Outbox, error)
+	MarkOutboxPublished(ctx context.Context, id int64) error
+	MarkVerificationRequested(ctx context.Context, arg MarkVerificationRequestedParams) (User, error)
+	MarkVerifyPhoneUsed(ctx context.Context, id int64) (VerifyPhone, error)
+	MarkWebhookDeliveryFailed(ctx context.Context, arg MarkWebhookDeliveryFailedParams) (WebhookDelivery, error)
+	MarkWebhookDeliverySucceeded(ctx context.Context, arg MarkWebhookDeliverySucceededParams) (WebhookDelivery, error)
+	NotifyAccountEvent(ctx context.Context, arg NotifyAccountEventParams) error
+	RecordWebhookFailure(ctx context.Context, arg RecordWebhookFailureParams) (WebhookSubscription, error)
+	RecordWebhookSuccess(ctx context.Context, id int64) error
+	RevokeUserTokens(ctx context.Context, arg RevokeUserTokensParams) error
+	RevokeVerifyEmails(ctx context.Context, username string) error
+	RevokeVerifyPhones(ctx context.Context, username string) error
+	SaveIdempotencyKeyResponse(ctx context.Context, arg SaveIdempotencyKeyResponseParams) error
 	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
+	UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error)
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
+	UpdateUserPhone(ctx context.Context, arg UpdateUserPhoneParams) (User, error)
 	UpdateVerifyEmail(ctx context.Context, arg UpdateVerifyEmailParams) (VerifyEmail, error)
+	UpdateWebhookSubscription(ctx context.Context, arg UpdateWebhookSubscriptionParams) (WebhookSubscription, error)
+	UpsertNotificationPreference(ctx context.Context, arg UpsertNotificationPreferenceParams) (NotificationPreference, error)
 }
 
 var _ Querier = (*Queries)(nil)
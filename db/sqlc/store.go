@@ -12,6 +12,20 @@ type Store interface {
 	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
 	CreateUserTx(ctx context.Context, arg CreateUserTxParams) (CreateUserTxResult, error)
 	VerifyEmailTx(ctx context.Context, arg VerifyEmailTxParams) (VerifyEmailTxResult, error)
+	UpdateUserAccessTx(ctx context.Context, arg UpdateUserAccessTxParams) (UpdateUserAccessTxResult, error)
+	CreateTenantTx(ctx context.Context, arg CreateTenantTxParams) (CreateTenantTxResult, error)
+	RelayOutboxTx(ctx context.Context, arg RelayOutboxTxParams) (RelayOutboxTxResult, error)
+	ResendVerifyEmailTx(ctx context.Context, arg ResendVerifyEmailTxParams) (ResendVerifyEmailTxResult, error)
+	UpdateUserTx(ctx context.Context, arg UpdateUserTxParams) (UpdateUserTxResult, error)
+	CreateAccountTx(ctx context.Context, arg CreateAccountTxParams) (CreateAccountTxResult, error)
+	ReplayWebhookDeliveryTx(ctx context.Context, arg ReplayWebhookDeliveryTxParams) (ReplayWebhookDeliveryTxResult, error)
+	UpdateUserPhoneTx(ctx context.Context, arg UpdateUserPhoneTxParams) (UpdateUserPhoneTxResult, error)
+	VerifyPhoneTx(ctx context.Context, arg VerifyPhoneTxParams) (VerifyPhoneTxResult, error)
+	AdjustBalanceTx(ctx context.Context, arg AdjustBalanceTxParams) (AdjustBalanceTxResult, error)
+	ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (ResetPasswordTxResult, error)
+	AppendAuditEventTx(ctx context.Context, arg AppendAuditEventTxParams) (AuditEvent, error)
+	ChangeAccountStatusTx(ctx context.Context, arg ChangeAccountStatusTxParams) (ChangeAccountStatusTxResult, error)
+	BlockUserSessionsTx(ctx context.Context, arg BlockUserSessionsTxParams) (BlockUserSessionsTxResult, error)
 }
 
 // SQLStore provides all functions to execute SQL queries and transactions
@@ -0,0 +1,55 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: tenant.sql
+
+package db
+
+import (
+	"context"
+)
+
+const createTenant = `-- name: CreateTenant :one
+INSERT INTO tenants (
+  id,
+  name,
+  allow_cross_tenant_transfers
+) VALUES (
+  $1, $2, $3
+) RETURNING id, name, allow_cross_tenant_transfers, created_at
+`
+
+type CreateTenantParams struct {
+	ID                        string `json:"id"`
+	Name                      string `json:"name"`
+	AllowCrossTenantTransfers bool   `json:"allow_cross_tenant_transfers"`
+}
+
+func (q *Queries) CreateTenant(ctx context.Context, arg CreateTenantParams) (Tenant, error) {
+	row := q.db.QueryRow(ctx, createTenant, arg.ID, arg.Name, arg.AllowCrossTenantTransfers)
+	var i Tenant
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.AllowCrossTenantTransfers,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getTenant = `-- name: GetTenant :one
+SELECT id, name, allow_cross_tenant_transfers, created_at FROM tenants
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetTenant(ctx context.Context, id string) (Tenant, error) {
+	row := q.db.QueryRow(ctx, getTenant, id)
+	var i Tenant
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.AllowCrossTenantTransfers,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,88 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: transfer_notification.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const createTransferNotification = `-- name: CreateTransferNotification :exec
+INSERT INTO transfer_notifications (
+    transfer_id,
+    username,
+    template,
+    channel
+) VALUES (
+    $1, $2, $3, $4
+) ON CONFLICT DO NOTHING
+`
+
+type CreateTransferNotificationParams struct {
+	TransferID int64  `json:"transfer_id"`
+	Username   string `json:"username"`
+	Template   string `json:"template"`
+	Channel    string `json:"channel"`
+}
+
+func (q *Queries) CreateTransferNotification(ctx context.Context, arg CreateTransferNotificationParams) error {
+	_, err := q.db.Exec(ctx, createTransferNotification,
+		arg.TransferID,
+		arg.Username,
+		arg.Template,
+		arg.Channel,
+	)
+	return err
+}
+
+const deleteTransferNotificationsSentBefore = `-- name: DeleteTransferNotificationsSentBefore :execrows
+DELETE FROM transfer_notifications
+WHERE sent_at < $1
+`
+
+func (q *Queries) DeleteTransferNotificationsSentBefore(ctx context.Context, sentBefore time.Time) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteTransferNotificationsSentBefore, sentBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const listSentTransferNotifications = `-- name: ListSentTransferNotifications :many
+SELECT transfer_id, username, template, channel, sent_at FROM transfer_notifications
+WHERE transfer_id = $1 AND username = $2
+`
+
+type ListSentTransferNotificationsParams struct {
+	TransferID int64  `json:"transfer_id"`
+	Username   string `json:"username"`
+}
+
+func (q *Queries) ListSentTransferNotifications(ctx context.Context, arg ListSentTransferNotificationsParams) ([]TransferNotification, error) {
+	rows, err := q.db.Query(ctx, listSentTransferNotifications, arg.TransferID, arg.Username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []TransferNotification{}
+	for rows.Next() {
+		var i TransferNotification
+		if err := rows.Scan(
+			&i.TransferID,
+			&i.Username,
+			&i.Template,
+			&i.Channel,
+			&i.SentAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,84 @@
+package db
+
+import "context"
+
+// AdjustBalanceTxParams contains the input parameters of a manual balance adjustment.
+type AdjustBalanceTxParams struct {
+	AccountID int64
+	Amount    int64
+	// Reason and CreatedBy are kept with the adjustment for auditing.
+	Reason    string
+	CreatedBy string
+	// AfterAdjust, when set, runs inside the transaction once the balance is updated.
+	AfterAdjust func(q Querier, result AdjustBalanceTxResult) error
+}
+
+type AdjustBalanceTxResult struct {
+	Adjustment Adjustment `json:"adjustment"`
+	Account    Account    `json:"account"`
+	Entry      Entry      `json:"entry"`
+}
+
+// AdjustBalanceTx credits or debits an account outside of a transfer. Like a
+// transfer, it records an entry so the balance still reconciles with the entries.
+// Frozen accounts can be adjusted, closed ones cannot, and a negative resulting
+// balance is rejected.
+func (store *SQLStore) AdjustBalanceTx(ctx context.Context, arg AdjustBalanceTxParams) (AdjustBalanceTxResult, error) {
+	var result AdjustBalanceTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.Entry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID: arg.AccountID,
+			Amount:    arg.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Account, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+			ID:     arg.AccountID,
+			Amount: arg.Amount,
+		})
+		if err != nil {
+			return err
+		}
+		if result.Account.Status == AccountStatusClosed {
+			return ErrAccountClosed
+		}
+		if result.Account.Balance < 0 {
+			return ErrNegativeBalance
+		}
+
+		result.Adjustment, err = q.CreateAdjustment(ctx, CreateAdjustmentParams{
+			AccountID: arg.AccountID,
+			EntryID:   result.Entry.ID,
+			Amount:    arg.Amount,
+			Reason:    arg.Reason,
+			CreatedBy: arg.CreatedBy,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = recordAccountEvent(ctx, q, result.Account, AccountEventBalanceAdjusted, AccountEventPayload{
+			AccountID:    result.Account.ID,
+			Amount:       result.Entry.Amount,
+			Balance:      result.Account.Balance,
+			Currency:     result.Account.Currency,
+			EntryID:      result.Entry.ID,
+			AdjustmentID: result.Adjustment.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		if arg.AfterAdjust != nil {
+			return arg.AfterAdjust(q, result)
+		}
+		return nil
+	})
+
+	return result, err
+}
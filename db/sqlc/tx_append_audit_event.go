@@ -0,0 +1,122 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// AppendAuditEventTxParams contains the input parameters of an audit event.
+// Before and After, when set, are stored as JSON and should only hold the
+// fields the action changed.
+type AppendAuditEventTxParams struct {
+	TenantID   string `json:"tenant_id"`
+	Actor      string `json:"actor"`
+	ActorRole  string `json:"actor_role"`
+	Action     string `json:"action"`
+	TargetType string `json:"target_type"`
+	TargetID   string `json:"target_id"`
+	Outcome    string `json:"outcome"`
+	Reason     string `json:"reason"`
+	RequestID  string `json:"request_id"`
+	ClientIP   string `json:"client_ip"`
+	Before     any    `json:"before"`
+	After      any    `json:"after"`
+}
+
+// AppendAuditEventTx appends an event in a transaction of its own. It is
+// meant for events that change nothing, such as failed or denied requests;
+// changes append their event with AppendAuditEvent in their own transaction.
+func (store *SQLStore) AppendAuditEventTx(ctx context.Context, arg AppendAuditEventTxParams) (AuditEvent, error) {
+	var result AuditEvent
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result, err = AppendAuditEvent(ctx, q, arg)
+		return err
+	})
+
+	return result, err
+}
+
+// AppendAuditEvent adds an event at the end of the audit chain of its tenant
+// within the transaction of q, so that the event is committed together with
+// the change it records, or not at all. Appends to a chain are serialized by
+// an advisory lock held until commit, so that every event is chained to the
+// one committed right before it; call it last in the transaction.
+func AppendAuditEvent(ctx context.Context, q Querier, arg AppendAuditEventTxParams) (AuditEvent, error) {
+	before, err := auditEventJSON(arg.Before)
+	if err != nil {
+		return AuditEvent{}, err
+	}
+	after, err := auditEventJSON(arg.After)
+	if err != nil {
+		return AuditEvent{}, err
+	}
+
+	if err := q.LockAuditEvents(ctx, arg.TenantID); err != nil {
+		return AuditEvent{}, err
+	}
+
+	prevHash := genesisAuditHash
+	last, err := q.GetLastAuditEvent(ctx, arg.TenantID)
+	switch {
+	case err == nil:
+		prevHash = last.Hash
+	case !errors.Is(err, ErrRecordNotFound):
+		return AuditEvent{}, err
+	}
+
+	event := AuditEvent{
+		TenantID:   arg.TenantID,
+		Actor:      arg.Actor,
+		ActorRole:  arg.ActorRole,
+		Action:     arg.Action,
+		TargetType: arg.TargetType,
+		TargetID:   arg.TargetID,
+		Outcome:    arg.Outcome,
+		Reason:     arg.Reason,
+		RequestID:  arg.RequestID,
+		ClientIp:   arg.ClientIP,
+		Before:     before,
+		After:      after,
+		// Postgres keeps microseconds, and the hash must match what is read back.
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+		PrevHash:  prevHash,
+	}
+	hash, err := AuditEventHash(event)
+	if err != nil {
+		return AuditEvent{}, err
+	}
+
+	return q.CreateAuditEvent(ctx, CreateAuditEventParams{
+		TenantID:   event.TenantID,
+		Actor:      event.Actor,
+		ActorRole:  event.ActorRole,
+		Action:     event.Action,
+		TargetType: event.TargetType,
+		TargetID:   event.TargetID,
+		Outcome:    event.Outcome,
+		Reason:     event.Reason,
+		RequestID:  event.RequestID,
+		ClientIp:   event.ClientIp,
+		Before:     event.Before,
+		After:      event.After,
+		CreatedAt:  event.CreatedAt,
+		PrevHash:   event.PrevHash,
+		Hash:       hash,
+	})
+}
+
+// auditEventJSON encodes the before or after of an event, nil for none.
+func auditEventJSON(value any) ([]byte, error) {
+	if value == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal audit event: %w", err)
+	}
+	return data, nil
+}
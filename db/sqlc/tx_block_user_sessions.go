@@ -0,0 +1,40 @@
+package db
+
+import "context"
+
+type BlockUserSessionsTxParams struct {
+	Username string
+	// AfterBlock, when set, runs inside the transaction once the sessions are blocked.
+	AfterBlock func(q Querier, user User) error
+}
+
+type BlockUserSessionsTxResult struct {
+	User User
+}
+
+// BlockUserSessionsTx blocks every session of a user, so their refresh tokens
+// can no longer be renewed.
+func (store *SQLStore) BlockUserSessionsTx(ctx context.Context, arg BlockUserSessionsTxParams) (BlockUserSessionsTxResult, error) {
+	var result BlockUserSessionsTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.User, err = q.GetUser(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+
+		err = q.BlockUserSessions(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+
+		if arg.AfterBlock != nil {
+			return arg.AfterBlock(q, result.User)
+		}
+		return nil
+	})
+
+	return result, err
+}
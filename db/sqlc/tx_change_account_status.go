@@ -0,0 +1,75 @@
+package db
+
+import "context"
+
+// ChangeAccountStatusTxParams contains the input parameters of an account status change
+type ChangeAccountStatusTxParams struct {
+	AccountID int64  `json:"account_id"`
+	Status    string `json:"status"`
+	Reason    string `json:"reason"`
+	// BeforeChange, when set, runs on the locked account before the change is
+	// checked, for example to verify that the caller may change it.
+	BeforeChange func(account Account) error `json:"-"`
+	// AfterChange, when set, runs inside the transaction once the status is changed.
+	AfterChange func(q Querier, result ChangeAccountStatusTxResult) error `json:"-"`
+}
+
+// ChangeAccountStatusTxResult is the result of an account status change
+type ChangeAccountStatusTxResult struct {
+	Account Account `json:"account"`
+	Before  Account `json:"-"` // the account as it was before the change
+}
+
+// ChangeAccountStatusTx moves an account to another status if the transition
+// and its reason are allowed. The account row stays locked until commit, so a
+// transfer either completes before the change or sees the new status, and a
+// closed account is known to have been empty.
+func (store *SQLStore) ChangeAccountStatusTx(ctx context.Context, arg ChangeAccountStatusTxParams) (ChangeAccountStatusTxResult, error) {
+	var result ChangeAccountStatusTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.Before, err = q.GetAccountByIDForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		if arg.BeforeChange != nil {
+			if err := arg.BeforeChange(result.Before); err != nil {
+				return err
+			}
+		}
+
+		if err := CheckAccountTransition(result.Before, arg.Status, arg.Reason); err != nil {
+			return err
+		}
+
+		result.Account, err = q.UpdateAccountStatus(ctx, UpdateAccountStatusParams{
+			ID:           arg.AccountID,
+			Status:       arg.Status,
+			StatusReason: arg.Reason,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = recordAccountEvent(ctx, q, result.Account, AccountEventStatusChanged, AccountEventPayload{
+			AccountID: result.Account.ID,
+			Balance:   result.Account.Balance,
+			Currency:  result.Account.Currency,
+			Status:    result.Account.Status,
+			Reason:    result.Account.StatusReason,
+		})
+		if err != nil {
+			return err
+		}
+
+		if arg.AfterChange != nil {
+			return arg.AfterChange(q, result)
+		}
+		return nil
+	})
+
+	return result, err
+}
@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type CreateTenantTxParams struct {
+	CreateTenantParams
+	// Admin is the first user of the tenant. It is created with AdminRole.
+	Admin     CreateUserParams
+	AdminRole string
+}
+
+type CreateTenantTxResult struct {
+	Tenant Tenant
+	Admin  User
+}
+
+// CreateTenantTx provisions a new tenant together with its first administrator.
+func (store *SQLStore) CreateTenantTx(ctx context.Context, arg CreateTenantTxParams) (CreateTenantTxResult, error) {
+	var result CreateTenantTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.Tenant, err = q.CreateTenant(ctx, arg.CreateTenantParams)
+		if err != nil {
+			return err
+		}
+
+		admin := arg.Admin
+		admin.TenantID = result.Tenant.ID
+		if _, err = q.CreateUser(ctx, admin); err != nil {
+			return err
+		}
+
+		result.Admin, err = q.UpdateUser(ctx, UpdateUserParams{
+			Username: admin.Username,
+			Role: pgtype.Text{
+				String: arg.AdminRole,
+				Valid:  true,
+			},
+		})
+		return err
+	})
+
+	return result, err
+}
@@ -4,7 +4,7 @@ import "context"
 
 type CreateUserTxParams struct {
 	CreateUserParams
-	AfterCreate func(user User) error
+	AfterCreate func(q Querier, user User) error
 }
 
 type CreateUserTxResult struct {
@@ -22,7 +22,7 @@ func (store *SQLStore) CreateUserTx(ctx context.Context, arg CreateUserTxParams)
 			return err
 		}
 
-		return arg.AfterCreate(result.User)
+		return arg.AfterCreate(q, result.User)
 	})
 
 	return result, err
@@ -0,0 +1,65 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+type RelayOutboxTxParams struct {
+	Limit int32
+	// MaxAttempts is the number of failed publishes after which a row is dead.
+	MaxAttempts int32
+	// RetryDelay returns how long to wait before publishing a row again after
+	// its attempts-th failure, counting from 1.
+	RetryDelay func(attempts int32) time.Duration
+	Publish    func(msg Outbox) error
+}
+
+type RelayOutboxTxResult struct {
+	Published int
+	Failed    int
+	// Dead is the number of failed rows that ran out of attempts.
+	Dead int
+}
+
+// RelayOutboxTx locks a batch of due outbox rows and hands them to Publish in order.
+// Rows are marked published only after Publish succeeds, so a crash between publishing
+// and commit re-delivers them on the next run. A row that fails is skipped until
+// RetryDelay has passed, and is marked dead after MaxAttempts failures.
+func (store *SQLStore) RelayOutboxTx(ctx context.Context, arg RelayOutboxTxParams) (RelayOutboxTxResult, error) {
+	var result RelayOutboxTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		msgs, err := q.ListPendingOutbox(ctx, arg.Limit)
+		if err != nil {
+			return err
+		}
+
+		for _, msg := range msgs {
+			if pubErr := arg.Publish(msg); pubErr != nil {
+				failed, err := q.MarkOutboxFailed(ctx, MarkOutboxFailedParams{
+					ID:            msg.ID,
+					LastError:     pubErr.Error(),
+					NextAttemptAt: time.Now().Add(arg.RetryDelay(msg.Attempts + 1)),
+					MaxAttempts:   arg.MaxAttempts,
+				})
+				if err != nil {
+					return err
+				}
+				result.Failed++
+				if failed.DeadAt.Valid {
+					result.Dead++
+				}
+				continue
+			}
+
+			if err := q.MarkOutboxPublished(ctx, msg.ID); err != nil {
+				return err
+			}
+			result.Published++
+		}
+		return nil
+	})
+
+	return result, err
+}
@@ -0,0 +1,43 @@
+package db
+
+import "context"
+
+type ReplayWebhookDeliveryTxParams struct {
+	SubscriptionID int64
+	DeliveryID     int64
+	// AfterCreate runs inside the transaction with the copy of the delivery.
+	AfterCreate func(q Querier, delivery WebhookDelivery) error
+}
+
+type ReplayWebhookDeliveryTxResult struct {
+	Delivery WebhookDelivery
+}
+
+// ReplayWebhookDeliveryTx records a new delivery of the same event as an
+// earlier delivery of the subscription, leaving the original in the log.
+func (store *SQLStore) ReplayWebhookDeliveryTx(ctx context.Context, arg ReplayWebhookDeliveryTxParams) (ReplayWebhookDeliveryTxResult, error) {
+	var result ReplayWebhookDeliveryTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		original, err := q.GetWebhookDelivery(ctx, arg.DeliveryID)
+		if err != nil {
+			return err
+		}
+		if original.SubscriptionID != arg.SubscriptionID {
+			return ErrRecordNotFound
+		}
+
+		result.Delivery, err = q.CreateWebhookDelivery(ctx, CreateWebhookDeliveryParams{
+			SubscriptionID: original.SubscriptionID,
+			EventType:      original.EventType,
+			Payload:        original.Payload,
+		})
+		if err != nil {
+			return err
+		}
+
+		return arg.AfterCreate(q, result.Delivery)
+	})
+
+	return result, err
+}
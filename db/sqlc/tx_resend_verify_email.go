@@ -0,0 +1,55 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+type ResendVerifyEmailTxParams struct {
+	Username string
+	// RequestedBefore rate limits resends: the previous request must be older than this.
+	RequestedBefore time.Time
+	AfterRequest    func(q Querier, user User) error
+}
+
+type ResendVerifyEmailTxResult struct {
+	User User
+}
+
+// ResendVerifyEmailTx revokes the unused verification codes of the user and lets
+// AfterRequest schedule a new verification email.
+func (store *SQLStore) ResendVerifyEmailTx(ctx context.Context, arg ResendVerifyEmailTxParams) (ResendVerifyEmailTxResult, error) {
+	var result ResendVerifyEmailTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.User, err = q.GetUser(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+		if result.User.IsEmailVerified {
+			return ErrEmailAlreadyVerified
+		}
+
+		result.User, err = q.MarkVerificationRequested(ctx, MarkVerificationRequestedParams{
+			Username:        arg.Username,
+			RequestedBefore: arg.RequestedBefore,
+		})
+		if errors.Is(err, ErrRecordNotFound) {
+			return ErrVerificationRateLimited
+		}
+		if err != nil {
+			return err
+		}
+
+		if err = q.RevokeVerifyEmails(ctx, arg.Username); err != nil {
+			return err
+		}
+
+		return arg.AfterRequest(q, result.User)
+	})
+
+	return result, err
+}
@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type ResetPasswordTxParams struct {
+	Username       string
+	HashedPassword string
+	// AfterReset, when set, runs inside the transaction once the sessions are blocked.
+	AfterReset func(q Querier, result ResetPasswordTxResult) error
+}
+
+type ResetPasswordTxResult struct {
+	User User
+}
+
+// ResetPasswordTx replaces a user's password and blocks all of the user's
+// sessions, so refresh tokens issued before the reset can no longer be renewed.
+func (store *SQLStore) ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (ResetPasswordTxResult, error) {
+	var result ResetPasswordTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.User, err = q.UpdateUser(ctx, UpdateUserParams{
+			Username:          arg.Username,
+			HashedPassword:    pgtype.Text{String: arg.HashedPassword, Valid: true},
+			PasswordChangedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		err = q.BlockUserSessions(ctx, result.User.Username)
+		if err != nil {
+			return err
+		}
+
+		if arg.AfterReset != nil {
+			return arg.AfterReset(q, result)
+		}
+		return nil
+	})
+
+	return result, err
+}
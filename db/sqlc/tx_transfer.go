@@ -4,9 +4,12 @@ import "context"
 
 // TransferTxParams contains the input parameters of the transfer transaction
 type TransferTxParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	TenantID      string `json:"tenant_id"`
+	// AfterTransfer, when set, runs inside the transaction once balances are updated.
+	AfterTransfer func(q Querier, result TransferTxResult) error `json:"-"`
 }
 
 // TransferTxResult is the result of the transfer transaction
@@ -26,7 +29,12 @@ func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (Tr
 	err := store.execTx(ctx, func(q *Queries) error {
 		var err error
 
-		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams(arg))
+		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+			FromAccountID: arg.FromAccountID,
+			ToAccountID:   arg.ToAccountID,
+			Amount:        arg.Amount,
+			TenantID:      arg.TenantID,
+		})
 		if err != nil {
 			return err
 		}
@@ -52,8 +60,56 @@ func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (Tr
 		} else {
 			result.ToAccount, result.FromAccount, err = addMoney(ctx, q, arg.ToAccountID, arg.Amount, arg.FromAccountID, -arg.Amount)
 		}
+		if err != nil {
+			return err
+		}
+
+		// The balance updates lock both rows, so a concurrent status change is either seen here or waits for this commit.
+		if err := CheckAccountUsable(result.FromAccount); err != nil {
+			return err
+		}
+		if err := CheckAccountUsable(result.ToAccount); err != nil {
+			return err
+		}
+
+		if result.FromAccount.Balance < 0 {
+			return ErrInsufficientFunds
+		}
+
+		// Both owners are locked up front, in order, before either event is recorded.
+		err = lockAccountEventOwners(ctx, q, result.FromAccount, result.ToAccount)
+		if err != nil {
+			return err
+		}
+
+		err = recordAccountEvent(ctx, q, result.FromAccount, AccountEventTransferSent, AccountEventPayload{
+			AccountID:  result.FromAccount.ID,
+			Amount:     result.FromEntry.Amount,
+			Balance:    result.FromAccount.Balance,
+			Currency:   result.FromAccount.Currency,
+			EntryID:    result.FromEntry.ID,
+			TransferID: result.Transfer.ID,
+		})
+		if err != nil {
+			return err
+		}
 
-		return err
+		err = recordAccountEvent(ctx, q, result.ToAccount, AccountEventTransferReceived, AccountEventPayload{
+			AccountID:  result.ToAccount.ID,
+			Amount:     result.ToEntry.Amount,
+			Balance:    result.ToAccount.Balance,
+			Currency:   result.ToAccount.Currency,
+			EntryID:    result.ToEntry.ID,
+			TransferID: result.Transfer.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		if arg.AfterTransfer != nil {
+			return arg.AfterTransfer(q, result)
+		}
+		return nil
 	})
 
 	return result, err
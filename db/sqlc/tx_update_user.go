@@ -0,0 +1,61 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type UpdateUserTxParams struct {
+	UpdateUserParams
+	// AfterEmailChange runs when the email is replaced, after the user is marked unverified.
+	AfterEmailChange func(q Querier, user User) error
+	// AfterUpdate, when set, runs last inside the transaction.
+	AfterUpdate func(q Querier, result UpdateUserTxResult) error
+}
+
+type UpdateUserTxResult struct {
+	User         User
+	Before       User // the user as it was before the update
+	EmailChanged bool
+}
+
+// UpdateUserTx updates a user. Changing the email clears is_email_verified and
+// revokes the codes sent to the previous address.
+func (store *SQLStore) UpdateUserTx(ctx context.Context, arg UpdateUserTxParams) (UpdateUserTxResult, error) {
+	var result UpdateUserTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result.Before, err = q.GetUser(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+		result.EmailChanged = arg.Email.Valid && result.Before.Email != arg.Email.String
+
+		if result.EmailChanged {
+			arg.IsEmailVerified = pgtype.Bool{Bool: false, Valid: true}
+			if err := q.RevokeVerifyEmails(ctx, arg.Username); err != nil {
+				return err
+			}
+		}
+
+		result.User, err = q.UpdateUser(ctx, arg.UpdateUserParams)
+		if err != nil {
+			return err
+		}
+
+		if result.EmailChanged && arg.AfterEmailChange != nil {
+			if err := arg.AfterEmailChange(q, result.User); err != nil {
+				return err
+			}
+		}
+
+		if arg.AfterUpdate != nil {
+			return arg.AfterUpdate(q, result)
+		}
+		return nil
+	})
+
+	return result, err
+}
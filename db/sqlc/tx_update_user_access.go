@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// UpdateUserAccessTxParams contains the input parameters of the user access update.
+// Null fields are left unchanged. Users outside TenantID are reported as not found.
+type UpdateUserAccessTxParams struct {
+	Username   string
+	TenantID   string
+	Role       pgtype.Text
+	IsDisabled pgtype.Bool
+	// AfterUpdate, when set, runs inside the transaction once the user is updated.
+	AfterUpdate func(q Querier, result UpdateUserAccessTxResult) error
+}
+
+type UpdateUserAccessTxResult struct {
+	User   User
+	Before User // the user as it was before the update
+}
+
+// UpdateUserAccessTx changes a user's role or disabled flag, blocks all of the
+// user's sessions, so refresh tokens carrying the old role can no longer be
+// renewed, and revokes the access tokens issued so far.
+func (store *SQLStore) UpdateUserAccessTx(ctx context.Context, arg UpdateUserAccessTxParams) (UpdateUserAccessTxResult, error) {
+	var result UpdateUserAccessTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.Before, err = q.GetUser(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+
+		result.User, err = q.UpdateUser(ctx, UpdateUserParams{
+			Username:   arg.Username,
+			TenantID:   pgtype.Text{String: arg.TenantID, Valid: true},
+			Role:       arg.Role,
+			IsDisabled: arg.IsDisabled,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = q.BlockUserSessions(ctx, result.User.Username)
+		if err != nil {
+			return err
+		}
+
+		// The time comes from the application, like the issued_at of the
+		// tokens it is compared with, so clock skew with the database does
+		// not reject a login that follows the change.
+		err = q.RevokeUserTokens(ctx, RevokeUserTokensParams{
+			Username:         result.User.Username,
+			TokensValidAfter: time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+
+		if arg.AfterUpdate != nil {
+			return arg.AfterUpdate(q, result)
+		}
+		return nil
+	})
+
+	return result, err
+}
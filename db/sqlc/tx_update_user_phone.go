@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+type UpdateUserPhoneTxParams struct {
+	Username string
+	Phone    string
+	// ResendBefore rate limits new codes for the current unverified number:
+	// the last code must have been created before this.
+	ResendBefore time.Time
+	// At most MaxCodes codes may be created after WindowStart, whatever the number.
+	WindowStart time.Time
+	MaxCodes    int64
+	// AfterUpdate schedules the verification code of the new phone number.
+	AfterUpdate func(q Querier, user User) error
+}
+
+type UpdateUserPhoneTxResult struct {
+	User User
+}
+
+// UpdateUserPhoneTx replaces the phone number of a user, marks it unverified and
+// revokes the codes sent before. Setting the current unverified number again
+// sends a new code once the previous one is older than ResendBefore. The user
+// row is locked, so concurrent requests cannot both pass the limits.
+func (store *SQLStore) UpdateUserPhoneTx(ctx context.Context, arg UpdateUserPhoneTxParams) (UpdateUserPhoneTxResult, error) {
+	var result UpdateUserPhoneTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		user, err := q.GetUserForUpdate(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+		if user.Phone == arg.Phone && user.IsPhoneVerified {
+			return ErrPhoneAlreadyVerified
+		}
+
+		if user.Phone == arg.Phone {
+			recent, err := q.CountVerifyPhonesSince(ctx, CountVerifyPhonesSinceParams{
+				Username:     arg.Username,
+				CreatedAfter: arg.ResendBefore,
+			})
+			if err != nil {
+				return err
+			}
+			if recent > 0 {
+				return ErrVerifyPhoneTooSoon
+			}
+		}
+
+		sent, err := q.CountVerifyPhonesSince(ctx, CountVerifyPhonesSinceParams{
+			Username:     arg.Username,
+			CreatedAfter: arg.WindowStart,
+		})
+		if err != nil {
+			return err
+		}
+		if sent >= arg.MaxCodes {
+			return ErrVerifyPhoneLimitReached
+		}
+
+		if err = q.RevokeVerifyPhones(ctx, arg.Username); err != nil {
+			return err
+		}
+
+		result.User, err = q.UpdateUserPhone(ctx, UpdateUserPhoneParams{
+			Username:        arg.Username,
+			Phone:           arg.Phone,
+			IsPhoneVerified: false,
+		})
+		if err != nil {
+			return err
+		}
+
+		return arg.AfterUpdate(q, result.User)
+	})
+
+	return result, err
+}
@@ -30,6 +30,15 @@ func (store *SQLStore) VerifyEmailTx(ctx context.Context, arg VerifyEmailTxParam
 			return err
 		}
 
+		// Codes sent to an address the user has since replaced are no longer valid.
+		result.User, err = q.GetUser(ctx, result.VerifyEmail.Username)
+		if err != nil {
+			return err
+		}
+		if result.User.Email != result.VerifyEmail.Email {
+			return ErrRecordNotFound
+		}
+
 		result.User, err = q.UpdateUser(ctx, UpdateUserParams{
 			Username: result.VerifyEmail.Username,
 			IsEmailVerified: pgtype.Bool{
@@ -0,0 +1,74 @@
+package db
+
+import (
+	"context"
+)
+
+type VerifyPhoneTxParams struct {
+	Username    string
+	MaxAttempts int32
+	// CheckCode reports whether the submitted code matches the stored hash.
+	CheckCode func(hashedCode string) bool
+}
+
+type VerifyPhoneTxResult struct {
+	User        User
+	VerifyPhone VerifyPhone
+	// Verified is false when the code is wrong. The failed attempt is still
+	// committed so that a code cannot be guessed more than MaxAttempts times.
+	Verified bool
+}
+
+// VerifyPhoneTx checks the code against the latest active code sent to the current
+// phone number of the user and marks the number verified when it matches.
+func (store *SQLStore) VerifyPhoneTx(ctx context.Context, arg VerifyPhoneTxParams) (VerifyPhoneTxResult, error) {
+	var result VerifyPhoneTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.User, err = q.GetUser(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+		if result.User.IsPhoneVerified {
+			return ErrPhoneAlreadyVerified
+		}
+
+		result.VerifyPhone, err = q.GetActiveVerifyPhoneForUpdate(ctx, GetActiveVerifyPhoneForUpdateParams{
+			Username:    arg.Username,
+			MaxAttempts: arg.MaxAttempts,
+		})
+		if err != nil {
+			return err
+		}
+		// Codes sent to a number the user has since replaced are no longer valid.
+		if result.VerifyPhone.Phone != result.User.Phone {
+			return ErrRecordNotFound
+		}
+
+		if !arg.CheckCode(result.VerifyPhone.HashedCode) {
+			result.VerifyPhone, err = q.IncrementVerifyPhoneAttempts(ctx, result.VerifyPhone.ID)
+			return err
+		}
+
+		result.VerifyPhone, err = q.MarkVerifyPhoneUsed(ctx, result.VerifyPhone.ID)
+		if err != nil {
+			return err
+		}
+
+		result.User, err = q.UpdateUserPhone(ctx, UpdateUserPhoneParams{
+			Username:        arg.Username,
+			Phone:           result.User.Phone,
+			IsPhoneVerified: true,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Verified = true
+		return nil
+	})
+
+	return result, err
+}
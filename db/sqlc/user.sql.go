@@ -7,6 +7,7 @@ package db
 
 import (
 	"context"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgtype"
 )
@@ -16,10 +17,11 @@ INSERT INTO users (
   username,
   hashed_password,
   full_name,
-  email
+  email,
+  tenant_id
 ) VALUES (
-  $1, $2, $3, $4
-) RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, is_email_verified, role
+  $1, $2, $3, $4, $5
+) RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, is_email_verified, role, is_disabled, tenant_id, locale, verification_requested_at, phone, is_phone_verified, tokens_valid_after
 `
 
 type CreateUserParams struct {
@@ -27,6 +29,7 @@ type CreateUserParams struct {
 	HashedPassword string `json:"hashed_password"`
 	FullName       string `json:"full_name"`
 	Email          string `json:"email"`
+	TenantID       string `json:"tenant_id"`
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
@@ -35,6 +38,7 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		arg.HashedPassword,
 		arg.FullName,
 		arg.Email,
+		arg.TenantID,
 	)
 	var i User
 	err := row.Scan(
@@ -46,15 +50,24 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.CreatedAt,
 		&i.IsEmailVerified,
 		&i.Role,
+		&i.IsDisabled,
+		&i.TenantID,
+		&i.Locale,
+		&i.VerificationRequestedAt,
+		&i.Phone,
+		&i.IsPhoneVerified,
+		&i.TokensValidAfter,
 	)
 	return i, err
 }
 
 const getUser = `-- name: GetUser :one
-SELECT username, hashed_password, full_name, email, password_changed_at, created_at, is_email_verified, role FROM users
+SELECT username, hashed_password, full_name, email, password_changed_at, created_at, is_email_verified, role, is_disabled, tenant_id, locale, verification_requested_at, phone, is_phone_verified, tokens_valid_after FROM users
 WHERE username = $1 LIMIT 1
 `
 
+// Usernames are unique across tenants, so this is not filtered by tenant.
+// Callers look up the authenticated user or check user.tenant_id themselves.
 func (q *Queries) GetUser(ctx context.Context, username string) (User, error) {
 	row := q.db.QueryRow(ctx, getUser, username)
 	var i User
@@ -67,10 +80,179 @@ func (q *Queries) GetUser(ctx context.Context, username string) (User, error) {
 		&i.CreatedAt,
 		&i.IsEmailVerified,
 		&i.Role,
+		&i.IsDisabled,
+		&i.TenantID,
+		&i.Locale,
+		&i.VerificationRequestedAt,
+		&i.Phone,
+		&i.IsPhoneVerified,
+		&i.TokensValidAfter,
 	)
 	return i, err
 }
 
+const getUserForUpdate = `-- name: GetUserForUpdate :one
+SELECT username, hashed_password, full_name, email, password_changed_at, created_at, is_email_verified, role, is_disabled, tenant_id, locale, verification_requested_at, phone, is_phone_verified, tokens_valid_after FROM users
+WHERE username = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetUserForUpdate(ctx context.Context, username string) (User, error) {
+	row := q.db.QueryRow(ctx, getUserForUpdate, username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.IsEmailVerified,
+		&i.Role,
+		&i.IsDisabled,
+		&i.TenantID,
+		&i.Locale,
+		&i.VerificationRequestedAt,
+		&i.Phone,
+		&i.IsPhoneVerified,
+		&i.TokensValidAfter,
+	)
+	return i, err
+}
+
+const getUserTokensValidAfter = `-- name: GetUserTokensValidAfter :one
+SELECT tokens_valid_after FROM users
+WHERE username = $1 LIMIT 1
+`
+
+func (q *Queries) GetUserTokensValidAfter(ctx context.Context, username string) (time.Time, error) {
+	row := q.db.QueryRow(ctx, getUserTokensValidAfter, username)
+	var tokens_valid_after time.Time
+	err := row.Scan(&tokens_valid_after)
+	return tokens_valid_after, err
+}
+
+const listUsers = `-- name: ListUsers :many
+SELECT username, hashed_password, full_name, email, password_changed_at, created_at, is_email_verified, role, is_disabled, tenant_id, locale, verification_requested_at, phone, is_phone_verified, tokens_valid_after FROM users
+WHERE
+  tenant_id = $1
+  AND ($2::varchar IS NULL
+    OR username ILIKE '%' || $2 || '%'
+    OR full_name ILIKE '%' || $2 || '%'
+    OR email ILIKE '%' || $2 || '%')
+  AND ($3::varchar IS NULL OR role = $3)
+ORDER BY username
+LIMIT $5
+OFFSET $4
+`
+
+type ListUsersParams struct {
+	TenantID string      `json:"tenant_id"`
+	Search   pgtype.Text `json:"search"`
+	Role     pgtype.Text `json:"role"`
+	Offset   int32       `json:"offset"`
+	Limit    int32       `json:"limit"`
+}
+
+func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error) {
+	rows, err := q.db.Query(ctx, listUsers,
+		arg.TenantID,
+		arg.Search,
+		arg.Role,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.Username,
+			&i.HashedPassword,
+			&i.FullName,
+			&i.Email,
+			&i.PasswordChangedAt,
+			&i.CreatedAt,
+			&i.IsEmailVerified,
+			&i.Role,
+			&i.IsDisabled,
+			&i.TenantID,
+			&i.Locale,
+			&i.VerificationRequestedAt,
+			&i.Phone,
+			&i.IsPhoneVerified,
+			&i.TokensValidAfter,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markVerificationRequested = `-- name: MarkVerificationRequested :one
+UPDATE users
+SET
+  verification_requested_at = now()
+WHERE
+  username = $1
+  AND is_email_verified = FALSE
+  AND verification_requested_at < $2
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, is_email_verified, role, is_disabled, tenant_id, locale, verification_requested_at, phone, is_phone_verified, tokens_valid_after
+`
+
+type MarkVerificationRequestedParams struct {
+	Username        string    `json:"username"`
+	RequestedBefore time.Time `json:"requested_before"`
+}
+
+func (q *Queries) MarkVerificationRequested(ctx context.Context, arg MarkVerificationRequestedParams) (User, error) {
+	row := q.db.QueryRow(ctx, markVerificationRequested, arg.Username, arg.RequestedBefore)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.IsEmailVerified,
+		&i.Role,
+		&i.IsDisabled,
+		&i.TenantID,
+		&i.Locale,
+		&i.VerificationRequestedAt,
+		&i.Phone,
+		&i.IsPhoneVerified,
+		&i.TokensValidAfter,
+	)
+	return i, err
+}
+
+const revokeUserTokens = `-- name: RevokeUserTokens :exec
+UPDATE users
+SET
+  tokens_valid_after = $1
+WHERE
+  username = $2
+`
+
+type RevokeUserTokensParams struct {
+	TokensValidAfter time.Time `json:"tokens_valid_after"`
+	Username         string    `json:"username"`
+}
+
+func (q *Queries) RevokeUserTokens(ctx context.Context, arg RevokeUserTokensParams) error {
+	_, err := q.db.Exec(ctx, revokeUserTokens, arg.TokensValidAfter, arg.Username)
+	return err
+}
+
 const updateUser = `-- name: UpdateUser :one
 UPDATE users
 SET
@@ -78,10 +260,14 @@ SET
   password_changed_at = COALESCE($2, password_changed_at),
   full_name = COALESCE($3, full_name),
   email = COALESCE($4, email),
-  is_email_verified = COALESCE($5, is_email_verified)
+  is_email_verified = COALESCE($5, is_email_verified),
+  role = COALESCE($6, role),
+  is_disabled = COALESCE($7, is_disabled),
+  locale = COALESCE($8, locale)
 WHERE
-  username = $6
-RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, is_email_verified, role
+  username = $9
+  AND tenant_id = COALESCE($10, tenant_id)
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, is_email_verified, role, is_disabled, tenant_id, locale, verification_requested_at, phone, is_phone_verified, tokens_valid_after
 `
 
 type UpdateUserParams struct {
@@ -90,7 +276,11 @@ type UpdateUserParams struct {
 	FullName          pgtype.Text        `json:"full_name"`
 	Email             pgtype.Text        `json:"email"`
 	IsEmailVerified   pgtype.Bool        `json:"is_email_verified"`
+	Role              pgtype.Text        `json:"role"`
+	IsDisabled        pgtype.Bool        `json:"is_disabled"`
+	Locale            pgtype.Text        `json:"locale"`
 	Username          string             `json:"username"`
+	TenantID          pgtype.Text        `json:"tenant_id"`
 }
 
 func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error) {
@@ -100,8 +290,51 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		arg.FullName,
 		arg.Email,
 		arg.IsEmailVerified,
+		arg.Role,
+		arg.IsDisabled,
+		arg.Locale,
 		arg.Username,
+		arg.TenantID,
+	)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.IsEmailVerified,
+		&i.Role,
+		&i.IsDisabled,
+		&i.TenantID,
+		&i.Locale,
+		&i.VerificationRequestedAt,
+		&i.Phone,
+		&i.IsPhoneVerified,
+		&i.TokensValidAfter,
 	)
+	return i, err
+}
+
+const updateUserPhone = `-- name: UpdateUserPhone :one
+UPDATE users
+SET
+  phone = $1,
+  is_phone_verified = $2
+WHERE
+  username = $3
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, is_email_verified, role, is_disabled, tenant_id, locale, verification_requested_at, phone, is_phone_verified, tokens_valid_after
+`
+
+type UpdateUserPhoneParams struct {
+	Phone           string `json:"phone"`
+	IsPhoneVerified bool   `json:"is_phone_verified"`
+	Username        string `json:"username"`
+}
+
+func (q *Queries) UpdateUserPhone(ctx context.Context, arg UpdateUserPhoneParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserPhone, arg.Phone, arg.IsPhoneVerified, arg.Username)
 	var i User
 	err := row.Scan(
 		&i.Username,
@@ -112,6 +345,13 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.CreatedAt,
 		&i.IsEmailVerified,
 		&i.Role,
+		&i.IsDisabled,
+		&i.TenantID,
+		&i.Locale,
+		&i.VerificationRequestedAt,
+		&i.Phone,
+		&i.IsPhoneVerified,
+		&i.TokensValidAfter,
 	)
 	return i, err
 }
@@ -7,6 +7,7 @@ package db
 
 import (
 	"context"
+	"time"
 )
 
 const createVerifyEmail = `-- name: CreateVerifyEmail :one
@@ -40,6 +41,34 @@ func (q *Queries) CreateVerifyEmail(ctx context.Context, arg CreateVerifyEmailPa
 	return i, err
 }
 
+const deleteExpiredVerifyEmails = `-- name: DeleteExpiredVerifyEmails :execrows
+DELETE FROM verify_emails
+WHERE expired_at < $1
+`
+
+func (q *Queries) DeleteExpiredVerifyEmails(ctx context.Context, expiredBefore time.Time) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredVerifyEmails, expiredBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const revokeVerifyEmails = `-- name: RevokeVerifyEmails :exec
+UPDATE verify_emails
+SET
+    expired_at = now()
+WHERE
+    username = $1
+    AND is_used = FALSE
+    AND expired_at > now()
+`
+
+func (q *Queries) RevokeVerifyEmails(ctx context.Context, username string) error {
+	_, err := q.db.Exec(ctx, revokeVerifyEmails, username)
+	return err
+}
+
 const updateVerifyEmail = `-- name: UpdateVerifyEmail :one
 UPDATE verify_emails
 SET
@@ -0,0 +1,173 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: verify_phone.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const countVerifyPhonesSince = `-- name: CountVerifyPhonesSince :one
+SELECT count(*) FROM verify_phones
+WHERE
+    username = $1
+    AND created_at > $2
+`
+
+type CountVerifyPhonesSinceParams struct {
+	Username     string    `json:"username"`
+	CreatedAfter time.Time `json:"created_after"`
+}
+
+func (q *Queries) CountVerifyPhonesSince(ctx context.Context, arg CountVerifyPhonesSinceParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countVerifyPhonesSince, arg.Username, arg.CreatedAfter)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createVerifyPhone = `-- name: CreateVerifyPhone :one
+INSERT INTO verify_phones (
+    username,
+    phone,
+    hashed_code
+) VALUES (
+    $1, $2, $3
+) RETURNING id, username, phone, hashed_code, attempts, is_used, created_at, expired_at
+`
+
+type CreateVerifyPhoneParams struct {
+	Username   string `json:"username"`
+	Phone      string `json:"phone"`
+	HashedCode string `json:"hashed_code"`
+}
+
+func (q *Queries) CreateVerifyPhone(ctx context.Context, arg CreateVerifyPhoneParams) (VerifyPhone, error) {
+	row := q.db.QueryRow(ctx, createVerifyPhone, arg.Username, arg.Phone, arg.HashedCode)
+	var i VerifyPhone
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Phone,
+		&i.HashedCode,
+		&i.Attempts,
+		&i.IsUsed,
+		&i.CreatedAt,
+		&i.ExpiredAt,
+	)
+	return i, err
+}
+
+const deleteExpiredVerifyPhones = `-- name: DeleteExpiredVerifyPhones :execrows
+DELETE FROM verify_phones
+WHERE expired_at < $1
+`
+
+func (q *Queries) DeleteExpiredVerifyPhones(ctx context.Context, expiredBefore time.Time) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredVerifyPhones, expiredBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getActiveVerifyPhoneForUpdate = `-- name: GetActiveVerifyPhoneForUpdate :one
+SELECT id, username, phone, hashed_code, attempts, is_used, created_at, expired_at FROM verify_phones
+WHERE
+    username = $1
+    AND is_used = FALSE
+    AND expired_at > now()
+    AND attempts < $2::int
+ORDER BY id DESC
+LIMIT 1
+FOR NO KEY UPDATE
+`
+
+type GetActiveVerifyPhoneForUpdateParams struct {
+	Username    string `json:"username"`
+	MaxAttempts int32  `json:"max_attempts"`
+}
+
+func (q *Queries) GetActiveVerifyPhoneForUpdate(ctx context.Context, arg GetActiveVerifyPhoneForUpdateParams) (VerifyPhone, error) {
+	row := q.db.QueryRow(ctx, getActiveVerifyPhoneForUpdate, arg.Username, arg.MaxAttempts)
+	var i VerifyPhone
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Phone,
+		&i.HashedCode,
+		&i.Attempts,
+		&i.IsUsed,
+		&i.CreatedAt,
+		&i.ExpiredAt,
+	)
+	return i, err
+}
+
+const incrementVerifyPhoneAttempts = `-- name: IncrementVerifyPhoneAttempts :one
+UPDATE verify_phones
+SET
+    attempts = attempts + 1
+WHERE
+    id = $1
+RETURNING id, username, phone, hashed_code, attempts, is_used, created_at, expired_at
+`
+
+func (q *Queries) IncrementVerifyPhoneAttempts(ctx context.Context, id int64) (VerifyPhone, error) {
+	row := q.db.QueryRow(ctx, incrementVerifyPhoneAttempts, id)
+	var i VerifyPhone
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Phone,
+		&i.HashedCode,
+		&i.Attempts,
+		&i.IsUsed,
+		&i.CreatedAt,
+		&i.ExpiredAt,
+	)
+	return i, err
+}
+
+const markVerifyPhoneUsed = `-- name: MarkVerifyPhoneUsed :one
+UPDATE verify_phones
+SET
+    is_used = TRUE
+WHERE
+    id = $1
+RETURNING id, username, phone, hashed_code, attempts, is_used, created_at, expired_at
+`
+
+func (q *Queries) MarkVerifyPhoneUsed(ctx context.Context, id int64) (VerifyPhone, error) {
+	row := q.db.QueryRow(ctx, markVerifyPhoneUsed, id)
+	var i VerifyPhone
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Phone,
+		&i.HashedCode,
+		&i.Attempts,
+		&i.IsUsed,
+		&i.CreatedAt,
+		&i.ExpiredAt,
+	)
+	return i, err
+}
+
+const revokeVerifyPhones = `-- name: RevokeVerifyPhones :exec
+UPDATE verify_phones
+SET
+    expired_at = now()
+WHERE
+    username = $1
+    AND is_used = FALSE
+    AND expired_at > now()
+`
+
+func (q *Queries) RevokeVerifyPhones(ctx context.Context, username string) error {
+	_, err := q.db.Exec(ctx, revokeVerifyPhones, username)
+	return err
+}
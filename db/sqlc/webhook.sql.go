@@ -0,0 +1,416 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: webhook.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createWebhookDelivery = `-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries (
+    subscription_id,
+    event_type,
+    payload
+) VALUES (
+    $1, $2, $3
+) RETURNING id, subscription_id, event_type, payload, status, attempts, response_status, last_error, delivered_at, created_at
+`
+
+type CreateWebhookDeliveryParams struct {
+	SubscriptionID int64  `json:"subscription_id"`
+	EventType      string `json:"event_type"`
+	Payload        []byte `json:"payload"`
+}
+
+func (q *Queries) CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) (WebhookDelivery, error) {
+	row := q.db.QueryRow(ctx, createWebhookDelivery, arg.SubscriptionID, arg.EventType, arg.Payload)
+	var i WebhookDelivery
+	err := row.Scan(
+		&i.ID,
+		&i.SubscriptionID,
+		&i.EventType,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.ResponseStatus,
+		&i.LastError,
+		&i.DeliveredAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createWebhookSubscription = `-- name: CreateWebhookSubscription :one
+INSERT INTO webhook_subscriptions (
+    username,
+    url,
+    events,
+    secret
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING id, username, url, events, secret, is_disabled, consecutive_failures, created_at
+`
+
+type CreateWebhookSubscriptionParams struct {
+	Username string   `json:"username"`
+	Url      string   `json:"url"`
+	Events   []string `json:"events"`
+	Secret   string   `json:"secret"`
+}
+
+func (q *Queries) CreateWebhookSubscription(ctx context.Context, arg CreateWebhookSubscriptionParams) (WebhookSubscription, error) {
+	row := q.db.QueryRow(ctx, createWebhookSubscription,
+		arg.Username,
+		arg.Url,
+		arg.Events,
+		arg.Secret,
+	)
+	var i WebhookSubscription
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Url,
+		&i.Events,
+		&i.Secret,
+		&i.IsDisabled,
+		&i.ConsecutiveFailures,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteWebhookSubscription = `-- name: DeleteWebhookSubscription :exec
+DELETE FROM webhook_subscriptions
+WHERE id = $1
+`
+
+func (q *Queries) DeleteWebhookSubscription(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, deleteWebhookSubscription, id)
+	return err
+}
+
+const getWebhookDelivery = `-- name: GetWebhookDelivery :one
+SELECT id, subscription_id, event_type, payload, status, attempts, response_status, last_error, delivered_at, created_at FROM webhook_deliveries
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetWebhookDelivery(ctx context.Context, id int64) (WebhookDelivery, error) {
+	row := q.db.QueryRow(ctx, getWebhookDelivery, id)
+	var i WebhookDelivery
+	err := row.Scan(
+		&i.ID,
+		&i.SubscriptionID,
+		&i.EventType,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.ResponseStatus,
+		&i.LastError,
+		&i.DeliveredAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getWebhookSubscription = `-- name: GetWebhookSubscription :one
+SELECT id, username, url, events, secret, is_disabled, consecutive_failures, created_at FROM webhook_subscriptions
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetWebhookSubscription(ctx context.Context, id int64) (WebhookSubscription, error) {
+	row := q.db.QueryRow(ctx, getWebhookSubscription, id)
+	var i WebhookSubscription
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Url,
+		&i.Events,
+		&i.Secret,
+		&i.IsDisabled,
+		&i.ConsecutiveFailures,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listWebhookDeliveries = `-- name: ListWebhookDeliveries :many
+SELECT id, subscription_id, event_type, payload, status, attempts, response_status, last_error, delivered_at, created_at FROM webhook_deliveries
+WHERE subscription_id = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3
+`
+
+type ListWebhookDeliveriesParams struct {
+	SubscriptionID int64 `json:"subscription_id"`
+	Limit          int32 `json:"limit"`
+	Offset         int32 `json:"offset"`
+}
+
+func (q *Queries) ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error) {
+	rows, err := q.db.Query(ctx, listWebhookDeliveries, arg.SubscriptionID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []WebhookDelivery{}
+	for rows.Next() {
+		var i WebhookDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubscriptionID,
+			&i.EventType,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.ResponseStatus,
+			&i.LastError,
+			&i.DeliveredAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhookSubscriptions = `-- name: ListWebhookSubscriptions :many
+SELECT id, username, url, events, secret, is_disabled, consecutive_failures, created_at FROM webhook_subscriptions
+WHERE username = $1
+ORDER BY id
+`
+
+func (q *Queries) ListWebhookSubscriptions(ctx context.Context, username string) ([]WebhookSubscription, error) {
+	rows, err := q.db.Query(ctx, listWebhookSubscriptions, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []WebhookSubscription{}
+	for rows.Next() {
+		var i WebhookSubscription
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.Url,
+			&i.Events,
+			&i.Secret,
+			&i.IsDisabled,
+			&i.ConsecutiveFailures,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhookSubscriptionsForEvent = `-- name: ListWebhookSubscriptionsForEvent :many
+SELECT id, username, url, events, secret, is_disabled, consecutive_failures, created_at FROM webhook_subscriptions
+WHERE
+    username = $1
+    AND is_disabled = FALSE
+    AND $2::varchar = ANY(events)
+ORDER BY id
+`
+
+type ListWebhookSubscriptionsForEventParams struct {
+	Username  string `json:"username"`
+	EventType string `json:"event_type"`
+}
+
+func (q *Queries) ListWebhookSubscriptionsForEvent(ctx context.Context, arg ListWebhookSubscriptionsForEventParams) ([]WebhookSubscription, error) {
+	rows, err := q.db.Query(ctx, listWebhookSubscriptionsForEvent, arg.Username, arg.EventType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []WebhookSubscription{}
+	for rows.Next() {
+		var i WebhookSubscription
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.Url,
+			&i.Events,
+			&i.Secret,
+			&i.IsDisabled,
+			&i.ConsecutiveFailures,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markWebhookDeliveryFailed = `-- name: MarkWebhookDeliveryFailed :one
+UPDATE webhook_deliveries
+SET
+    status = 'failed',
+    attempts = attempts + 1,
+    response_status = $1,
+    last_error = $2
+WHERE id = $3
+RETURNING id, subscription_id, event_type, payload, status, attempts, response_status, last_error, delivered_at, created_at
+`
+
+type MarkWebhookDeliveryFailedParams struct {
+	ResponseStatus int32  `json:"response_status"`
+	LastError      string `json:"last_error"`
+	ID             int64  `json:"id"`
+}
+
+func (q *Queries) MarkWebhookDeliveryFailed(ctx context.Context, arg MarkWebhookDeliveryFailedParams) (WebhookDelivery, error) {
+	row := q.db.QueryRow(ctx, markWebhookDeliveryFailed, arg.ResponseStatus, arg.LastError, arg.ID)
+	var i WebhookDelivery
+	err := row.Scan(
+		&i.ID,
+		&i.SubscriptionID,
+		&i.EventType,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.ResponseStatus,
+		&i.LastError,
+		&i.DeliveredAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const markWebhookDeliverySucceeded = `-- name: MarkWebhookDeliverySucceeded :one
+UPDATE webhook_deliveries
+SET
+    status = 'succeeded',
+    attempts = attempts + 1,
+    response_status = $1,
+    last_error = '',
+    delivered_at = now()
+WHERE id = $2
+RETURNING id, subscription_id, event_type, payload, status, attempts, response_status, last_error, delivered_at, created_at
+`
+
+type MarkWebhookDeliverySucceededParams struct {
+	ResponseStatus int32 `json:"response_status"`
+	ID             int64 `json:"id"`
+}
+
+func (q *Queries) MarkWebhookDeliverySucceeded(ctx context.Context, arg MarkWebhookDeliverySucceededParams) (WebhookDelivery, error) {
+	row := q.db.QueryRow(ctx, markWebhookDeliverySucceeded, arg.ResponseStatus, arg.ID)
+	var i WebhookDelivery
+	err := row.Scan(
+		&i.ID,
+		&i.SubscriptionID,
+		&i.EventType,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.ResponseStatus,
+		&i.LastError,
+		&i.DeliveredAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const recordWebhookFailure = `-- name: RecordWebhookFailure :one
+UPDATE webhook_subscriptions
+SET
+    consecutive_failures = consecutive_failures + 1,
+    is_disabled = is_disabled OR consecutive_failures + 1 >= $1::int
+WHERE id = $2
+RETURNING id, username, url, events, secret, is_disabled, consecutive_failures, created_at
+`
+
+type RecordWebhookFailureParams struct {
+	MaxFailures int32 `json:"max_failures"`
+	ID          int64 `json:"id"`
+}
+
+func (q *Queries) RecordWebhookFailure(ctx context.Context, arg RecordWebhookFailureParams) (WebhookSubscription, error) {
+	row := q.db.QueryRow(ctx, recordWebhookFailure, arg.MaxFailures, arg.ID)
+	var i WebhookSubscription
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Url,
+		&i.Events,
+		&i.Secret,
+		&i.IsDisabled,
+		&i.ConsecutiveFailures,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const recordWebhookSuccess = `-- name: RecordWebhookSuccess :exec
+UPDATE webhook_subscriptions
+SET consecutive_failures = 0
+WHERE id = $1
+`
+
+func (q *Queries) RecordWebhookSuccess(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, recordWebhookSuccess, id)
+	return err
+}
+
+const updateWebhookSubscription = `-- name: UpdateWebhookSubscription :one
+UPDATE webhook_subscriptions
+SET
+    url = COALESCE($1, url),
+    events = COALESCE($2::varchar[], events),
+    is_disabled = COALESCE($3, is_disabled),
+    consecutive_failures = CASE
+        WHEN $3 = FALSE THEN 0
+        ELSE consecutive_failures
+    END
+WHERE
+    id = $4
+RETURNING id, username, url, events, secret, is_disabled, consecutive_failures, created_at
+`
+
+type UpdateWebhookSubscriptionParams struct {
+	Url        pgtype.Text `json:"url"`
+	Events     []string    `json:"events"`
+	IsDisabled pgtype.Bool `json:"is_disabled"`
+	ID         int64       `json:"id"`
+}
+
+func (q *Queries) UpdateWebhookSubscription(ctx context.Context, arg UpdateWebhookSubscriptionParams) (WebhookSubscription, error) {
+	row := q.db.QueryRow(ctx, updateWebhookSubscription,
+		arg.Url,
+		arg.Events,
+		arg.IsDisabled,
+		arg.ID,
+	)
+	var i WebhookSubscription
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Url,
+		&i.Events,
+		&i.Secret,
+		&i.IsDisabled,
+		&i.ConsecutiveFailures,
+		&i.CreatedAt,
+	)
+	return i, err
+}
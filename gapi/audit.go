@@ -0,0 +1,64 @@
+package gapi
+
+import (
+	"context"
+
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/token"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// recordAudit appends event to the audit log once an RPC returned err. It is
+// deferred by the audited RPCs, which therefore name their error result. The
+// actor is taken from the access token unless the RPC set one, as login does.
+//
+// RPCs that change money or access append the success with appendAudit in
+// the transaction of the change instead, so it is recorded here only if that
+// transaction did not commit.
+func (server *Server) recordAudit(ctx context.Context, event *db.AppendAuditEventTxParams, err error) {
+	if err == nil && event.Outcome == db.AuditOutcomeSuccess {
+		return
+	}
+
+	event.Outcome = db.AuditOutcomeSuccess
+	if err != nil {
+		code := status.Code(err)
+		event.Outcome = db.AuditOutcomeFailure
+		if code == codes.PermissionDenied {
+			event.Outcome = db.AuditOutcomeDenied
+		}
+		event.Reason = code.String()
+	}
+	server.setAuditCaller(ctx, event)
+
+	// Nothing changed, so a failure to record is only logged. The event is
+	// kept even if the client left.
+	_, err = server.store.AppendAuditEventTx(context.WithoutCancel(ctx), *event)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("action", event.Action).Msg("cannot record audit event")
+	}
+}
+
+// appendAudit appends event as a success within the transaction of q, which
+// fails, and rolls the change back, if the event cannot be recorded.
+func (server *Server) appendAudit(ctx context.Context, q db.Querier, event *db.AppendAuditEventTxParams) error {
+	event.Outcome = db.AuditOutcomeSuccess
+	server.setAuditCaller(ctx, event)
+	_, err := db.AppendAuditEvent(ctx, q, *event)
+	return err
+}
+
+// setAuditCaller sets the actor of event from the access token, unless the
+// RPC set one, and the request ID and client IP.
+func (server *Server) setAuditCaller(ctx context.Context, event *db.AppendAuditEventTxParams) {
+	if payload, ok := ctx.Value(authPayloadKey{}).(*token.Payload); ok && event.Actor == "" {
+		event.Actor = payload.Username
+		event.ActorRole = payload.Role
+		event.TenantID = payload.TenantID
+	}
+	mtdt := server.extractMetadata(ctx)
+	event.RequestID = mtdt.RequestID
+	event.ClientIP = mtdt.ClientIP
+}
@@ -0,0 +1,147 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/metrics"
+	"github.com/LamThanhNguyen/banking-system/pb"
+	"github.com/LamThanhNguyen/banking-system/token"
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	authorizationHeader = "authorization"
+	authorizationBearer = "bearer"
+)
+
+// methodPermissions lists the RPCs that need an access token and the Casbin
+// action each one requires, as the Require middleware of the HTTP API does.
+// RPCs missing from the map are public.
+var methodPermissions = map[string]string{
+	pb.BankingSystem_UpdateUser_FullMethodName:     "users:update",
+	pb.BankingSystem_CreateAccount_FullMethodName:  "accounts:create",
+	pb.BankingSystem_GetAccount_FullMethodName:     "accounts:read",
+	pb.BankingSystem_ListAccounts_FullMethodName:   "accounts:list",
+	pb.BankingSystem_CreateTransfer_FullMethodName: "transfers:create",
+}
+
+// verifiedEmailMethods move money and are subject to REQUIRE_VERIFIED_EMAIL.
+var verifiedEmailMethods = map[string]bool{
+	pb.BankingSystem_CreateAccount_FullMethodName:  true,
+	pb.BankingSystem_CreateTransfer_FullMethodName: true,
+}
+
+type authPayloadKey struct{}
+
+// authInterceptor verifies the access token of protected RPCs, enforces
+// their permission and stores the token payload in the context.
+func (server *Server) authInterceptor(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	action, ok := methodPermissions[info.FullMethod]
+	if !ok {
+		return handler(ctx, req)
+	}
+
+	payload, err := server.authenticate(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	// Changing a user's role or disabling them revokes the tokens issued so far.
+	validAfter, err := server.store.GetUserTokensValidAfter(ctx, payload.Username)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, unauthenticatedError(fmt.Errorf("user no longer exists"))
+		}
+		return nil, status.Errorf(codes.Internal, "failed to check access token: %s", err)
+	}
+	if payload.IssuedAt.Before(validAfter) {
+		return nil, unauthenticatedError(fmt.Errorf("access token has been revoked"))
+	}
+
+	sub := util.Subject{Role: payload.Role, Name: payload.Username}
+	obj := util.Object{Name: "*"} // wildcard for everything except ABAC handlers
+	if err := server.enforce(sub, payload.TenantID, obj, action); err != nil {
+		return nil, err
+	}
+
+	if verifiedEmailMethods[info.FullMethod] && server.config.RequireVerifiedEmail {
+		user, err := server.store.GetUser(ctx, payload.Username)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to get user: %s", err)
+		}
+		if !user.IsEmailVerified {
+			return nil, status.Error(codes.PermissionDenied, "email address must be verified before moving money")
+		}
+	}
+
+	return handler(context.WithValue(ctx, authPayloadKey{}, payload), req)
+}
+
+func (server *Server) authenticate(ctx context.Context) (*token.Payload, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("missing metadata")
+	}
+
+	values := md.Get(authorizationHeader)
+	if len(values) == 0 {
+		return nil, fmt.Errorf("missing authorization header")
+	}
+
+	fields := strings.Fields(values[0])
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("invalid authorization header format")
+	}
+
+	authType := strings.ToLower(fields[0])
+	if authType != authorizationBearer {
+		return nil, fmt.Errorf("unsupported authorization type: %s", authType)
+	}
+
+	payload, err := server.tokenMaker.VerifyToken(fields[1], token.TokenTypeAccessToken)
+	if err != nil {
+		return nil, fmt.Errorf("invalid access token: %s", err)
+	}
+	return payload, nil
+}
+
+// enforce returns a PermissionDenied status unless the subject may perform the action.
+func (server *Server) enforce(sub util.Subject, dom string, obj util.Object, action string) error {
+	allowed, err := server.enforcer.Enforce(sub, dom, obj, action)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to enforce policy: %s", err)
+	}
+	if !allowed {
+		metrics.ObserveAuthorizationDenial(action)
+		if server.config.LogAuthzDenials {
+			log.Warn().
+				Str("sub_role", sub.Role).
+				Str("sub_name", sub.Name).
+				Str("dom", dom).
+				Str("obj", obj.Name).
+				Str("act", action).
+				Msg("authorization denied")
+		}
+		return status.Error(codes.PermissionDenied, "forbidden")
+	}
+	return nil
+}
+
+// authPayload returns the payload stored by authInterceptor.
+func authPayload(ctx context.Context) *token.Payload {
+	return ctx.Value(authPayloadKey{}).(*token.Payload)
+}
@@ -0,0 +1,139 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	mockdb "github.com/LamThanhNguyen/banking-system/db/mock"
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/pb"
+	"github.com/LamThanhNguyen/banking-system/token"
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/LamThanhNguyen/banking-system/worker"
+	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/model"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newTestEnforcer(t *testing.T) *casbin.Enforcer {
+	m, err := model.NewModelFromString(`
+        [request_definition]
+        r = sub, dom, obj, act
+
+        [policy_definition]
+        p = sub, dom, obj, act
+
+        [policy_effect]
+        e = some(where (p.eft == allow))
+
+        [matchers]
+        m = true
+    `)
+	require.NoError(t, err)
+
+	e, err := casbin.NewEnforcer(m)
+	require.NoError(t, err)
+	return e
+}
+
+func newTestServer(
+	t *testing.T,
+	store db.Store,
+	enforcer *casbin.Enforcer,
+	taskDistributor worker.TaskDistributor,
+) *Server {
+	if enforcer == nil {
+		enforcer = newTestEnforcer(t)
+	}
+	if mock, ok := store.(*mockdb.MockStore); ok {
+		// None of the users has had their tokens revoked. Expectations set
+		// by the test itself come first.
+		mock.EXPECT().
+			GetUserTokensValidAfter(gomock.Any(), gomock.Any()).
+			AnyTimes().
+			Return(time.Time{}, nil)
+	}
+
+	config := util.RuntimeConfig{
+		Config: util.Config{
+			TokenSymmetricKey:    util.RandomString(32),
+			Environment:          "test",
+			AccessTokenDuration:  "1m",
+			RefreshTokenDuration: "10m",
+		},
+		AccessTokenDurationParsed:  time.Minute,
+		RefreshTokenDurationParsed: 10 * time.Minute,
+	}
+
+	server, err := NewServer(config, store, enforcer, taskDistributor)
+	require.NoError(t, err)
+
+	return server
+}
+
+// newTestClient serves the server in memory, so requests go through the interceptors.
+func newTestClient(t *testing.T, server *Server) pb.BankingSystemClient {
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := server.GRPCServer()
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewBankingSystemClient(conn)
+}
+
+func newContextWithBearerToken(t *testing.T, tokenMaker token.Maker, username string, role string, duration time.Duration) context.Context {
+	accessToken, _, err := tokenMaker.CreateToken(username, role, util.DefaultTenant, duration, token.TokenTypeAccessToken)
+	require.NoError(t, err)
+
+	bearerToken := fmt.Sprintf("%s %s", authorizationBearer, accessToken)
+	md := metadata.MD{
+		authorizationHeader: []string{bearerToken},
+	}
+
+	return metadata.NewOutgoingContext(context.Background(), md)
+}
+
+func randomUser(t *testing.T) (user db.User, password string) {
+	password = util.RandomString(10)
+	hashedPassword, err := util.HashPassword(password)
+	require.NoError(t, err)
+
+	user = db.User{
+		Username:       util.RandomOwner(),
+		HashedPassword: hashedPassword,
+		FullName:       util.RandomOwner(),
+		Email:          util.RandomEmail(),
+		Role:           util.DepositorRole,
+		TenantID:       util.DefaultTenant,
+	}
+	return
+}
+
+func randomAccount(owner string) db.Account {
+	return db.Account{
+		ID:       util.RandomInt(1, 1000),
+		Owner:    owner,
+		Balance:  util.RandomMoney(),
+		Currency: util.RandomCurrency(),
+		TenantID: util.DefaultTenant,
+		Status:   db.AccountStatusActive,
+	}
+}
@@ -0,0 +1,55 @@
+package gapi
+
+import (
+	"context"
+
+	"github.com/LamThanhNguyen/banking-system/logging"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	grpcGatewayUserAgentHeader = "grpcgateway-user-agent"
+	userAgentHeader            = "user-agent"
+	xForwardedForHeader        = "x-forwarded-for"
+	xRequestIDHeader           = "x-request-id"
+)
+
+// Metadata describes the client of a request, whether it came over gRPC or through the gateway.
+type Metadata struct {
+	UserAgent string
+	ClientIP  string
+	RequestID string
+}
+
+func (server *Server) extractMetadata(ctx context.Context) *Metadata {
+	mtdt := &Metadata{}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if userAgents := md.Get(grpcGatewayUserAgentHeader); len(userAgents) > 0 {
+			mtdt.UserAgent = userAgents[0]
+		}
+
+		if userAgents := md.Get(userAgentHeader); len(userAgents) > 0 && mtdt.UserAgent == "" {
+			mtdt.UserAgent = userAgents[0]
+		}
+
+		if clientIPs := md.Get(xForwardedForHeader); len(clientIPs) > 0 {
+			mtdt.ClientIP = clientIPs[0]
+		}
+
+		if requestIDs := md.Get(xRequestIDHeader); len(requestIDs) > 0 && logging.ValidRequestID(requestIDs[0]) {
+			mtdt.RequestID = requestIDs[0]
+		}
+	}
+
+	if p, ok := peer.FromContext(ctx); ok && mtdt.ClientIP == "" {
+		mtdt.ClientIP = p.Addr.String()
+	}
+
+	if mtdt.RequestID == "" {
+		mtdt.RequestID = logging.RequestIDFromContext(ctx)
+	}
+
+	return mtdt
+}
@@ -0,0 +1,113 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/pb"
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/LamThanhNguyen/banking-system/worker"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) CreateAccount(ctx context.Context, req *pb.CreateAccountRequest) (*pb.CreateAccountResponse, error) {
+	if !util.IsSupportedCurrency(req.GetCurrency()) {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{
+			fieldViolation("currency", fmt.Errorf("is not a supported currency")),
+		})
+	}
+
+	authPayload := authPayload(ctx)
+	arg := db.CreateAccountTxParams{
+		CreateAccountParams: db.CreateAccountParams{
+			Owner:    authPayload.Username,
+			Currency: req.GetCurrency(),
+			Balance:  0,
+			TenantID: authPayload.TenantID,
+		},
+		AfterCreate: func(q db.Querier, account db.Account) error {
+			return worker.DispatchWebhookEvent(ctx, q, account.Owner, util.WebhookAccountCreated, account)
+		},
+	}
+
+	txResult, err := server.store.CreateAccountTx(ctx, arg)
+	if err != nil {
+		switch db.ErrorCode(err) {
+		case db.UniqueViolation:
+			return nil, status.Errorf(codes.AlreadyExists, "an account in %s already exists", req.GetCurrency())
+		case db.ForeignKeyViolation:
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to create account: %s", err)
+	}
+
+	rsp := &pb.CreateAccountResponse{
+		Account: convertAccount(txResult.Account),
+	}
+	return rsp, nil
+}
+
+func (server *Server) GetAccount(ctx context.Context, req *pb.GetAccountRequest) (*pb.GetAccountResponse, error) {
+	if req.GetId() < 1 {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{
+			fieldViolation("id", fmt.Errorf("must be a positive integer")),
+		})
+	}
+
+	authPayload := authPayload(ctx)
+	account, err := server.store.GetAccount(ctx, db.GetAccountParams{
+		ID:       req.GetId(),
+		TenantID: authPayload.TenantID,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, status.Error(codes.NotFound, "account not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get account: %s", err)
+	}
+
+	if account.Owner != authPayload.Username {
+		return nil, status.Error(codes.PermissionDenied, "account doesn't belong to the authenticated user")
+	}
+
+	rsp := &pb.GetAccountResponse{
+		Account: convertAccount(account),
+	}
+	return rsp, nil
+}
+
+func (server *Server) ListAccounts(ctx context.Context, req *pb.ListAccountsRequest) (*pb.ListAccountsResponse, error) {
+	var violations []*errdetails.BadRequest_FieldViolation
+	if req.GetPageId() < 1 {
+		violations = append(violations, fieldViolation("page_id", fmt.Errorf("must be at least 1")))
+	}
+	if req.GetPageSize() < 5 || req.GetPageSize() > 10 {
+		violations = append(violations, fieldViolation("page_size", fmt.Errorf("must be from 5 to 10")))
+	}
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	authPayload := authPayload(ctx)
+	accounts, err := server.store.ListAccounts(ctx, db.ListAccountsParams{
+		Owner:    authPayload.Username,
+		TenantID: authPayload.TenantID,
+		Limit:    req.GetPageSize(),
+		Offset:   (req.GetPageId() - 1) * req.GetPageSize(),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list accounts: %s", err)
+	}
+
+	rsp := &pb.ListAccountsResponse{
+		Accounts: make([]*pb.Account, len(accounts)),
+	}
+	for i, account := range accounts {
+		rsp.Accounts[i] = convertAccount(account)
+	}
+	return rsp, nil
+}
@@ -0,0 +1,193 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/metrics"
+	"github.com/LamThanhNguyen/banking-system/pb"
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/LamThanhNguyen/banking-system/worker"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) CreateTransfer(ctx context.Context, req *pb.CreateTransferRequest) (_ *pb.CreateTransferResponse, err error) {
+	violations := validateCreateTransferRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	transfer := map[string]any{
+		"to_account_id": req.GetToAccountId(),
+		"amount":        req.GetAmount(),
+		"currency":      req.GetCurrency(),
+	}
+	audit := db.AppendAuditEventTxParams{
+		Action:     db.AuditActionTransferCreate,
+		TargetType: db.AuditTargetAccount,
+		TargetID:   strconv.FormatInt(req.GetFromAccountId(), 10),
+		After:      transfer,
+	}
+	defer func() { server.recordAudit(ctx, &audit, err) }()
+
+	authPayload := authPayload(ctx)
+
+	fromAccount, err := server.validAccount(ctx, req.GetFromAccountId(), authPayload.TenantID, req.GetCurrency())
+	if err != nil {
+		return nil, err
+	}
+
+	if fromAccount.Owner != authPayload.Username {
+		return nil, status.Error(codes.PermissionDenied, "from account doesn't belong to the authenticated user")
+	}
+
+	toTenantID, err := server.recipientTenant(ctx, req.GetToAccountId(), authPayload.TenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := server.validAccount(ctx, req.GetToAccountId(), toTenantID, req.GetCurrency()); err != nil {
+		return nil, err
+	}
+
+	arg := db.TransferTxParams{
+		FromAccountID: req.GetFromAccountId(),
+		ToAccountID:   req.GetToAccountId(),
+		Amount:        req.GetAmount(),
+		TenantID:      authPayload.TenantID,
+		AfterTransfer: func(q db.Querier, result db.TransferTxResult) error {
+			err := worker.DistributeTransferNotifications(ctx, worker.NewOutboxTaskDistributor(q), result, req.GetCurrency())
+			if err != nil {
+				return err
+			}
+			err = worker.DispatchTransferWebhooks(ctx, q, result, req.GetCurrency())
+			if err != nil {
+				return err
+			}
+
+			transfer["transfer_id"] = result.Transfer.ID
+			return server.appendAudit(ctx, q, &audit)
+		},
+	}
+
+	result, err := server.store.TransferTx(ctx, arg)
+	metrics.ObserveTransfer(req.GetCurrency(), req.GetAmount(), err)
+	if err != nil {
+		server.notifyTransferFailed(ctx, authPayload.Username, req)
+		switch {
+		case errors.Is(err, db.ErrAccountFrozen) || errors.Is(err, db.ErrAccountClosed):
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		case errors.Is(err, db.ErrInsufficientFunds):
+			return nil, status.Errorf(codes.FailedPrecondition, "account [%d] has insufficient funds", req.GetFromAccountId())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to transfer: %s", err)
+	}
+
+	rsp := &pb.CreateTransferResponse{
+		Transfer:    convertTransfer(result.Transfer),
+		FromAccount: convertAccount(result.FromAccount),
+		ToAccount:   convertAccount(result.ToAccount),
+		FromEntry:   convertEntry(result.FromEntry),
+		ToEntry:     convertEntry(result.ToEntry),
+	}
+	return rsp, nil
+}
+
+func validateCreateTransferRequest(req *pb.CreateTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.GetFromAccountId() < 1 {
+		violations = append(violations, fieldViolation("from_account_id", fmt.Errorf("must be a positive integer")))
+	}
+
+	if req.GetToAccountId() < 1 {
+		violations = append(violations, fieldViolation("to_account_id", fmt.Errorf("must be a positive integer")))
+	}
+
+	if req.GetAmount() <= 0 {
+		violations = append(violations, fieldViolation("amount", fmt.Errorf("must be greater than 0")))
+	}
+
+	if !util.IsSupportedCurrency(req.GetCurrency()) {
+		violations = append(violations, fieldViolation("currency", fmt.Errorf("is not a supported currency")))
+	}
+
+	return violations
+}
+
+// notifyTransferFailed tells the sender that a transfer did not go through.
+// The transaction was rolled back, so the task is enqueued directly and a failure is only logged.
+func (server *Server) notifyTransferFailed(ctx context.Context, username string, req *pb.CreateTransferRequest) {
+	payload := &worker.PayloadSendTransferFailedNotification{
+		Username:      username,
+		FromAccountID: req.GetFromAccountId(),
+		ToAccountID:   req.GetToAccountId(),
+		Amount:        req.GetAmount(),
+		Currency:      req.GetCurrency(),
+	}
+
+	err := server.taskDistributor.DistributeTaskSendTransferFailedNotification(ctx, payload, asynq.MaxRetry(10))
+	if err != nil {
+		log.Error().Err(err).Str("username", username).Msg("failed to distribute transfer failed notification")
+	}
+}
+
+func (server *Server) validAccount(ctx context.Context, accountID int64, tenantID string, currency string) (db.Account, error) {
+	account, err := server.store.GetAccount(ctx, db.GetAccountParams{
+		ID:       accountID,
+		TenantID: tenantID,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return account, status.Errorf(codes.NotFound, "account [%d] not found", accountID)
+		}
+		return account, status.Errorf(codes.Internal, "failed to get account: %s", err)
+	}
+
+	if account.Currency != currency {
+		return account, status.Errorf(codes.InvalidArgument, "account [%d] currency mismatch: %s vs %s", accountID, account.Currency, currency)
+	}
+
+	switch account.Status {
+	case db.AccountStatusFrozen:
+		return account, status.Errorf(codes.PermissionDenied, "account [%d] is frozen", accountID)
+	case db.AccountStatusClosed:
+		return account, status.Errorf(codes.PermissionDenied, "account [%d] is closed", accountID)
+	}
+
+	return account, nil
+}
+
+// recipientTenant returns the tenant of the destination account. Transfers to another
+// tenant are only allowed when both tenants have enabled cross-tenant transfers.
+func (server *Server) recipientTenant(ctx context.Context, accountID int64, tenantID string) (string, error) {
+	toTenantID, err := server.store.GetAccountTenant(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return "", status.Errorf(codes.NotFound, "account [%d] not found", accountID)
+		}
+		return "", status.Errorf(codes.Internal, "failed to get account tenant: %s", err)
+	}
+
+	if toTenantID == tenantID {
+		return toTenantID, nil
+	}
+
+	for _, id := range []string{tenantID, toTenantID} {
+		tenant, err := server.store.GetTenant(ctx, id)
+		if err != nil {
+			return "", status.Errorf(codes.Internal, "failed to get tenant: %s", err)
+		}
+
+		if !tenant.AllowCrossTenantTransfers {
+			return "", status.Errorf(codes.PermissionDenied, "cross-tenant transfers are not enabled for tenant %s", tenant.ID)
+		}
+	}
+
+	return toTenantID, nil
+}
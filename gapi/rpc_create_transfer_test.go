@@ -0,0 +1,315 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	mockdb "github.com/LamThanhNguyen/banking-system/db/mock"
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/pb"
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/LamThanhNguyen/banking-system/worker"
+	mockwk "github.com/LamThanhNguyen/banking-system/worker/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCreateTransferAPI(t *testing.T) {
+	amount := int64(10)
+
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+
+	account1 := randomAccount(user1.Username)
+	account2 := randomAccount(user2.Username)
+	account3 := randomAccount(user2.Username)
+	account1.Currency = util.USD
+	account2.Currency = util.USD
+	account3.Currency = util.EUR
+
+	frozenAccount := account2
+	frozenAccount.Status = db.AccountStatusFrozen
+
+	testCases := []struct {
+		name          string
+		req           *pb.CreateTransferRequest
+		buildStubs    func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor)
+		checkResponse func(t *testing.T, res *pb.CreateTransferResponse, err error)
+	}{
+		{
+			name: "OK",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        amount,
+				Currency:      util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(db.GetAccountParams{ID: account1.ID, TenantID: util.DefaultTenant})).
+					Times(1).
+					Return(account1, nil)
+				store.EXPECT().
+					GetAccountTenant(gomock.Any(), gomock.Eq(account2.ID)).
+					Times(1).
+					Return(util.DefaultTenant, nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(db.GetAccountParams{ID: account2.ID, TenantID: util.DefaultTenant})).
+					Times(1).
+					Return(account2, nil)
+
+				result := db.TransferTxResult{
+					Transfer:    db.Transfer{ID: 1, FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: amount},
+					FromAccount: account1,
+					ToAccount:   account2,
+				}
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.TransferTxParams) (db.TransferTxResult, error) {
+						require.Equal(t, account1.ID, arg.FromAccountID)
+						require.Equal(t, account2.ID, arg.ToAccountID)
+						require.Equal(t, amount, arg.Amount)
+						return result, arg.AfterTransfer(store, result)
+					})
+				store.EXPECT().
+					CreateOutbox(gomock.Any(), gomock.Any()).
+					Times(2).
+					DoAndReturn(func(_ context.Context, arg db.CreateOutboxParams) (db.Outbox, error) {
+						require.Equal(t, worker.TaskSendTransferNotification, arg.TaskType)
+						return db.Outbox{ID: 1, TaskType: arg.TaskType, Payload: arg.Payload}, nil
+					})
+				store.EXPECT().
+					ListWebhookSubscriptionsForEvent(gomock.Any(), gomock.Any()).
+					Times(2).
+					Return(nil, nil)
+				store.EXPECT().
+					LockAuditEvents(gomock.Any(), gomock.Eq(util.DefaultTenant)).
+					Times(1)
+				store.EXPECT().
+					GetLastAuditEvent(gomock.Any(), gomock.Eq(util.DefaultTenant)).
+					Times(1).
+					Return(db.AuditEvent{}, db.ErrRecordNotFound)
+				store.EXPECT().
+					CreateAuditEvent(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateAuditEventParams) (db.AuditEvent, error) {
+						require.Equal(t, db.AuditActionTransferCreate, arg.Action)
+						require.Equal(t, db.AuditOutcomeSuccess, arg.Outcome)
+						require.Equal(t, user1.Username, arg.Actor)
+						require.NotEmpty(t, arg.ClientIp)
+						return db.AuditEvent{ID: 1}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, amount, res.GetTransfer().GetAmount())
+				require.Equal(t, account1.ID, res.GetFromAccount().GetId())
+				require.Equal(t, account2.ID, res.GetToAccount().GetId())
+			},
+		},
+		{
+			name: "FromAccountNotOwned",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account2.ID,
+				ToAccountId:   account1.ID,
+				Amount:        amount,
+				Currency:      util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(db.GetAccountParams{ID: account2.ID, TenantID: util.DefaultTenant})).
+					Times(1).
+					Return(account2, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					AppendAuditEventTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, event db.AppendAuditEventTxParams) (db.AuditEvent, error) {
+						require.Equal(t, db.AuditOutcomeDenied, event.Outcome)
+						require.Equal(t, codes.PermissionDenied.String(), event.Reason)
+						return db.AuditEvent{}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "ToAccountCurrencyMismatch",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account3.ID,
+				Amount:        amount,
+				Currency:      util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(db.GetAccountParams{ID: account1.ID, TenantID: util.DefaultTenant})).
+					Times(1).
+					Return(account1, nil)
+				store.EXPECT().
+					GetAccountTenant(gomock.Any(), gomock.Eq(account3.ID)).
+					Times(1).
+					Return(util.DefaultTenant, nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(db.GetAccountParams{ID: account3.ID, TenantID: util.DefaultTenant})).
+					Times(1).
+					Return(account3, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "ToAccountFrozen",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        amount,
+				Currency:      util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(db.GetAccountParams{ID: account1.ID, TenantID: util.DefaultTenant})).
+					Times(1).
+					Return(account1, nil)
+				store.EXPECT().
+					GetAccountTenant(gomock.Any(), gomock.Eq(account2.ID)).
+					Times(1).
+					Return(util.DefaultTenant, nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(db.GetAccountParams{ID: account2.ID, TenantID: util.DefaultTenant})).
+					Times(1).
+					Return(frozenAccount, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "TransferTxFrozen",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        amount,
+				Currency:      util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(2).
+					DoAndReturn(func(_ context.Context, arg db.GetAccountParams) (db.Account, error) {
+						if arg.ID == account1.ID {
+							return account1, nil
+						}
+						return account2, nil
+					})
+				store.EXPECT().
+					GetAccountTenant(gomock.Any(), gomock.Eq(account2.ID)).
+					Times(1).
+					Return(util.DefaultTenant, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrAccountFrozen)
+				distributor.EXPECT().
+					DistributeTaskSendTransferFailedNotification(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "InsufficientFunds",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        amount,
+				Currency:      util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(2).
+					DoAndReturn(func(_ context.Context, arg db.GetAccountParams) (db.Account, error) {
+						if arg.ID == account1.ID {
+							return account1, nil
+						}
+						return account2, nil
+					})
+				store.EXPECT().
+					GetAccountTenant(gomock.Any(), gomock.Eq(account2.ID)).
+					Times(1).
+					Return(util.DefaultTenant, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+				distributor.EXPECT().
+					DistributeTaskSendTransferFailedNotification(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+				require.Equal(t, fmt.Sprintf("account [%d] has insufficient funds", account1.ID), status.Convert(err).Message())
+			},
+		},
+		{
+			name: "InvalidAmount",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        -amount,
+				Currency:      util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			distributor := mockwk.NewMockTaskDistributor(ctrl)
+			tc.buildStubs(store, distributor)
+			store.EXPECT().AppendAuditEventTx(gomock.Any(), gomock.Any()).AnyTimes()
+
+			server := newTestServer(t, store, nil, distributor)
+			client := newTestClient(t, server)
+
+			ctx := newContextWithBearerToken(t, server.tokenMaker, user1.Username, user1.Role, time.Minute)
+			res, err := client.CreateTransfer(ctx, tc.req)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/pb"
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/LamThanhNguyen/banking-system/val"
+	"github.com/LamThanhNguyen/banking-system/worker"
+	"github.com/hibiken/asynq"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.CreateUserResponse, error) {
+	violations := validateCreateUserRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	hashedPassword, err := util.HashPassword(req.GetPassword())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to hash password: %s", err)
+	}
+
+	arg := db.CreateUserTxParams{
+		CreateUserParams: db.CreateUserParams{
+			Username:       req.GetUsername(),
+			HashedPassword: hashedPassword,
+			FullName:       req.GetFullName(),
+			Email:          req.GetEmail(),
+			// The caller is not authenticated, so it cannot choose a tenant.
+			TenantID: util.DefaultTenant,
+		},
+		AfterCreate: func(q db.Querier, user db.User) error {
+			return distributeVerifyEmail(ctx, q, user)
+		},
+	}
+
+	txResult, err := server.store.CreateUserTx(ctx, arg)
+	if err != nil {
+		switch db.ErrorCode(err) {
+		case db.UniqueViolation:
+			return nil, status.Error(codes.AlreadyExists, "username or email already exists")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to create user: %s", err)
+	}
+
+	rsp := &pb.CreateUserResponse{
+		User: convertUser(txResult.User),
+	}
+	return rsp, nil
+}
+
+func validateCreateUserRequest(req *pb.CreateUserRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateUsername(req.GetUsername()); err != nil {
+		violations = append(violations, fieldViolation("username", err))
+	}
+
+	if err := val.ValidatePassword(req.GetPassword()); err != nil {
+		violations = append(violations, fieldViolation("password", err))
+	}
+
+	if err := val.ValidateFullname(req.GetFullName()); err != nil {
+		violations = append(violations, fieldViolation("full_name", err))
+	}
+
+	if err := val.ValidateEmail(req.GetEmail()); err != nil {
+		violations = append(violations, fieldViolation("email", err))
+	}
+
+	return violations
+}
+
+// distributeVerifyEmail schedules the verification email through the outbox of the running transaction.
+func distributeVerifyEmail(ctx context.Context, q db.Querier, user db.User) error {
+	taskPayload := &worker.PayloadSendVerifyEmail{
+		Username: user.Username,
+	}
+	opts := []asynq.Option{
+		asynq.MaxRetry(10),
+		asynq.ProcessIn(10 * time.Second),
+		asynq.Queue(worker.QueueCritical),
+	}
+
+	if err := worker.NewOutboxTaskDistributor(q).DistributeTaskSendVerifyEmail(ctx, taskPayload, opts...); err != nil {
+		return fmt.Errorf("failed to distribute verify email task: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/metrics"
+	"github.com/LamThanhNguyen/banking-system/pb"
+	"github.com/LamThanhNguyen/banking-system/token"
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/LamThanhNguyen/banking-system/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (server *Server) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (_ *pb.LoginUserResponse, err error) {
+	violations := validateLoginUserRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	// Unknown usernames have no tenant, so they are recorded in the default one.
+	audit := db.AppendAuditEventTxParams{
+		TenantID:   util.DefaultTenant,
+		Actor:      req.GetUsername(),
+		Action:     db.AuditActionLogin,
+		TargetType: db.AuditTargetUser,
+		TargetID:   req.GetUsername(),
+	}
+	defer func() { server.recordAudit(ctx, &audit, err) }()
+
+	user, err := server.store.GetUser(ctx, req.GetUsername())
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			metrics.ObserveLoginFailure(metrics.LoginUserNotFound)
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to find user")
+	}
+	audit.TenantID = user.TenantID
+	audit.ActorRole = user.Role
+
+	err = util.CheckPassword(req.GetPassword(), user.HashedPassword)
+	if err != nil {
+		metrics.ObserveLoginFailure(metrics.LoginInvalidCredentials)
+		return nil, status.Error(codes.Unauthenticated, "incorrect password")
+	}
+
+	if user.IsDisabled {
+		metrics.ObserveLoginFailure(metrics.LoginUserDisabled)
+		return nil, status.Error(codes.PermissionDenied, "user is disabled")
+	}
+
+	accessToken, accessPayload, err := server.tokenMaker.CreateToken(
+		user.Username,
+		user.Role,
+		user.TenantID,
+		server.config.AccessTokenDurationParsed,
+		token.TokenTypeAccessToken,
+	)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to create access token")
+	}
+
+	refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(
+		user.Username,
+		user.Role,
+		user.TenantID,
+		server.config.RefreshTokenDurationParsed,
+		token.TokenTypeRefreshToken,
+	)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to create refresh token")
+	}
+
+	mtdt := server.extractMetadata(ctx)
+	session, err := server.store.CreateSession(ctx, db.CreateSessionParams{
+		ID:           refreshPayload.ID,
+		Username:     user.Username,
+		RefreshToken: refreshToken,
+		UserAgent:    mtdt.UserAgent,
+		ClientIp:     mtdt.ClientIP,
+		IsBlocked:    false,
+		ExpiresAt:    refreshPayload.ExpiredAt,
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to create session")
+	}
+
+	audit.After = map[string]any{"session_id": session.ID}
+
+	rsp := &pb.LoginUserResponse{
+		User:                  convertUser(user),
+		SessionId:             session.ID.String(),
+		AccessToken:           accessToken,
+		RefreshToken:          refreshToken,
+		AccessTokenExpiresAt:  timestamppb.New(accessPayload.ExpiredAt),
+		RefreshTokenExpiresAt: timestamppb.New(refreshPayload.ExpiredAt),
+	}
+	return rsp, nil
+}
+
+func validateLoginUserRequest(req *pb.LoginUserRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateUsername(req.GetUsername()); err != nil {
+		violations = append(violations, fieldViolation("username", err))
+	}
+
+	if err := val.ValidatePassword(req.GetPassword()); err != nil {
+		violations = append(violations, fieldViolation("password", err))
+	}
+
+	return violations
+}
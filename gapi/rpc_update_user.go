@@ -0,0 +1,124 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/pb"
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/LamThanhNguyen/banking-system/val"
+	"github.com/jackc/pgx/v5/pgtype"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (_ *pb.UpdateUserResponse, err error) {
+	authPayload := authPayload(ctx)
+
+	audit := db.AppendAuditEventTxParams{
+		Action:     db.AuditActionUserUpdate,
+		TargetType: db.AuditTargetUser,
+		TargetID:   req.GetUsername(),
+	}
+	defer func() { server.recordAudit(ctx, &audit, err) }()
+
+	violations := validateUpdateUserRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	if req.FullName == nil && req.Email == nil && req.Password == nil {
+		return nil, status.Error(codes.InvalidArgument, "no fields to update")
+	}
+
+	// bankers may update any user of their tenant, depositors only themselves
+	sub := util.Subject{Role: authPayload.Role, Name: authPayload.Username}
+	obj := util.Object{Name: req.GetUsername()}
+	if err := server.enforce(sub, authPayload.TenantID, obj, "users:update"); err != nil {
+		return nil, err
+	}
+
+	arg := db.UpdateUserParams{
+		Username: req.GetUsername(),
+		TenantID: pgtype.Text{String: authPayload.TenantID, Valid: true},
+		FullName: pgtype.Text{
+			String: req.GetFullName(),
+			Valid:  req.FullName != nil,
+		},
+		Email: pgtype.Text{
+			String: req.GetEmail(),
+			Valid:  req.Email != nil,
+		},
+	}
+
+	if req.Password != nil {
+		hashedPassword, err := util.HashPassword(req.GetPassword())
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to hash password: %s", err)
+		}
+
+		arg.HashedPassword = pgtype.Text{
+			String: hashedPassword,
+			Valid:  true,
+		}
+
+		arg.PasswordChangedAt = pgtype.Timestamptz{
+			Time:  time.Now(),
+			Valid: true,
+		}
+	}
+
+	txResult, err := server.store.UpdateUserTx(ctx, db.UpdateUserTxParams{
+		UpdateUserParams: arg,
+		AfterEmailChange: func(q db.Querier, user db.User) error {
+			return distributeVerifyEmail(ctx, q, user)
+		},
+		AfterUpdate: func(q db.Querier, result db.UpdateUserTxResult) error {
+			audit.Before, audit.After = db.AuditUserChanges(result.Before, result.User)
+			return server.appendAudit(ctx, q, &audit)
+		},
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrRecordNotFound):
+			return nil, status.Error(codes.NotFound, "user not found")
+		case db.ErrorCode(err) == db.UniqueViolation:
+			return nil, status.Error(codes.AlreadyExists, "email already exists")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to update user: %s", err)
+	}
+
+	rsp := &pb.UpdateUserResponse{
+		User: convertUser(txResult.User),
+	}
+	return rsp, nil
+}
+
+func validateUpdateUserRequest(req *pb.UpdateUserRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateUsername(req.GetUsername()); err != nil {
+		violations = append(violations, fieldViolation("username", err))
+	}
+
+	if req.Password != nil {
+		if err := val.ValidatePassword(req.GetPassword()); err != nil {
+			violations = append(violations, fieldViolation("password", err))
+		}
+	}
+
+	if req.FullName != nil {
+		if err := val.ValidateFullname(req.GetFullName()); err != nil {
+			violations = append(violations, fieldViolation("full_name", err))
+		}
+	}
+
+	if req.Email != nil {
+		if err := val.ValidateEmail(req.GetEmail()); err != nil {
+			violations = append(violations, fieldViolation("email", err))
+		}
+	}
+
+	return violations
+}
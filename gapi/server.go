@@ -0,0 +1,87 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/pb"
+	"github.com/LamThanhNguyen/banking-system/token"
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/LamThanhNguyen/banking-system/worker"
+	"github.com/casbin/casbin/v2"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Server serves gRPC requests for the banking service.
+type Server struct {
+	pb.UnimplementedBankingSystemServer
+	config          util.RuntimeConfig
+	store           db.Store
+	enforcer        *casbin.Enforcer
+	tokenMaker      token.Maker
+	taskDistributor worker.TaskDistributor
+}
+
+// NewServer creates a new gRPC server.
+func NewServer(
+	config util.RuntimeConfig,
+	store db.Store,
+	enforcer *casbin.Enforcer,
+	taskDistributor worker.TaskDistributor,
+) (*Server, error) {
+	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create token maker: %w", err)
+	}
+
+	return &Server{
+		config:          config,
+		store:           store,
+		enforcer:        enforcer,
+		tokenMaker:      tokenMaker,
+		taskDistributor: taskDistributor,
+	}, nil
+}
+
+// GRPCServer returns a grpc.Server with the service registered behind the
+// logging and authorization interceptors.
+func (server *Server) GRPCServer() *grpc.Server {
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			GrpcLogger,
+			server.authInterceptor,
+		),
+	)
+	pb.RegisterBankingSystemServer(grpcServer, server)
+	reflection.Register(grpcServer)
+	return grpcServer
+}
+
+// NewGatewayHandler returns the grpc-gateway REST handler. It forwards every
+// request to the gRPC server at grpcAddress, so the same interceptors apply.
+func NewGatewayHandler(ctx context.Context, grpcAddress string) (http.Handler, error) {
+	jsonOption := runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+		MarshalOptions: protojson.MarshalOptions{
+			UseProtoNames:   true,
+			EmitUnpopulated: true,
+		},
+		UnmarshalOptions: protojson.UnmarshalOptions{
+			DiscardUnknown: true,
+		},
+	})
+	grpcMux := runtime.NewServeMux(jsonOption)
+
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	err := pb.RegisterBankingSystemHandlerFromEndpoint(ctx, grpcMux, grpcAddress, opts)
+	if err != nil {
+		return nil, fmt.Errorf("cannot register gateway handler: %w", err)
+	}
+
+	return HttpLogger(grpcMux), nil
+}
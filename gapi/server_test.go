@@ -0,0 +1,81 @@
+package gapi
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	mockdb "github.com/LamThanhNguyen/banking-system/db/mock"
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/token"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGatewayHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetAccount(gomock.Any(), gomock.Eq(db.GetAccountParams{ID: account.ID, TenantID: account.TenantID})).
+		Times(1).
+		Return(account, nil)
+
+	server := newTestServer(t, store, nil, nil)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	grpcServer := server.GRPCServer()
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	handler, err := NewGatewayHandler(ctx, listener.Addr().String())
+	require.NoError(t, err)
+
+	accessToken, _, err := server.tokenMaker.CreateToken(user.Username, user.Role, user.TenantID, server.config.AccessTokenDurationParsed, token.TokenTypeAccessToken)
+	require.NoError(t, err)
+
+	// the gateway forwards the header as metadata, so the interceptors authorize it
+	for _, tc := range []struct {
+		name  string
+		token string
+		code  int
+	}{
+		{name: "OK", token: "Bearer " + accessToken, code: http.StatusOK},
+		{name: "NoAuthorization", code: http.StatusUnauthorized},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/v1/accounts/"+strconv.FormatInt(account.ID, 10), nil)
+			if tc.token != "" {
+				request.Header.Set("Authorization", tc.token)
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+			require.Equal(t, tc.code, recorder.Code, recorder.Body.String())
+
+			if tc.code != http.StatusOK {
+				return
+			}
+			var body struct {
+				Account struct {
+					ID    string `json:"id"`
+					Owner string `json:"owner"`
+				} `json:"account"`
+			}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+			require.Equal(t, strconv.FormatInt(account.ID, 10), body.Account.ID)
+			require.Equal(t, account.Owner, body.Account.Owner)
+		})
+	}
+}
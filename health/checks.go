@@ -0,0 +1,58 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/redis/go-redis/v9"
+)
+
+// Pinger is implemented by pgxpool.Pool.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Ping checks that a connection can be acquired and used.
+func Ping(pinger Pinger) CheckFunc {
+	return pinger.Ping
+}
+
+// Redis checks that Redis answers a PING.
+func Redis(client redis.UniversalClient) CheckFunc {
+	return func(ctx context.Context) error {
+		return client.Ping(ctx).Err()
+	}
+}
+
+// RowQuerier is implemented by pgxpool.Pool.
+type RowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// MigrationVersion checks that the database has every migration the binary
+// was built with, which golang-migrate records in schema_migrations. A newer
+// version is accepted, because migrations must stay compatible with the
+// previous release during a rollout.
+func MigrationVersion(db RowQuerier, expected uint) CheckFunc {
+	return func(ctx context.Context) error {
+		var version int64
+		var dirty bool
+		err := db.QueryRow(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("no migration applied, want version %d", expected)
+		}
+		if err != nil {
+			return fmt.Errorf("cannot read migration version: %w", err)
+		}
+
+		if dirty {
+			return fmt.Errorf("migration %d failed and is dirty", version)
+		}
+		if version < int64(expected) {
+			return fmt.Errorf("migration version %d is behind %d", version, expected)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,179 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func probe(t *testing.T, handler http.Handler) (int, Report) {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	var report Report
+	require.NoError(t, json.NewDecoder(recorder.Body).Decode(&report))
+	return recorder.Code, report
+}
+
+func TestReadyHandler(t *testing.T) {
+	testCases := []struct {
+		name          string
+		setupChecker  func(checker *Checker)
+		checkResponse func(t *testing.T, status int, report Report)
+	}{
+		{
+			name: "OK",
+			setupChecker: func(checker *Checker) {
+				checker.Add("postgres", func(ctx context.Context) error {
+					time.Sleep(5 * time.Millisecond)
+					return nil
+				})
+				checker.Add("redis", func(ctx context.Context) error { return nil })
+			},
+			checkResponse: func(t *testing.T, status int, report Report) {
+				require.Equal(t, http.StatusOK, status)
+				require.Equal(t, StatusOK, report.Status)
+				require.Len(t, report.Checks, 2)
+				require.Equal(t, StatusOK, report.Checks["postgres"].Status)
+				require.GreaterOrEqual(t, report.Checks["postgres"].LatencyMS, 5.0)
+			},
+		},
+		{
+			name: "CheckFailed",
+			setupChecker: func(checker *Checker) {
+				checker.Add("postgres", func(ctx context.Context) error { return nil })
+				checker.Add("redis", func(ctx context.Context) error { return errors.New("connection refused") })
+			},
+			checkResponse: func(t *testing.T, status int, report Report) {
+				require.Equal(t, http.StatusServiceUnavailable, status)
+				require.Equal(t, StatusFailed, report.Status)
+				require.Equal(t, StatusOK, report.Checks["postgres"].Status)
+				require.Equal(t, StatusFailed, report.Checks["redis"].Status)
+				require.Equal(t, "connection refused", report.Checks["redis"].Error)
+			},
+		},
+		{
+			name: "CheckTimedOut",
+			setupChecker: func(checker *Checker) {
+				checker.Add("postgres", func(ctx context.Context) error {
+					<-ctx.Done()
+					return ctx.Err()
+				})
+			},
+			checkResponse: func(t *testing.T, status int, report Report) {
+				require.Equal(t, http.StatusServiceUnavailable, status)
+				require.Equal(t, context.DeadlineExceeded.Error(), report.Checks["postgres"].Error)
+			},
+		},
+		{
+			name: "ShuttingDown",
+			setupChecker: func(checker *Checker) {
+				checker.Add("postgres", func(ctx context.Context) error { return nil })
+				checker.Shutdown()
+			},
+			checkResponse: func(t *testing.T, status int, report Report) {
+				require.Equal(t, http.StatusServiceUnavailable, status)
+				require.Equal(t, StatusShuttingDown, report.Status)
+				require.Empty(t, report.Checks)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			checker := NewChecker(50 * time.Millisecond)
+			tc.setupChecker(checker)
+
+			status, report := probe(t, checker.ReadyHandler())
+			tc.checkResponse(t, status, report)
+		})
+	}
+}
+
+func TestLiveHandler(t *testing.T) {
+	checker := NewChecker(time.Second)
+	checker.Add("postgres", func(ctx context.Context) error { return errors.New("connection refused") })
+	checker.Shutdown()
+
+	// Liveness ignores the dependencies, so a broken database does not restart the pod.
+	status, report := probe(t, checker.LiveHandler())
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, StatusOK, report.Status)
+}
+
+type fakeRow struct {
+	version int64
+	dirty   bool
+	err     error
+}
+
+func (r fakeRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	*dest[0].(*int64) = r.version
+	*dest[1].(*bool) = r.dirty
+	return nil
+}
+
+type fakeQuerier struct {
+	row fakeRow
+}
+
+func (q fakeQuerier) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return q.row
+}
+
+func TestMigrationVersion(t *testing.T) {
+	testCases := []struct {
+		name     string
+		row      fakeRow
+		expected string
+	}{
+		{
+			name: "UpToDate",
+			row:  fakeRow{version: 19},
+		},
+		{
+			name: "Newer",
+			row:  fakeRow{version: 20},
+		},
+		{
+			name:     "Behind",
+			row:      fakeRow{version: 18},
+			expected: "migration version 18 is behind 19",
+		},
+		{
+			name:     "Dirty",
+			row:      fakeRow{version: 19, dirty: true},
+			expected: "migration 19 failed and is dirty",
+		},
+		{
+			name:     "NoMigration",
+			row:      fakeRow{err: pgx.ErrNoRows},
+			expected: "no migration applied, want version 19",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			err := MigrationVersion(fakeQuerier{row: tc.row}, 19)(context.Background())
+			if tc.expected == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tc.expected)
+		})
+	}
+}
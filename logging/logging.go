@@ -0,0 +1,59 @@
+// Package logging ties the logs of a request together. Every HTTP request
+// gets a request ID, which is carried by a zerolog logger stored in its
+// context and by the headers of the tasks it enqueues, so that the logs of
+// the handler and of the worker running those tasks can be correlated.
+package logging
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// RequestIDHeader is the HTTP header that carries the request ID.
+	RequestIDHeader = "X-Request-ID"
+	// RequestIDKey is the log field and task header that carry the request ID.
+	RequestIDKey = "request_id"
+)
+
+// requestID bounds the request IDs accepted from callers, so that a header
+// cannot inject arbitrary text into the logs.
+var requestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+func init() {
+	// log.Ctx falls back to the global logger outside a request, such as in
+	// the scheduler, instead of dropping the entries.
+	zerolog.DefaultContextLogger = &log.Logger
+	// Errors often quote the input that caused them, such as the email of a
+	// duplicate user.
+	zerolog.ErrorMarshalFunc = func(err error) any {
+		return Redact(err.Error())
+	}
+}
+
+type requestIDKey struct{}
+
+// NewRequestID returns a random request ID.
+func NewRequestID() string {
+	return uuid.NewString()
+}
+
+// ValidRequestID reports whether id, sent by a caller, can be used as a request ID.
+func ValidRequestID(id string) bool {
+	return requestID.MatchString(id)
+}
+
+// WithRequestID returns a copy of ctx that carries the request ID.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, if any.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
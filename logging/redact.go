@@ -0,0 +1,94 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Redacted replaces secrets that are left out of the logs entirely.
+const Redacted = "[REDACTED]"
+
+var (
+	emailAddress   = regexp.MustCompile(`([A-Za-z0-9._%+-])[A-Za-z0-9._%+-]*@([A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,})`)
+	pasetoToken    = regexp.MustCompile(`v[1-4]\.(?:local|public)\.[A-Za-z0-9_-]+(?:\.[A-Za-z0-9_-]+)?`)
+	bearerToken    = regexp.MustCompile(`(?i)(bearer\s+)\S+`)
+	urlCredentials = regexp.MustCompile(`(://[^:/@\s]+):[^@/\s]+@`)
+)
+
+// sensitiveKeys are the parts of JSON keys whose string values are always
+// redacted, compared after lowering the key and removing _ and -.
+var sensitiveKeys = []string{
+	"password",
+	"secret",
+	"token",
+	"apikey",
+	"privatekey",
+	"phone",
+}
+
+// Redact masks the personal data and credentials found in s: email
+// addresses keep their first letter and domain, while PASETO and bearer
+// tokens and the passwords of URLs, such as DB_SOURCE, are removed.
+func Redact(s string) string {
+	s = emailAddress.ReplaceAllString(s, "$1***@$2")
+	s = pasetoToken.ReplaceAllString(s, Redacted)
+	s = bearerToken.ReplaceAllString(s, "${1}"+Redacted)
+	return urlCredentials.ReplaceAllString(s, "$1:"+Redacted+"@")
+}
+
+// RedactJSON returns a copy of the JSON document data, such as a task
+// payload, that is safe to log. The string values of sensitive keys are
+// removed and the other strings go through Redact. Data that is not JSON is
+// redacted as text and returned as a JSON string, so the result can always be
+// logged with RawJSON.
+func RedactJSON(data []byte) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var doc any
+	if err := decoder.Decode(&doc); err != nil {
+		doc = string(data)
+	}
+
+	redacted, err := json.Marshal(redactValue(doc))
+	if err != nil {
+		return []byte(strconv.Quote(Redacted))
+	}
+	return redacted
+}
+
+func redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if s, ok := field.(string); ok && s != "" && isSensitiveKey(key) {
+				v[key] = Redacted
+				continue
+			}
+			v[key] = redactValue(field)
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+		return v
+	case string:
+		return Redact(v)
+	default:
+		return v
+	}
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(key))
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
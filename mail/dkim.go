@@ -0,0 +1,95 @@
+package mail
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/emersion/go-msgauth/dkim"
+)
+
+// DKIMConfig enables DKIM signing of outgoing emails.
+// PrivateKey is either a PEM encoded RSA or Ed25519 key, or the path of a file holding one.
+type DKIMConfig struct {
+	Domain     string
+	Selector   string
+	PrivateKey string
+}
+
+// dkimSigner signs raw messages. A nil signer leaves messages untouched.
+type dkimSigner struct {
+	options *dkim.SignOptions
+}
+
+func newDKIMSigner(config DKIMConfig) (*dkimSigner, error) {
+	if config.Domain == "" {
+		return nil, nil
+	}
+	if config.Selector == "" || config.PrivateKey == "" {
+		return nil, errors.New("dkim selector and private key are required when dkim domain is set")
+	}
+
+	keyPEM := []byte(config.PrivateKey)
+	if !strings.HasPrefix(strings.TrimSpace(config.PrivateKey), "-----BEGIN") {
+		data, err := os.ReadFile(config.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read dkim private key: %w", err)
+		}
+		keyPEM = data
+	}
+
+	key, err := parsePrivateKey(keyPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dkimSigner{
+		options: &dkim.SignOptions{
+			Domain:   config.Domain,
+			Selector: config.Selector,
+			Signer:   key,
+			HeaderKeys: []string{
+				"From", "To", "Cc", "Subject", "Date", "Message-Id", "Mime-Version", "Content-Type",
+			},
+		},
+	}, nil
+}
+
+func (signer *dkimSigner) sign(msg []byte) ([]byte, error) {
+	if signer == nil {
+		return msg, nil
+	}
+
+	var signed bytes.Buffer
+	if err := dkim.Sign(&signed, bytes.NewReader(msg), signer.options); err != nil {
+		return nil, fmt.Errorf("failed to sign email with dkim: %w", err)
+	}
+	return signed.Bytes(), nil
+}
+
+func parsePrivateKey(keyPEM []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, errors.New("dkim private key is not PEM encoded")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse dkim private key: %w", err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("unsupported dkim private key type")
+	}
+	return signer, nil
+}
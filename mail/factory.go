@@ -0,0 +1,70 @@
+package mail
+
+import (
+	"fmt"
+
+	"github.com/LamThanhNguyen/banking-system/util"
+)
+
+// Email transports selectable with EMAIL_TRANSPORT
+const (
+	TransportGmail   = "gmail"
+	TransportSMTP    = "smtp"
+	TransportMailHog = "mailhog"
+	TransportMaildir = "maildir"
+	TransportMemory  = "memory"
+)
+
+const (
+	mailHogHost = "localhost"
+	mailHogPort = 1025
+)
+
+// NewEmailSender creates the EmailSender selected by config.EmailTransport.
+// An empty transport keeps the Gmail sender for backward compatibility.
+func NewEmailSender(config util.Config) (EmailSender, error) {
+	dkimConfig := DKIMConfig{
+		Domain:     config.DKIMDomain,
+		Selector:   config.DKIMSelector,
+		PrivateKey: config.DKIMPrivateKey,
+	}
+
+	switch config.EmailTransport {
+	case "", TransportGmail:
+		return NewGmailSender(config.EmailSenderName, config.EmailSenderAddress, config.EmailSenderPassword), nil
+
+	case TransportSMTP:
+		return NewSMTPSender(config.EmailSenderName, config.EmailSenderAddress, SMTPConfig{
+			Host:     config.SMTPHost,
+			Port:     config.SMTPPort,
+			Username: config.SMTPUsername,
+			Password: config.SMTPPassword,
+			TLSMode:  config.SMTPTLSMode,
+			DKIM:     dkimConfig,
+		})
+
+	case TransportMailHog:
+		smtpConfig := SMTPConfig{
+			Host:    config.SMTPHost,
+			Port:    config.SMTPPort,
+			TLSMode: TLSModeNone,
+			DKIM:    dkimConfig,
+		}
+		if smtpConfig.Host == "" {
+			smtpConfig.Host = mailHogHost
+		}
+		if smtpConfig.Port == 0 {
+			smtpConfig.Port = mailHogPort
+		}
+		return NewSMTPSender(config.EmailSenderName, config.EmailSenderAddress, smtpConfig)
+
+	case TransportMaildir:
+		return NewMaildirSender(config.EmailSenderName, config.EmailSenderAddress, config.EmailMaildir, dkimConfig)
+
+	case TransportMemory:
+		return NewMemorySender(), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported email transport %q", config.EmailTransport)
+	}
+}
@@ -0,0 +1,83 @@
+package mail
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+// MaildirSender writes every email into a Maildir instead of sending it,
+// so that local development can read them with any mail client.
+type MaildirSender struct {
+	name             string
+	fromEmailAddress string
+	dir              string
+	hostname         string
+	dkim             *dkimSigner
+	seq              atomic.Uint64
+}
+
+func NewMaildirSender(name string, fromEmailAddress string, dir string, dkimConfig DKIMConfig) (EmailSender, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("maildir directory is required")
+	}
+	for _, sub := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create maildir: %w", err)
+		}
+	}
+
+	signer, err := newDKIMSigner(dkimConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "localhost"
+	}
+
+	return &MaildirSender{
+		name:             name,
+		fromEmailAddress: fromEmailAddress,
+		dir:              dir,
+		hostname:         hostname,
+		dkim:             signer,
+	}, nil
+}
+
+func (sender *MaildirSender) SendEmail(
+	subject string,
+	content Content,
+	to []string,
+	cc []string,
+	bcc []string,
+	attachFiles []string,
+) error {
+	e, err := newEmail(sender.name, sender.fromEmailAddress, subject, content, to, cc, bcc, attachFiles)
+	if err != nil {
+		return err
+	}
+
+	msg, err := e.Bytes()
+	if err != nil {
+		return fmt.Errorf("failed to build email: %w", err)
+	}
+	msg, err = sender.dkim.sign(msg)
+	if err != nil {
+		return err
+	}
+
+	// Files are written to tmp and moved to new, so readers never see partial emails.
+	filename := fmt.Sprintf("%d.%d_%d.%s", time.Now().UnixNano(), os.Getpid(), sender.seq.Add(1), sender.hostname)
+	tmpPath := filepath.Join(sender.dir, "tmp", filename)
+	if err := os.WriteFile(tmpPath, msg, 0o644); err != nil {
+		return fmt.Errorf("failed to write email: %w", err)
+	}
+	if err := os.Rename(tmpPath, filepath.Join(sender.dir, "new", filename)); err != nil {
+		return fmt.Errorf("failed to deliver email to maildir: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package mail
+
+import "sync"
+
+// Message is an email recorded by MemorySender.
+type Message struct {
+	Subject     string
+	Content     Content
+	To          []string
+	Cc          []string
+	Bcc         []string
+	AttachFiles []string
+}
+
+// MemorySender keeps sent emails in memory so that tests can inspect them.
+type MemorySender struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func NewMemorySender() *MemorySender {
+	return &MemorySender{}
+}
+
+func (sender *MemorySender) SendEmail(
+	subject string,
+	content Content,
+	to []string,
+	cc []string,
+	bcc []string,
+	attachFiles []string,
+) error {
+	sender.mu.Lock()
+	defer sender.mu.Unlock()
+
+	sender.messages = append(sender.messages, Message{
+		Subject:     subject,
+		Content:     content,
+		To:          to,
+		Cc:          cc,
+		Bcc:         bcc,
+		AttachFiles: attachFiles,
+	})
+	return nil
+}
+
+// Messages returns the recorded emails in the order they were sent.
+func (sender *MemorySender) Messages() []Message {
+	sender.mu.Lock()
+	defer sender.mu.Unlock()
+
+	return append([]Message(nil), sender.messages...)
+}
+
+// SentTo returns the recorded emails addressed to the given recipient.
+func (sender *MemorySender) SentTo(address string) []Message {
+	var messages []Message
+	for _, msg := range sender.Messages() {
+		for _, rcpt := range recipients(msg.To, msg.Cc, msg.Bcc) {
+			if rcpt == address {
+				messages = append(messages, msg)
+				break
+			}
+		}
+	}
+	return messages
+}
+
+// Reset forgets all recorded emails.
+func (sender *MemorySender) Reset() {
+	sender.mu.Lock()
+	defer sender.mu.Unlock()
+
+	sender.messages = nil
+}
@@ -12,10 +12,16 @@ const (
 	smtpServerAddress = "smtp.gmail.com:587"
 )
 
+// Content is the body of an email. Text is sent as the plain-text alternative when set.
+type Content struct {
+	HTML string
+	Text string
+}
+
 type EmailSender interface {
 	SendEmail(
 		subject string,
-		content string,
+		content Content,
 		to []string,
 		cc []string,
 		bcc []string,
@@ -39,16 +45,36 @@ func NewGmailSender(name string, fromEmailAddress string, fromEmailPassword stri
 
 func (sender *GmailSender) SendEmail(
 	subject string,
-	content string,
+	content Content,
 	to []string,
 	cc []string,
 	bcc []string,
 	attachFiles []string,
 ) error {
+	e, err := newEmail(sender.name, sender.fromEmailAddress, subject, content, to, cc, bcc, attachFiles)
+	if err != nil {
+		return err
+	}
+
+	smtpAuth := smtp.PlainAuth("", sender.fromEmailAddress, sender.fromEmailPassword, smtpAuthAddress)
+	return e.Send(smtpServerAddress, smtpAuth)
+}
+
+func newEmail(
+	name string,
+	fromEmailAddress string,
+	subject string,
+	content Content,
+	to []string,
+	cc []string,
+	bcc []string,
+	attachFiles []string,
+) (*email.Email, error) {
 	e := email.NewEmail()
-	e.From = fmt.Sprintf("%s <%s>", sender.name, sender.fromEmailAddress)
+	e.From = fmt.Sprintf("%s <%s>", name, fromEmailAddress)
 	e.Subject = subject
-	e.HTML = []byte(content)
+	e.HTML = []byte(content.HTML)
+	e.Text = []byte(content.Text)
 	e.To = to
 	e.Cc = cc
 	e.Bcc = bcc
@@ -56,10 +82,14 @@ func (sender *GmailSender) SendEmail(
 	for _, f := range attachFiles {
 		_, err := e.AttachFile(f)
 		if err != nil {
-			return fmt.Errorf("failed to attach file %s: %w", f, err)
+			return nil, fmt.Errorf("failed to attach file %s: %w", f, err)
 		}
 	}
+	return e, nil
+}
 
-	smtpAuth := smtp.PlainAuth("", sender.fromEmailAddress, sender.fromEmailPassword, smtpAuthAddress)
-	return e.Send(smtpServerAddress, smtpAuth)
+// recipients merges the To, Cc and Bcc addresses of an email.
+func recipients(to []string, cc []string, bcc []string) []string {
+	rcpts := make([]string, 0, len(to)+len(cc)+len(bcc))
+	return append(append(append(rcpts, to...), cc...), bcc...)
 }
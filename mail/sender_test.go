@@ -0,0 +1,293 @@
+package mail
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/emersion/go-msgauth/dkim"
+	"github.com/stretchr/testify/require"
+)
+
+var testContent = Content{HTML: "<p>Hello</p>", Text: "Hello"}
+
+// fakeSMTPServer accepts a single plain SMTP session and records its envelope and data.
+type fakeSMTPServer struct {
+	listener net.Listener
+	rcpts    chan []string
+	data     chan string
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	server := &fakeSMTPServer{
+		listener: listener,
+		rcpts:    make(chan []string, 1),
+		data:     make(chan string, 1),
+	}
+	go server.serve()
+	return server
+}
+
+func (server *fakeSMTPServer) port() int {
+	return server.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (server *fakeSMTPServer) serve() {
+	conn, err := server.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+
+	var rcpts []string
+	reply("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(cmd, "MAIL FROM"):
+			reply("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO"):
+			rcpts = append(rcpts, strings.Trim(strings.TrimSpace(line)[len("RCPT TO:"):], "<>"))
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			server.rcpts <- rcpts
+			server.data <- data.String()
+			reply("250 OK")
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+func TestSMTPSender(t *testing.T) {
+	server := newFakeSMTPServer(t)
+
+	sender, err := NewSMTPSender("Banking System", "noreply@example.com", SMTPConfig{
+		Host:    "127.0.0.1",
+		Port:    server.port(),
+		TLSMode: TLSModeNone,
+	})
+	require.NoError(t, err)
+
+	err = sender.SendEmail("A test email", testContent, []string{"to@example.com"}, []string{"cc@example.com"}, []string{"bcc@example.com"}, nil)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"to@example.com", "cc@example.com", "bcc@example.com"}, <-server.rcpts)
+	data := <-server.data
+	require.Contains(t, data, "Subject: A test email")
+	require.Contains(t, data, "text/plain")
+	require.Contains(t, data, "text/html")
+	require.NotContains(t, data, "bcc@example.com")
+}
+
+func TestSMTPSenderRequiresStartTLS(t *testing.T) {
+	server := newFakeSMTPServer(t)
+
+	sender, err := NewSMTPSender("Banking System", "noreply@example.com", SMTPConfig{
+		Host:    "127.0.0.1",
+		Port:    server.port(),
+		TLSMode: TLSModeStartTLS,
+	})
+	require.NoError(t, err)
+
+	err = sender.SendEmail("A test email", testContent, []string{"to@example.com"}, nil, nil, nil)
+	require.ErrorContains(t, err, "does not support STARTTLS")
+}
+
+func TestMaildirSenderWithDKIM(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	dir := t.TempDir()
+	sender, err := NewMaildirSender("Banking System", "noreply@example.com", dir, DKIMConfig{
+		Domain:     "example.com",
+		Selector:   "mail",
+		PrivateKey: string(keyPEM),
+	})
+	require.NoError(t, err)
+
+	err = sender.SendEmail("A signed email", testContent, []string{"to@example.com"}, nil, nil, nil)
+	require.NoError(t, err)
+
+	files, err := os.ReadDir(filepath.Join(dir, "new"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	msg, err := os.ReadFile(filepath.Join(dir, "new", files[0].Name()))
+	require.NoError(t, err)
+
+	pub, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	verifications, err := dkim.VerifyWithOptions(bytes.NewReader(msg), &dkim.VerifyOptions{
+		LookupTXT: func(domain string) ([]string, error) {
+			require.Equal(t, "mail._domainkey.example.com", domain)
+			return []string{"v=DKIM1; k=rsa; p=" + base64.StdEncoding.EncodeToString(pub)}, nil
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, verifications, 1)
+	require.NoError(t, verifications[0].Err)
+}
+
+func TestMemorySender(t *testing.T) {
+	sender := NewMemorySender()
+
+	require.NoError(t, sender.SendEmail("first", testContent, []string{"a@example.com"}, nil, nil, nil))
+	require.NoError(t, sender.SendEmail("second", testContent, []string{"b@example.com"}, nil, []string{"a@example.com"}, nil))
+
+	require.Len(t, sender.Messages(), 2)
+	require.Len(t, sender.SentTo("a@example.com"), 2)
+	require.Equal(t, "second", sender.SentTo("b@example.com")[0].Subject)
+
+	sender.Reset()
+	require.Empty(t, sender.Messages())
+}
+
+func TestNewEmailSender(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config util.Config
+		check  func(t *testing.T, sender EmailSender, err error)
+	}{
+		{
+			name:   "DefaultGmail",
+			config: util.Config{},
+			check: func(t *testing.T, sender EmailSender, err error) {
+				require.NoError(t, err)
+				require.IsType(t, &GmailSender{}, sender)
+			},
+		},
+		{
+			name:   "MailHog",
+			config: util.Config{EmailTransport: TransportMailHog},
+			check: func(t *testing.T, sender EmailSender, err error) {
+				require.NoError(t, err)
+				smtpSender := sender.(*SMTPSender)
+				require.Equal(t, mailHogHost, smtpSender.config.Host)
+				require.Equal(t, mailHogPort, smtpSender.config.Port)
+				require.Equal(t, TLSModeNone, smtpSender.config.TLSMode)
+			},
+		},
+		{
+			name: "SMTPDefaultsToStartTLS",
+			config: util.Config{
+				EmailTransport: TransportSMTP,
+				SMTPHost:       "smtp.example.com",
+				SMTPPort:       587,
+			},
+			check: func(t *testing.T, sender EmailSender, err error) {
+				require.NoError(t, err)
+				require.Equal(t, TLSModeStartTLS, sender.(*SMTPSender).config.TLSMode)
+			},
+		},
+		{
+			name:   "SMTPMissingHost",
+			config: util.Config{EmailTransport: TransportSMTP},
+			check: func(t *testing.T, sender EmailSender, err error) {
+				require.Error(t, err)
+			},
+		},
+		{
+			name: "InvalidTLSMode",
+			config: util.Config{
+				EmailTransport: TransportSMTP,
+				SMTPHost:       "smtp.example.com",
+				SMTPPort:       25,
+				SMTPTLSMode:    "ssl3",
+			},
+			check: func(t *testing.T, sender EmailSender, err error) {
+				require.Error(t, err)
+			},
+		},
+		{
+			name: "DKIMWithoutKey",
+			config: util.Config{
+				EmailTransport: TransportMailHog,
+				DKIMDomain:     "example.com",
+			},
+			check: func(t *testing.T, sender EmailSender, err error) {
+				require.Error(t, err)
+			},
+		},
+		{
+			name:   "Memory",
+			config: util.Config{EmailTransport: TransportMemory},
+			check: func(t *testing.T, sender EmailSender, err error) {
+				require.NoError(t, err)
+				require.IsType(t, &MemorySender{}, sender)
+			},
+		},
+		{
+			name:   "Unknown",
+			config: util.Config{EmailTransport: "carrier-pigeon"},
+			check: func(t *testing.T, sender EmailSender, err error) {
+				require.Error(t, err)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			sender, err := NewEmailSender(tc.config)
+			tc.check(t, sender, err)
+		})
+	}
+}
+
+func TestMaildirRequiresDir(t *testing.T) {
+	_, err := NewEmailSender(util.Config{EmailTransport: TransportMaildir})
+	require.Error(t, err)
+
+	dir := filepath.Join(t.TempDir(), "maildir")
+	sender, err := NewEmailSender(util.Config{EmailTransport: TransportMaildir, EmailMaildir: dir})
+	require.NoError(t, err)
+	require.NotNil(t, sender)
+
+	for _, sub := range []string{"tmp", "new", "cur"} {
+		info, err := os.Stat(filepath.Join(dir, sub))
+		require.NoError(t, err)
+		require.True(t, info.IsDir(), strconv.Quote(sub))
+	}
+}
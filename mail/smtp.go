@@ -0,0 +1,159 @@
+package mail
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// TLS modes of the SMTP sender
+const (
+	TLSModeNone     = "none"     // plain connection, e.g. MailHog
+	TLSModeStartTLS = "starttls" // upgrade with STARTTLS, usually port 587
+	TLSModeTLS      = "tls"      // implicit TLS, usually port 465
+)
+
+const smtpTimeout = 30 * time.Second
+
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	TLSMode  string
+	DKIM     DKIMConfig
+}
+
+// SMTPSender delivers emails to any SMTP server.
+type SMTPSender struct {
+	name             string
+	fromEmailAddress string
+	config           SMTPConfig
+	dkim             *dkimSigner
+}
+
+func NewSMTPSender(name string, fromEmailAddress string, config SMTPConfig) (EmailSender, error) {
+	switch config.TLSMode {
+	case "":
+		config.TLSMode = TLSModeStartTLS
+	case TLSModeNone, TLSModeStartTLS, TLSModeTLS:
+	default:
+		return nil, fmt.Errorf("unsupported smtp tls mode %q", config.TLSMode)
+	}
+	if config.Host == "" || config.Port == 0 {
+		return nil, fmt.Errorf("smtp host and port are required")
+	}
+
+	signer, err := newDKIMSigner(config.DKIM)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SMTPSender{
+		name:             name,
+		fromEmailAddress: fromEmailAddress,
+		config:           config,
+		dkim:             signer,
+	}, nil
+}
+
+func (sender *SMTPSender) SendEmail(
+	subject string,
+	content Content,
+	to []string,
+	cc []string,
+	bcc []string,
+	attachFiles []string,
+) error {
+	e, err := newEmail(sender.name, sender.fromEmailAddress, subject, content, to, cc, bcc, attachFiles)
+	if err != nil {
+		return err
+	}
+
+	msg, err := e.Bytes()
+	if err != nil {
+		return fmt.Errorf("failed to build email: %w", err)
+	}
+	msg, err = sender.dkim.sign(msg)
+	if err != nil {
+		return err
+	}
+
+	client, err := sender.dial()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if sender.config.Username != "" {
+		auth := smtp.PlainAuth("", sender.config.Username, sender.config.Password, sender.config.Host)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("failed to authenticate to smtp server: %w", err)
+		}
+	}
+
+	if err := client.Mail(sender.fromEmailAddress); err != nil {
+		return fmt.Errorf("failed to set sender: %w", err)
+	}
+	for _, rcpt := range recipients(to, cc, bcc) {
+		if err := client.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("failed to add recipient %s: %w", rcpt, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to start data: %w", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return fmt.Errorf("failed to write email: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return client.Quit()
+}
+
+func (sender *SMTPSender) dial() (*smtp.Client, error) {
+	addr := net.JoinHostPort(sender.config.Host, strconv.Itoa(sender.config.Port))
+	tlsConfig := &tls.Config{ServerName: sender.config.Host}
+	dialer := &net.Dialer{Timeout: smtpTimeout}
+
+	var conn net.Conn
+	var err error
+	if sender.config.TLSMode == TLSModeTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to smtp server %s: %w", addr, err)
+	}
+	if err := conn.SetDeadline(time.Now().Add(smtpTimeout)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	client, err := smtp.NewClient(conn, sender.config.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to greet smtp server %s: %w", addr, err)
+	}
+
+	if sender.config.TLSMode == TLSModeStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			client.Close()
+			return nil, fmt.Errorf("smtp server %s does not support STARTTLS", addr)
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("failed to start tls: %w", err)
+		}
+	}
+
+	return client, nil
+}
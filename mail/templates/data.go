@@ -0,0 +1,86 @@
+package templates
+
+type VerifyEmailData struct {
+	FullName  string
+	VerifyURL string
+}
+
+// TransferData is used by the TransferSent and TransferReceived emails.
+type TransferData struct {
+	FullName              string
+	TransferID            int64
+	AccountID             int64
+	CounterpartyAccountID int64
+	Amount                int64
+	Currency              string
+	Balance               int64
+}
+
+type TransferFailedData struct {
+	FullName      string
+	FromAccountID int64
+	ToAccountID   int64
+	Amount        int64
+	Currency      string
+}
+
+// AlertData is used by the LowBalance and LargeTransaction emails.
+type AlertData struct {
+	FullName   string
+	TransferID int64
+	AccountID  int64
+	Amount     int64
+	Currency   string
+	Balance    int64
+	Threshold  int64
+}
+
+var samples = map[string]any{
+	VerifyEmail: VerifyEmailData{
+		FullName:  "Jane Doe",
+		VerifyURL: "http://localhost:3000/api/v1/users/verify-email?email_id=1&secret_code=secret",
+	},
+	TransferSent: TransferData{
+		FullName:              "Jane Doe",
+		TransferID:            42,
+		AccountID:             1,
+		CounterpartyAccountID: 2,
+		Amount:                250,
+		Currency:              "USD",
+		Balance:               750,
+	},
+	TransferReceived: TransferData{
+		FullName:              "John Roe",
+		TransferID:            42,
+		AccountID:             2,
+		CounterpartyAccountID: 1,
+		Amount:                250,
+		Currency:              "USD",
+		Balance:               1250,
+	},
+	TransferFailed: TransferFailedData{
+		FullName:      "Jane Doe",
+		FromAccountID: 1,
+		ToAccountID:   2,
+		Amount:        250,
+		Currency:      "USD",
+	},
+	LowBalance: AlertData{
+		FullName:   "Jane Doe",
+		TransferID: 42,
+		AccountID:  1,
+		Amount:     250,
+		Currency:   "USD",
+		Balance:    50,
+		Threshold:  100,
+	},
+	LargeTransaction: AlertData{
+		FullName:   "Jane Doe",
+		TransferID: 42,
+		AccountID:  1,
+		Amount:     5000,
+		Currency:   "USD",
+		Balance:    1000,
+		Threshold:  1000,
+	},
+}
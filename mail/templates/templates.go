@@ -0,0 +1,205 @@
+// Package templates renders the emails sent by the worker. Every email has an
+// HTML and a plain-text template under files/emails that fill the "content" block
+// of the matching layout in files/layouts. The text template also defines the
+// "subject" block. Strings come from files/locales/<locale>.json through the "t"
+// function, and keys missing from a locale fall back to util.DefaultLocale.
+package templates
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"os"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/LamThanhNguyen/banking-system/util"
+)
+
+//go:embed files
+var embedded embed.FS
+
+// Names of all email templates
+const (
+	VerifyEmail      = "verify_email"
+	TransferSent     = "transfer_sent"
+	TransferReceived = "transfer_received"
+	TransferFailed   = "transfer_failed"
+	LowBalance       = "low_balance"
+	LargeTransaction = "large_transaction"
+)
+
+// Names lists every email template.
+var Names = []string{
+	VerifyEmail,
+	TransferSent,
+	TransferReceived,
+	TransferFailed,
+	LowBalance,
+	LargeTransaction,
+}
+
+var locales = []string{util.EnglishLocale, util.VietnameseLocale}
+
+// Email is a rendered email.
+type Email struct {
+	Subject string `json:"subject"`
+	HTML    string `json:"html"`
+	Text    string `json:"text"`
+}
+
+type view struct {
+	AppName string
+	Locale  string
+	Data    any
+}
+
+type Renderer struct {
+	fsys    fs.FS
+	appName string
+}
+
+// New creates a renderer for the embedded templates. When overrideDir is set, files
+// found under it replace the embedded file with the same path, e.g.
+// <overrideDir>/emails/verify_email.html. Every template is rendered once with its
+// sample data so that broken overrides fail at startup.
+func New(appName string, overrideDir string) (*Renderer, error) {
+	fsys, err := fs.Sub(embedded, "files")
+	if err != nil {
+		return nil, err
+	}
+	if overrideDir != "" {
+		fsys = overlayFS{upper: os.DirFS(overrideDir), lower: fsys}
+	}
+
+	renderer := &Renderer{
+		fsys:    fsys,
+		appName: appName,
+	}
+
+	for _, name := range Names {
+		for _, locale := range locales {
+			if _, err := renderer.Preview(name, locale); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return renderer, nil
+}
+
+// Render renders the named email in the given locale.
+// Unsupported locales are rendered in util.DefaultLocale.
+func (renderer *Renderer) Render(name string, locale string, data any) (Email, error) {
+	if !util.IsSupportedLocale(locale) {
+		locale = util.DefaultLocale
+	}
+
+	translations, err := renderer.translations(locale)
+	if err != nil {
+		return Email{}, err
+	}
+	funcs := map[string]any{
+		"t": func(key string, args ...any) (string, error) {
+			msg, ok := translations[key]
+			if !ok {
+				return "", fmt.Errorf("missing translation %q", key)
+			}
+			return fmt.Sprintf(msg, args...), nil
+		},
+	}
+
+	v := view{AppName: renderer.appName, Locale: locale, Data: data}
+	var email Email
+
+	textTmpl, err := texttemplate.New(name).Funcs(funcs).
+		ParseFS(renderer.fsys, "layouts/base.txt", "emails/"+name+".txt")
+	if err != nil {
+		return email, fmt.Errorf("failed to parse text template %s: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := textTmpl.ExecuteTemplate(&buf, "subject", v); err != nil {
+		return email, fmt.Errorf("failed to render subject of %s: %w", name, err)
+	}
+	email.Subject = strings.TrimSpace(buf.String())
+
+	buf.Reset()
+	if err := textTmpl.ExecuteTemplate(&buf, "base.txt", v); err != nil {
+		return email, fmt.Errorf("failed to render text template %s: %w", name, err)
+	}
+	email.Text = buf.String()
+
+	htmlTmpl, err := htmltemplate.New(name).Funcs(funcs).
+		ParseFS(renderer.fsys, "layouts/base.html", "emails/"+name+".html")
+	if err != nil {
+		return email, fmt.Errorf("failed to parse html template %s: %w", name, err)
+	}
+
+	buf.Reset()
+	if err := htmlTmpl.ExecuteTemplate(&buf, "base.html", v); err != nil {
+		return email, fmt.Errorf("failed to render html template %s: %w", name, err)
+	}
+	email.HTML = buf.String()
+
+	return email, nil
+}
+
+// Preview renders the named email with its sample data.
+func (renderer *Renderer) Preview(name string, locale string) (Email, error) {
+	data, ok := samples[name]
+	if !ok {
+		return Email{}, fmt.Errorf("unknown email template %q", name)
+	}
+	return renderer.Render(name, locale, data)
+}
+
+// translations returns the strings of the locale on top of the default locale.
+func (renderer *Renderer) translations(locale string) (map[string]string, error) {
+	translations, err := renderer.readLocale(util.DefaultLocale)
+	if err != nil {
+		return nil, err
+	}
+	if locale == util.DefaultLocale {
+		return translations, nil
+	}
+
+	overrides, err := renderer.readLocale(locale)
+	if err != nil {
+		return nil, err
+	}
+	for key, msg := range overrides {
+		translations[key] = msg
+	}
+	return translations, nil
+}
+
+func (renderer *Renderer) readLocale(locale string) (map[string]string, error) {
+	data, err := fs.ReadFile(renderer.fsys, "locales/"+locale+".json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read locale %s: %w", locale, err)
+	}
+
+	var translations map[string]string
+	if err := json.Unmarshal(data, &translations); err != nil {
+		return nil, fmt.Errorf("failed to parse locale %s: %w", locale, err)
+	}
+	return translations, nil
+}
+
+// overlayFS serves files from upper and falls back to lower when they don't exist there.
+type overlayFS struct {
+	upper fs.FS
+	lower fs.FS
+}
+
+func (o overlayFS) Open(name string) (fs.File, error) {
+	f, err := o.upper.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return o.lower.Open(name)
+	}
+	return f, err
+}
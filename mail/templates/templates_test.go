@@ -0,0 +1,83 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderVerifyEmail(t *testing.T) {
+	renderer, err := New("Banking System", "")
+	require.NoError(t, err)
+
+	data := VerifyEmailData{
+		FullName:  "<script>alert(1)</script>",
+		VerifyURL: "http://localhost:3000/verify?email_id=1&secret_code=abc",
+	}
+	email, err := renderer.Render(VerifyEmail, util.EnglishLocale, data)
+	require.NoError(t, err)
+
+	require.Equal(t, "Welcome to Banking System", email.Subject)
+	require.NotContains(t, email.HTML, "<script>")
+	require.Contains(t, email.HTML, "&lt;script&gt;")
+	require.Contains(t, email.HTML, `href="http://localhost:3000/verify?email_id=1&amp;secret_code=abc"`)
+	require.Contains(t, email.Text, data.FullName)
+	require.Contains(t, email.Text, data.VerifyURL)
+}
+
+func TestRenderLocale(t *testing.T) {
+	renderer, err := New("Banking System", "")
+	require.NoError(t, err)
+
+	email, err := renderer.Preview(TransferFailed, util.VietnameseLocale)
+	require.NoError(t, err)
+	require.Equal(t, "Giao dịch chuyển khoản của bạn không thành công", email.Subject)
+
+	email, err = renderer.Preview(TransferFailed, "fr")
+	require.NoError(t, err)
+	require.Equal(t, "Your transfer could not be completed", email.Subject)
+}
+
+func TestRenderOverride(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "locales"), 0o755))
+	err := os.WriteFile(filepath.Join(dir, "locales", "en.json"),
+		[]byte(`{"greeting": "Hi %s,", "footer": "%s", "transfer_failed.subject": "Transfer failed",
+		"transfer_failed.body": "%d %s %d %d", "transfer_failed.hint": "",
+		"verify_email.subject": "%s", "verify_email.body": "%s", "verify_email.action": "Verify",
+		"transfer.balance": "%d %s", "transfer.reference": "#%d",
+		"transfer_sent.subject": "#%d", "transfer_sent.body": "%d %s %d %d",
+		"transfer_received.subject": "%d %s", "transfer_received.body": "%d %d %s %d",
+		"low_balance.subject": "#%d", "low_balance.body": "%d %d %s %d %s",
+		"large_transaction.subject": "#%d", "large_transaction.body": "%d %d %s %d %d %s"}`), 0o644)
+	require.NoError(t, err)
+
+	renderer, err := New("Banking System", dir)
+	require.NoError(t, err)
+
+	email, err := renderer.Preview(TransferFailed, util.EnglishLocale)
+	require.NoError(t, err)
+	require.Equal(t, "Transfer failed", email.Subject)
+	require.Contains(t, email.Text, "Hi Jane Doe,")
+}
+
+func TestNewWithBrokenOverride(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "emails"), 0o755))
+	err := os.WriteFile(filepath.Join(dir, "emails", "verify_email.html"), []byte(`{{define "content"}}{{.Missing`), 0o644)
+	require.NoError(t, err)
+
+	_, err = New("Banking System", dir)
+	require.Error(t, err)
+}
+
+func TestPreviewUnknown(t *testing.T) {
+	renderer, err := New("Banking System", "")
+	require.NoError(t, err)
+
+	_, err = renderer.Preview("unknown", util.EnglishLocale)
+	require.Error(t, err)
+}
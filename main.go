@@ -2,28 +2,24 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
-	"net/http"
+	"flag"
+	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 
-	"github.com/LamThanhNguyen/banking-system/api"
 	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
 	_ "github.com/LamThanhNguyen/banking-system/docs" // swagger docs init
-	"github.com/LamThanhNguyen/banking-system/mail"
+	"github.com/LamThanhNguyen/banking-system/logging"
 	pgxadapter "github.com/LamThanhNguyen/banking-system/pgxadapter"
 	"github.com/LamThanhNguyen/banking-system/util"
-	"github.com/LamThanhNguyen/banking-system/worker"
 	"github.com/casbin/casbin/v2"
-	"github.com/golang-migrate/migrate/v4"
-	_ "github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
-	"github.com/hibiken/asynq"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rs/zerolog/log"
-	"golang.org/x/sync/errgroup"
 )
 
 var interruptSignals = []os.Signal{
@@ -32,6 +28,22 @@ var interruptSignals = []os.Signal{
 	syscall.SIGINT,
 }
 
+// command is a subcommand of the binary. run receives the arguments after its name.
+type command struct {
+	name  string
+	usage string
+	run   func(ctx context.Context, args []string) error
+}
+
+var commands = []command{
+	{"serve", "run the HTTP API server", runServeCommand},
+	{"worker", "run the task processor, scheduler and outbox relay", runWorkerCommand},
+	{"migrate", "apply, roll back or show database migrations (up, down, status)", runMigrateCommand},
+	{"seed-policies", "add the default authorization policies", runSeedPoliciesCommand},
+	{"admin", "run operational tasks on users, accounts and policies", runAdminCommand},
+	{"audit", "verify the hash chain of the audit log (verify)", runAuditCommand},
+}
+
 // @title           Be Banking System API
 // @version         1.0
 // @description     API documentation for the Be Banking System project.
@@ -43,166 +55,106 @@ func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), interruptSignals...)
 	defer stop()
 
-	config, err := util.LoadConfig(ctx, ".")
-	if err != nil {
-		log.Fatal().Err(err).Msg("cannot load config")
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
 	}
 
-	runtimeCfg, err := util.NewRuntimeConfig(config)
-	if err != nil {
-		log.Fatal().Err(err).Msg("invalid config values")
+	name, args := os.Args[1], os.Args[2:]
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+		err := cmd.run(ctx, args)
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		if err != nil {
+			log.Fatal().Err(err).Str("command", name).Msg("command failed")
+		}
+		return
 	}
 
-	log.Info().Interface("config", runtimeCfg).Msg("loaded config")
-
-	connPool, err := pgxpool.New(ctx, runtimeCfg.DBSource)
-	if err != nil {
-		log.Fatal().Err(err).Msg("cannot connect to db")
+	if name != "help" && name != "-h" && name != "--help" {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
 	}
+	usage()
+	os.Exit(2)
+}
 
-	// Run migration to database
-	runDBMigration(runtimeCfg.MigrationURL, runtimeCfg.DBSource)
-
-	casbin_adapter, err := pgxadapter.New(ctx, connPool)
-	if err != nil {
-		log.Fatal().Err(err).Msg("cannot create casbin adapter")
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s <command> [flags]\n\nCommands:\n", filepath.Base(os.Args[0]))
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-14s %s\n", cmd.name, cmd.usage)
 	}
+	fmt.Fprintf(os.Stderr, "\nRun '%s <command> -h' for the flags of a command.\n", filepath.Base(os.Args[0]))
+}
 
-	casbin_enforcer, err := casbin.NewEnforcer("model.conf", casbin_adapter)
-	if err != nil {
-		log.Fatal().Err(err).Msg("cannot create casbin enforcer")
+// newFlagSet returns the flag set of a subcommand; -h prints its flags.
+func newFlagSet(name, args string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s %s %s\n\nFlags:\n", filepath.Base(os.Args[0]), name, args)
+		flags.PrintDefaults()
 	}
-	casbin_enforcer.EnableAutoSave(true)
+	return flags
+}
 
-	if err := seedPolicies(casbin_enforcer); err != nil {
-		log.Fatal().Err(err).Msg("cannot seed Policies")
+func loadRuntimeConfig(ctx context.Context) (util.RuntimeConfig, error) {
+	config, err := util.LoadConfig(ctx, ".")
+	if err != nil {
+		return util.RuntimeConfig{}, fmt.Errorf("cannot load config: %w", err)
 	}
 
-	store := db.NewStore(connPool)
-
-	redisOpt := asynq.RedisClientOpt{
-		Addr: runtimeCfg.RedisAddress,
+	runtimeCfg, err := util.NewRuntimeConfig(config)
+	if err != nil {
+		return util.RuntimeConfig{}, fmt.Errorf("invalid config values: %w", err)
 	}
 
-	taskDistributor := worker.NewRedisTaskDistributor(redisOpt)
-
-	waitGroup, ctx := errgroup.WithContext(ctx)
-
-	runTaskProcessor(ctx, waitGroup, runtimeCfg, redisOpt, store)
-	runServer(ctx, waitGroup, runtimeCfg, store, casbin_enforcer, taskDistributor)
-
-	if err = waitGroup.Wait(); err != nil {
-		log.Fatal().Err(err).Msg("err from wait group")
+	// The config holds the keys and passwords of every dependency.
+	configJSON, err := json.Marshal(runtimeCfg)
+	if err != nil {
+		return util.RuntimeConfig{}, fmt.Errorf("cannot encode config: %w", err)
 	}
-
-	log.Info().Msg("application shutdown complete")
+	log.Info().RawJSON("config", logging.RedactJSON(configJSON)).Msg("loaded config")
+	return runtimeCfg, nil
 }
 
-func runDBMigration(migrationURL string, dbSource string) {
-	migration, err := migrate.New(migrationURL, dbSource)
-	if err != nil {
-		log.Fatal().Err(err).Msg("cannot create new migrate instance")
-	}
+// flushTracing exports the spans that are still buffered on exit.
+func flushTracing(shutdown func(context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
-	if err = migration.Up(); err != nil && err != migrate.ErrNoChange {
-		log.Fatal().Err(err).Msg("failed to run migrate up")
+	if err := shutdown(ctx); err != nil {
+		log.Error().Err(err).Msg("failed to flush spans")
 	}
-
-	log.Info().Msg("db migrated successfully")
 }
 
-func seedPolicies(casbin_enforcer *casbin.Enforcer) error {
-	add := func(sub, act string) {
-		_, _ = casbin_enforcer.AddPolicy(sub, "*", act)
+// newConnPool connects to the database with a pool whose queries are traced.
+func newConnPool(ctx context.Context, dbSource string) (*pgxpool.Pool, error) {
+	poolConfig, err := pgxpool.ParseConfig(dbSource)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DB_SOURCE: %w", err)
 	}
+	poolConfig.ConnConfig.Tracer = db.NewQueryTracer()
 
-	// banker
-	add("banker", "accounts:create")
-	add("banker", "accounts:read")
-	add("banker", "accounts:list")
-	add("banker", "users:update")
-	add("banker", "transfers:create")
-
-	// depositer
-	add("depositor", "accounts:create")
-	add("depositor", "accounts:read")
-	add("depositor", "accounts:list")
-	add("depositor", "users:update")
-	add("depositor", "transfers:create")
-	return nil
-}
-
-func runTaskProcessor(
-	ctx context.Context,
-	waitGroup *errgroup.Group,
-	config util.RuntimeConfig,
-	redisOpt asynq.RedisClientOpt,
-	store db.Store,
-) {
-	mailer := mail.NewGmailSender(config.EmailSenderName, config.EmailSenderAddress, config.EmailSenderPassword)
-	taskProcessor := worker.NewRedisTaskProcessor(redisOpt, store, mailer, config)
-
-	if err := taskProcessor.Start(); err != nil {
-		log.Fatal().Err(err).Msg("failed to start task processor")
+	connPool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to db: %w", err)
 	}
-	log.Info().Msg("task processor started")
-
-	waitGroup.Go(func() error {
-		<-ctx.Done()
-		log.Info().Msg("graceful shutdown task processor")
-
-		taskProcessor.Shutdown()
-		log.Info().Msg("task processor is stopped")
-
-		return nil
-	})
+	return connPool, nil
 }
 
-func runServer(
-	ctx context.Context,
-	waitGroup *errgroup.Group,
-	config util.RuntimeConfig,
-	store db.Store,
-	enforcer *casbin.Enforcer,
-	taskDistributor worker.TaskDistributor,
-) {
-	server, err := api.NewServer(config, store, enforcer, taskDistributor)
+func newCasbinEnforcer(ctx context.Context, connPool *pgxpool.Pool) (*casbin.Enforcer, error) {
+	casbin_adapter, err := pgxadapter.New(ctx, connPool)
 	if err != nil {
-		log.Fatal().Err(err).Msg("cannot create server")
+		return nil, fmt.Errorf("cannot create casbin adapter: %w", err)
 	}
 
-	server.SetupRouter() // initialize routes
-
-	// Setup HTTP server
-	httpServer := &http.Server{
-		Addr:    config.HTTPServerAddress,
-		Handler: server.Router(), // use the Gin router
+	casbin_enforcer, err := casbin.NewEnforcer("model.conf", casbin_adapter)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create casbin enforcer: %w", err)
 	}
-
-	// Start HTTP server in goroutine
-	waitGroup.Go(func() error {
-		log.Info().Msgf("start HTTP server at %s", httpServer.Addr)
-		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			log.Error().Err(err).Msg("HTTP server failed to serve")
-			return err
-		}
-		return nil
-	})
-
-	// Graceful shutdown on context cancel
-	waitGroup.Go(func() error {
-		<-ctx.Done()
-		log.Info().Msg("graceful shutdown HTTP server")
-
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-
-		if err := httpServer.Shutdown(shutdownCtx); err != nil {
-			log.Error().Err(err).Msg("failed to shutdown HTTP server")
-			return err
-		}
-
-		log.Info().Msg("HTTP server is stopped")
-		return nil
-	})
+	casbin_enforcer.EnableAutoSave(true)
+	return casbin_enforcer, nil
 }
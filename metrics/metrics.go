@@ -0,0 +1,132 @@
+// Package metrics holds the Prometheus metrics shared by the HTTP and gRPC
+// APIs and the task workers. They are registered with the default registry,
+// which the admin server started by serve and worker exposes on /metrics.
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Outcomes of a transfer
+const (
+	TransferSucceeded = "succeeded"
+	TransferFailed    = "failed"
+)
+
+// Reasons of a failed login
+const (
+	LoginUserNotFound       = "user_not_found"
+	LoginInvalidCredentials = "invalid_credentials"
+	LoginUserDisabled       = "user_disabled"
+)
+
+var (
+	httpRequestDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Duration of HTTP requests by route template.",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"method", "route", "status"},
+	)
+
+	transfers = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "banking_transfers_total",
+			Help: "Number of transfers by currency and outcome.",
+		},
+		[]string{"currency", "result"},
+	)
+
+	transferAmount = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "banking_transfer_amount_total",
+			Help: "Amount moved by successful transfers, in minor units of the currency.",
+		},
+		[]string{"currency"},
+	)
+
+	loginFailures = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "banking_login_failures_total",
+			Help: "Number of rejected logins by reason.",
+		},
+		[]string{"reason"},
+	)
+
+	authorizationDenials = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "banking_authorization_denials_total",
+			Help: "Number of requests denied by the authorization policies, by action.",
+		},
+		[]string{"action"},
+	)
+
+	tasksEnqueued = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "asynq_tasks_enqueued_total",
+			Help: "Number of tasks enqueued by the distributors and the outbox relay, by type and outcome.",
+		},
+		[]string{"type", "result"},
+	)
+
+	taskDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "asynq_task_duration_seconds",
+			Help:    "Duration of the tasks run by this process, by type and outcome.",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"type", "result"},
+	)
+)
+
+// ObserveHTTPRequest records a served request. Route is the template the
+// request matched, such as /api/v1/accounts/:id, so that IDs do not create a
+// series each.
+func ObserveHTTPRequest(method string, route string, status int, duration time.Duration) {
+	if route == "" {
+		route = "unmatched"
+	}
+	httpRequestDuration.WithLabelValues(method, route, strconv.Itoa(status)).Observe(duration.Seconds())
+}
+
+// ObserveTransfer records a transfer attempt and, if it succeeded, its amount.
+func ObserveTransfer(currency string, amount int64, err error) {
+	if err != nil {
+		transfers.WithLabelValues(currency, TransferFailed).Inc()
+		return
+	}
+	transfers.WithLabelValues(currency, TransferSucceeded).Inc()
+	transferAmount.WithLabelValues(currency).Add(float64(amount))
+}
+
+// ObserveLoginFailure records a rejected login.
+func ObserveLoginFailure(reason string) {
+	loginFailures.WithLabelValues(reason).Inc()
+}
+
+// ObserveAuthorizationDenial records a request denied by the policies.
+func ObserveAuthorizationDenial(action string) {
+	authorizationDenials.WithLabelValues(action).Inc()
+}
+
+// ObserveTaskEnqueued records an attempt to enqueue a task.
+func ObserveTaskEnqueued(taskType string, err error) {
+	tasksEnqueued.WithLabelValues(taskType, result(err)).Inc()
+}
+
+// ObserveTaskProcessed records a run of a task.
+func ObserveTaskProcessed(taskType string, duration time.Duration, err error) {
+	taskDuration.WithLabelValues(taskType, result(err)).Observe(duration.Seconds())
+}
+
+func result(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "success"
+}
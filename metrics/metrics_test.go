@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserveTransfer(t *testing.T) {
+	succeeded := testutil.ToFloat64(transfers.WithLabelValues("EUR", TransferSucceeded))
+	failed := testutil.ToFloat64(transfers.WithLabelValues("EUR", TransferFailed))
+	amount := testutil.ToFloat64(transferAmount.WithLabelValues("EUR"))
+
+	ObserveTransfer("EUR", 150, nil)
+	ObserveTransfer("EUR", 70, errors.New("insufficient funds"))
+
+	require.Equal(t, succeeded+1, testutil.ToFloat64(transfers.WithLabelValues("EUR", TransferSucceeded)))
+	require.Equal(t, failed+1, testutil.ToFloat64(transfers.WithLabelValues("EUR", TransferFailed)))
+	// Failed transfers move no money.
+	require.Equal(t, amount+150, testutil.ToFloat64(transferAmount.WithLabelValues("EUR")))
+}
+
+func TestObserveLoginFailure(t *testing.T) {
+	before := testutil.ToFloat64(loginFailures.WithLabelValues(LoginInvalidCredentials))
+	ObserveLoginFailure(LoginInvalidCredentials)
+	require.Equal(t, before+1, testutil.ToFloat64(loginFailures.WithLabelValues(LoginInvalidCredentials)))
+}
+
+func TestObserveAuthorizationDenial(t *testing.T) {
+	before := testutil.ToFloat64(authorizationDenials.WithLabelValues("accounts:create"))
+	ObserveAuthorizationDenial("accounts:create")
+	require.Equal(t, before+1, testutil.ToFloat64(authorizationDenials.WithLabelValues("accounts:create")))
+}
+
+func TestObserveTaskEnqueued(t *testing.T) {
+	success := testutil.ToFloat64(tasksEnqueued.WithLabelValues("task:test", "success"))
+	failure := testutil.ToFloat64(tasksEnqueued.WithLabelValues("task:test", "error"))
+
+	ObserveTaskEnqueued("task:test", nil)
+	ObserveTaskEnqueued("task:test", errors.New("redis unavailable"))
+
+	require.Equal(t, success+1, testutil.ToFloat64(tasksEnqueued.WithLabelValues("task:test", "success")))
+	require.Equal(t, failure+1, testutil.ToFloat64(tasksEnqueued.WithLabelValues("task:test", "error")))
+}
+
+func histogramCount(t *testing.T, method string, route string, status string) uint64 {
+	var m dto.Metric
+	observer := httpRequestDuration.WithLabelValues(method, route, status)
+	require.NoError(t, observer.(prometheus.Histogram).Write(&m))
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestObserveHTTPRequest(t *testing.T) {
+	unmatched := histogramCount(t, "GET", "unmatched", "404")
+	account := histogramCount(t, "GET", "/api/v1/accounts/:id", "200")
+
+	ObserveHTTPRequest("GET", "", 404, time.Millisecond)
+	ObserveHTTPRequest("GET", "/api/v1/accounts/:id", 200, time.Millisecond)
+
+	// Requests that match no route share one series.
+	require.Equal(t, unmatched+1, histogramCount(t, "GET", "unmatched", "404"))
+	require.Equal(t, account+1, histogramCount(t, "GET", "/api/v1/accounts/:id", "200"))
+}
@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PoolCollector exports the connection stats of a pgx pool, read on every scrape.
+type PoolCollector struct {
+	pool *pgxpool.Pool
+
+	acquiredConns        *prometheus.Desc
+	idleConns            *prometheus.Desc
+	constructingConns    *prometheus.Desc
+	maxConns             *prometheus.Desc
+	acquireCount         *prometheus.Desc
+	acquireDuration      *prometheus.Desc
+	emptyAcquireCount    *prometheus.Desc
+	canceledAcquireCount *prometheus.Desc
+	newConnsCount        *prometheus.Desc
+}
+
+func NewPoolCollector(pool *pgxpool.Pool) *PoolCollector {
+	return &PoolCollector{
+		pool: pool,
+		acquiredConns: prometheus.NewDesc(
+			"pgxpool_acquired_conns",
+			"Number of connections currently in use.",
+			nil, nil,
+		),
+		idleConns: prometheus.NewDesc(
+			"pgxpool_idle_conns",
+			"Number of idle connections in the pool.",
+			nil, nil,
+		),
+		constructingConns: prometheus.NewDesc(
+			"pgxpool_constructing_conns",
+			"Number of connections being opened.",
+			nil, nil,
+		),
+		maxConns: prometheus.NewDesc(
+			"pgxpool_max_conns",
+			"Maximum size of the pool.",
+			nil, nil,
+		),
+		acquireCount: prometheus.NewDesc(
+			"pgxpool_acquires_total",
+			"Number of successful acquires from the pool.",
+			nil, nil,
+		),
+		acquireDuration: prometheus.NewDesc(
+			"pgxpool_acquire_duration_seconds_total",
+			"Total time spent waiting for a connection.",
+			nil, nil,
+		),
+		emptyAcquireCount: prometheus.NewDesc(
+			"pgxpool_empty_acquires_total",
+			"Number of acquires that had to wait because no connection was idle.",
+			nil, nil,
+		),
+		canceledAcquireCount: prometheus.NewDesc(
+			"pgxpool_canceled_acquires_total",
+			"Number of acquires cancelled by their context.",
+			nil, nil,
+		),
+		newConnsCount: prometheus.NewDesc(
+			"pgxpool_new_conns_total",
+			"Number of connections opened.",
+			nil, nil,
+		),
+	}
+}
+
+func (c *PoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.acquiredConns
+	ch <- c.idleConns
+	ch <- c.constructingConns
+	ch <- c.maxConns
+	ch <- c.acquireCount
+	ch <- c.acquireDuration
+	ch <- c.emptyAcquireCount
+	ch <- c.canceledAcquireCount
+	ch <- c.newConnsCount
+}
+
+func (c *PoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+
+	ch <- prometheus.MustNewConstMetric(c.acquiredConns, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.constructingConns, prometheus.GaugeValue, float64(stat.ConstructingConns()))
+	ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquireCount, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireDuration, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquireCount, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.canceledAcquireCount, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.newConnsCount, prometheus.CounterValue, float64(stat.NewConnsCount()))
+}
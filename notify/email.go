@@ -0,0 +1,30 @@
+package notify
+
+import (
+	"context"
+
+	"github.com/LamThanhNguyen/banking-system/mail"
+	"github.com/LamThanhNguyen/banking-system/util"
+)
+
+// EmailNotifier sends notifications with a mail.EmailSender.
+type EmailNotifier struct {
+	sender mail.EmailSender
+}
+
+func NewEmailNotifier(sender mail.EmailSender) Notifier {
+	return &EmailNotifier{sender: sender}
+}
+
+func (notifier *EmailNotifier) Channel() string {
+	return util.ChannelEmail
+}
+
+func (notifier *EmailNotifier) Notify(_ context.Context, to Recipient, msg Message) error {
+	if to.Address(util.ChannelEmail) == "" {
+		return ErrNoAddress
+	}
+
+	content := mail.Content{HTML: msg.HTML, Text: msg.Text}
+	return notifier.sender.SendEmail(msg.Subject, content, []string{to.Email}, nil, nil, nil)
+}
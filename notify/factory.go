@@ -0,0 +1,54 @@
+package notify
+
+import (
+	"fmt"
+
+	"github.com/LamThanhNguyen/banking-system/mail"
+	"github.com/LamThanhNguyen/banking-system/util"
+)
+
+// Providers selectable with SMS_PROVIDER and PUSH_PROVIDER
+const (
+	ProviderHTTP   = "http"
+	ProviderLog    = "log"
+	ProviderMemory = "memory"
+)
+
+// NewNotifiers creates the Notifier of every channel. Email goes through mailer,
+// and SMS and push use the providers selected by config.
+func NewNotifiers(config util.Config, mailer mail.EmailSender) (Notifiers, error) {
+	sms, err := newNotifier(util.ChannelSMS, config.SMSProvider, config.SMSEndpoint, config.SMSAPIKey)
+	if err != nil {
+		return nil, err
+	}
+
+	push, err := newNotifier(util.ChannelPush, config.PushProvider, config.PushEndpoint, config.PushAPIKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return Notifiers{
+		util.ChannelEmail: NewEmailNotifier(mailer),
+		util.ChannelSMS:   sms,
+		util.ChannelPush:  push,
+	}, nil
+}
+
+func newNotifier(channel string, provider string, endpoint string, apiKey string) (Notifier, error) {
+	switch provider {
+	case "", ProviderLog:
+		return NewLogNotifier(channel), nil
+
+	case ProviderMemory:
+		return NewMemoryNotifier(channel), nil
+
+	case ProviderHTTP:
+		if channel == util.ChannelSMS {
+			return NewHTTPSMSNotifier(endpoint, apiKey)
+		}
+		return NewHTTPPushNotifier(endpoint, apiKey)
+
+	default:
+		return nil, fmt.Errorf("unsupported %s provider %q", channel, provider)
+	}
+}
@@ -0,0 +1,107 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/LamThanhNguyen/banking-system/util"
+)
+
+const httpTimeout = 10 * time.Second
+
+// SMSRequest is the body posted to the SMS endpoint.
+type SMSRequest struct {
+	To      string `json:"to"`
+	Message string `json:"message"`
+}
+
+// PushRequest is the body posted to the push endpoint, which maps usernames to devices.
+type PushRequest struct {
+	To    string `json:"to"`
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+// HTTPNotifier posts notifications as JSON to a provider endpoint,
+// authenticated with a bearer API key when one is configured.
+type HTTPNotifier struct {
+	channel  string
+	endpoint string
+	apiKey   string
+	client   *http.Client
+	body     func(to Recipient, msg Message) any
+}
+
+// NewHTTPSMSNotifier sends an SMSRequest to the verified phone number of the recipient.
+func NewHTTPSMSNotifier(endpoint string, apiKey string) (Notifier, error) {
+	return newHTTPNotifier(util.ChannelSMS, endpoint, apiKey, func(to Recipient, msg Message) any {
+		return SMSRequest{To: to.Phone, Message: msg.ShortText()}
+	})
+}
+
+// NewHTTPPushNotifier sends a PushRequest addressed to the username of the recipient.
+func NewHTTPPushNotifier(endpoint string, apiKey string) (Notifier, error) {
+	return newHTTPNotifier(util.ChannelPush, endpoint, apiKey, func(to Recipient, msg Message) any {
+		return PushRequest{To: to.Username, Title: msg.Subject, Body: msg.ShortText()}
+	})
+}
+
+func newHTTPNotifier(
+	channel string,
+	endpoint string,
+	apiKey string,
+	body func(to Recipient, msg Message) any,
+) (Notifier, error) {
+	if endpoint == "" {
+		return nil, fmt.Errorf("%s endpoint is required by the http provider", channel)
+	}
+
+	return &HTTPNotifier{
+		channel:  channel,
+		endpoint: endpoint,
+		apiKey:   apiKey,
+		client:   &http.Client{Timeout: httpTimeout},
+		body:     body,
+	}, nil
+}
+
+func (notifier *HTTPNotifier) Channel() string {
+	return notifier.channel
+}
+
+func (notifier *HTTPNotifier) Notify(ctx context.Context, to Recipient, msg Message) error {
+	if to.Address(notifier.channel) == "" {
+		return ErrNoAddress
+	}
+
+	data, err := json.Marshal(notifier.body(to, msg))
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s request: %w", notifier.channel, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, notifier.endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if notifier.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+notifier.apiKey)
+	}
+
+	rsp, err := notifier.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send %s: %w", notifier.channel, err)
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode < 200 || rsp.StatusCode > 299 {
+		text, _ := io.ReadAll(io.LimitReader(rsp.Body, 256))
+		return fmt.Errorf("%s provider returned status %d: %s", notifier.channel, rsp.StatusCode, text)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPNotifier(t *testing.T) {
+	to := Recipient{Username: "alice", Email: "alice@example.com", Phone: "+84901234567"}
+	msg := Message{Subject: "Transfer receipt #7", Text: "long text"}
+
+	testCases := []struct {
+		name       string
+		newNotify  func(endpoint string) (Notifier, error)
+		to         Recipient
+		status     int
+		checkBody  func(t *testing.T, body []byte)
+		checkError func(t *testing.T, err error, received int)
+	}{
+		{
+			name:      "SMS",
+			newNotify: func(endpoint string) (Notifier, error) { return NewHTTPSMSNotifier(endpoint, "key") },
+			to:        to,
+			status:    http.StatusAccepted,
+			checkBody: func(t *testing.T, body []byte) {
+				var req SMSRequest
+				require.NoError(t, json.Unmarshal(body, &req))
+				require.Equal(t, SMSRequest{To: to.Phone, Message: msg.Subject}, req)
+			},
+			checkError: func(t *testing.T, err error, received int) {
+				require.NoError(t, err)
+				require.Equal(t, 1, received)
+			},
+		},
+		{
+			name:      "Push",
+			newNotify: func(endpoint string) (Notifier, error) { return NewHTTPPushNotifier(endpoint, "key") },
+			to:        to,
+			status:    http.StatusOK,
+			checkBody: func(t *testing.T, body []byte) {
+				var req PushRequest
+				require.NoError(t, json.Unmarshal(body, &req))
+				require.Equal(t, PushRequest{To: to.Username, Title: msg.Subject, Body: msg.Subject}, req)
+			},
+			checkError: func(t *testing.T, err error, received int) {
+				require.NoError(t, err)
+				require.Equal(t, 1, received)
+			},
+		},
+		{
+			name:      "NoPhone",
+			newNotify: func(endpoint string) (Notifier, error) { return NewHTTPSMSNotifier(endpoint, "key") },
+			to:        Recipient{Username: "alice"},
+			checkError: func(t *testing.T, err error, received int) {
+				require.ErrorIs(t, err, ErrNoAddress)
+				require.Zero(t, received)
+			},
+		},
+		{
+			name:      "ProviderError",
+			newNotify: func(endpoint string) (Notifier, error) { return NewHTTPSMSNotifier(endpoint, "key") },
+			to:        to,
+			status:    http.StatusBadGateway,
+			checkBody: func(t *testing.T, body []byte) {},
+			checkError: func(t *testing.T, err error, received int) {
+				require.ErrorContains(t, err, "status 502")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			received := 0
+			provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received++
+				require.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+
+				var body json.RawMessage
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				tc.checkBody(t, body)
+				w.WriteHeader(tc.status)
+			}))
+			defer provider.Close()
+
+			notifier, err := tc.newNotify(provider.URL)
+			require.NoError(t, err)
+
+			err = notifier.Notify(context.Background(), tc.to, msg)
+			tc.checkError(t, err, received)
+		})
+	}
+}
+
+func TestNewNotifiers(t *testing.T) {
+	notifiers, err := NewNotifiers(util.Config{}, nil)
+	require.NoError(t, err)
+	require.IsType(t, &LogNotifier{}, notifiers[util.ChannelSMS])
+	require.IsType(t, &LogNotifier{}, notifiers[util.ChannelPush])
+	require.Equal(t, util.ChannelEmail, notifiers[util.ChannelEmail].Channel())
+
+	_, err = NewNotifiers(util.Config{SMSProvider: ProviderHTTP}, nil)
+	require.ErrorContains(t, err, "sms endpoint is required")
+
+	_, err = NewNotifiers(util.Config{PushProvider: "carrier-pigeon"}, nil)
+	require.ErrorContains(t, err, "unsupported push provider")
+}
@@ -0,0 +1,32 @@
+package notify
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+)
+
+// LogNotifier only logs notifications. It stands in for SMS and push providers
+// during development.
+type LogNotifier struct {
+	channel string
+}
+
+func NewLogNotifier(channel string) Notifier {
+	return &LogNotifier{channel: channel}
+}
+
+func (notifier *LogNotifier) Channel() string {
+	return notifier.channel
+}
+
+func (notifier *LogNotifier) Notify(_ context.Context, to Recipient, msg Message) error {
+	address := to.Address(notifier.channel)
+	if address == "" {
+		return ErrNoAddress
+	}
+
+	log.Info().Str("channel", notifier.channel).Str("to", address).
+		Str("text", msg.ShortText()).Msg("notification not sent by log provider")
+	return nil
+}
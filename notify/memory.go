@@ -0,0 +1,47 @@
+package notify
+
+import (
+	"context"
+	"sync"
+)
+
+// Notification is a message recorded by MemoryNotifier.
+type Notification struct {
+	To      Recipient
+	Message Message
+}
+
+// MemoryNotifier keeps notifications in memory so that tests can inspect them.
+type MemoryNotifier struct {
+	channel       string
+	mu            sync.Mutex
+	notifications []Notification
+}
+
+func NewMemoryNotifier(channel string) *MemoryNotifier {
+	return &MemoryNotifier{channel: channel}
+}
+
+func (notifier *MemoryNotifier) Channel() string {
+	return notifier.channel
+}
+
+func (notifier *MemoryNotifier) Notify(_ context.Context, to Recipient, msg Message) error {
+	if to.Address(notifier.channel) == "" {
+		return ErrNoAddress
+	}
+
+	notifier.mu.Lock()
+	defer notifier.mu.Unlock()
+
+	notifier.notifications = append(notifier.notifications, Notification{To: to, Message: msg})
+	return nil
+}
+
+// Notifications returns the recorded notifications in the order they were sent.
+func (notifier *MemoryNotifier) Notifications() []Notification {
+	notifier.mu.Lock()
+	defer notifier.mu.Unlock()
+
+	return append([]Notification(nil), notifier.notifications...)
+}
@@ -0,0 +1,60 @@
+// Package notify delivers messages to users over a notification channel.
+// Every channel in util (email, SMS and push) has a Notifier, and the worker
+// picks the ones enabled by the user's notification preferences.
+package notify
+
+import (
+	"context"
+	"errors"
+
+	"github.com/LamThanhNguyen/banking-system/util"
+)
+
+// ErrNoAddress is returned when the recipient cannot be reached on the channel,
+// for example an SMS to a user without a verified phone number.
+var ErrNoAddress = errors.New("recipient has no address on this channel")
+
+// Recipient identifies a user on every channel. Phone is only set once verified,
+// except when sending the verification code itself.
+type Recipient struct {
+	Username string
+	Email    string
+	Phone    string
+}
+
+// Address returns the address of the recipient on the channel, or "" when it has none.
+func (to Recipient) Address(channel string) string {
+	switch channel {
+	case util.ChannelEmail:
+		return to.Email
+	case util.ChannelSMS:
+		return to.Phone
+	default:
+		return to.Username
+	}
+}
+
+// Message is the content of a notification. Email uses Subject, HTML and Text,
+// while SMS and push send Short, or Subject when Short is empty.
+type Message struct {
+	Subject string
+	HTML    string
+	Text    string
+	Short   string
+}
+
+// ShortText returns the text sent on channels that cannot carry a full email.
+func (msg Message) ShortText() string {
+	if msg.Short != "" {
+		return msg.Short
+	}
+	return msg.Subject
+}
+
+type Notifier interface {
+	Channel() string
+	Notify(ctx context.Context, to Recipient, msg Message) error
+}
+
+// Notifiers holds the configured Notifier of each channel.
+type Notifiers map[string]Notifier
@@ -0,0 +1,107 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.3
+// source: service_banking_system.proto
+
+package pb
+
+import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+var File_service_banking_system_proto protoreflect.FileDescriptor
+
+const file_service_banking_system_proto_rawDesc = "" +
+	"\n" +
+	"\x1cservice_banking_system.proto\x12\x02pb\x1a\x1cgoogle/api/annotations.proto\x1a\x18rpc_create_account.proto\x1a\x19rpc_create_transfer.proto\x1a\x15rpc_create_user.proto\x1a\x15rpc_get_account.proto\x1a\x17rpc_list_accounts.proto\x1a\x14rpc_login_user.proto\x1a\x15rpc_update_user.proto2\x89\x05\n" +
+	"\rBankingSystem\x12Q\n" +
+	"\n" +
+	"CreateUser\x12\x15.pb.CreateUserRequest\x1a\x16.pb.CreateUserResponse\"\x14\x82\xd3\xe4\x93\x02\x0e:\x01*\"\t/v1/users\x12T\n" +
+	"\tLoginUser\x12\x14.pb.LoginUserRequest\x1a\x15.pb.LoginUserResponse\"\x1a\x82\xd3\xe4\x93\x02\x14:\x01*\"\x0f/v1/users/login\x12\\\n" +
+	"\n" +
+	"UpdateUser\x12\x15.pb.UpdateUserRequest\x1a\x16.pb.UpdateUserResponse\"\x1f\x82\xd3\xe4\x93\x02\x19:\x01*2\x14/v1/users/{username}\x12]\n" +
+	"\rCreateAccount\x12\x18.pb.CreateAccountRequest\x1a\x19.pb.CreateAccountResponse\"\x17\x82\xd3\xe4\x93\x02\x11:\x01*\"\f/v1/accounts\x12V\n" +
+	"\n" +
+	"GetAccount\x12\x15.pb.GetAccountRequest\x1a\x16.pb.GetAccountResponse\"\x19\x82\xd3\xe4\x93\x02\x13\x12\x11/v1/accounts/{id}\x12W\n" +
+	"\fListAccounts\x12\x17.pb.ListAccountsRequest\x1a\x18.pb.ListAccountsResponse\"\x14\x82\xd3\xe4\x93\x02\x0e\x12\f/v1/accounts\x12a\n" +
+	"\x0eCreateTransfer\x12\x19.pb.CreateTransferRequest\x1a\x1a.pb.CreateTransferResponse\"\x18\x82\xd3\xe4\x93\x02\x12:\x01*\"\r/v1/transfersB-Z+github.com/LamThanhNguyen/banking-system/pbb\x06proto3"
+
+var file_service_banking_system_proto_goTypes = []any{
+	(*CreateUserRequest)(nil),      // 0: pb.CreateUserRequest
+	(*LoginUserRequest)(nil),       // 1: pb.LoginUserRequest
+	(*UpdateUserRequest)(nil),      // 2: pb.UpdateUserRequest
+	(*CreateAccountRequest)(nil),   // 3: pb.CreateAccountRequest
+	(*GetAccountRequest)(nil),      // 4: pb.GetAccountRequest
+	(*ListAccountsRequest)(nil),    // 5: pb.ListAccountsRequest
+	(*CreateTransferRequest)(nil),  // 6: pb.CreateTransferRequest
+	(*CreateUserResponse)(nil),     // 7: pb.CreateUserResponse
+	(*LoginUserResponse)(nil),      // 8: pb.LoginUserResponse
+	(*UpdateUserResponse)(nil),     // 9: pb.UpdateUserResponse
+	(*CreateAccountResponse)(nil),  // 10: pb.CreateAccountResponse
+	(*GetAccountResponse)(nil),     // 11: pb.GetAccountResponse
+	(*ListAccountsResponse)(nil),   // 12: pb.ListAccountsResponse
+	(*CreateTransferResponse)(nil), // 13: pb.CreateTransferResponse
+}
+var file_service_banking_system_proto_depIdxs = []int32{
+	0,  // 0: pb.BankingSystem.CreateUser:input_type -> pb.CreateUserRequest
+	1,  // 1: pb.BankingSystem.LoginUser:input_type -> pb.LoginUserRequest
+	2,  // 2: pb.BankingSystem.UpdateUser:input_type -> pb.UpdateUserRequest
+	3,  // 3: pb.BankingSystem.CreateAccount:input_type -> pb.CreateAccountRequest
+	4,  // 4: pb.BankingSystem.GetAccount:input_type -> pb.GetAccountRequest
+	5,  // 5: pb.BankingSystem.ListAccounts:input_type -> pb.ListAccountsRequest
+	6,  // 6: pb.BankingSystem.CreateTransfer:input_type -> pb.CreateTransferRequest
+	7,  // 7: pb.BankingSystem.CreateUser:output_type -> pb.CreateUserResponse
+	8,  // 8: pb.BankingSystem.LoginUser:output_type -> pb.LoginUserResponse
+	9,  // 9: pb.BankingSystem.UpdateUser:output_type -> pb.UpdateUserResponse
+	10, // 10: pb.BankingSystem.CreateAccount:output_type -> pb.CreateAccountResponse
+	11, // 11: pb.BankingSystem.GetAccount:output_type -> pb.GetAccountResponse
+	12, // 12: pb.BankingSystem.ListAccounts:output_type -> pb.ListAccountsResponse
+	13, // 13: pb.BankingSystem.CreateTransfer:output_type -> pb.CreateTransferResponse
+	7,  // [7:14] is the sub-list for method output_type
+	0,  // [0:7] is the sub-list for method input_type
+	0,  // [0:0] is the sub-list for extension type_name
+	0,  // [0:0] is the sub-list for extension extendee
+	0,  // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_service_banking_system_proto_init() }
+func file_service_banking_system_proto_init() {
+	if File_service_banking_system_proto != nil {
+		return
+	}
+	file_rpc_create_account_proto_init()
+	file_rpc_create_transfer_proto_init()
+	file_rpc_create_user_proto_init()
+	file_rpc_get_account_proto_init()
+	file_rpc_list_accounts_proto_init()
+	file_rpc_login_user_proto_init()
+	file_rpc_update_user_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_service_banking_system_proto_rawDesc), len(file_service_banking_system_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   0,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_service_banking_system_proto_goTypes,
+		DependencyIndexes: file_service_banking_system_proto_depIdxs,
+	}.Build()
+	File_service_banking_system_proto = out.File
+	file_service_banking_system_proto_goTypes = nil
+	file_service_banking_system_proto_depIdxs = nil
+}
@@ -0,0 +1,140 @@
+package stream
+
+import (
+	"sync"
+
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+)
+
+// subscriptionBuffer is how many events a subscriber can fall behind before it
+// is dropped. A dropped client reconnects and resumes from its last event ID.
+const subscriptionBuffer = 64
+
+type subscriberKey struct {
+	tenantID string
+	owner    string
+}
+
+// Broker fans account events out to the subscribers of their owner in this
+// process. Events reach every replica through Listen, so a client only needs
+// to be connected to one of them.
+type Broker struct {
+	mu          sync.Mutex
+	closed      bool
+	subscribers map[subscriberKey]map[*Subscription]struct{}
+}
+
+func NewBroker() *Broker {
+	return &Broker{
+		subscribers: make(map[subscriberKey]map[*Subscription]struct{}),
+	}
+}
+
+// Subscription receives the events of one owner on C. C is closed when the
+// subscriber falls behind, the broker loses its connection to the database or
+// the broker is closed; the events missed since then have to be read back from
+// the store.
+type Subscription struct {
+	C <-chan db.AccountEvent
+
+	ch     chan db.AccountEvent
+	key    subscriberKey
+	broker *Broker
+}
+
+// Subscribe returns a subscription to the events of owner in tenantID. The
+// caller must Close it.
+func (broker *Broker) Subscribe(tenantID string, owner string) *Subscription {
+	ch := make(chan db.AccountEvent, subscriptionBuffer)
+	sub := &Subscription{
+		C:      ch,
+		ch:     ch,
+		key:    subscriberKey{tenantID: tenantID, owner: owner},
+		broker: broker,
+	}
+
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+
+	if broker.closed {
+		close(ch)
+		return sub
+	}
+
+	subs, ok := broker.subscribers[sub.key]
+	if !ok {
+		subs = make(map[*Subscription]struct{})
+		broker.subscribers[sub.key] = subs
+	}
+	subs[sub] = struct{}{}
+	return sub
+}
+
+// Close unsubscribes and closes C. It is safe to call more than once.
+func (sub *Subscription) Close() {
+	sub.broker.mu.Lock()
+	defer sub.broker.mu.Unlock()
+
+	sub.broker.remove(sub)
+}
+
+// Publish delivers event to the subscribers of its owner without blocking.
+func (broker *Broker) Publish(event db.AccountEvent) {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+
+	key := subscriberKey{tenantID: event.TenantID, owner: event.Owner}
+	for sub := range broker.subscribers[key] {
+		select {
+		case sub.ch <- event:
+		default:
+			broker.remove(sub)
+		}
+	}
+}
+
+// Reset drops every subscriber. It is called when events may have been missed,
+// so that clients reconnect and read them back from the store.
+func (broker *Broker) Reset() {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+
+	broker.removeAll()
+}
+
+// Close drops every subscriber and closes new subscriptions immediately, which
+// ends the open streams on shutdown.
+func (broker *Broker) Close() {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+
+	broker.closed = true
+	broker.removeAll()
+}
+
+// remove must be called with mu held.
+func (broker *Broker) remove(sub *Subscription) {
+	subs, ok := broker.subscribers[sub.key]
+	if !ok {
+		return
+	}
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(broker.subscribers, sub.key)
+	}
+	close(sub.ch)
+}
+
+// removeAll must be called with mu held.
+func (broker *Broker) removeAll() {
+	for _, subs := range broker.subscribers {
+		for sub := range subs {
+			close(sub.ch)
+		}
+	}
+	broker.subscribers = make(map[subscriberKey]map[*Subscription]struct{})
+}
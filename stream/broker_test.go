@@ -0,0 +1,104 @@
+package stream
+
+import (
+	"testing"
+
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func randomEvent(id int64, tenantID string, owner string) db.AccountEvent {
+	return db.AccountEvent{
+		ID:        id,
+		AccountID: 1,
+		Owner:     owner,
+		TenantID:  tenantID,
+		EventType: db.AccountEventTransferReceived,
+		Payload:   []byte(`{}`),
+	}
+}
+
+// requireClosed fails unless C is closed after the events already buffered.
+func requireClosed(t *testing.T, sub *Subscription) {
+	for {
+		select {
+		case _, ok := <-sub.C:
+			if !ok {
+				return
+			}
+		default:
+			t.Fatal("subscription is still open")
+		}
+	}
+}
+
+func TestBrokerPublish(t *testing.T) {
+	broker := NewBroker()
+
+	alice := broker.Subscribe("default", "alice")
+	defer alice.Close()
+	aliceOtherTab := broker.Subscribe("default", "alice")
+	defer aliceOtherTab.Close()
+	bob := broker.Subscribe("default", "bob")
+	defer bob.Close()
+	aliceOtherTenant := broker.Subscribe("acme", "alice")
+	defer aliceOtherTenant.Close()
+
+	event := randomEvent(1, "default", "alice")
+	broker.Publish(event)
+
+	require.Equal(t, event, <-alice.C)
+	require.Equal(t, event, <-aliceOtherTab.C)
+	require.Empty(t, bob.C)
+	require.Empty(t, aliceOtherTenant.C)
+}
+
+func TestBrokerDropsSlowSubscriber(t *testing.T) {
+	broker := NewBroker()
+
+	slow := broker.Subscribe("default", "alice")
+	defer slow.Close()
+
+	for i := 0; i <= subscriptionBuffer; i++ {
+		broker.Publish(randomEvent(int64(i+1), "default", "alice"))
+	}
+
+	require.Len(t, slow.C, subscriptionBuffer)
+	requireClosed(t, slow)
+
+	// Later events go to new subscribers only.
+	next := broker.Subscribe("default", "alice")
+	defer next.Close()
+	broker.Publish(randomEvent(100, "default", "alice"))
+	require.Equal(t, int64(100), (<-next.C).ID)
+}
+
+func TestBrokerReset(t *testing.T) {
+	broker := NewBroker()
+
+	sub := broker.Subscribe("default", "alice")
+	broker.Reset()
+	requireClosed(t, sub)
+
+	// Closing after the broker dropped it must not panic.
+	sub.Close()
+
+	next := broker.Subscribe("default", "alice")
+	defer next.Close()
+	broker.Publish(randomEvent(1, "default", "alice"))
+	require.Equal(t, int64(1), (<-next.C).ID)
+}
+
+func TestBrokerClose(t *testing.T) {
+	broker := NewBroker()
+
+	sub := broker.Subscribe("default", "alice")
+	broker.Close()
+	requireClosed(t, sub)
+
+	late := broker.Subscribe("default", "alice")
+	requireClosed(t, late)
+	late.Close()
+
+	broker.Publish(randomEvent(1, "default", "alice"))
+}
@@ -0,0 +1,69 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+const reconnectDelay = 5 * time.Second
+
+// Listen publishes the account events notified on db.AccountEventsChannel to
+// broker until ctx is cancelled, then closes broker. It listens on its own
+// connection rather than a pooled one, and reconnects when the connection is
+// lost.
+func Listen(ctx context.Context, connPool *pgxpool.Pool, broker *Broker) error {
+	defer broker.Close()
+
+	for {
+		err := listen(ctx, connPool, broker)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		// Notifications sent while reconnecting are lost, so make the clients
+		// resume from the store.
+		broker.Reset()
+		log.Error().Err(err).Dur("retry_in", reconnectDelay).Msg("account event listener failed")
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+func listen(ctx context.Context, connPool *pgxpool.Pool, broker *Broker) error {
+	conn, err := pgx.ConnectConfig(ctx, connPool.Config().ConnConfig.Copy())
+	if err != nil {
+		return fmt.Errorf("cannot connect to db: %w", err)
+	}
+	defer conn.Close(context.Background())
+
+	channel := pgx.Identifier{db.AccountEventsChannel}.Sanitize()
+	if _, err := conn.Exec(ctx, "LISTEN "+channel); err != nil {
+		return fmt.Errorf("cannot listen on %s: %w", channel, err)
+	}
+	log.Info().Str("channel", db.AccountEventsChannel).Msg("listening for account events")
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var event db.AccountEvent
+		if err := json.Unmarshal([]byte(notification.Payload), &event); err != nil {
+			log.Error().Err(err).Msg("cannot decode account event")
+			continue
+		}
+		broker.Publish(event)
+	}
+}
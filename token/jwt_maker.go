@@ -21,8 +21,8 @@ func NewJWTMaker(secretKey string) (Maker, error) {
 	return &JWTMaker{secretKey}, nil
 }
 
-func (maker *JWTMaker) CreateToken(username string, role string, duration time.Duration, tokenType TokenType) (string, *Payload, error) {
-	payload, err := NewPayload(username, role, duration, tokenType)
+func (maker *JWTMaker) CreateToken(username string, role string, tenantID string, duration time.Duration, tokenType TokenType) (string, *Payload, error) {
+	payload, err := NewPayload(username, role, tenantID, duration, tokenType)
 	if err != nil {
 		return "", payload, err
 	}
@@ -21,12 +21,13 @@ func TestJWTMaker(t *testing.T) {
 
 	username := util.RandomOwner()
 	role := util.DepositorRole
+	tenantID := util.DefaultTenant
 	duration := time.Minute
 
 	issuedAt := time.Now()
 	expiredAt := issuedAt.Add(duration)
 
-	token, payload, err := maker.CreateToken(username, role, duration, TokenTypeAccessToken)
+	token, payload, err := maker.CreateToken(username, role, tenantID, duration, TokenTypeAccessToken)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 	require.NotEmpty(t, payload)
@@ -38,6 +39,7 @@ func TestJWTMaker(t *testing.T) {
 	require.NotZero(t, payload.ID)
 	require.Equal(t, username, payload.Username)
 	require.Equal(t, role, payload.Role)
+	require.Equal(t, tenantID, payload.TenantID)
 	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
 	require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
 }
@@ -46,7 +48,7 @@ func TestExpiredJWTToken(t *testing.T) {
 	maker, err := NewJWTMaker(util.RandomString(32))
 	require.NoError(t, err)
 
-	token, payload, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, -time.Minute, TokenTypeAccessToken)
+	token, payload, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, util.DefaultTenant, -time.Minute, TokenTypeAccessToken)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 	require.NotEmpty(t, payload)
@@ -58,7 +60,7 @@ func TestExpiredJWTToken(t *testing.T) {
 }
 
 func TestInvalidJWTTokenAlgNone(t *testing.T) {
-	payload, err := NewPayload(util.RandomOwner(), util.DepositorRole, time.Minute, TokenTypeAccessToken)
+	payload, err := NewPayload(util.RandomOwner(), util.DepositorRole, util.DefaultTenant, time.Minute, TokenTypeAccessToken)
 	require.NoError(t, err)
 
 	jwtToken := jwt.NewWithClaims(jwt.SigningMethodNone, payload)
@@ -78,7 +80,7 @@ func TestJWTWrongTokenType(t *testing.T) {
 	maker, err := NewJWTMaker(util.RandomString(32))
 	require.NoError(t, err)
 
-	token, payload, err := maker.CreateToken(util.RandomOwner(), util.BankerRole, time.Minute, TokenTypeAccessToken)
+	token, payload, err := maker.CreateToken(util.RandomOwner(), util.BankerRole, util.DefaultTenant, time.Minute, TokenTypeAccessToken)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 	require.NotEmpty(t, payload)
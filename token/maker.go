@@ -4,8 +4,8 @@ import "time"
 
 // Maker is an interface for managing tokens
 type Maker interface {
-	// CreateToken creates a new token for a specific username and duration
-	CreateToken(username string, role string, duration time.Duration, tokenType TokenType) (string, *Payload, error)
+	// CreateToken creates a new token for a specific username, tenant and duration
+	CreateToken(username string, role string, tenantID string, duration time.Duration, tokenType TokenType) (string, *Payload, error)
 
 	// VerifyToken checks if the token is valid or not
 	VerifyToken(token string, tokenType TokenType) (*Payload, error)
@@ -20,12 +20,13 @@ func TestPasetoMaker(t *testing.T) {
 
 	username := util.RandomOwner()
 	role := util.DepositorRole
+	tenantID := util.DefaultTenant
 	duration := time.Minute
 
 	issuedAt := time.Now()
 	expiredAt := issuedAt.Add(duration)
 
-	token, payload, err := maker.CreateToken(username, role, duration, TokenTypeAccessToken)
+	token, payload, err := maker.CreateToken(username, role, tenantID, duration, TokenTypeAccessToken)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 	require.NotEmpty(t, payload)
@@ -37,6 +38,7 @@ func TestPasetoMaker(t *testing.T) {
 	require.NotZero(t, payload.ID)
 	require.Equal(t, username, payload.Username)
 	require.Equal(t, role, payload.Role)
+	require.Equal(t, tenantID, payload.TenantID)
 	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
 	require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
 }
@@ -45,7 +47,7 @@ func TestExpiredPasetoToken(t *testing.T) {
 	maker, err := NewPasetoMaker(util.RandomString(32))
 	require.NoError(t, err)
 
-	token, payload, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, -time.Minute, TokenTypeAccessToken)
+	token, payload, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, util.DefaultTenant, -time.Minute, TokenTypeAccessToken)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 	require.NotEmpty(t, payload)
@@ -60,7 +62,7 @@ func TestPasetoWrongTokenType(t *testing.T) {
 	maker, err := NewPasetoMaker(util.RandomString(32))
 	require.NoError(t, err)
 
-	token, payload, err := maker.CreateToken(util.RandomOwner(), util.BankerRole, time.Minute, TokenTypeAccessToken)
+	token, payload, err := maker.CreateToken(util.RandomOwner(), util.BankerRole, util.DefaultTenant, time.Minute, TokenTypeAccessToken)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 	require.NotEmpty(t, payload)
@@ -25,11 +25,12 @@ type Payload struct {
 	Type      TokenType `json:"token_type"`
 	Username  string    `json:"username"`
 	Role      string    `json:"role"`
+	TenantID  string    `json:"tenant_id"`
 	IssuedAt  time.Time `json:"issued_at"`
 	ExpiredAt time.Time `json:"expired_at"`
 }
 
-func NewPayload(username string, role string, duration time.Duration, tokenType TokenType) (*Payload, error) {
+func NewPayload(username string, role string, tenantID string, duration time.Duration, tokenType TokenType) (*Payload, error) {
 	tokenID, err := uuid.NewRandom()
 	if err != nil {
 		return nil, err
@@ -40,6 +41,7 @@ func NewPayload(username string, role string, duration time.Duration, tokenType
 		Type:      tokenType,
 		Username:  username,
 		Role:      role,
+		TenantID:  tenantID,
 		IssuedAt:  time.Now(),
 		ExpiredAt: time.Now().Add(duration),
 	}
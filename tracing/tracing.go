@@ -0,0 +1,78 @@
+// Package tracing sets up OpenTelemetry tracing for the serve and worker
+// commands. Spans of HTTP requests, SQL queries and background tasks share
+// the W3C trace context, so a task run by a worker continues the trace of the
+// request that enqueued it.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/LamThanhNguyen/banking-system/util"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Service names of the commands that export spans
+const (
+	ServiceAPI    = "banking-system-api"
+	ServiceWorker = "banking-system-worker"
+)
+
+// Setup installs the global propagator and, when TRACING_EXPORTER is set, a
+// tracer provider that exports the spans of serviceName. The returned
+// function flushes the spans that are still buffered and must be called
+// before exiting.
+func Setup(ctx context.Context, config util.RuntimeConfig, serviceName string) (func(context.Context) error, error) {
+	// Trace context is propagated even when this process exports nothing,
+	// so that callers and workers can still join their spans.
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch config.TracingExporter {
+	case "":
+		return func(context.Context) error { return nil }, nil
+	case util.TracingExporterOTLP:
+		var opts []otlptracegrpc.Option
+		if config.TracingOTLPEndpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpointURL(config.TracingOTLPEndpoint))
+		}
+		exporter, err = otlptracegrpc.New(ctx, opts...)
+	case util.TracingExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	default:
+		return nil, fmt.Errorf("unsupported tracing exporter %q", config.TracingExporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot create %s span exporter: %w", config.TracingExporter, err)
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(
+			semconv.ServiceName(serviceName),
+			semconv.DeploymentEnvironment(config.Environment),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create tracing resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
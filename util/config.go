@@ -22,21 +22,94 @@ type Config struct {
 	MigrationURL         string   `mapstructure:"MIGRATION_URL" json:"MIGRATION_URL"`
 	RedisAddress         string   `mapstructure:"REDIS_ADDRESS" json:"REDIS_ADDRESS"`
 	HTTPServerAddress    string   `mapstructure:"HTTP_SERVER_ADDRESS" json:"HTTP_SERVER_ADDRESS"`
+	GRPCServerAddress    string   `mapstructure:"GRPC_SERVER_ADDRESS" json:"GRPC_SERVER_ADDRESS"`
+	GatewayServerAddress string   `mapstructure:"GRPC_GATEWAY_ADDRESS" json:"GRPC_GATEWAY_ADDRESS"`
 	TokenSymmetricKey    string   `mapstructure:"TOKEN_SYMMETRIC_KEY" json:"TOKEN_SYMMETRIC_KEY"`
 	AccessTokenDuration  string   `mapstructure:"ACCESS_TOKEN_DURATION" json:"ACCESS_TOKEN_DURATION"`
 	RefreshTokenDuration string   `mapstructure:"REFRESH_TOKEN_DURATION" json:"REFRESH_TOKEN_DURATION"`
 	EmailSenderName      string   `mapstructure:"EMAIL_SENDER_NAME" json:"EMAIL_SENDER_NAME"`
 	EmailSenderAddress   string   `mapstructure:"EMAIL_SENDER_ADDRESS" json:"EMAIL_SENDER_ADDRESS"`
 	EmailSenderPassword  string   `mapstructure:"EMAIL_SENDER_PASSWORD" json:"EMAIL_SENDER_PASSWORD"`
+	EmailTemplateDir     string   `mapstructure:"EMAIL_TEMPLATE_DIR" json:"EMAIL_TEMPLATE_DIR"`
+	EmailTransport       string   `mapstructure:"EMAIL_TRANSPORT" json:"EMAIL_TRANSPORT"`
+	EmailMaildir         string   `mapstructure:"EMAIL_MAILDIR" json:"EMAIL_MAILDIR"`
+	SMTPHost             string   `mapstructure:"SMTP_HOST" json:"SMTP_HOST"`
+	SMTPPort             int      `mapstructure:"SMTP_PORT" json:"SMTP_PORT"`
+	SMTPUsername         string   `mapstructure:"SMTP_USERNAME" json:"SMTP_USERNAME"`
+	SMTPPassword         string   `mapstructure:"SMTP_PASSWORD" json:"SMTP_PASSWORD"`
+	SMTPTLSMode          string   `mapstructure:"SMTP_TLS_MODE" json:"SMTP_TLS_MODE"`
+	DKIMDomain           string   `mapstructure:"DKIM_DOMAIN" json:"DKIM_DOMAIN"`
+	DKIMSelector         string   `mapstructure:"DKIM_SELECTOR" json:"DKIM_SELECTOR"`
+	DKIMPrivateKey       string   `mapstructure:"DKIM_PRIVATE_KEY" json:"DKIM_PRIVATE_KEY"`
 	FrontendDomain       string   `mapstructure:"FRONTEND_DOMAIN" json:"FRONTEND_DOMAIN"`
+	LogAuthzDenials      bool     `mapstructure:"LOG_AUTHZ_DENIALS" json:"LOG_AUTHZ_DENIALS"`
+	RequireVerifiedEmail bool     `mapstructure:"REQUIRE_VERIFIED_EMAIL" json:"REQUIRE_VERIFIED_EMAIL"`
+	VerifyEmailCooldown  string   `mapstructure:"VERIFY_EMAIL_COOLDOWN" json:"VERIFY_EMAIL_COOLDOWN"`
+	// Cron specs of the maintenance jobs; "off" disables a job.
+	PruneSessionsSchedule        string `mapstructure:"PRUNE_SESSIONS_SCHEDULE" json:"PRUNE_SESSIONS_SCHEDULE"`
+	PruneVerifyEmailsSchedule    string `mapstructure:"PRUNE_VERIFY_EMAILS_SCHEDULE" json:"PRUNE_VERIFY_EMAILS_SCHEDULE"`
+	PruneOutboxSchedule          string `mapstructure:"PRUNE_OUTBOX_SCHEDULE" json:"PRUNE_OUTBOX_SCHEDULE"`
+	PruneIdempotencyKeysSchedule string `mapstructure:"PRUNE_IDEMPOTENCY_KEYS_SCHEDULE" json:"PRUNE_IDEMPOTENCY_KEYS_SCHEDULE"`
+	ReconcileSchedule            string `mapstructure:"RECONCILE_SCHEDULE" json:"RECONCILE_SCHEDULE"`
+	MaintenanceRetention         string `mapstructure:"MAINTENANCE_RETENTION" json:"MAINTENANCE_RETENTION"`
+	// SMS and push providers: "http" posts to the endpoint, "log" (the default) only logs.
+	SMSProvider  string `mapstructure:"SMS_PROVIDER" json:"SMS_PROVIDER"`
+	SMSEndpoint  string `mapstructure:"SMS_ENDPOINT" json:"SMS_ENDPOINT"`
+	SMSAPIKey    string `mapstructure:"SMS_API_KEY" json:"SMS_API_KEY"`
+	PushProvider string `mapstructure:"PUSH_PROVIDER" json:"PUSH_PROVIDER"`
+	PushEndpoint string `mapstructure:"PUSH_ENDPOINT" json:"PUSH_ENDPOINT"`
+	PushAPIKey   string `mapstructure:"PUSH_API_KEY" json:"PUSH_API_KEY"`
+	// TaskQueue selects where background tasks are queued: "redis" (the default) or "memory".
+	TaskQueue string `mapstructure:"TASK_QUEUE" json:"TASK_QUEUE"`
+	// How often an idle account event stream sends a heartbeat comment.
+	StreamHeartbeatInterval string `mapstructure:"STREAM_HEARTBEAT_INTERVAL" json:"STREAM_HEARTBEAT_INTERVAL"`
+	// How long readiness fails before the servers stop on shutdown, so load balancers stop routing first.
+	ShutdownDrainDelay string `mapstructure:"SHUTDOWN_DRAIN_DELAY" json:"SHUTDOWN_DRAIN_DELAY"`
+	// Admin listen address of the Prometheus /metrics endpoint; empty disables it.
+	MetricsServerAddress string `mapstructure:"METRICS_SERVER_ADDRESS" json:"METRICS_SERVER_ADDRESS"`
+	// TracingExporter selects where spans are sent: "otlp", "stdout", or "" (the default) to disable tracing.
+	TracingExporter string `mapstructure:"TRACING_EXPORTER" json:"TRACING_EXPORTER"`
+	// OTLP/gRPC collector URL, such as http://otel-collector:4317; the OTEL_EXPORTER_OTLP_* variables apply when empty.
+	TracingOTLPEndpoint string `mapstructure:"TRACING_OTLP_ENDPOINT" json:"TRACING_OTLP_ENDPOINT"`
 }
 
 type RuntimeConfig struct {
 	Config
 	AccessTokenDurationParsed  time.Duration
 	RefreshTokenDurationParsed time.Duration
+	VerifyEmailCooldownParsed  time.Duration
+	MaintenanceRetentionParsed time.Duration
+	StreamHeartbeatParsed      time.Duration
+	ShutdownDrainDelayParsed   time.Duration
 }
 
+const (
+	defaultVerifyEmailCooldown       = time.Minute
+	defaultPruneSessionsSchedule     = "@hourly"
+	defaultPruneVerifyEmailsSchedule = "@hourly"
+	defaultPruneOutboxSchedule       = "@hourly"
+	defaultPruneIdempotencySchedule  = "@hourly"
+	defaultReconcileSchedule         = "0 2 * * *"
+	defaultMaintenanceRetention      = 24 * time.Hour
+	defaultStreamHeartbeatInterval   = 15 * time.Second
+	defaultShutdownDrainDelay        = 10 * time.Second
+)
+
+// ScheduleOff disables a maintenance job.
+const ScheduleOff = "off"
+
+// Span exporters selectable with TRACING_EXPORTER
+const (
+	TracingExporterOTLP   = "otlp"
+	TracingExporterStdout = "stdout"
+)
+
+// Task queues selectable with TASK_QUEUE
+const (
+	TaskQueueRedis  = "redis"
+	TaskQueueMemory = "memory"
+)
+
 // LoadConfig reads configuration from file or environment variables.
 func LoadConfig(ctx context.Context, path string) (Config, error) {
 	environment := strings.ToLower(os.Getenv("ENVIRONMENT"))
@@ -94,9 +167,68 @@ func NewRuntimeConfig(cfg Config) (RuntimeConfig, error) {
 	if err != nil {
 		return RuntimeConfig{}, fmt.Errorf("invalid REFRESH_TOKEN_DURATION: %w", err)
 	}
+	vec := defaultVerifyEmailCooldown
+	if cfg.VerifyEmailCooldown != "" {
+		vec, err = time.ParseDuration(cfg.VerifyEmailCooldown)
+		if err != nil {
+			return RuntimeConfig{}, fmt.Errorf("invalid VERIFY_EMAIL_COOLDOWN: %w", err)
+		}
+	}
+	mr := defaultMaintenanceRetention
+	if cfg.MaintenanceRetention != "" {
+		mr, err = time.ParseDuration(cfg.MaintenanceRetention)
+		if err != nil {
+			return RuntimeConfig{}, fmt.Errorf("invalid MAINTENANCE_RETENTION: %w", err)
+		}
+	}
+	shi := defaultStreamHeartbeatInterval
+	if cfg.StreamHeartbeatInterval != "" {
+		shi, err = time.ParseDuration(cfg.StreamHeartbeatInterval)
+		if err != nil || shi <= 0 {
+			return RuntimeConfig{}, fmt.Errorf("invalid STREAM_HEARTBEAT_INTERVAL: %q", cfg.StreamHeartbeatInterval)
+		}
+	}
+	sdd := defaultShutdownDrainDelay
+	if cfg.ShutdownDrainDelay != "" {
+		sdd, err = time.ParseDuration(cfg.ShutdownDrainDelay)
+		if err != nil || sdd < 0 {
+			return RuntimeConfig{}, fmt.Errorf("invalid SHUTDOWN_DRAIN_DELAY: %q", cfg.ShutdownDrainDelay)
+		}
+	}
+	if cfg.PruneSessionsSchedule == "" {
+		cfg.PruneSessionsSchedule = defaultPruneSessionsSchedule
+	}
+	if cfg.PruneVerifyEmailsSchedule == "" {
+		cfg.PruneVerifyEmailsSchedule = defaultPruneVerifyEmailsSchedule
+	}
+	if cfg.PruneOutboxSchedule == "" {
+		cfg.PruneOutboxSchedule = defaultPruneOutboxSchedule
+	}
+	if cfg.PruneIdempotencyKeysSchedule == "" {
+		cfg.PruneIdempotencyKeysSchedule = defaultPruneIdempotencySchedule
+	}
+	if cfg.ReconcileSchedule == "" {
+		cfg.ReconcileSchedule = defaultReconcileSchedule
+	}
+	switch cfg.TaskQueue {
+	case "":
+		cfg.TaskQueue = TaskQueueRedis
+	case TaskQueueRedis, TaskQueueMemory:
+	default:
+		return RuntimeConfig{}, fmt.Errorf("invalid TASK_QUEUE: %q", cfg.TaskQueue)
+	}
+	switch cfg.TracingExporter {
+	case "", TracingExporterOTLP, TracingExporterStdout:
+	default:
+		return RuntimeConfig{}, fmt.Errorf("invalid TRACING_EXPORTER: %q", cfg.TracingExporter)
+	}
 	return RuntimeConfig{
 		Config:                     cfg,
 		AccessTokenDurationParsed:  atd,
 		RefreshTokenDurationParsed: rtd,
+		VerifyEmailCooldownParsed:  vec,
+		MaintenanceRetentionParsed: mr,
+		StreamHeartbeatParsed:      shi,
+		ShutdownDrainDelayParsed:   sdd,
 	}, nil
 }
@@ -0,0 +1,7 @@
+package util
+
+import "time"
+
+// IdempotencyKeyTTL is how long an idempotency key keeps its response; after
+// that it can be reused, and the maintenance job may delete it.
+const IdempotencyKeyTTL = 24 * time.Hour
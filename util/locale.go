@@ -0,0 +1,17 @@
+package util
+
+// Constants for all supported locales
+const (
+	EnglishLocale    = "en"
+	VietnameseLocale = "vi"
+	DefaultLocale    = EnglishLocale
+)
+
+// IsSupportedLocale returns true if the locale is supported
+func IsSupportedLocale(locale string) bool {
+	switch locale {
+	case EnglishLocale, VietnameseLocale:
+		return true
+	}
+	return false
+}
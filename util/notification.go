@@ -0,0 +1,56 @@
+package util
+
+// Constants for all notification event types
+const (
+	NotificationTransferSent     = "transfer_sent"
+	NotificationTransferReceived = "transfer_received"
+	NotificationTransferFailed   = "transfer_failed"
+	NotificationLowBalance       = "low_balance"
+	NotificationLargeTransaction = "large_transaction"
+)
+
+// Constants for all notification channels
+const (
+	ChannelEmail = "email"
+	ChannelSMS   = "sms"
+	ChannelPush  = "push"
+)
+
+// IsSupportedNotificationEvent returns true if the notification event type is supported
+func IsSupportedNotificationEvent(event string) bool {
+	switch event {
+	case NotificationTransferSent, NotificationTransferReceived, NotificationTransferFailed,
+		NotificationLowBalance, NotificationLargeTransaction:
+		return true
+	}
+	return false
+}
+
+// IsSupportedNotificationChannel returns true if the notification channel is supported
+func IsSupportedNotificationChannel(channel string) bool {
+	switch channel {
+	case ChannelEmail, ChannelSMS, ChannelPush:
+		return true
+	}
+	return false
+}
+
+// IsNotificationAlert reports whether the event is a threshold alert. Thresholds are
+// amounts in the minor unit of a currency, so alerts are set per currency.
+func IsNotificationAlert(event string) bool {
+	return event == NotificationLowBalance || event == NotificationLargeTransaction
+}
+
+// IsNotificationEnabledByDefault reports whether an event is delivered on a channel to a
+// user who has no stored preference for it. Alerts need a threshold and SMS and push
+// cost money or attention, so they are opt-in.
+func IsNotificationEnabledByDefault(event string, channel string) bool {
+	if channel != ChannelEmail {
+		return false
+	}
+	switch event {
+	case NotificationTransferSent, NotificationTransferReceived, NotificationTransferFailed:
+		return true
+	}
+	return false
+}
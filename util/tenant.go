@@ -0,0 +1,5 @@
+package util
+
+// DefaultTenant is the tenant every pre-existing user, account and transfer belongs to.
+// Bankers of this tenant operate the platform and may provision new tenants.
+const DefaultTenant = "default"
@@ -0,0 +1,16 @@
+package util
+
+// Constants for all webhook event types
+const (
+	WebhookAccountCreated  = "account.created"
+	WebhookTransferCreated = "transfer.created"
+)
+
+// IsSupportedWebhookEvent returns true if the webhook event type is supported
+func IsSupportedWebhookEvent(event string) bool {
+	switch event {
+	case WebhookAccountCreated, WebhookTransferCreated:
+		return true
+	}
+	return false
+}
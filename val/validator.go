@@ -3,12 +3,21 @@ package val
 import (
 	"fmt"
 	"net/mail"
+	"net/netip"
+	"net/url"
 	"regexp"
+	"strings"
 )
 
 var (
 	isValidUsername = regexp.MustCompile(`^[a-z0-9_]+$`).MatchString
 	isValidFullname = regexp.MustCompile(`^[a-zA-Z\s]+$`).MatchString
+	isValidTenantID = regexp.MustCompile(`^[a-z0-9-]+$`).MatchString
+	isValidPhone    = regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`).MatchString
+	isValidOTP      = regexp.MustCompile(`^[0-9]{6}$`).MatchString
+
+	// sharedAddressSpace is the carrier-grade NAT range of RFC 6598.
+	sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
 )
 
 func ValidateString(value string, minLength int, maxLength int) error {
@@ -39,6 +48,16 @@ func ValidateFullname(value string) error {
 	return nil
 }
 
+func ValidateTenantID(value string) error {
+	if err := ValidateString(value, 3, 20); err != nil {
+		return err
+	}
+	if !isValidTenantID(value) {
+		return fmt.Errorf("must contain only lowercase letters, digits, or hyphen")
+	}
+	return nil
+}
+
 func ValidatePassword(value string) error {
 	return ValidateString(value, 8, 50)
 }
@@ -63,3 +82,47 @@ func ValidateEmailId(value int64) error {
 func ValidateSecretCode(value string) error {
 	return ValidateString(value, 32, 128)
 }
+
+// ValidateWebhookURL accepts absolute http and https URLs whose host is a
+// name or a public IP address. Names are only resolved when the worker dials
+// them, which checks the address again.
+func ValidateWebhookURL(value string) error {
+	if err := ValidateString(value, 10, 2048); err != nil {
+		return err
+	}
+	u, err := url.Parse(value)
+	if err != nil || u.Hostname() == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("must be an absolute http or https URL")
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("must not point to a local address")
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !IsPublicAddr(addr) {
+		return fmt.Errorf("must not point to a local address")
+	}
+	return nil
+}
+
+// IsPublicAddr reports whether addr can be reached on the internet: it is not
+// unspecified, loopback, private, link-local, multicast or shared (CGNAT).
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() && !addr.IsPrivate() && !sharedAddressSpace.Contains(addr)
+}
+
+// ValidatePhone accepts phone numbers in E.164 format, such as +84901234567.
+func ValidatePhone(value string) error {
+	if !isValidPhone(value) {
+		return fmt.Errorf("must be a phone number in E.164 format")
+	}
+	return nil
+}
+
+func ValidateOTP(value string) error {
+	if !isValidOTP(value) {
+		return fmt.Errorf("must be a 6-digit code")
+	}
+	return nil
+}
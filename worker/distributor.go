@@ -2,8 +2,13 @@ package worker
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 
+	"github.com/LamThanhNguyen/banking-system/logging"
+	"github.com/LamThanhNguyen/banking-system/metrics"
 	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
 )
 
 type TaskDistributor interface {
@@ -12,6 +17,26 @@ type TaskDistributor interface {
 		payload *PayloadSendVerifyEmail,
 		opts ...asynq.Option,
 	) error
+	DistributeTaskSendVerifyPhone(
+		ctx context.Context,
+		payload *PayloadSendVerifyPhone,
+		opts ...asynq.Option,
+	) error
+	DistributeTaskSendTransferNotification(
+		ctx context.Context,
+		payload *PayloadSendTransferNotification,
+		opts ...asynq.Option,
+	) error
+	DistributeTaskSendTransferFailedNotification(
+		ctx context.Context,
+		payload *PayloadSendTransferFailedNotification,
+		opts ...asynq.Option,
+	) error
+	DistributeTaskDeliverWebhook(
+		ctx context.Context,
+		payload *PayloadDeliverWebhook,
+		opts ...asynq.Option,
+	) error
 }
 
 type RedisTaskDistributor struct {
@@ -24,3 +49,26 @@ func NewRedisTaskDistributor(redisOpt asynq.RedisClientOpt) TaskDistributor {
 		client: client,
 	}
 }
+
+func (distributor *RedisTaskDistributor) enqueue(
+	ctx context.Context,
+	taskType string,
+	payload any,
+	opts ...asynq.Option,
+) error {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+
+	task := asynq.NewTaskWithHeaders(taskType, jsonPayload, taskHeaders(ctx), opts...)
+	info, err := distributor.client.EnqueueContext(ctx, task)
+	metrics.ObserveTaskEnqueued(taskType, err)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	log.Ctx(ctx).Info().Str("type", task.Type()).RawJSON("payload", logging.RedactJSON(task.Payload())).
+		Str("queue", info.Queue).Int("max_retry", info.MaxRetry).Msg("enqueued task")
+	return nil
+}
@@ -0,0 +1,46 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/LamThanhNguyen/banking-system/logging"
+	"github.com/LamThanhNguyen/banking-system/metrics"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+// MemoryTaskDistributor enqueues tasks into a MemoryQueue, for running without Redis.
+type MemoryTaskDistributor struct {
+	queue *MemoryQueue
+}
+
+func NewMemoryTaskDistributor(queue *MemoryQueue) TaskDistributor {
+	return &MemoryTaskDistributor{
+		queue: queue,
+	}
+}
+
+func (distributor *MemoryTaskDistributor) enqueue(
+	ctx context.Context,
+	taskType string,
+	payload any,
+	opts ...asynq.Option,
+) error {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+
+	task := asynq.NewTaskWithHeaders(taskType, jsonPayload, taskHeaders(ctx))
+	info, err := distributor.queue.EnqueueContext(ctx, task, opts...)
+	metrics.ObserveTaskEnqueued(taskType, err)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	log.Ctx(ctx).Info().Str("type", task.Type()).RawJSON("payload", logging.RedactJSON(task.Payload())).
+		Str("queue", info.Queue).Int("max_retry", info.MaxRetry).Msg("enqueued task in memory")
+	return nil
+}
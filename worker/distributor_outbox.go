@@ -0,0 +1,77 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/logging"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const defaultMaxRetry = 25
+
+// OutboxTaskDistributor writes tasks to the outbox table instead of Redis.
+// Created with the Querier of an open transaction, the tasks are committed
+// or rolled back together with the rest of the transaction, and OutboxRelay
+// publishes them to asynq afterwards.
+type OutboxTaskDistributor struct {
+	q db.Querier
+}
+
+func NewOutboxTaskDistributor(q db.Querier) TaskDistributor {
+	return &OutboxTaskDistributor{
+		q: q,
+	}
+}
+
+func (distributor *OutboxTaskDistributor) enqueue(
+	ctx context.Context,
+	taskType string,
+	payload any,
+	opts ...asynq.Option,
+) error {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+	headers, err := json.Marshal(taskHeaders(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to marshal task headers: %w", err)
+	}
+
+	arg := db.CreateOutboxParams{
+		TaskType:  taskType,
+		Payload:   jsonPayload,
+		Queue:     QueueDefault,
+		MaxRetry:  defaultMaxRetry,
+		ProcessAt: time.Now(),
+		Headers:   headers,
+	}
+	for _, opt := range opts {
+		switch opt.Type() {
+		case asynq.QueueOpt:
+			arg.Queue = opt.Value().(string)
+		case asynq.MaxRetryOpt:
+			arg.MaxRetry = int32(opt.Value().(int))
+		case asynq.ProcessInOpt:
+			arg.ProcessAt = time.Now().Add(opt.Value().(time.Duration))
+		case asynq.ProcessAtOpt:
+			arg.ProcessAt = opt.Value().(time.Time)
+		default:
+			return fmt.Errorf("unsupported outbox task option: %s", opt.String())
+		}
+	}
+
+	msg, err := distributor.q.CreateOutbox(ctx, arg)
+	if err != nil {
+		return fmt.Errorf("failed to write task to outbox: %w", err)
+	}
+
+	log.Ctx(ctx).Info().Str("type", msg.TaskType).RawJSON("payload", logging.RedactJSON(msg.Payload)).
+		Int64("outbox_id", msg.ID).Str("queue", msg.Queue).Msg("stored task in outbox")
+	return nil
+}
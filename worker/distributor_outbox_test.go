@@ -0,0 +1,110 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	mockdb "github.com/LamThanhNguyen/banking-system/db/mock"
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestOutboxDistributeTaskSendVerifyEmail(t *testing.T) {
+	payload := &PayloadSendVerifyEmail{Username: "alice"}
+
+	testCases := []struct {
+		name       string
+		opts       []asynq.Option
+		buildStubs func(store *mockdb.MockStore)
+		checkErr   func(t *testing.T, err error)
+	}{
+		{
+			name: "OK",
+			opts: []asynq.Option{
+				asynq.MaxRetry(10),
+				asynq.ProcessIn(10 * time.Second),
+				asynq.Queue(QueueCritical),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateOutbox(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateOutboxParams) (db.Outbox, error) {
+						require.Equal(t, TaskSendVerifyEmail, arg.TaskType)
+						require.Equal(t, QueueCritical, arg.Queue)
+						require.Equal(t, int32(10), arg.MaxRetry)
+						require.WithinDuration(t, time.Now().Add(10*time.Second), arg.ProcessAt, time.Second)
+
+						var got PayloadSendVerifyEmail
+						require.NoError(t, json.Unmarshal(arg.Payload, &got))
+						require.Equal(t, *payload, got)
+
+						return db.Outbox{ID: 1, TaskType: arg.TaskType, Payload: arg.Payload, Queue: arg.Queue}, nil
+					})
+			},
+			checkErr: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "Defaults",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateOutbox(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateOutboxParams) (db.Outbox, error) {
+						require.Equal(t, QueueDefault, arg.Queue)
+						require.Equal(t, int32(defaultMaxRetry), arg.MaxRetry)
+						return db.Outbox{ID: 1}, nil
+					})
+			},
+			checkErr: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "UnsupportedOption",
+			opts: []asynq.Option{asynq.Unique(time.Minute)},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateOutbox(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkErr: func(t *testing.T, err error) {
+				require.Error(t, err)
+			},
+		},
+		{
+			name: "DBError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateOutbox(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Outbox{}, context.DeadlineExceeded)
+			},
+			checkErr: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, context.DeadlineExceeded)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			distributor := NewOutboxTaskDistributor(store)
+			err := distributor.DistributeTaskSendVerifyEmail(context.Background(), payload, tc.opts...)
+			tc.checkErr(t, err)
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package worker
+
+import (
+	"fmt"
+
+	"github.com/hibiken/asynq"
+)
+
+// TaskInspector reads and mutates the state of queued tasks, e.g. to recover
+// tasks that exhausted their retries and landed in the archived (dead-letter) set.
+type TaskInspector interface {
+	ListQueues() ([]*asynq.QueueInfo, error)
+	ListTasks(queue string, state asynq.TaskState, page, pageSize int) ([]*asynq.TaskInfo, error)
+	GetTask(queue, id string) (*asynq.TaskInfo, error)
+	RunTask(queue, id string) error
+	DeleteTask(queue, id string) error
+}
+
+type RedisTaskInspector struct {
+	inspector *asynq.Inspector
+}
+
+func NewRedisTaskInspector(redisOpt asynq.RedisClientOpt) TaskInspector {
+	return &RedisTaskInspector{
+		inspector: asynq.NewInspector(redisOpt),
+	}
+}
+
+// ListQueues returns the current stats of every known queue.
+func (i *RedisTaskInspector) ListQueues() ([]*asynq.QueueInfo, error) {
+	queues, err := i.inspector.Queues()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list queues: %w", err)
+	}
+
+	infos := make([]*asynq.QueueInfo, 0, len(queues))
+	for _, queue := range queues {
+		info, err := i.inspector.GetQueueInfo(queue)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get queue %s: %w", queue, err)
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// ListTasks returns one page of the tasks of a queue in the given state.
+func (i *RedisTaskInspector) ListTasks(
+	queue string,
+	state asynq.TaskState,
+	page, pageSize int,
+) ([]*asynq.TaskInfo, error) {
+	opts := []asynq.ListOption{asynq.Page(page), asynq.PageSize(pageSize)}
+
+	switch state {
+	case asynq.TaskStatePending:
+		return i.inspector.ListPendingTasks(queue, opts...)
+	case asynq.TaskStateActive:
+		return i.inspector.ListActiveTasks(queue, opts...)
+	case asynq.TaskStateScheduled:
+		return i.inspector.ListScheduledTasks(queue, opts...)
+	case asynq.TaskStateRetry:
+		return i.inspector.ListRetryTasks(queue, opts...)
+	case asynq.TaskStateArchived:
+		return i.inspector.ListArchivedTasks(queue, opts...)
+	case asynq.TaskStateCompleted:
+		return i.inspector.ListCompletedTasks(queue, opts...)
+	default:
+		return nil, fmt.Errorf("cannot list tasks in state %s", state)
+	}
+}
+
+func (i *RedisTaskInspector) GetTask(queue, id string) (*asynq.TaskInfo, error) {
+	return i.inspector.GetTaskInfo(queue, id)
+}
+
+// RunTask moves a scheduled, retry or archived task back to the pending state.
+func (i *RedisTaskInspector) RunTask(queue, id string) error {
+	return i.inspector.RunTask(queue, id)
+}
+
+func (i *RedisTaskInspector) DeleteTask(queue, id string) error {
+	return i.inspector.DeleteTask(queue, id)
+}
+
+// ParseTaskState converts the name of a task state, as printed by asynq, back
+// into its value.
+func ParseTaskState(name string) (asynq.TaskState, error) {
+	for _, state := range []asynq.TaskState{
+		asynq.TaskStatePending,
+		asynq.TaskStateActive,
+		asynq.TaskStateScheduled,
+		asynq.TaskStateRetry,
+		asynq.TaskStateArchived,
+		asynq.TaskStateCompleted,
+	} {
+		if state.String() == name {
+			return state, nil
+		}
+	}
+	return 0, fmt.Errorf("unsupported task state %q", name)
+}
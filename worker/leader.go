@@ -0,0 +1,64 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// acquireScript takes the lock if it is free and extends it if the caller
+// already holds it.
+var acquireScript = redis.NewScript(`
+local owner = redis.call("GET", KEYS[1])
+if owner == false then
+	redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+	return 1
+end
+if owner == ARGV[1] then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+	return 1
+end
+return 0
+`)
+
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// LeaderLock elects a single holder among replicas with a Redis key that
+// expires unless the holder keeps extending it.
+type LeaderLock struct {
+	client redis.UniversalClient
+	key    string
+	owner  string
+	ttl    time.Duration
+}
+
+func NewLeaderLock(client redis.UniversalClient, key string, ttl time.Duration) *LeaderLock {
+	return &LeaderLock{
+		client: client,
+		key:    key,
+		owner:  uuid.NewString(),
+		ttl:    ttl,
+	}
+}
+
+// TryAcquire reports whether the caller holds the lock for the next ttl,
+// taking it over when it is free.
+func (l *LeaderLock) TryAcquire(ctx context.Context) (bool, error) {
+	held, err := acquireScript.Run(ctx, l.client, []string{l.key}, l.owner, l.ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return held == 1, nil
+}
+
+// Release frees the lock if the caller still holds it.
+func (l *LeaderLock) Release(ctx context.Context) error {
+	return releaseScript.Run(ctx, l.client, []string{l.key}, l.owner).Err()
+}
@@ -4,8 +4,11 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/LamThanhNguyen/banking-system/logging"
+	"github.com/hibiken/asynq"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Logger struct{}
@@ -41,3 +44,32 @@ func (logger *Logger) Error(args ...interface{}) {
 func (logger *Logger) Fatal(args ...interface{}) {
 	logger.Print(zerolog.FatalLevel, args...)
 }
+
+// taskLogger returns a logger that adds the type and ID of task, and the
+// request ID and trace ID it was enqueued with, to every entry.
+func taskLogger(ctx context.Context, task *asynq.Task) zerolog.Logger {
+	logCtx := log.With().Str("type", task.Type())
+	if id, ok := asynq.GetTaskID(ctx); ok {
+		logCtx = logCtx.Str("task_id", id)
+	}
+	if requestID := task.Headers()[logging.RequestIDKey]; requestID != "" {
+		logCtx = logCtx.Str(logging.RequestIDKey, requestID)
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		logCtx = logCtx.Str("trace_id", sc.TraceID().String())
+	}
+	return logCtx.Logger()
+}
+
+// logTask stores the logger of every task and its request ID in the context
+// of the handler, so that the tasks it enqueues keep the request ID.
+func logTask(next asynq.Handler) asynq.Handler {
+	return asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		logger := taskLogger(ctx, task)
+		ctx = logger.WithContext(ctx)
+		if requestID := task.Headers()[logging.RequestIDKey]; requestID != "" {
+			ctx = logging.WithRequestID(ctx, requestID)
+		}
+		return next.ProcessTask(ctx, task)
+	})
+}
@@ -0,0 +1,64 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/LamThanhNguyen/banking-system/logging"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogTaskRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	prevLogger := log.Logger
+	log.Logger = zerolog.New(&buf)
+	defer func() {
+		log.Logger = prevLogger
+	}()
+
+	ctx := logging.WithRequestID(context.Background(), "req-42")
+	queue := NewMemoryQueue()
+	distributor := NewMemoryTaskDistributor(queue)
+	require.NoError(t, distributor.DistributeTaskSendVerifyEmail(ctx, &PayloadSendVerifyEmail{Username: "alice"}))
+
+	var taskRequestID string
+	handler := logTask(asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		taskRequestID = logging.RequestIDFromContext(ctx)
+		log.Ctx(ctx).Info().Msg("in task")
+		return nil
+	}))
+	require.Equal(t, 1, drain(queue, handler))
+
+	// The task keeps the request ID, so the tasks it enqueues keep it too...
+	require.Equal(t, "req-42", taskRequestID)
+
+	// ...and its logs carry it.
+	var entry map[string]any
+	decoder := json.NewDecoder(&buf)
+	for decoder.More() {
+		require.NoError(t, decoder.Decode(&entry))
+		if entry["message"] == "in task" {
+			break
+		}
+	}
+	require.Equal(t, "in task", entry["message"])
+	require.Equal(t, "req-42", entry[logging.RequestIDKey])
+	require.Equal(t, TaskSendVerifyEmail, entry["type"])
+}
+
+func TestLogTaskWithoutRequestID(t *testing.T) {
+	var taskRequestID string
+	handler := logTask(asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		taskRequestID = logging.RequestIDFromContext(ctx)
+		return nil
+	}))
+
+	// Tasks enqueued outside a request, such as maintenance jobs, have no request ID.
+	require.NoError(t, handler.ProcessTask(context.Background(), asynq.NewTask(TaskPruneSessions, nil)))
+	require.Empty(t, taskRequestID)
+}
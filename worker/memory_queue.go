@@ -0,0 +1,382 @@
+package worker
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+)
+
+const (
+	// memoryMaxArchived bounds the dead-letter set of each queue; the oldest
+	// archived tasks are dropped first.
+	memoryMaxArchived = 1000
+	// memoryTaskTimeout matches the default task timeout of asynq.
+	memoryTaskTimeout = 30 * time.Minute
+)
+
+// memoryQueues lists the queues in the order they are served.
+var memoryQueues = []string{QueueCritical, QueueDefault}
+
+type memoryStats struct {
+	processed int
+	failed    int
+}
+
+// MemoryQueue keeps tasks in process memory with the semantics of asynq:
+// delayed tasks, retries with RetryDelayFunc, SkipRetry and an archived
+// (dead-letter) state. Tasks are lost when the process exits, so it is meant
+// for development, single-binary deployments and tests.
+//
+// It implements TaskInspector and, like asynq.Client, TaskEnqueuer.
+type MemoryQueue struct {
+	mu    sync.Mutex
+	tasks map[string]map[string]*asynq.TaskInfo
+	stats map[string]*memoryStats
+	// wake is signalled when a task is enqueued or made runnable.
+	wake chan struct{}
+	now  func() time.Time
+}
+
+func NewMemoryQueue() *MemoryQueue {
+	queue := &MemoryQueue{
+		tasks: make(map[string]map[string]*asynq.TaskInfo),
+		stats: make(map[string]*memoryStats),
+		wake:  make(chan struct{}, 1),
+		now:   time.Now,
+	}
+	for _, name := range memoryQueues {
+		queue.tasks[name] = make(map[string]*asynq.TaskInfo)
+		queue.stats[name] = &memoryStats{}
+	}
+	return queue
+}
+
+// EnqueueContext adds a task to its queue. It supports the Queue, MaxRetry,
+// ProcessIn, ProcessAt and TaskID options and, like asynq, rejects a task ID
+// that is still present with asynq.ErrTaskIDConflict.
+func (queue *MemoryQueue) EnqueueContext(ctx context.Context, task *asynq.Task, opts ...asynq.Option) (*asynq.TaskInfo, error) {
+	now := queue.now()
+	info := &asynq.TaskInfo{
+		ID:            uuid.NewString(),
+		Queue:         QueueDefault,
+		Type:          task.Type(),
+		Payload:       task.Payload(),
+		Headers:       task.Headers(),
+		State:         asynq.TaskStatePending,
+		MaxRetry:      defaultMaxRetry,
+		NextProcessAt: now,
+	}
+	for _, opt := range opts {
+		switch opt.Type() {
+		case asynq.QueueOpt:
+			info.Queue = opt.Value().(string)
+		case asynq.MaxRetryOpt:
+			info.MaxRetry = opt.Value().(int)
+		case asynq.ProcessInOpt:
+			info.NextProcessAt = now.Add(opt.Value().(time.Duration))
+		case asynq.ProcessAtOpt:
+			info.NextProcessAt = opt.Value().(time.Time)
+		case asynq.TaskIDOpt:
+			info.ID = opt.Value().(string)
+		default:
+			return nil, fmt.Errorf("unsupported memory task option: %s", opt.String())
+		}
+	}
+	if info.NextProcessAt.After(now) {
+		info.State = asynq.TaskStateScheduled
+	}
+
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+
+	tasks, ok := queue.tasks[info.Queue]
+	if !ok {
+		return nil, fmt.Errorf("unknown queue %q", info.Queue)
+	}
+	if _, ok := tasks[info.ID]; ok {
+		return nil, asynq.ErrTaskIDConflict
+	}
+	tasks[info.ID] = info
+	queue.signal()
+
+	copied := *info
+	return &copied, nil
+}
+
+// Close is a no-op; it lets MemoryQueue stand in for an asynq.Client.
+func (queue *MemoryQueue) Close() error {
+	return nil
+}
+
+func (queue *MemoryQueue) signal() {
+	select {
+	case queue.wake <- struct{}{}:
+	default:
+	}
+}
+
+// next marks the next runnable task active and returns a copy of it. When
+// nothing is runnable, it returns how long until the next scheduled task.
+func (queue *MemoryQueue) next() (*asynq.TaskInfo, time.Duration) {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+
+	now := queue.now()
+	wait := time.Duration(-1)
+	for _, name := range memoryQueues {
+		var due *asynq.TaskInfo
+		for _, info := range queue.tasks[name] {
+			switch info.State {
+			case asynq.TaskStatePending, asynq.TaskStateScheduled, asynq.TaskStateRetry:
+			default:
+				continue
+			}
+
+			if delay := info.NextProcessAt.Sub(now); delay > 0 {
+				if wait < 0 || delay < wait {
+					wait = delay
+				}
+				continue
+			}
+			if due == nil || info.NextProcessAt.Before(due.NextProcessAt) {
+				due = info
+			}
+		}
+
+		if due != nil {
+			due.State = asynq.TaskStateActive
+			copied := *due
+			return &copied, 0
+		}
+	}
+	return nil, wait
+}
+
+// done records the result of an active task: success deletes it, a failure
+// schedules a retry with retryDelay or archives it once retries are exhausted.
+func (queue *MemoryQueue) done(info *asynq.TaskInfo, err error) {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+
+	task, ok := queue.tasks[info.Queue][info.ID]
+	if !ok {
+		return
+	}
+
+	stats := queue.stats[info.Queue]
+	stats.processed++
+	if err == nil {
+		delete(queue.tasks[info.Queue], info.ID)
+		return
+	}
+
+	stats.failed++
+	now := queue.now()
+	task.LastErr = err.Error()
+	task.LastFailedAt = now
+
+	if errors.Is(err, asynq.SkipRetry) || task.Retried >= task.MaxRetry {
+		task.State = asynq.TaskStateArchived
+		task.NextProcessAt = time.Time{}
+		queue.trimArchived(info.Queue)
+		return
+	}
+
+	delay := retryDelay(task.Retried, err, asynq.NewTask(task.Type, task.Payload))
+	task.Retried++
+	task.State = asynq.TaskStateRetry
+	task.NextProcessAt = now.Add(delay)
+	queue.signal()
+}
+
+func (queue *MemoryQueue) trimArchived(name string) {
+	var archived []*asynq.TaskInfo
+	for _, info := range queue.tasks[name] {
+		if info.State == asynq.TaskStateArchived {
+			archived = append(archived, info)
+		}
+	}
+	if len(archived) <= memoryMaxArchived {
+		return
+	}
+
+	slices.SortFunc(archived, func(a, b *asynq.TaskInfo) int {
+		return a.LastFailedAt.Compare(b.LastFailedAt)
+	})
+	for _, info := range archived[:len(archived)-memoryMaxArchived] {
+		delete(queue.tasks[name], info.ID)
+	}
+}
+
+// process runs one task with the handler and reports whether there was one to run.
+func (queue *MemoryQueue) process(ctx context.Context, handler asynq.Handler) (bool, time.Duration) {
+	info, wait := queue.next()
+	if info == nil {
+		return false, wait
+	}
+
+	taskCtx, cancel := context.WithTimeout(ctx, memoryTaskTimeout)
+	defer cancel()
+
+	err := handler.ProcessTask(taskCtx, asynq.NewTaskWithHeaders(info.Type, info.Payload, info.Headers))
+	queue.done(info, err)
+	return true, 0
+}
+
+// run processes tasks with the handler until ctx is done.
+func (queue *MemoryQueue) run(ctx context.Context, handler asynq.Handler) {
+	for {
+		ran, wait := queue.process(ctx, handler)
+		if ran {
+			continue
+		}
+
+		var timer <-chan time.Time
+		if wait >= 0 {
+			timer = time.After(wait)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-queue.wake:
+		case <-timer:
+		}
+	}
+}
+
+// ListQueues returns the current stats of every queue.
+func (queue *MemoryQueue) ListQueues() ([]*asynq.QueueInfo, error) {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+
+	now := queue.now()
+	infos := make([]*asynq.QueueInfo, 0, len(memoryQueues))
+	for _, name := range memoryQueues {
+		stats := queue.stats[name]
+		info := &asynq.QueueInfo{
+			Queue:          name,
+			Processed:      stats.processed,
+			Failed:         stats.failed,
+			ProcessedTotal: stats.processed,
+			FailedTotal:    stats.failed,
+			Timestamp:      now,
+		}
+
+		for _, task := range queue.tasks[name] {
+			info.Size++
+			switch task.State {
+			case asynq.TaskStatePending:
+				info.Pending++
+				info.Latency = max(info.Latency, now.Sub(task.NextProcessAt))
+			case asynq.TaskStateActive:
+				info.Active++
+			case asynq.TaskStateScheduled:
+				info.Scheduled++
+			case asynq.TaskStateRetry:
+				info.Retry++
+			case asynq.TaskStateArchived:
+				info.Archived++
+			}
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// ListTasks returns one page of the tasks of a queue in the given state,
+// ordered by their next process time, then their last failure.
+func (queue *MemoryQueue) ListTasks(name string, state asynq.TaskState, page, pageSize int) ([]*asynq.TaskInfo, error) {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+
+	tasks, ok := queue.tasks[name]
+	if !ok {
+		return nil, fmt.Errorf("queue %q: %w", name, asynq.ErrQueueNotFound)
+	}
+
+	var infos []*asynq.TaskInfo
+	for _, info := range tasks {
+		if info.State == state {
+			copied := *info
+			infos = append(infos, &copied)
+		}
+	}
+	slices.SortFunc(infos, func(a, b *asynq.TaskInfo) int {
+		return cmp.Or(
+			a.NextProcessAt.Compare(b.NextProcessAt),
+			a.LastFailedAt.Compare(b.LastFailedAt),
+			strings.Compare(a.ID, b.ID),
+		)
+	})
+
+	start := min((page-1)*pageSize, len(infos))
+	end := min(start+pageSize, len(infos))
+	return infos[start:end], nil
+}
+
+func (queue *MemoryQueue) GetTask(name, id string) (*asynq.TaskInfo, error) {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+
+	info, err := queue.lookup(name, id)
+	if err != nil {
+		return nil, err
+	}
+	copied := *info
+	return &copied, nil
+}
+
+// RunTask moves a scheduled, retry or archived task back to the pending state.
+func (queue *MemoryQueue) RunTask(name, id string) error {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+
+	info, err := queue.lookup(name, id)
+	if err != nil {
+		return err
+	}
+	if info.State == asynq.TaskStatePending || info.State == asynq.TaskStateActive {
+		return fmt.Errorf("task is already %s", info.State)
+	}
+
+	info.State = asynq.TaskStatePending
+	info.NextProcessAt = queue.now()
+	queue.signal()
+	return nil
+}
+
+func (queue *MemoryQueue) DeleteTask(name, id string) error {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+
+	info, err := queue.lookup(name, id)
+	if err != nil {
+		return err
+	}
+	if info.State == asynq.TaskStateActive {
+		return errors.New("cannot delete an active task")
+	}
+
+	delete(queue.tasks[name], id)
+	return nil
+}
+
+func (queue *MemoryQueue) lookup(name, id string) (*asynq.TaskInfo, error) {
+	tasks, ok := queue.tasks[name]
+	if !ok {
+		return nil, fmt.Errorf("queue %q: %w", name, asynq.ErrQueueNotFound)
+	}
+	info, ok := tasks[id]
+	if !ok {
+		return nil, fmt.Errorf("task %q: %w", id, asynq.ErrTaskNotFound)
+	}
+	return info, nil
+}
@@ -0,0 +1,176 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestMemoryQueue returns a queue with a clock that only moves when advanced.
+func newTestMemoryQueue() (*MemoryQueue, func(d time.Duration)) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	queue := NewMemoryQueue()
+	queue.now = func() time.Time { return now }
+	return queue, func(d time.Duration) { now = now.Add(d) }
+}
+
+// drain processes every due task with the handler and returns how many ran.
+func drain(queue *MemoryQueue, handler asynq.Handler) int {
+	n := 0
+	for {
+		ran, _ := queue.process(context.Background(), handler)
+		if !ran {
+			return n
+		}
+		n++
+	}
+}
+
+func TestMemoryQueueRetry(t *testing.T) {
+	queue, advance := newTestMemoryQueue()
+	ctx := context.Background()
+
+	calls := 0
+	handler := asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		calls++
+		if calls < 3 {
+			return fmt.Errorf("attempt %d failed", calls)
+		}
+		return nil
+	})
+
+	info, err := queue.EnqueueContext(ctx, asynq.NewTask("test:retry", nil), asynq.MaxRetry(5))
+	require.NoError(t, err)
+
+	require.Equal(t, 1, drain(queue, handler))
+	task, err := queue.GetTask(QueueDefault, info.ID)
+	require.NoError(t, err)
+	require.Equal(t, asynq.TaskStateRetry, task.State)
+	require.Equal(t, 1, task.Retried)
+	require.Equal(t, "attempt 1 failed", task.LastErr)
+
+	// retries wait for the delay of asynq.DefaultRetryDelayFunc
+	require.Zero(t, drain(queue, handler))
+	advance(time.Hour)
+	require.Equal(t, 1, drain(queue, handler))
+	advance(time.Hour)
+	require.Equal(t, 1, drain(queue, handler))
+	require.Equal(t, 3, calls)
+
+	_, err = queue.GetTask(QueueDefault, info.ID)
+	require.ErrorIs(t, err, asynq.ErrTaskNotFound)
+
+	queues, err := queue.ListQueues()
+	require.NoError(t, err)
+	require.Equal(t, QueueDefault, queues[1].Queue)
+	require.Equal(t, 3, queues[1].ProcessedTotal)
+	require.Equal(t, 2, queues[1].FailedTotal)
+	require.Zero(t, queues[1].Size)
+}
+
+func TestMemoryQueueArchive(t *testing.T) {
+	queue, advance := newTestMemoryQueue()
+	ctx := context.Background()
+
+	handler := asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		if string(task.Payload()) == "skip" {
+			return fmt.Errorf("bad payload: %w", asynq.SkipRetry)
+		}
+		return errors.New("always fails")
+	})
+
+	skipped, err := queue.EnqueueContext(ctx, asynq.NewTask("test:archive", []byte("skip")), asynq.MaxRetry(5))
+	require.NoError(t, err)
+	exhausted, err := queue.EnqueueContext(ctx, asynq.NewTask("test:archive", nil), asynq.MaxRetry(1))
+	require.NoError(t, err)
+
+	require.Equal(t, 2, drain(queue, handler))
+	advance(time.Hour)
+	require.Equal(t, 1, drain(queue, handler))
+
+	archived, err := queue.ListTasks(QueueDefault, asynq.TaskStateArchived, 1, 10)
+	require.NoError(t, err)
+	require.Len(t, archived, 2)
+	require.Equal(t, skipped.ID, archived[0].ID)
+	require.Zero(t, archived[0].Retried)
+	require.Equal(t, exhausted.ID, archived[1].ID)
+	require.Equal(t, 1, archived[1].Retried)
+
+	// archived tasks can be run again
+	require.NoError(t, queue.RunTask(QueueDefault, exhausted.ID))
+	require.Equal(t, 1, drain(queue, handler))
+
+	require.NoError(t, queue.DeleteTask(QueueDefault, skipped.ID))
+	_, err = queue.GetTask(QueueDefault, skipped.ID)
+	require.ErrorIs(t, err, asynq.ErrTaskNotFound)
+}
+
+func TestMemoryQueueScheduling(t *testing.T) {
+	queue, advance := newTestMemoryQueue()
+	ctx := context.Background()
+
+	var ran []string
+	handler := asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		ran = append(ran, task.Type())
+		return nil
+	})
+
+	delayed, err := queue.EnqueueContext(ctx, asynq.NewTask("test:delayed", nil), asynq.ProcessIn(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, asynq.TaskStateScheduled, delayed.State)
+
+	_, err = queue.EnqueueContext(ctx, asynq.NewTask("test:default", nil))
+	require.NoError(t, err)
+	_, err = queue.EnqueueContext(ctx, asynq.NewTask("test:critical", nil), asynq.Queue(QueueCritical))
+	require.NoError(t, err)
+
+	_, err = queue.EnqueueContext(ctx, asynq.NewTask("test:default", nil), asynq.TaskID("outbox:1"))
+	require.NoError(t, err)
+	_, err = queue.EnqueueContext(ctx, asynq.NewTask("test:default", nil), asynq.TaskID("outbox:1"))
+	require.ErrorIs(t, err, asynq.ErrTaskIDConflict)
+
+	_, err = queue.EnqueueContext(ctx, asynq.NewTask("test:unknown", nil), asynq.Queue("low"))
+	require.Error(t, err)
+
+	// the critical queue is served first and delayed tasks wait
+	require.Equal(t, 3, drain(queue, handler))
+	require.Equal(t, []string{"test:critical", "test:default", "test:default"}, ran)
+
+	advance(time.Minute)
+	require.Equal(t, 1, drain(queue, handler))
+	require.Equal(t, "test:delayed", ran[3])
+}
+
+func TestMemoryTaskProcessorStart(t *testing.T) {
+	queue := NewMemoryQueue()
+	processor := NewMemoryTaskProcessor(queue, nil, nil, nil, util.RuntimeConfig{})
+
+	require.NoError(t, processor.Start())
+	defer processor.Shutdown()
+
+	// a task without a handler fails and, without retries left, is archived
+	info, err := queue.EnqueueContext(context.Background(), asynq.NewTask("test:unhandled", nil), asynq.MaxRetry(0))
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		task, err := queue.GetTask(QueueDefault, info.ID)
+		return err == nil && task.State == asynq.TaskStateArchived
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestMemoryTaskProcessorPing(t *testing.T) {
+	processor := NewMemoryTaskProcessor(NewMemoryQueue(), nil, nil, nil, util.RuntimeConfig{})
+	require.ErrorIs(t, processor.Ping(), ErrProcessorStopped)
+
+	require.NoError(t, processor.Start())
+	require.NoError(t, processor.Ping())
+
+	processor.Shutdown()
+	require.ErrorIs(t, processor.Ping(), ErrProcessorStopped)
+}
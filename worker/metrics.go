@@ -0,0 +1,83 @@
+package worker
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+)
+
+// QueueCollector exports queue depth and failure counts, read from the
+// inspector on every scrape.
+type QueueCollector struct {
+	inspector TaskInspector
+
+	size        *prometheus.Desc
+	latency     *prometheus.Desc
+	processed   *prometheus.Desc
+	failed      *prometheus.Desc
+	scrapeError *prometheus.Desc
+}
+
+func NewQueueCollector(inspector TaskInspector) *QueueCollector {
+	return &QueueCollector{
+		inspector: inspector,
+		size: prometheus.NewDesc(
+			"asynq_queue_tasks",
+			"Number of tasks in the queue by state.",
+			[]string{"queue", "state"}, nil,
+		),
+		latency: prometheus.NewDesc(
+			"asynq_queue_latency_seconds",
+			"Age of the oldest pending task in the queue.",
+			[]string{"queue"}, nil,
+		),
+		processed: prometheus.NewDesc(
+			"asynq_tasks_processed_total",
+			"Number of tasks processed by the queue, including failures.",
+			[]string{"queue"}, nil,
+		),
+		failed: prometheus.NewDesc(
+			"asynq_tasks_failed_total",
+			"Number of task executions that returned an error.",
+			[]string{"queue"}, nil,
+		),
+		scrapeError: prometheus.NewDesc(
+			"asynq_scrape_error",
+			"1 if the last read of the queue stats failed.",
+			nil, nil,
+		),
+	}
+}
+
+func (c *QueueCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.size
+	ch <- c.latency
+	ch <- c.processed
+	ch <- c.failed
+	ch <- c.scrapeError
+}
+
+func (c *QueueCollector) Collect(ch chan<- prometheus.Metric) {
+	queues, err := c.inspector.ListQueues()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to collect queue metrics")
+		ch <- prometheus.MustNewConstMetric(c.scrapeError, prometheus.GaugeValue, 1)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(c.scrapeError, prometheus.GaugeValue, 0)
+
+	for _, info := range queues {
+		for state, n := range map[string]int{
+			"pending":   info.Pending,
+			"active":    info.Active,
+			"scheduled": info.Scheduled,
+			"retry":     info.Retry,
+			"archived":  info.Archived,
+			"completed": info.Completed,
+		} {
+			ch <- prometheus.MustNewConstMetric(c.size, prometheus.GaugeValue, float64(n), info.Queue, state)
+		}
+		ch <- prometheus.MustNewConstMetric(c.latency, prometheus.GaugeValue, info.Latency.Seconds(), info.Queue)
+		ch <- prometheus.MustNewConstMetric(c.processed, prometheus.CounterValue, float64(info.ProcessedTotal), info.Queue)
+		ch <- prometheus.MustNewConstMetric(c.failed, prometheus.CounterValue, float64(info.FailedTotal), info.Queue)
+	}
+}
@@ -0,0 +1,74 @@
+package worker
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hibiken/asynq"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeInspector serves fixed queue stats; the other methods are not used by the collector.
+type fakeInspector struct {
+	TaskInspector
+	queues []*asynq.QueueInfo
+	err    error
+}
+
+func (f fakeInspector) ListQueues() ([]*asynq.QueueInfo, error) {
+	return f.queues, f.err
+}
+
+func TestQueueCollector(t *testing.T) {
+	inspector := fakeInspector{
+		queues: []*asynq.QueueInfo{
+			{Queue: QueueCritical, Archived: 2, Retry: 1, ProcessedTotal: 40, FailedTotal: 12},
+		},
+	}
+
+	expected := `
+# HELP asynq_queue_tasks Number of tasks in the queue by state.
+# TYPE asynq_queue_tasks gauge
+asynq_queue_tasks{queue="critical",state="active"} 0
+asynq_queue_tasks{queue="critical",state="archived"} 2
+asynq_queue_tasks{queue="critical",state="completed"} 0
+asynq_queue_tasks{queue="critical",state="pending"} 0
+asynq_queue_tasks{queue="critical",state="retry"} 1
+asynq_queue_tasks{queue="critical",state="scheduled"} 0
+# HELP asynq_tasks_failed_total Number of task executions that returned an error.
+# TYPE asynq_tasks_failed_total counter
+asynq_tasks_failed_total{queue="critical"} 12
+# HELP asynq_tasks_processed_total Number of tasks processed by the queue, including failures.
+# TYPE asynq_tasks_processed_total counter
+asynq_tasks_processed_total{queue="critical"} 40
+`
+	err := testutil.CollectAndCompare(
+		NewQueueCollector(inspector),
+		strings.NewReader(expected),
+		"asynq_queue_tasks", "asynq_tasks_failed_total", "asynq_tasks_processed_total",
+	)
+	require.NoError(t, err)
+}
+
+func TestQueueCollectorScrapeError(t *testing.T) {
+	inspector := fakeInspector{err: errors.New("redis unavailable")}
+
+	expected := `
+# HELP asynq_scrape_error 1 if the last read of the queue stats failed.
+# TYPE asynq_scrape_error gauge
+asynq_scrape_error 1
+`
+	err := testutil.CollectAndCompare(NewQueueCollector(inspector), strings.NewReader(expected))
+	require.NoError(t, err)
+}
+
+func TestParseTaskState(t *testing.T) {
+	state, err := ParseTaskState("archived")
+	require.NoError(t, err)
+	require.Equal(t, asynq.TaskStateArchived, state)
+
+	_, err = ParseTaskState("dead")
+	require.Error(t, err)
+}
@@ -42,6 +42,63 @@ func (m *MockTaskDistributor) EXPECT() *MockTaskDistributorMockRecorder {
 	return m.recorder
 }
 
+// DistributeTaskDeliverWebhook mocks base method.
+func (m *MockTaskDistributor) DistributeTaskDeliverWebhook(ctx context.Context, payload *worker.PayloadDeliverWebhook, opts ...asynq.Option) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, payload}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DistributeTaskDeliverWebhook", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DistributeTaskDeliverWebhook indicates an expected call of DistributeTaskDeliverWebhook.
+func (mr *MockTaskDistributorMockRecorder) DistributeTaskDeliverWebhook(ctx, payload any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, payload}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DistributeTaskDeliverWebhook", reflect.TypeOf((*MockTaskDistributor)(nil).DistributeTaskDeliverWebhook), varargs...)
+}
+
+// DistributeTaskSendTransferFailedNotification mocks base method.
+func (m *MockTaskDistributor) DistributeTaskSendTransferFailedNotification(ctx context.Context, payload *worker.PayloadSendTransferFailedNotification, opts ...asynq.Option) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, payload}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DistributeTaskSendTransferFailedNotification", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DistributeTaskSendTransferFailedNotification indicates an expected call of DistributeTaskSendTransferFailedNotification.
+func (mr *MockTaskDistributorMockRecorder) DistributeTaskSendTransferFailedNotification(ctx, payload any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, payload}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DistributeTaskSendTransferFailedNotification", reflect.TypeOf((*MockTaskDistributor)(nil).DistributeTaskSendTransferFailedNotification), varargs...)
+}
+
+// DistributeTaskSendTransferNotification mocks base method.
+func (m *MockTaskDistributor) DistributeTaskSendTransferNotification(ctx context.Context, payload *worker.PayloadSendTransferNotification, opts ...asynq.Option) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, payload}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DistributeTaskSendTransferNotification", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DistributeTaskSendTransferNotification indicates an expected call of DistributeTaskSendTransferNotification.
+func (mr *MockTaskDistributorMockRecorder) DistributeTaskSendTransferNotification(ctx, payload any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, payload}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DistributeTaskSendTransferNotification", reflect.TypeOf((*MockTaskDistributor)(nil).DistributeTaskSendTransferNotification), varargs...)
+}
+
 // DistributeTaskSendVerifyEmail mocks base method.
 func (m *MockTaskDistributor) DistributeTaskSendVerifyEmail(ctx context.Context, payload *worker.PayloadSendVerifyEmail, opts ...asynq.Option) error {
 	m.ctrl.T.Helper()
@@ -60,3 +117,22 @@ func (mr *MockTaskDistributorMockRecorder) DistributeTaskSendVerifyEmail(ctx, pa
 	varargs := append([]any{ctx, payload}, opts...)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DistributeTaskSendVerifyEmail", reflect.TypeOf((*MockTaskDistributor)(nil).DistributeTaskSendVerifyEmail), varargs...)
 }
+
+// DistributeTaskSendVerifyPhone mocks base method.
+func (m *MockTaskDistributor) DistributeTaskSendVerifyPhone(ctx context.Context, payload *worker.PayloadSendVerifyPhone, opts ...asynq.Option) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, payload}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DistributeTaskSendVerifyPhone", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DistributeTaskSendVerifyPhone indicates an expected call of DistributeTaskSendVerifyPhone.
+func (mr *MockTaskDistributorMockRecorder) DistributeTaskSendVerifyPhone(ctx, payload any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, payload}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DistributeTaskSendVerifyPhone", reflect.TypeOf((*MockTaskDistributor)(nil).DistributeTaskSendVerifyPhone), varargs...)
+}
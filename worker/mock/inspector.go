@@ -0,0 +1,114 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/LamThanhNguyen/banking-system/worker (interfaces: TaskInspector)
+//
+// Generated by this command:
+//
+//	mockgen -package mockwk -destination worker/mock/inspector.go github.com/LamThanhNguyen/banking-system/worker TaskInspector
+//
+
+// Package mockwk is a generated GoMock package.
+package mockwk
+
+import (
+	reflect "reflect"
+
+	asynq "github.com/hibiken/asynq"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockTaskInspector is a mock of TaskInspector interface.
+type MockTaskInspector struct {
+	ctrl     *gomock.Controller
+	recorder *MockTaskInspectorMockRecorder
+	isgomock struct{}
+}
+
+// MockTaskInspectorMockRecorder is the mock recorder for MockTaskInspector.
+type MockTaskInspectorMockRecorder struct {
+	mock *MockTaskInspector
+}
+
+// NewMockTaskInspector creates a new mock instance.
+func NewMockTaskInspector(ctrl *gomock.Controller) *MockTaskInspector {
+	mock := &MockTaskInspector{ctrl: ctrl}
+	mock.recorder = &MockTaskInspectorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTaskInspector) EXPECT() *MockTaskInspectorMockRecorder {
+	return m.recorder
+}
+
+// DeleteTask mocks base method.
+func (m *MockTaskInspector) DeleteTask(queue, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTask", queue, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTask indicates an expected call of DeleteTask.
+func (mr *MockTaskInspectorMockRecorder) DeleteTask(queue, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTask", reflect.TypeOf((*MockTaskInspector)(nil).DeleteTask), queue, id)
+}
+
+// GetTask mocks base method.
+func (m *MockTaskInspector) GetTask(queue, id string) (*asynq.TaskInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTask", queue, id)
+	ret0, _ := ret[0].(*asynq.TaskInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTask indicates an expected call of GetTask.
+func (mr *MockTaskInspectorMockRecorder) GetTask(queue, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTask", reflect.TypeOf((*MockTaskInspector)(nil).GetTask), queue, id)
+}
+
+// ListQueues mocks base method.
+func (m *MockTaskInspector) ListQueues() ([]*asynq.QueueInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListQueues")
+	ret0, _ := ret[0].([]*asynq.QueueInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListQueues indicates an expected call of ListQueues.
+func (mr *MockTaskInspectorMockRecorder) ListQueues() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListQueues", reflect.TypeOf((*MockTaskInspector)(nil).ListQueues))
+}
+
+// ListTasks mocks base method.
+func (m *MockTaskInspector) ListTasks(queue string, state asynq.TaskState, page, pageSize int) ([]*asynq.TaskInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTasks", queue, state, page, pageSize)
+	ret0, _ := ret[0].([]*asynq.TaskInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTasks indicates an expected call of ListTasks.
+func (mr *MockTaskInspectorMockRecorder) ListTasks(queue, state, page, pageSize any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTasks", reflect.TypeOf((*MockTaskInspector)(nil).ListTasks), queue, state, page, pageSize)
+}
+
+// RunTask mocks base method.
+func (m *MockTaskInspector) RunTask(queue, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunTask", queue, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RunTask indicates an expected call of RunTask.
+func (mr *MockTaskInspectorMockRecorder) RunTask(queue, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunTask", reflect.TypeOf((*MockTaskInspector)(nil).RunTask), queue, id)
+}
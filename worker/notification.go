@@ -0,0 +1,97 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/notify"
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/rs/zerolog/log"
+)
+
+// notificationSettings holds a user's stored preferences keyed by event type, channel
+// and, for alerts, currency.
+type notificationSettings map[string]db.NotificationPreference
+
+func notificationKey(event string, channel string, currency string) string {
+	return event + "/" + channel + "/" + currency
+}
+
+func (processor *RedisTaskProcessor) loadNotificationSettings(ctx context.Context, username string) (notificationSettings, error) {
+	prefs, err := processor.store.ListNotificationPreferences(ctx, username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notification preferences: %w", err)
+	}
+
+	settings := make(notificationSettings, len(prefs))
+	for _, pref := range prefs {
+		settings[notificationKey(pref.EventType, pref.Channel, pref.Currency)] = pref
+	}
+	return settings, nil
+}
+
+// lookup returns whether the event is enabled on the channel.
+// Events without a stored preference fall back to the default.
+func (settings notificationSettings) lookup(event string, channel string) bool {
+	pref, ok := settings[notificationKey(event, channel, "")]
+	if !ok {
+		return util.IsNotificationEnabledByDefault(event, channel)
+	}
+	return pref.Enabled
+}
+
+// alert reports whether a threshold alert is enabled on the channel for the currency
+// and returns its threshold. Alerts are off unless set for the currency.
+func (settings notificationSettings) alert(event string, channel string, currency string) (bool, int64) {
+	pref := settings[notificationKey(event, channel, currency)]
+	return pref.Enabled && pref.Threshold > 0, pref.Threshold
+}
+
+// notificationChannels lists the channels in the order notifications are sent on.
+var notificationChannels = []string{util.ChannelEmail, util.ChannelSMS, util.ChannelPush}
+
+// newRecipient returns the addresses of the user. SMS only go to a verified phone number.
+func newRecipient(user db.User) notify.Recipient {
+	to := notify.Recipient{
+		Username: user.Username,
+		Email:    user.Email,
+	}
+	if user.IsPhoneVerified {
+		to.Phone = user.Phone
+	}
+	return to
+}
+
+// sendTemplated renders the named template in the user's locale and sends it on the
+// channel. It reports false when the channel has no notifier or no address for the user.
+func (processor *RedisTaskProcessor) sendTemplated(
+	ctx context.Context,
+	user db.User,
+	channel string,
+	name string,
+	data any,
+) (bool, error) {
+	notifier, ok := processor.notifiers[channel]
+	if !ok {
+		return false, nil
+	}
+
+	email, err := processor.templates.Render(name, user.Locale, data)
+	if err != nil {
+		return false, fmt.Errorf("failed to render %s notification: %w", name, err)
+	}
+
+	msg := notify.Message{Subject: email.Subject, HTML: email.HTML, Text: email.Text}
+	err = notifier.Notify(ctx, newRecipient(user), msg)
+	if errors.Is(err, notify.ErrNoAddress) {
+		log.Ctx(ctx).Debug().Str("username", user.Username).Str("channel", channel).Str("template", name).
+			Msg("no address on channel, skipped notification")
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to send %s %s notification: %w", name, channel, err)
+	}
+	return true, nil
+}
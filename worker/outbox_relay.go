@@ -0,0 +1,133 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/logging"
+	"github.com/LamThanhNguyen/banking-system/metrics"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	outboxBatchSize   = 100
+	outboxMaxAttempts = 20
+	outboxBaseDelay   = 5 * time.Second
+	outboxMaxDelay    = time.Hour
+)
+
+// OutboxRelay polls the outbox table and publishes pending tasks to asynq.
+// Delivery is at least once: each task gets the ID "outbox:<id>", so a row
+// that is published again after a failed commit is rejected by asynq as long
+// as the first copy is still retained. A row that cannot be published is
+// retried with backoff, and left dead in the table after outboxMaxAttempts.
+type OutboxRelay struct {
+	client   TaskEnqueuer
+	store    db.Store
+	interval time.Duration
+}
+
+// TaskEnqueuer publishes tasks. It is implemented by asynq.Client and MemoryQueue.
+type TaskEnqueuer interface {
+	EnqueueContext(ctx context.Context, task *asynq.Task, opts ...asynq.Option) (*asynq.TaskInfo, error)
+	Close() error
+}
+
+// NewOutboxRelay creates a relay that publishes to client, which it closes when Run returns.
+func NewOutboxRelay(client TaskEnqueuer, store db.Store, interval time.Duration) *OutboxRelay {
+	return &OutboxRelay{
+		client:   client,
+		store:    store,
+		interval: interval,
+	}
+}
+
+// Run relays pending tasks every interval until ctx is cancelled.
+func (relay *OutboxRelay) Run(ctx context.Context) error {
+	defer relay.client.Close()
+
+	ticker := time.NewTicker(relay.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := relay.RelayPending(ctx); err != nil && ctx.Err() == nil {
+				log.Error().Err(err).Msg("failed to relay outbox")
+			}
+		}
+	}
+}
+
+// RelayPending publishes batches until no row is due. Failed rows are not due
+// again until their backoff has passed, so they do not hold up the rest.
+func (relay *OutboxRelay) RelayPending(ctx context.Context) error {
+	for {
+		result, err := relay.store.RelayOutboxTx(ctx, db.RelayOutboxTxParams{
+			Limit:       outboxBatchSize,
+			MaxAttempts: outboxMaxAttempts,
+			RetryDelay:  outboxRetryDelay,
+			Publish: func(msg db.Outbox) error {
+				return relay.publish(ctx, msg)
+			},
+		})
+		if err != nil {
+			return err
+		}
+		if result.Dead > 0 {
+			log.Error().Int("dead", result.Dead).Int("max_attempts", outboxMaxAttempts).
+				Msg("outbox tasks ran out of attempts and will not be published")
+		}
+		if result.Published+result.Failed < outboxBatchSize {
+			return nil
+		}
+	}
+}
+
+// outboxRetryDelay backs off exponentially from outboxBaseDelay up to
+// outboxMaxDelay, with up to 10% jitter.
+func outboxRetryDelay(attempts int32) time.Duration {
+	delay := outboxMaxDelay
+	if n := attempts - 1; n < 20 {
+		delay = min(outboxBaseDelay<<n, outboxMaxDelay)
+	}
+	return delay + rand.N(delay/10+1)
+}
+
+func (relay *OutboxRelay) publish(ctx context.Context, msg db.Outbox) error {
+	headers, err := decodeHeaders(msg.Headers)
+	if err != nil {
+		// The trace is lost, but the task is still worth running.
+		log.Warn().Err(err).Int64("outbox_id", msg.ID).Msg("invalid outbox task headers")
+	}
+
+	task := asynq.NewTaskWithHeaders(msg.TaskType, msg.Payload, headers)
+	info, err := relay.client.EnqueueContext(
+		ctx,
+		task,
+		asynq.TaskID(fmt.Sprintf("outbox:%d", msg.ID)),
+		asynq.Queue(msg.Queue),
+		asynq.MaxRetry(int(msg.MaxRetry)),
+		asynq.ProcessAt(msg.ProcessAt),
+	)
+	metrics.ObserveTaskEnqueued(msg.TaskType, err)
+	if errors.Is(err, asynq.ErrTaskIDConflict) {
+		log.Info().Str("type", task.Type()).Int64("outbox_id", msg.ID).Msg("outbox task already enqueued")
+		return nil
+	}
+	if err != nil {
+		log.Error().Err(err).Str("type", task.Type()).Int64("outbox_id", msg.ID).Msg("failed to publish outbox task")
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).RawJSON("payload", logging.RedactJSON(task.Payload())).
+		Int64("outbox_id", msg.ID).Str("queue", info.Queue).Msg("published outbox task")
+	return nil
+}
@@ -0,0 +1,76 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mockdb "github.com/LamThanhNguyen/banking-system/db/mock"
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// failingEnqueuer rejects the tasks of one outbox row and queues the rest.
+type failingEnqueuer struct {
+	*MemoryQueue
+	failID string
+}
+
+func (e failingEnqueuer) EnqueueContext(ctx context.Context, task *asynq.Task, opts ...asynq.Option) (*asynq.TaskInfo, error) {
+	for _, opt := range opts {
+		if opt.Type() == asynq.TaskIDOpt && opt.Value() == e.failID {
+			return nil, errors.New("redis unavailable")
+		}
+	}
+	return e.MemoryQueue.EnqueueContext(ctx, task, opts...)
+}
+
+func TestRelayPendingSkipsFailedRows(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	msgs := []db.Outbox{
+		{ID: 1, TaskType: TaskSendVerifyEmail, Payload: []byte(`{}`), Queue: QueueDefault},
+		{ID: 2, TaskType: TaskSendVerifyEmail, Payload: []byte(`{}`), Queue: QueueDefault},
+		{ID: 3, TaskType: TaskSendVerifyEmail, Payload: []byte(`{}`), Queue: QueueDefault},
+	}
+
+	var failed []int64
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		RelayOutboxTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.RelayOutboxTxParams) (db.RelayOutboxTxResult, error) {
+			require.Equal(t, int32(outboxMaxAttempts), arg.MaxAttempts)
+			var result db.RelayOutboxTxResult
+			for _, msg := range msgs {
+				if err := arg.Publish(msg); err != nil {
+					failed = append(failed, msg.ID)
+					result.Failed++
+					continue
+				}
+				result.Published++
+			}
+			return result, nil
+		})
+
+	queue := NewMemoryQueue()
+	relay := NewOutboxRelay(failingEnqueuer{queue, "outbox:2"}, store, 0)
+	require.NoError(t, relay.RelayPending(context.Background()))
+
+	require.Equal(t, []int64{2}, failed)
+	require.Equal(t, 2, drain(queue, asynq.HandlerFunc(func(context.Context, *asynq.Task) error {
+		return nil
+	})))
+}
+
+func TestOutboxRetryDelay(t *testing.T) {
+	require.GreaterOrEqual(t, outboxRetryDelay(1), outboxBaseDelay)
+	require.Less(t, outboxRetryDelay(1), outboxBaseDelay*11/10+time.Nanosecond)
+	require.GreaterOrEqual(t, outboxRetryDelay(4), 8*outboxBaseDelay)
+	require.GreaterOrEqual(t, outboxRetryDelay(40), outboxMaxDelay)
+	require.LessOrEqual(t, outboxRetryDelay(40), outboxMaxDelay*11/10)
+}
@@ -2,13 +2,19 @@ package worker
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
 
 	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
-	"github.com/LamThanhNguyen/banking-system/mail"
+	"github.com/LamThanhNguyen/banking-system/logging"
+	"github.com/LamThanhNguyen/banking-system/mail/templates"
+	"github.com/LamThanhNguyen/banking-system/metrics"
+	"github.com/LamThanhNguyen/banking-system/notify"
 	"github.com/LamThanhNguyen/banking-system/util"
 	"github.com/hibiken/asynq"
 	"github.com/redis/go-redis/v9"
-	"github.com/rs/zerolog/log"
 )
 
 const (
@@ -19,20 +25,38 @@ const (
 type TaskProcessor interface {
 	Start() error
 	Shutdown()
+	// Ping returns an error unless the processor is running and can reach its queue.
+	Ping() error
 	ProcessTaskSendVerifyEmail(ctx context.Context, task *asynq.Task) error
+	ProcessTaskSendVerifyPhone(ctx context.Context, task *asynq.Task) error
+	ProcessTaskSendTransferNotification(ctx context.Context, task *asynq.Task) error
+	ProcessTaskSendTransferFailedNotification(ctx context.Context, task *asynq.Task) error
+	ProcessTaskPruneSessions(ctx context.Context, task *asynq.Task) error
+	ProcessTaskPruneVerifyEmails(ctx context.Context, task *asynq.Task) error
+	ProcessTaskPruneOutbox(ctx context.Context, task *asynq.Task) error
+	ProcessTaskPruneIdempotencyKeys(ctx context.Context, task *asynq.Task) error
+	ProcessTaskReconcileBalances(ctx context.Context, task *asynq.Task) error
+	ProcessTaskDeliverWebhook(ctx context.Context, task *asynq.Task) error
 }
 
 type RedisTaskProcessor struct {
-	server *asynq.Server
-	store  db.Store
-	mailer mail.EmailSender
-	config util.RuntimeConfig
+	server     *asynq.Server
+	store      db.Store
+	notifiers  notify.Notifiers
+	templates  *templates.Renderer
+	config     util.RuntimeConfig
+	httpClient *http.Client
+	running    atomic.Bool
 }
 
+// ErrProcessorStopped is returned by Ping before Start and after Shutdown.
+var ErrProcessorStopped = errors.New("task processor is not running")
+
 func NewRedisTaskProcessor(
 	redisOpt asynq.RedisClientOpt,
 	store db.Store,
-	mailer mail.EmailSender,
+	notifiers notify.Notifiers,
+	renderer *templates.Renderer,
 	config util.RuntimeConfig,
 ) TaskProcessor {
 	logger := NewLogger()
@@ -46,29 +70,77 @@ func NewRedisTaskProcessor(
 				QueueDefault:  5,
 			},
 			ErrorHandler: asynq.ErrorHandlerFunc(func(ctx context.Context, task *asynq.Task, err error) {
-				log.Error().Err(err).Str("type", task.Type()).
-					Bytes("payload", task.Payload()).Msg("process task failed")
+				logger := taskLogger(ctx, task)
+				logger.Error().Err(err).RawJSON("payload", logging.RedactJSON(task.Payload())).
+					Msg("process task failed")
 			}),
-			Logger: logger,
+			RetryDelayFunc: retryDelay,
+			Logger:         logger,
 		},
 	)
 
 	return &RedisTaskProcessor{
-		server: server,
-		store:  store,
-		mailer: mailer,
-		config: config,
+		server:     server,
+		store:      store,
+		notifiers:  notifiers,
+		templates:  renderer,
+		config:     config,
+		httpClient: newWebhookClient(),
+	}
+}
+
+func retryDelay(n int, err error, task *asynq.Task) time.Duration {
+	if task.Type() == TaskDeliverWebhook {
+		return webhookRetryDelay(n)
 	}
+	return asynq.DefaultRetryDelayFunc(n, err, task)
 }
 
 func (processor *RedisTaskProcessor) Start() error {
+	if err := processor.server.Start(processor.newServeMux()); err != nil {
+		return err
+	}
+	processor.running.Store(true)
+	return nil
+}
+
+// newServeMux routes every task type to its handler.
+func (processor *RedisTaskProcessor) newServeMux() *asynq.ServeMux {
 	mux := asynq.NewServeMux()
+	mux.Use(traceTask, logTask, observeTask)
 
 	mux.HandleFunc(TaskSendVerifyEmail, processor.ProcessTaskSendVerifyEmail)
+	mux.HandleFunc(TaskSendVerifyPhone, processor.ProcessTaskSendVerifyPhone)
+	mux.HandleFunc(TaskSendTransferNotification, processor.ProcessTaskSendTransferNotification)
+	mux.HandleFunc(TaskSendTransferFailedNotification, processor.ProcessTaskSendTransferFailedNotification)
+	mux.HandleFunc(TaskPruneSessions, processor.ProcessTaskPruneSessions)
+	mux.HandleFunc(TaskPruneVerifyEmails, processor.ProcessTaskPruneVerifyEmails)
+	mux.HandleFunc(TaskPruneOutbox, processor.ProcessTaskPruneOutbox)
+	mux.HandleFunc(TaskPruneIdempotencyKeys, processor.ProcessTaskPruneIdempotencyKeys)
+	mux.HandleFunc(TaskReconcileBalances, processor.ProcessTaskReconcileBalances)
+	mux.HandleFunc(TaskDeliverWebhook, processor.ProcessTaskDeliverWebhook)
+
+	return mux
+}
 
-	return processor.server.Start(mux)
+// observeTask records the duration and outcome of every task run.
+func observeTask(next asynq.Handler) asynq.Handler {
+	return asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		start := time.Now()
+		err := next.ProcessTask(ctx, task)
+		metrics.ObserveTaskProcessed(task.Type(), time.Since(start), err)
+		return err
+	})
 }
 
 func (processor *RedisTaskProcessor) Shutdown() {
+	processor.running.Store(false)
 	processor.server.Shutdown()
 }
+
+func (processor *RedisTaskProcessor) Ping() error {
+	if !processor.running.Load() {
+		return ErrProcessorStopped
+	}
+	return processor.server.Ping()
+}
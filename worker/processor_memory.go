@@ -0,0 +1,97 @@
+package worker
+
+import (
+	"context"
+	"runtime"
+	"sync"
+
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/mail/templates"
+	"github.com/LamThanhNguyen/banking-system/notify"
+	"github.com/LamThanhNguyen/banking-system/util"
+)
+
+// MemoryTaskProcessor runs the tasks of a MemoryQueue in process, with the
+// same handlers as RedisTaskProcessor.
+type MemoryTaskProcessor struct {
+	// RedisTaskProcessor provides the task handlers; its asynq server is not used.
+	*RedisTaskProcessor
+	queue   *MemoryQueue
+	workers int
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+func NewMemoryTaskProcessor(
+	queue *MemoryQueue,
+	store db.Store,
+	notifiers notify.Notifiers,
+	renderer *templates.Renderer,
+	config util.RuntimeConfig,
+) *MemoryTaskProcessor {
+	return &MemoryTaskProcessor{
+		RedisTaskProcessor: &RedisTaskProcessor{
+			store:      store,
+			notifiers:  notifiers,
+			templates:  renderer,
+			config:     config,
+			httpClient: newWebhookClient(),
+		},
+		queue:   queue,
+		workers: runtime.NumCPU(),
+	}
+}
+
+// Start runs the workers in the background until Shutdown.
+func (processor *MemoryTaskProcessor) Start() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	processor.cancel = cancel
+
+	mux := processor.newServeMux()
+	for range processor.workers {
+		processor.wg.Add(1)
+		go func() {
+			defer processor.wg.Done()
+			processor.queue.run(ctx, mux)
+		}()
+	}
+	processor.running.Store(true)
+	return nil
+}
+
+// Shutdown cancels the running tasks and waits for the workers to stop.
+// Cancelled tasks are scheduled for retry but, like everything else in the
+// queue, are lost when the process exits.
+func (processor *MemoryTaskProcessor) Shutdown() {
+	if processor.cancel == nil {
+		return
+	}
+	processor.running.Store(false)
+	processor.cancel()
+	processor.wg.Wait()
+}
+
+// Ping returns ErrProcessorStopped unless the workers are running. The queue
+// is in process, so there is nothing else to reach.
+func (processor *MemoryTaskProcessor) Ping() error {
+	if !processor.running.Load() {
+		return ErrProcessorStopped
+	}
+	return nil
+}
+
+// ProcessPending runs every task that is due, one at a time in the calling
+// goroutine, and returns how many ran. Tasks retried with a delay are left
+// scheduled. It gives tests a deterministic alternative to Start.
+func (processor *MemoryTaskProcessor) ProcessPending(ctx context.Context) int {
+	mux := processor.newServeMux()
+
+	n := 0
+	for {
+		ran, _ := processor.queue.process(ctx, mux)
+		if !ran {
+			return n
+		}
+		n++
+	}
+}
@@ -0,0 +1,151 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
+	"github.com/robfig/cron/v3"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	schedulerLockKey = "banking:scheduler:leader"
+	schedulerLockTTL = 30 * time.Second
+)
+
+type scheduleEntry struct {
+	spec     string
+	taskType string
+}
+
+// Scheduler enqueues the maintenance tasks on their cron schedules. Every
+// replica runs one, but only the holder of the leader lock registers the
+// entries, so each job is enqueued once per tick.
+type Scheduler struct {
+	redisOpt asynq.RedisClientOpt
+	client   redis.UniversalClient
+	lock     *LeaderLock
+	entries  []scheduleEntry
+}
+
+func NewScheduler(redisOpt asynq.RedisClientOpt, config util.RuntimeConfig) (*Scheduler, error) {
+	entries, err := maintenanceEntries(config)
+	if err != nil {
+		return nil, err
+	}
+
+	client := redisOpt.MakeRedisClient().(redis.UniversalClient)
+	return &Scheduler{
+		redisOpt: redisOpt,
+		client:   client,
+		lock:     NewLeaderLock(client, schedulerLockKey, schedulerLockTTL),
+		entries:  entries,
+	}, nil
+}
+
+func maintenanceEntries(config util.RuntimeConfig) ([]scheduleEntry, error) {
+	var entries []scheduleEntry
+	for _, entry := range []struct {
+		name string
+		scheduleEntry
+	}{
+		{"PRUNE_SESSIONS_SCHEDULE", scheduleEntry{config.PruneSessionsSchedule, TaskPruneSessions}},
+		{"PRUNE_VERIFY_EMAILS_SCHEDULE", scheduleEntry{config.PruneVerifyEmailsSchedule, TaskPruneVerifyEmails}},
+		{"PRUNE_OUTBOX_SCHEDULE", scheduleEntry{config.PruneOutboxSchedule, TaskPruneOutbox}},
+		{"PRUNE_IDEMPOTENCY_KEYS_SCHEDULE", scheduleEntry{config.PruneIdempotencyKeysSchedule, TaskPruneIdempotencyKeys}},
+		{"RECONCILE_SCHEDULE", scheduleEntry{config.ReconcileSchedule, TaskReconcileBalances}},
+	} {
+		if entry.spec == util.ScheduleOff {
+			continue
+		}
+		if _, err := cron.ParseStandard(entry.spec); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", entry.name, err)
+		}
+		entries = append(entries, entry.scheduleEntry)
+	}
+	return entries, nil
+}
+
+// Run campaigns for the leader lock until ctx is done, starting the asynq
+// scheduler while it is held and stopping it as soon as it is lost. It closes
+// the Redis client of the lock on return.
+func (s *Scheduler) Run(ctx context.Context) error {
+	defer func() {
+		if err := s.client.Close(); err != nil {
+			log.Error().Err(err).Msg("failed to close scheduler redis client")
+		}
+	}()
+
+	if len(s.entries) == 0 {
+		log.Info().Msg("no maintenance jobs scheduled")
+		return nil
+	}
+
+	ticker := time.NewTicker(schedulerLockTTL / 3)
+	defer ticker.Stop()
+
+	var scheduler *asynq.Scheduler
+	defer func() {
+		if scheduler != nil {
+			scheduler.Shutdown()
+		}
+		if err := s.lock.Release(context.Background()); err != nil {
+			log.Error().Err(err).Msg("failed to release scheduler lock")
+		}
+	}()
+
+	for {
+		leader, err := s.lock.TryAcquire(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Error().Err(err).Msg("failed to acquire scheduler lock")
+		}
+
+		switch {
+		case leader && scheduler == nil:
+			scheduler, err = s.start()
+			if err != nil {
+				return err
+			}
+			log.Info().Int("entries", len(s.entries)).Msg("scheduler elected leader")
+		case !leader && scheduler != nil:
+			scheduler.Shutdown()
+			scheduler = nil
+			log.Warn().Msg("scheduler lost leadership")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) start() (*asynq.Scheduler, error) {
+	scheduler := asynq.NewScheduler(s.redisOpt, &asynq.SchedulerOpts{
+		Logger: NewLogger(),
+		PostEnqueueFunc: func(info *asynq.TaskInfo, err error) {
+			if err != nil {
+				log.Error().Err(err).Msg("failed to enqueue scheduled task")
+				return
+			}
+			log.Info().Str("type", info.Type).Str("id", info.ID).Msg("enqueued scheduled task")
+		},
+	})
+
+	for _, entry := range s.entries {
+		task := asynq.NewTask(entry.taskType, nil)
+		if _, err := scheduler.Register(entry.spec, task, asynq.Queue(QueueDefault), asynq.MaxRetry(3)); err != nil {
+			return nil, fmt.Errorf("failed to register %s: %w", entry.taskType, err)
+		}
+	}
+
+	if err := scheduler.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start scheduler: %w", err)
+	}
+	return scheduler, nil
+}
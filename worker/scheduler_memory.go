@@ -0,0 +1,62 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/hibiken/asynq"
+	"github.com/robfig/cron/v3"
+	"github.com/rs/zerolog/log"
+)
+
+// MemoryScheduler enqueues the maintenance tasks into a MemoryQueue on their
+// cron schedules. There is a single process, so no leader election is needed.
+type MemoryScheduler struct {
+	queue   *MemoryQueue
+	entries []scheduleEntry
+}
+
+func NewMemoryScheduler(queue *MemoryQueue, config util.RuntimeConfig) (*MemoryScheduler, error) {
+	entries, err := maintenanceEntries(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MemoryScheduler{
+		queue:   queue,
+		entries: entries,
+	}, nil
+}
+
+// Run enqueues the scheduled tasks until ctx is done.
+func (s *MemoryScheduler) Run(ctx context.Context) error {
+	if len(s.entries) == 0 {
+		log.Info().Msg("no maintenance jobs scheduled")
+		return nil
+	}
+
+	scheduler := cron.New(cron.WithLocation(time.UTC))
+	for _, entry := range s.entries {
+		taskType := entry.taskType
+		_, err := scheduler.AddFunc(entry.spec, func() {
+			info, err := s.queue.EnqueueContext(ctx, asynq.NewTask(taskType, nil), asynq.Queue(QueueDefault), asynq.MaxRetry(3))
+			if err != nil {
+				log.Error().Err(err).Str("type", taskType).Msg("failed to enqueue scheduled task")
+				return
+			}
+			log.Info().Str("type", info.Type).Str("id", info.ID).Msg("enqueued scheduled task")
+		})
+		if err != nil {
+			return fmt.Errorf("failed to register %s: %w", taskType, err)
+		}
+	}
+
+	scheduler.Start()
+	log.Info().Int("entries", len(s.entries)).Msg("in-memory scheduler started")
+
+	<-ctx.Done()
+	<-scheduler.Stop().Done()
+	return nil
+}
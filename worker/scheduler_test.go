@@ -0,0 +1,173 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mockdb "github.com/LamThanhNguyen/banking-system/db/mock"
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestLeaderLock(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	ctx := context.Background()
+
+	first := NewLeaderLock(client, "leader", time.Minute)
+	second := NewLeaderLock(client, "leader", time.Minute)
+
+	held, err := first.TryAcquire(ctx)
+	require.NoError(t, err)
+	require.True(t, held)
+
+	held, err = second.TryAcquire(ctx)
+	require.NoError(t, err)
+	require.False(t, held)
+
+	// the holder extends its lease
+	mr.FastForward(30 * time.Second)
+	held, err = first.TryAcquire(ctx)
+	require.NoError(t, err)
+	require.True(t, held)
+	require.Equal(t, time.Minute, mr.TTL("leader"))
+
+	// a lease that is not extended expires and can be taken over
+	mr.FastForward(2 * time.Minute)
+	held, err = second.TryAcquire(ctx)
+	require.NoError(t, err)
+	require.True(t, held)
+
+	// releasing a lock held by someone else does nothing
+	require.NoError(t, first.Release(ctx))
+	held, err = first.TryAcquire(ctx)
+	require.NoError(t, err)
+	require.False(t, held)
+
+	require.NoError(t, second.Release(ctx))
+	held, err = first.TryAcquire(ctx)
+	require.NoError(t, err)
+	require.True(t, held)
+}
+
+func TestMaintenanceEntries(t *testing.T) {
+	config, err := util.NewRuntimeConfig(util.Config{
+		AccessTokenDuration:  "15m",
+		RefreshTokenDuration: "24h",
+		ReconcileSchedule:    util.ScheduleOff,
+	})
+	require.NoError(t, err)
+
+	entries, err := maintenanceEntries(config)
+	require.NoError(t, err)
+	require.Equal(t, []scheduleEntry{
+		{"@hourly", TaskPruneSessions},
+		{"@hourly", TaskPruneVerifyEmails},
+		{"@hourly", TaskPruneOutbox},
+		{"@hourly", TaskPruneIdempotencyKeys},
+	}, entries)
+
+	config.PruneSessionsSchedule = "every minute"
+	_, err = maintenanceEntries(config)
+	require.ErrorContains(t, err, "PRUNE_SESSIONS_SCHEDULE")
+}
+
+func TestSchedulerRun(t *testing.T) {
+	mr := miniredis.RunT(t)
+	config, err := util.NewRuntimeConfig(util.Config{
+		AccessTokenDuration:  "15m",
+		RefreshTokenDuration: "24h",
+	})
+	require.NoError(t, err)
+
+	scheduler, err := NewScheduler(asynq.RedisClientOpt{Addr: mr.Addr()}, config)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- scheduler.Run(ctx)
+	}()
+	require.Eventually(t, func() bool {
+		return mr.Exists(schedulerLockKey)
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+
+	// the lock is released before the client is closed
+	require.False(t, mr.Exists(schedulerLockKey))
+	require.ErrorIs(t, scheduler.client.Ping(context.Background()).Err(), redis.ErrClosed)
+}
+
+func TestProcessMaintenanceTasks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	config := util.RuntimeConfig{MaintenanceRetentionParsed: 24 * time.Hour}
+	processor := &RedisTaskProcessor{store: store, config: config}
+	ctx := context.Background()
+
+	checkCutoff := func(expiredBefore time.Time) {
+		require.WithinDuration(t, time.Now().Add(-24*time.Hour), expiredBefore, time.Second)
+	}
+	store.EXPECT().
+		DeleteExpiredSessions(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, expiredBefore time.Time) (int64, error) {
+			checkCutoff(expiredBefore)
+			return 3, nil
+		})
+	store.EXPECT().
+		DeleteExpiredVerifyEmails(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, expiredBefore time.Time) (int64, error) {
+			checkCutoff(expiredBefore)
+			return 5, nil
+		})
+	store.EXPECT().
+		DeleteExpiredVerifyPhones(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, expiredBefore time.Time) (int64, error) {
+			checkCutoff(expiredBefore)
+			return 2, nil
+		})
+	store.EXPECT().
+		DeleteOutboxPublishedBefore(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, publishedBefore time.Time) (int64, error) {
+			checkCutoff(publishedBefore)
+			return 7, nil
+		})
+	store.EXPECT().
+		DeleteTransferNotificationsSentBefore(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, sentBefore time.Time) (int64, error) {
+			checkCutoff(sentBefore)
+			return 4, nil
+		})
+	store.EXPECT().
+		DeleteExpiredIdempotencyKeys(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, expiredBefore time.Time) (int64, error) {
+			require.WithinDuration(t, time.Now().Add(-24*time.Hour-util.IdempotencyKeyTTL), expiredBefore, time.Second)
+			return 6, nil
+		})
+	store.EXPECT().
+		ListBalanceMismatches(gomock.Any()).
+		Times(1).
+		Return([]db.ListBalanceMismatchesRow{{ID: 1, Balance: 100, EntriesTotal: 90}}, nil)
+
+	require.NoError(t, processor.ProcessTaskPruneSessions(ctx, asynq.NewTask(TaskPruneSessions, nil)))
+	require.NoError(t, processor.ProcessTaskPruneVerifyEmails(ctx, asynq.NewTask(TaskPruneVerifyEmails, nil)))
+	require.NoError(t, processor.ProcessTaskPruneOutbox(ctx, asynq.NewTask(TaskPruneOutbox, nil)))
+	require.NoError(t, processor.ProcessTaskPruneIdempotencyKeys(ctx, asynq.NewTask(TaskPruneIdempotencyKeys, nil)))
+	require.NoError(t, processor.ProcessTaskReconcileBalances(ctx, asynq.NewTask(TaskReconcileBalances, nil)))
+}
@@ -0,0 +1,289 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskDeliverWebhook = "task:deliver_webhook"
+
+const (
+	// WebhookSignatureHeader carries "sha256=" followed by the hex HMAC-SHA256
+	// of the request body, keyed with the subscription secret.
+	WebhookSignatureHeader = "X-Signature"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+
+	webhookMaxRetry = 12
+	// webhookMaxFailures consecutive failed attempts disable a subscription.
+	webhookMaxFailures = 20
+	webhookBaseDelay   = 30 * time.Second
+	webhookMaxDelay    = 6 * time.Hour
+	webhookTimeout     = 10 * time.Second
+)
+
+type PayloadDeliverWebhook struct {
+	DeliveryID int64 `json:"delivery_id"`
+}
+
+// WebhookEvent is the body posted to subscribers. Replayed deliveries keep the
+// event ID so that receivers can deduplicate.
+type WebhookEvent struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+// WebhookTransferData is the data of a transfer.created event.
+type WebhookTransferData struct {
+	ID            int64     `json:"id"`
+	FromAccountID int64     `json:"from_account_id"`
+	ToAccountID   int64     `json:"to_account_id"`
+	Amount        int64     `json:"amount"`
+	Currency      string    `json:"currency"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func (distributor *RedisTaskDistributor) DistributeTaskDeliverWebhook(
+	ctx context.Context,
+	payload *PayloadDeliverWebhook,
+	opts ...asynq.Option,
+) error {
+	return distributor.enqueue(ctx, TaskDeliverWebhook, payload, opts...)
+}
+
+func (distributor *OutboxTaskDistributor) DistributeTaskDeliverWebhook(
+	ctx context.Context,
+	payload *PayloadDeliverWebhook,
+	opts ...asynq.Option,
+) error {
+	return distributor.enqueue(ctx, TaskDeliverWebhook, payload, opts...)
+}
+
+func (distributor *MemoryTaskDistributor) DistributeTaskDeliverWebhook(
+	ctx context.Context,
+	payload *PayloadDeliverWebhook,
+	opts ...asynq.Option,
+) error {
+	return distributor.enqueue(ctx, TaskDeliverWebhook, payload, opts...)
+}
+
+// DispatchWebhookEvent records a delivery for every active subscription of the
+// user to the event and schedules them through the outbox of q, so it must be
+// called with the Querier of the transaction that produced the event.
+func DispatchWebhookEvent(ctx context.Context, q db.Querier, username, eventType string, data any) error {
+	subscriptions, err := q.ListWebhookSubscriptionsForEvent(ctx, db.ListWebhookSubscriptionsForEventParams{
+		Username:  username,
+		EventType: eventType,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(WebhookEvent{
+		ID:        uuid.NewString(),
+		Type:      eventType,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook event: %w", err)
+	}
+
+	for _, subscription := range subscriptions {
+		delivery, err := q.CreateWebhookDelivery(ctx, db.CreateWebhookDeliveryParams{
+			SubscriptionID: subscription.ID,
+			EventType:      eventType,
+			Payload:        payload,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create webhook delivery: %w", err)
+		}
+
+		if err := DistributeWebhookDelivery(ctx, q, delivery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DispatchTransferWebhooks sends a transfer.created event to the subscriptions
+// of the owners of both accounts.
+func DispatchTransferWebhooks(ctx context.Context, q db.Querier, result db.TransferTxResult, currency string) error {
+	data := WebhookTransferData{
+		ID:            result.Transfer.ID,
+		FromAccountID: result.Transfer.FromAccountID,
+		ToAccountID:   result.Transfer.ToAccountID,
+		Amount:        result.Transfer.Amount,
+		Currency:      currency,
+		CreatedAt:     result.Transfer.CreatedAt,
+	}
+
+	owners := []string{result.FromAccount.Owner}
+	if result.ToAccount.Owner != result.FromAccount.Owner {
+		owners = append(owners, result.ToAccount.Owner)
+	}
+	for _, owner := range owners {
+		if err := DispatchWebhookEvent(ctx, q, owner, util.WebhookTransferCreated, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DistributeWebhookDelivery schedules a recorded delivery through the outbox of q.
+func DistributeWebhookDelivery(ctx context.Context, q db.Querier, delivery db.WebhookDelivery) error {
+	return NewOutboxTaskDistributor(q).DistributeTaskDeliverWebhook(
+		ctx,
+		&PayloadDeliverWebhook{DeliveryID: delivery.ID},
+		asynq.MaxRetry(webhookMaxRetry),
+		asynq.Queue(QueueDefault),
+	)
+}
+
+// SignWebhookPayload returns the X-Signature header value of a webhook body.
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookRetryDelay backs off exponentially from webhookBaseDelay up to
+// webhookMaxDelay, with up to 10% jitter.
+func webhookRetryDelay(n int) time.Duration {
+	delay := webhookMaxDelay
+	if n < 20 {
+		delay = min(webhookBaseDelay<<n, webhookMaxDelay)
+	}
+	return delay + rand.N(delay/10+1)
+}
+
+func (processor *RedisTaskProcessor) ProcessTaskDeliverWebhook(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadDeliverWebhook
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	delivery, err := processor.store.GetWebhookDelivery(ctx, payload.DeliveryID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return fmt.Errorf("webhook delivery %d not found: %w", payload.DeliveryID, asynq.SkipRetry)
+		}
+		return fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+
+	subscription, err := processor.store.GetWebhookSubscription(ctx, delivery.SubscriptionID)
+	if err != nil {
+		return fmt.Errorf("failed to get webhook subscription: %w", err)
+	}
+
+	if subscription.IsDisabled {
+		_, err := processor.store.MarkWebhookDeliveryFailed(ctx, db.MarkWebhookDeliveryFailedParams{
+			ID:        delivery.ID,
+			LastError: "subscription is disabled",
+		})
+		if err != nil {
+			return fmt.Errorf("failed to mark webhook delivery: %w", err)
+		}
+		return fmt.Errorf("webhook subscription %d is disabled: %w", subscription.ID, asynq.SkipRetry)
+	}
+
+	status, postErr := processor.postWebhook(ctx, subscription, delivery)
+	if postErr == nil {
+		_, err := processor.store.MarkWebhookDeliverySucceeded(ctx, db.MarkWebhookDeliverySucceededParams{
+			ID:             delivery.ID,
+			ResponseStatus: int32(status),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to mark webhook delivery: %w", err)
+		}
+		if err := processor.store.RecordWebhookSuccess(ctx, subscription.ID); err != nil {
+			return fmt.Errorf("failed to record webhook success: %w", err)
+		}
+
+		log.Ctx(ctx).Info().Int64("delivery_id", delivery.ID).
+			Int64("subscription_id", subscription.ID).Int("status", status).Msg("processed task")
+		return nil
+	}
+
+	_, err = processor.store.MarkWebhookDeliveryFailed(ctx, db.MarkWebhookDeliveryFailedParams{
+		ID:             delivery.ID,
+		ResponseStatus: int32(status),
+		LastError:      postErr.Error(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark webhook delivery: %w", err)
+	}
+
+	subscription, err = processor.store.RecordWebhookFailure(ctx, db.RecordWebhookFailureParams{
+		ID:          subscription.ID,
+		MaxFailures: webhookMaxFailures,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record webhook failure: %w", err)
+	}
+
+	if subscription.IsDisabled {
+		log.Ctx(ctx).Warn().Int64("subscription_id", subscription.ID).Str("username", subscription.Username).
+			Int32("consecutive_failures", subscription.ConsecutiveFailures).Msg("disabled failing webhook subscription")
+		return fmt.Errorf("failed to deliver webhook: %v: %w", postErr, asynq.SkipRetry)
+	}
+	return fmt.Errorf("failed to deliver webhook: %w", postErr)
+}
+
+// postWebhook posts the delivery and returns the response status, or 0 when
+// no response was received.
+func (processor *RedisTaskProcessor) postWebhook(
+	ctx context.Context,
+	subscription db.WebhookSubscription,
+	delivery db.WebhookDelivery,
+) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "banking-system-webhooks/1.0")
+	req.Header.Set(WebhookEventHeader, delivery.EventType)
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(subscription.Secret, delivery.Payload))
+
+	rsp, err := processor.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer rsp.Body.Close()
+
+	// The body is drained so the connection can be reused, but never kept:
+	// subscribers can read the deliveries, and the body is not theirs to see
+	// if the URL reached something it should not have.
+	_, _ = io.Copy(io.Discard, io.LimitReader(rsp.Body, 4096))
+	if rsp.StatusCode < 200 || rsp.StatusCode > 299 {
+		return rsp.StatusCode, fmt.Errorf("unexpected status %d", rsp.StatusCode)
+	}
+	return rsp.StatusCode, nil
+}
@@ -0,0 +1,248 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	mockdb "github.com/LamThanhNguyen/banking-system/db/mock"
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestProcessTaskDeliverWebhook(t *testing.T) {
+	payload := []byte(`{"id":"evt","type":"transfer.created","data":{"id":7}}`)
+	delivery := db.WebhookDelivery{
+		ID:             11,
+		SubscriptionID: 3,
+		EventType:      util.WebhookTransferCreated,
+		Payload:        payload,
+	}
+
+	testCases := []struct {
+		name       string
+		status     int
+		disabled   bool
+		buildStubs func(store *mockdb.MockStore, subscription db.WebhookSubscription)
+		checkError func(t *testing.T, err error, received int)
+	}{
+		{
+			name:   "Delivered",
+			status: http.StatusNoContent,
+			buildStubs: func(store *mockdb.MockStore, subscription db.WebhookSubscription) {
+				store.EXPECT().
+					MarkWebhookDeliverySucceeded(gomock.Any(), gomock.Eq(db.MarkWebhookDeliverySucceededParams{
+						ID:             delivery.ID,
+						ResponseStatus: http.StatusNoContent,
+					})).
+					Times(1)
+				store.EXPECT().
+					RecordWebhookSuccess(gomock.Any(), gomock.Eq(subscription.ID)).
+					Times(1)
+			},
+			checkError: func(t *testing.T, err error, received int) {
+				require.NoError(t, err)
+				require.Equal(t, 1, received)
+			},
+		},
+		{
+			name:   "ReceiverError",
+			status: http.StatusInternalServerError,
+			buildStubs: func(store *mockdb.MockStore, subscription db.WebhookSubscription) {
+				store.EXPECT().
+					MarkWebhookDeliveryFailed(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.MarkWebhookDeliveryFailedParams) (db.WebhookDelivery, error) {
+						require.Equal(t, int32(http.StatusInternalServerError), arg.ResponseStatus)
+						require.Equal(t, "unexpected status 500", arg.LastError)
+						return delivery, nil
+					})
+				store.EXPECT().
+					RecordWebhookFailure(gomock.Any(), gomock.Eq(db.RecordWebhookFailureParams{
+						ID:          subscription.ID,
+						MaxFailures: webhookMaxFailures,
+					})).
+					Times(1).
+					Return(subscription, nil)
+			},
+			checkError: func(t *testing.T, err error, received int) {
+				require.Error(t, err)
+				require.NotErrorIs(t, err, asynq.SkipRetry)
+				require.Equal(t, 1, received)
+			},
+		},
+		{
+			name:   "DisabledAfterTooManyFailures",
+			status: http.StatusGone,
+			buildStubs: func(store *mockdb.MockStore, subscription db.WebhookSubscription) {
+				store.EXPECT().
+					MarkWebhookDeliveryFailed(gomock.Any(), gomock.Any()).
+					Times(1)
+				subscription.IsDisabled = true
+				subscription.ConsecutiveFailures = webhookMaxFailures
+				store.EXPECT().
+					RecordWebhookFailure(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(subscription, nil)
+			},
+			checkError: func(t *testing.T, err error, received int) {
+				require.ErrorIs(t, err, asynq.SkipRetry)
+			},
+		},
+		{
+			name:     "SubscriptionDisabled",
+			disabled: true,
+			buildStubs: func(store *mockdb.MockStore, subscription db.WebhookSubscription) {
+				store.EXPECT().
+					MarkWebhookDeliveryFailed(gomock.Any(), gomock.Eq(db.MarkWebhookDeliveryFailedParams{
+						ID:        delivery.ID,
+						LastError: "subscription is disabled",
+					})).
+					Times(1)
+			},
+			checkError: func(t *testing.T, err error, received int) {
+				require.ErrorIs(t, err, asynq.SkipRetry)
+				require.Zero(t, received)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			received := 0
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received++
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				require.Equal(t, payload, body)
+				require.Equal(t, SignWebhookPayload("topsecret", body), r.Header.Get(WebhookSignatureHeader))
+				require.Equal(t, util.WebhookTransferCreated, r.Header.Get(WebhookEventHeader))
+				require.Equal(t, strconv.FormatInt(delivery.ID, 10), r.Header.Get(WebhookDeliveryHeader))
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte("internal details"))
+			}))
+			defer receiver.Close()
+
+			subscription := db.WebhookSubscription{
+				ID:         delivery.SubscriptionID,
+				Username:   "alice",
+				Url:        receiver.URL,
+				Secret:     "topsecret",
+				IsDisabled: tc.disabled,
+			}
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				GetWebhookDelivery(gomock.Any(), gomock.Eq(delivery.ID)).
+				Times(1).
+				Return(delivery, nil)
+			store.EXPECT().
+				GetWebhookSubscription(gomock.Any(), gomock.Eq(subscription.ID)).
+				Times(1).
+				Return(subscription, nil)
+			tc.buildStubs(store, subscription)
+
+			processor := &RedisTaskProcessor{store: store, httpClient: receiver.Client()}
+
+			data, err := json.Marshal(PayloadDeliverWebhook{DeliveryID: delivery.ID})
+			require.NoError(t, err)
+
+			err = processor.ProcessTaskDeliverWebhook(context.Background(), asynq.NewTask(TaskDeliverWebhook, data))
+			tc.checkError(t, err, received)
+		})
+	}
+}
+
+func TestDispatchWebhookEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListWebhookSubscriptionsForEvent(gomock.Any(), gomock.Eq(db.ListWebhookSubscriptionsForEventParams{
+			Username:  "alice",
+			EventType: util.WebhookAccountCreated,
+		})).
+		Times(1).
+		Return([]db.WebhookSubscription{{ID: 1}, {ID: 2}}, nil)
+
+	var eventIDs []string
+	store.EXPECT().
+		CreateWebhookDelivery(gomock.Any(), gomock.Any()).
+		Times(2).
+		DoAndReturn(func(_ context.Context, arg db.CreateWebhookDeliveryParams) (db.WebhookDelivery, error) {
+			var event WebhookEvent
+			require.NoError(t, json.Unmarshal(arg.Payload, &event))
+			require.Equal(t, util.WebhookAccountCreated, event.Type)
+			require.Equal(t, map[string]any{"id": float64(9)}, event.Data)
+			eventIDs = append(eventIDs, event.ID)
+			return db.WebhookDelivery{ID: arg.SubscriptionID * 10, SubscriptionID: arg.SubscriptionID}, nil
+		})
+	store.EXPECT().
+		CreateOutbox(gomock.Any(), gomock.Any()).
+		Times(2).
+		DoAndReturn(func(_ context.Context, arg db.CreateOutboxParams) (db.Outbox, error) {
+			require.Equal(t, TaskDeliverWebhook, arg.TaskType)
+			require.Equal(t, int32(webhookMaxRetry), arg.MaxRetry)
+			return db.Outbox{}, nil
+		})
+
+	err := DispatchWebhookEvent(context.Background(), store, "alice", util.WebhookAccountCreated, map[string]int{"id": 9})
+	require.NoError(t, err)
+	require.Len(t, eventIDs, 2)
+	require.Equal(t, eventIDs[0], eventIDs[1])
+}
+
+func TestDispatchWebhookEventError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListWebhookSubscriptionsForEvent(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(nil, errors.New("connection refused"))
+	store.EXPECT().
+		CreateWebhookDelivery(gomock.Any(), gomock.Any()).
+		Times(0)
+
+	err := DispatchWebhookEvent(context.Background(), store, "alice", util.WebhookAccountCreated, nil)
+	require.Error(t, err)
+}
+
+func TestWebhookRetryDelay(t *testing.T) {
+	require.GreaterOrEqual(t, webhookRetryDelay(0), webhookBaseDelay)
+	require.Less(t, webhookRetryDelay(0), webhookBaseDelay*11/10+time.Nanosecond)
+	require.GreaterOrEqual(t, webhookRetryDelay(3), 8*webhookBaseDelay)
+	require.GreaterOrEqual(t, webhookRetryDelay(40), webhookMaxDelay)
+	require.LessOrEqual(t, webhookRetryDelay(40), webhookMaxDelay*11/10)
+}
+
+func TestWebhookClientRejectsLocalAddresses(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("webhook client reached a loopback address")
+	}))
+	defer receiver.Close()
+
+	_, err := newWebhookClient().Post(receiver.URL, "application/json", nil)
+	require.ErrorIs(t, err, ErrWebhookAddressNotAllowed)
+}
+
+func TestWebhookClientDoesNotFollowRedirects(t *testing.T) {
+	client := newWebhookClient()
+	require.ErrorIs(t, client.CheckRedirect(nil, nil), http.ErrUseLastResponse)
+}
@@ -0,0 +1,108 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+// Maintenance tasks carry no payload; they are enqueued by the Scheduler only.
+const (
+	TaskPruneSessions        = "maintenance:prune_sessions"
+	TaskPruneVerifyEmails    = "maintenance:prune_verify_emails"
+	TaskPruneOutbox          = "maintenance:prune_outbox"
+	TaskPruneIdempotencyKeys = "maintenance:prune_idempotency_keys"
+	TaskReconcileBalances    = "maintenance:reconcile_balances"
+)
+
+// ProcessTaskPruneSessions deletes the sessions that expired longer than the
+// maintenance retention ago.
+func (processor *RedisTaskProcessor) ProcessTaskPruneSessions(ctx context.Context, task *asynq.Task) error {
+	expiredBefore := time.Now().Add(-processor.config.MaintenanceRetentionParsed)
+	n, err := processor.store.DeleteExpiredSessions(ctx, expiredBefore)
+	if err != nil {
+		return fmt.Errorf("failed to delete expired sessions: %w", err)
+	}
+
+	log.Ctx(ctx).Info().Int64("deleted", n).
+		Time("expired_before", expiredBefore).Msg("processed task")
+	return nil
+}
+
+// ProcessTaskPruneVerifyEmails deletes the email and phone verification codes
+// that expired longer than the maintenance retention ago, used or not.
+func (processor *RedisTaskProcessor) ProcessTaskPruneVerifyEmails(ctx context.Context, task *asynq.Task) error {
+	expiredBefore := time.Now().Add(-processor.config.MaintenanceRetentionParsed)
+	n, err := processor.store.DeleteExpiredVerifyEmails(ctx, expiredBefore)
+	if err != nil {
+		return fmt.Errorf("failed to delete expired verify emails: %w", err)
+	}
+
+	phones, err := processor.store.DeleteExpiredVerifyPhones(ctx, expiredBefore)
+	if err != nil {
+		return fmt.Errorf("failed to delete expired verify phones: %w", err)
+	}
+
+	log.Ctx(ctx).Info().Int64("deleted", n).Int64("deleted_phones", phones).
+		Time("expired_before", expiredBefore).Msg("processed task")
+	return nil
+}
+
+// ProcessTaskPruneOutbox deletes the outbox rows published longer than the
+// maintenance retention ago, and the records of the transfer notifications
+// sent before then, which only guard against sending them twice on retry.
+// Dead rows are kept for investigation.
+func (processor *RedisTaskProcessor) ProcessTaskPruneOutbox(ctx context.Context, task *asynq.Task) error {
+	publishedBefore := time.Now().Add(-processor.config.MaintenanceRetentionParsed)
+	n, err := processor.store.DeleteOutboxPublishedBefore(ctx, publishedBefore)
+	if err != nil {
+		return fmt.Errorf("failed to delete published outbox rows: %w", err)
+	}
+
+	notifications, err := processor.store.DeleteTransferNotificationsSentBefore(ctx, publishedBefore)
+	if err != nil {
+		return fmt.Errorf("failed to delete sent transfer notifications: %w", err)
+	}
+
+	log.Ctx(ctx).Info().Int64("deleted", n).Int64("deleted_notifications", notifications).
+		Time("published_before", publishedBefore).Msg("processed task")
+	return nil
+}
+
+// ProcessTaskPruneIdempotencyKeys deletes the idempotency keys that expired
+// longer than the maintenance retention ago. A key expires
+// util.IdempotencyKeyTTL after it is claimed.
+func (processor *RedisTaskProcessor) ProcessTaskPruneIdempotencyKeys(ctx context.Context, task *asynq.Task) error {
+	expiredBefore := time.Now().Add(-processor.config.MaintenanceRetentionParsed - util.IdempotencyKeyTTL)
+	n, err := processor.store.DeleteExpiredIdempotencyKeys(ctx, expiredBefore)
+	if err != nil {
+		return fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+
+	log.Ctx(ctx).Info().Int64("deleted", n).
+		Time("expired_before", expiredBefore).Msg("processed task")
+	return nil
+}
+
+// ProcessTaskReconcileBalances compares every account balance with the sum of
+// its entries and reports the accounts that do not match. It never corrects
+// balances; mismatches need to be investigated by a banker.
+func (processor *RedisTaskProcessor) ProcessTaskReconcileBalances(ctx context.Context, task *asynq.Task) error {
+	mismatches, err := processor.store.ListBalanceMismatches(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list balance mismatches: %w", err)
+	}
+
+	for _, m := range mismatches {
+		log.Ctx(ctx).Warn().Int64("account_id", m.ID).Str("owner", m.Owner).
+			Str("currency", m.Currency).Int64("balance", m.Balance).
+			Int64("entries_total", m.EntriesTotal).Msg("account balance does not match its entries")
+	}
+
+	log.Ctx(ctx).Info().Int("mismatches", len(mismatches)).Msg("processed task")
+	return nil
+}
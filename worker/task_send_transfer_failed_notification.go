@@ -0,0 +1,91 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/LamThanhNguyen/banking-system/logging"
+	"github.com/LamThanhNguyen/banking-system/mail/templates"
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskSendTransferFailedNotification = "task:send_transfer_failed_notification"
+
+type PayloadSendTransferFailedNotification struct {
+	Username      string `json:"username"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	Currency      string `json:"currency"`
+}
+
+func (distributor *RedisTaskDistributor) DistributeTaskSendTransferFailedNotification(
+	ctx context.Context,
+	payload *PayloadSendTransferFailedNotification,
+	opts ...asynq.Option,
+) error {
+	return distributor.enqueue(ctx, TaskSendTransferFailedNotification, payload, opts...)
+}
+
+func (distributor *OutboxTaskDistributor) DistributeTaskSendTransferFailedNotification(
+	ctx context.Context,
+	payload *PayloadSendTransferFailedNotification,
+	opts ...asynq.Option,
+) error {
+	return distributor.enqueue(ctx, TaskSendTransferFailedNotification, payload, opts...)
+}
+
+func (distributor *MemoryTaskDistributor) DistributeTaskSendTransferFailedNotification(
+	ctx context.Context,
+	payload *PayloadSendTransferFailedNotification,
+	opts ...asynq.Option,
+) error {
+	return distributor.enqueue(ctx, TaskSendTransferFailedNotification, payload, opts...)
+}
+
+func (processor *RedisTaskProcessor) ProcessTaskSendTransferFailedNotification(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadSendTransferFailedNotification
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	user, err := processor.store.GetUser(ctx, payload.Username)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	settings, err := processor.loadNotificationSettings(ctx, user.Username)
+	if err != nil {
+		return err
+	}
+
+	data := templates.TransferFailedData{
+		FullName:      user.FullName,
+		FromAccountID: payload.FromAccountID,
+		ToAccountID:   payload.ToAccountID,
+		Amount:        payload.Amount,
+		Currency:      payload.Currency,
+	}
+
+	sent := 0
+	for _, channel := range notificationChannels {
+		if !settings.lookup(util.NotificationTransferFailed, channel) {
+			continue
+		}
+
+		ok, err := processor.sendTemplated(ctx, user, channel, templates.TransferFailed, data)
+		if err != nil {
+			return err
+		}
+		if ok {
+			sent++
+		}
+	}
+
+	log.Ctx(ctx).Info().RawJSON("payload", logging.RedactJSON(task.Payload())).
+		Str("username", user.Username).Int("sent", sent).Msg("processed task")
+	return nil
+}
@@ -0,0 +1,197 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/logging"
+	"github.com/LamThanhNguyen/banking-system/mail/templates"
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskSendTransferNotification = "task:send_transfer_notification"
+
+// PayloadSendTransferNotification describes one side of a completed transfer.
+// Event is either util.NotificationTransferSent or util.NotificationTransferReceived,
+// and Balance is the balance of AccountID right after the transfer.
+type PayloadSendTransferNotification struct {
+	Username              string `json:"username"`
+	Event                 string `json:"event"`
+	TransferID            int64  `json:"transfer_id"`
+	AccountID             int64  `json:"account_id"`
+	CounterpartyAccountID int64  `json:"counterparty_account_id"`
+	Amount                int64  `json:"amount"`
+	Currency              string `json:"currency"`
+	Balance               int64  `json:"balance"`
+}
+
+func (distributor *RedisTaskDistributor) DistributeTaskSendTransferNotification(
+	ctx context.Context,
+	payload *PayloadSendTransferNotification,
+	opts ...asynq.Option,
+) error {
+	return distributor.enqueue(ctx, TaskSendTransferNotification, payload, opts...)
+}
+
+func (distributor *OutboxTaskDistributor) DistributeTaskSendTransferNotification(
+	ctx context.Context,
+	payload *PayloadSendTransferNotification,
+	opts ...asynq.Option,
+) error {
+	return distributor.enqueue(ctx, TaskSendTransferNotification, payload, opts...)
+}
+
+func (distributor *MemoryTaskDistributor) DistributeTaskSendTransferNotification(
+	ctx context.Context,
+	payload *PayloadSendTransferNotification,
+	opts ...asynq.Option,
+) error {
+	return distributor.enqueue(ctx, TaskSendTransferNotification, payload, opts...)
+}
+
+func (processor *RedisTaskProcessor) ProcessTaskSendTransferNotification(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadSendTransferNotification
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	user, err := processor.store.GetUser(ctx, payload.Username)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	settings, err := processor.loadNotificationSettings(ctx, user.Username)
+	if err != nil {
+		return err
+	}
+
+	name := templates.TransferSent
+	if payload.Event == util.NotificationTransferReceived {
+		name = templates.TransferReceived
+	}
+	receipt := templates.TransferData{
+		FullName:              user.FullName,
+		TransferID:            payload.TransferID,
+		AccountID:             payload.AccountID,
+		CounterpartyAccountID: payload.CounterpartyAccountID,
+		Amount:                payload.Amount,
+		Currency:              payload.Currency,
+		Balance:               payload.Balance,
+	}
+
+	// A retry skips the notifications that an earlier attempt already sent.
+	done, err := processor.store.ListSentTransferNotifications(ctx, db.ListSentTransferNotificationsParams{
+		TransferID: payload.TransferID,
+		Username:   user.Username,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list sent notifications: %w", err)
+	}
+	skip := make(map[[2]string]bool, len(done))
+	for _, n := range done {
+		skip[[2]string{n.Template, n.Channel}] = true
+	}
+
+	sent := 0
+	send := func(channel string, name string, data any) error {
+		if skip[[2]string{name, channel}] {
+			return nil
+		}
+		ok, err := processor.sendTemplated(ctx, user, channel, name, data)
+		if err != nil || !ok {
+			return err
+		}
+		sent++
+		err = processor.store.CreateTransferNotification(ctx, db.CreateTransferNotificationParams{
+			TransferID: payload.TransferID,
+			Username:   user.Username,
+			Template:   name,
+			Channel:    channel,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to record sent notification: %w", err)
+		}
+		return nil
+	}
+
+	// Alert thresholds are set per channel and currency.
+	for _, channel := range notificationChannels {
+		if settings.lookup(payload.Event, channel) {
+			if err := send(channel, name, receipt); err != nil {
+				return err
+			}
+		}
+
+		alert := templates.AlertData{
+			FullName:   user.FullName,
+			TransferID: payload.TransferID,
+			AccountID:  payload.AccountID,
+			Amount:     payload.Amount,
+			Currency:   payload.Currency,
+			Balance:    payload.Balance,
+		}
+
+		if enabled, threshold := settings.alert(util.NotificationLowBalance, channel, payload.Currency); enabled &&
+			payload.Event == util.NotificationTransferSent && payload.Balance < threshold {
+			alert.Threshold = threshold
+			if err := send(channel, templates.LowBalance, alert); err != nil {
+				return err
+			}
+		}
+
+		if enabled, threshold := settings.alert(util.NotificationLargeTransaction, channel, payload.Currency); enabled &&
+			payload.Amount >= threshold {
+			alert.Threshold = threshold
+			if err := send(channel, templates.LargeTransaction, alert); err != nil {
+				return err
+			}
+		}
+	}
+
+	log.Ctx(ctx).Info().RawJSON("payload", logging.RedactJSON(task.Payload())).
+		Str("username", user.Username).Int("sent", sent).Msg("processed task")
+	return nil
+}
+
+// DistributeTransferNotifications enqueues a receipt task for the sender and the receiver.
+func DistributeTransferNotifications(
+	ctx context.Context,
+	distributor TaskDistributor,
+	result db.TransferTxResult,
+	currency string,
+) error {
+	payloads := []*PayloadSendTransferNotification{
+		{
+			Username:              result.FromAccount.Owner,
+			Event:                 util.NotificationTransferSent,
+			TransferID:            result.Transfer.ID,
+			AccountID:             result.FromAccount.ID,
+			CounterpartyAccountID: result.ToAccount.ID,
+			Amount:                result.Transfer.Amount,
+			Currency:              currency,
+			Balance:               result.FromAccount.Balance,
+		},
+		{
+			Username:              result.ToAccount.Owner,
+			Event:                 util.NotificationTransferReceived,
+			TransferID:            result.Transfer.ID,
+			AccountID:             result.ToAccount.ID,
+			CounterpartyAccountID: result.FromAccount.ID,
+			Amount:                result.Transfer.Amount,
+			Currency:              currency,
+			Balance:               result.ToAccount.Balance,
+		},
+	}
+
+	for _, payload := range payloads {
+		err := distributor.DistributeTaskSendTransferNotification(ctx, payload, asynq.MaxRetry(10))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	mockdb "github.com/LamThanhNguyen/banking-system/db/mock"
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/mail"
+	"github.com/LamThanhNguyen/banking-system/mail/templates"
+	"github.com/LamThanhNguyen/banking-system/notify"
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestProcessTaskSendTransferNotification(t *testing.T) {
+	user := db.User{
+		Username:        "alice",
+		FullName:        "Alice",
+		Email:           "alice@example.com",
+		Locale:          util.EnglishLocale,
+		Phone:           "+84901234567",
+		IsPhoneVerified: true,
+	}
+
+	payload := PayloadSendTransferNotification{
+		Username:              user.Username,
+		Event:                 util.NotificationTransferSent,
+		TransferID:            7,
+		AccountID:             1,
+		CounterpartyAccountID: 2,
+		Amount:                500,
+		Currency:              util.USD,
+		Balance:               40,
+	}
+
+	testCases := []struct {
+		name     string
+		user     func(user db.User) db.User
+		prefs    []db.NotificationPreference
+		sent     []db.TransferNotification
+		subjects []string
+		sms      []string
+	}{
+		{
+			name:     "ReceiptOnly",
+			subjects: []string{"Transfer receipt #7"},
+		},
+		{
+			name: "ReceiptAndAlerts",
+			prefs: []db.NotificationPreference{
+				{EventType: util.NotificationLowBalance, Channel: util.ChannelEmail, Currency: util.USD, Enabled: true, Threshold: 100},
+				{EventType: util.NotificationLargeTransaction, Channel: util.ChannelEmail, Currency: util.USD, Enabled: true, Threshold: 500},
+			},
+			subjects: []string{"Transfer receipt #7", "Low balance on account #1", "Large transaction on account #1"},
+		},
+		{
+			name: "ThresholdsPerCurrency",
+			prefs: []db.NotificationPreference{
+				{EventType: util.NotificationLowBalance, Channel: util.ChannelEmail, Currency: util.EUR, Enabled: true, Threshold: 100},
+				{EventType: util.NotificationLowBalance, Channel: util.ChannelEmail, Currency: util.USD, Enabled: true, Threshold: 50},
+				{EventType: util.NotificationLargeTransaction, Channel: util.ChannelEmail, Currency: util.EUR, Enabled: true, Threshold: 100},
+				{EventType: util.NotificationLargeTransaction, Channel: util.ChannelEmail, Currency: util.USD, Enabled: true, Threshold: 1000},
+			},
+			subjects: []string{"Transfer receipt #7", "Low balance on account #1"},
+		},
+		{
+			name: "RetrySkipsSent",
+			prefs: []db.NotificationPreference{
+				{EventType: util.NotificationLowBalance, Channel: util.ChannelEmail, Currency: util.USD, Enabled: true, Threshold: 100},
+				{EventType: util.NotificationLargeTransaction, Channel: util.ChannelEmail, Currency: util.USD, Enabled: true, Threshold: 500},
+			},
+			sent: []db.TransferNotification{
+				{TransferID: 7, Username: "alice", Template: templates.TransferSent, Channel: util.ChannelEmail},
+				{TransferID: 7, Username: "alice", Template: templates.LowBalance, Channel: util.ChannelEmail},
+			},
+			subjects: []string{"Large transaction on account #1"},
+		},
+		{
+			name: "OptedOut",
+			prefs: []db.NotificationPreference{
+				{EventType: util.NotificationTransferSent, Channel: util.ChannelEmail, Enabled: false},
+				{EventType: util.NotificationLowBalance, Channel: util.ChannelEmail, Currency: util.USD, Enabled: true, Threshold: 10},
+			},
+		},
+		{
+			name: "SMSAlert",
+			prefs: []db.NotificationPreference{
+				{EventType: util.NotificationLowBalance, Channel: util.ChannelSMS, Currency: util.USD, Enabled: true, Threshold: 100},
+			},
+			subjects: []string{"Transfer receipt #7"},
+			sms:      []string{"Low balance on account #1"},
+		},
+		{
+			name: "SMSToUnverifiedPhone",
+			user: func(user db.User) db.User {
+				user.IsPhoneVerified = false
+				return user
+			},
+			prefs: []db.NotificationPreference{
+				{EventType: util.NotificationTransferSent, Channel: util.ChannelSMS, Enabled: true},
+			},
+			subjects: []string{"Transfer receipt #7"},
+		},
+	}
+
+	renderer, err := templates.New("Banking System", "")
+	require.NoError(t, err)
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			user := user
+			if tc.user != nil {
+				user = tc.user(user)
+			}
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(user, nil)
+			store.EXPECT().
+				ListNotificationPreferences(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(tc.prefs, nil)
+			store.EXPECT().
+				ListSentTransferNotifications(gomock.Any(), gomock.Eq(db.ListSentTransferNotificationsParams{
+					TransferID: payload.TransferID,
+					Username:   user.Username,
+				})).
+				Times(1).
+				Return(tc.sent, nil)
+
+			var recorded []string
+			store.EXPECT().
+				CreateTransferNotification(gomock.Any(), gomock.Any()).
+				AnyTimes().
+				DoAndReturn(func(_ context.Context, arg db.CreateTransferNotificationParams) error {
+					require.Equal(t, payload.TransferID, arg.TransferID)
+					recorded = append(recorded, arg.Channel)
+					return nil
+				})
+
+			mailer := mail.NewMemorySender()
+			sms := notify.NewMemoryNotifier(util.ChannelSMS)
+			notifiers := notify.Notifiers{
+				util.ChannelEmail: notify.NewEmailNotifier(mailer),
+				util.ChannelSMS:   sms,
+			}
+			processor := &RedisTaskProcessor{store: store, notifiers: notifiers, templates: renderer}
+
+			data, err := json.Marshal(payload)
+			require.NoError(t, err)
+
+			err = processor.ProcessTaskSendTransferNotification(context.Background(), asynq.NewTask(TaskSendTransferNotification, data))
+			require.NoError(t, err)
+
+			var subjects []string
+			for _, msg := range mailer.SentTo(user.Email) {
+				subjects = append(subjects, msg.Subject)
+				require.NotEmpty(t, msg.Content.Text)
+			}
+			require.Equal(t, tc.subjects, subjects)
+
+			var texts []string
+			for _, n := range sms.Notifications() {
+				require.Equal(t, user.Phone, n.To.Phone)
+				texts = append(texts, n.Message.ShortText())
+			}
+			require.Equal(t, tc.sms, texts)
+			require.Len(t, recorded, len(subjects)+len(texts))
+		})
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"net/url"
 
 	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/logging"
+	"github.com/LamThanhNguyen/banking-system/mail/templates"
 	"github.com/LamThanhNguyen/banking-system/util"
 	"github.com/hibiken/asynq"
 	"github.com/rs/zerolog/log"
@@ -23,20 +25,23 @@ func (distributor *RedisTaskDistributor) DistributeTaskSendVerifyEmail(
 	payload *PayloadSendVerifyEmail,
 	opts ...asynq.Option,
 ) error {
-	jsonPayload, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Errorf("failed to marshal task payload: %w", err)
-	}
+	return distributor.enqueue(ctx, TaskSendVerifyEmail, payload, opts...)
+}
 
-	task := asynq.NewTask(TaskSendVerifyEmail, jsonPayload, opts...)
-	info, err := distributor.client.EnqueueContext(ctx, task)
-	if err != nil {
-		return fmt.Errorf("failed to enqueue task: %w", err)
-	}
+func (distributor *OutboxTaskDistributor) DistributeTaskSendVerifyEmail(
+	ctx context.Context,
+	payload *PayloadSendVerifyEmail,
+	opts ...asynq.Option,
+) error {
+	return distributor.enqueue(ctx, TaskSendVerifyEmail, payload, opts...)
+}
 
-	log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
-		Str("queue", info.Queue).Int("max_retry", info.MaxRetry).Msg("enqueued task")
-	return nil
+func (distributor *MemoryTaskDistributor) DistributeTaskSendVerifyEmail(
+	ctx context.Context,
+	payload *PayloadSendVerifyEmail,
+	opts ...asynq.Option,
+) error {
+	return distributor.enqueue(ctx, TaskSendVerifyEmail, payload, opts...)
 }
 
 func (processor *RedisTaskProcessor) ProcessTaskSendVerifyEmail(ctx context.Context, task *asynq.Task) error {
@@ -50,6 +55,11 @@ func (processor *RedisTaskProcessor) ProcessTaskSendVerifyEmail(ctx context.Cont
 		return fmt.Errorf("failed to get user: %w", err)
 	}
 
+	if user.IsEmailVerified {
+		log.Ctx(ctx).Info().Str("username", user.Username).Msg("email already verified, skipped task")
+		return nil
+	}
+
 	verifyEmail, err := processor.store.CreateVerifyEmail(ctx, db.CreateVerifyEmailParams{
 		Username:   user.Username,
 		Email:      user.Email,
@@ -59,23 +69,20 @@ func (processor *RedisTaskProcessor) ProcessTaskSendVerifyEmail(ctx context.Cont
 		return fmt.Errorf("failed to create verify email: %w", err)
 	}
 
-	subject := "Welcome to Simple Bank"
 	safely_code := url.QueryEscape(verifyEmail.SecretCode)
 	// TODO: replace this URL with an environment variable that points to a front-end page
 	verifyUrl := fmt.Sprintf("%s/api/v1/users/verify-email?email_id=%d&secret_code=%s",
 		processor.config.FrontendDomain, verifyEmail.ID, safely_code)
-	content := fmt.Sprintf(`Hello %s,<br/>
-	Thank you for registering with us!<br/>
-	Please <a href="%s">click here</a> to verify your email address.<br/>
-	`, user.FullName, verifyUrl)
-	to := []string{user.Email}
 
-	err = processor.mailer.SendEmail(subject, content, to, nil, nil, nil)
+	_, err = processor.sendTemplated(ctx, user, util.ChannelEmail, templates.VerifyEmail, templates.VerifyEmailData{
+		FullName:  user.FullName,
+		VerifyURL: verifyUrl,
+	})
 	if err != nil {
-		return fmt.Errorf("failed to send verify email: %w", err)
+		return err
 	}
 
-	log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
+	log.Ctx(ctx).Info().RawJSON("payload", logging.RedactJSON(task.Payload())).
 		Str("email", user.Email).Msg("processed task")
 	return nil
 }
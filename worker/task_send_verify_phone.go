@@ -0,0 +1,114 @@
+package worker
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/notify"
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskSendVerifyPhone = "task:send_verify_phone"
+
+// VerifyPhoneCodeLength is the number of digits of a phone verification code.
+const VerifyPhoneCodeLength = 6
+
+type PayloadSendVerifyPhone struct {
+	Username string `json:"username"`
+}
+
+func (distributor *RedisTaskDistributor) DistributeTaskSendVerifyPhone(
+	ctx context.Context,
+	payload *PayloadSendVerifyPhone,
+	opts ...asynq.Option,
+) error {
+	return distributor.enqueue(ctx, TaskSendVerifyPhone, payload, opts...)
+}
+
+func (distributor *OutboxTaskDistributor) DistributeTaskSendVerifyPhone(
+	ctx context.Context,
+	payload *PayloadSendVerifyPhone,
+	opts ...asynq.Option,
+) error {
+	return distributor.enqueue(ctx, TaskSendVerifyPhone, payload, opts...)
+}
+
+func (distributor *MemoryTaskDistributor) DistributeTaskSendVerifyPhone(
+	ctx context.Context,
+	payload *PayloadSendVerifyPhone,
+	opts ...asynq.Option,
+) error {
+	return distributor.enqueue(ctx, TaskSendVerifyPhone, payload, opts...)
+}
+
+// ProcessTaskSendVerifyPhone sends a one-time code by SMS to the unverified phone
+// number of the user. Only a hash of the code is stored.
+func (processor *RedisTaskProcessor) ProcessTaskSendVerifyPhone(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadSendVerifyPhone
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	user, err := processor.store.GetUser(ctx, payload.Username)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if user.Phone == "" || user.IsPhoneVerified {
+		log.Ctx(ctx).Info().Str("username", user.Username).Msg("no phone to verify, skipped task")
+		return nil
+	}
+
+	notifier, ok := processor.notifiers[util.ChannelSMS]
+	if !ok {
+		return fmt.Errorf("no sms notifier configured: %w", asynq.SkipRetry)
+	}
+
+	code, err := newVerifyPhoneCode()
+	if err != nil {
+		return err
+	}
+	hashedCode, err := util.HashPassword(code)
+	if err != nil {
+		return fmt.Errorf("failed to hash verification code: %w", err)
+	}
+
+	_, err = processor.store.CreateVerifyPhone(ctx, db.CreateVerifyPhoneParams{
+		Username:   user.Username,
+		Phone:      user.Phone,
+		HashedCode: hashedCode,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create verify phone: %w", err)
+	}
+
+	// The code goes to the number being verified, not to a verified one.
+	to := notify.Recipient{Username: user.Username, Phone: user.Phone}
+	msg := notify.Message{
+		Subject: "Phone verification",
+		Short: fmt.Sprintf("%s verification code: %s. It expires in 10 minutes.",
+			processor.config.EmailSenderName, code),
+	}
+	if err := notifier.Notify(ctx, to, msg); err != nil {
+		return fmt.Errorf("failed to send verification code: %w", err)
+	}
+
+	log.Ctx(ctx).Info().Str("username", user.Username).Msg("processed task")
+	return nil
+}
+
+// newVerifyPhoneCode returns a random code of VerifyPhoneCodeLength digits.
+func newVerifyPhoneCode() (string, error) {
+	max := big.NewInt(1_000_000)
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate verification code: %w", err)
+	}
+	return fmt.Sprintf("%0*d", VerifyPhoneCodeLength, n), nil
+}
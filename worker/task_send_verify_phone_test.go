@@ -0,0 +1,97 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	mockdb "github.com/LamThanhNguyen/banking-system/db/mock"
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/notify"
+	"github.com/LamThanhNguyen/banking-system/util"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestProcessTaskSendVerifyPhone(t *testing.T) {
+	user := db.User{
+		Username: "alice",
+		Phone:    "+84901234567",
+	}
+
+	testCases := []struct {
+		name       string
+		user       db.User
+		buildStubs func(store *mockdb.MockStore, hashedCode *string)
+		checkSent  func(t *testing.T, sent []notify.Notification, hashedCode string)
+	}{
+		{
+			name: "OK",
+			user: user,
+			buildStubs: func(store *mockdb.MockStore, hashedCode *string) {
+				store.EXPECT().
+					CreateVerifyPhone(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateVerifyPhoneParams) (db.VerifyPhone, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, user.Phone, arg.Phone)
+						*hashedCode = arg.HashedCode
+						return db.VerifyPhone{HashedCode: arg.HashedCode}, nil
+					})
+			},
+			checkSent: func(t *testing.T, sent []notify.Notification, hashedCode string) {
+				require.Len(t, sent, 1)
+				require.Equal(t, user.Phone, sent[0].To.Phone)
+
+				code := regexp.MustCompile(`\d{6}`).FindString(sent[0].Message.ShortText())
+				require.NotEmpty(t, code)
+				require.NoError(t, util.CheckPassword(code, hashedCode))
+			},
+		},
+		{
+			name: "AlreadyVerified",
+			user: db.User{Username: user.Username, Phone: user.Phone, IsPhoneVerified: true},
+			buildStubs: func(store *mockdb.MockStore, hashedCode *string) {
+				store.EXPECT().
+					CreateVerifyPhone(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkSent: func(t *testing.T, sent []notify.Notification, hashedCode string) {
+				require.Empty(t, sent)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var hashedCode string
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(tc.user, nil)
+			tc.buildStubs(store, &hashedCode)
+
+			sms := notify.NewMemoryNotifier(util.ChannelSMS)
+			processor := &RedisTaskProcessor{
+				store:     store,
+				notifiers: notify.Notifiers{util.ChannelSMS: sms},
+				config:    util.RuntimeConfig{Config: util.Config{EmailSenderName: "Banking System"}},
+			}
+
+			data, err := json.Marshal(PayloadSendVerifyPhone{Username: user.Username})
+			require.NoError(t, err)
+
+			err = processor.ProcessTaskSendVerifyPhone(context.Background(), asynq.NewTask(TaskSendVerifyPhone, data))
+			require.NoError(t, err)
+			tc.checkSent(t, sms.Notifications(), hashedCode)
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/LamThanhNguyen/banking-system/logging"
+	"github.com/hibiken/asynq"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/LamThanhNguyen/banking-system/worker"
+
+// taskHeaders returns the trace context and request ID of ctx as task
+// headers, so that the span and logs of the task continue those of the
+// request that enqueued it.
+func taskHeaders(ctx context.Context) map[string]string {
+	headers := make(map[string]string)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(headers))
+	if requestID := logging.RequestIDFromContext(ctx); requestID != "" {
+		headers[logging.RequestIDKey] = requestID
+	}
+	return headers
+}
+
+// decodeHeaders reads the task headers stored in the outbox.
+func decodeHeaders(data []byte) (map[string]string, error) {
+	var headers map[string]string
+	if len(data) == 0 {
+		return headers, nil
+	}
+	err := json.Unmarshal(data, &headers)
+	return headers, err
+}
+
+// traceTask runs every task in a span that continues the trace found in its headers.
+func traceTask(next asynq.Handler) asynq.Handler {
+	tracer := otel.Tracer(tracerName)
+
+	return asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(task.Headers()))
+
+		attrs := []attribute.KeyValue{
+			attribute.String("messaging.system", "asynq"),
+			attribute.String("messaging.operation.type", "process"),
+		}
+		if id, ok := asynq.GetTaskID(ctx); ok {
+			attrs = append(attrs, attribute.String("messaging.message.id", id))
+		}
+		if retried, ok := asynq.GetRetryCount(ctx); ok {
+			attrs = append(attrs, attribute.Int("asynq.retry_count", retried))
+		}
+
+		ctx, span := tracer.Start(ctx, task.Type(),
+			trace.WithSpanKind(trace.SpanKindConsumer),
+			trace.WithAttributes(attrs...),
+		)
+		defer span.End()
+
+		err := next.ProcessTask(ctx, task)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		return err
+	})
+}
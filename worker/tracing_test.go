@@ -0,0 +1,130 @@
+package worker
+
+import (
+	"context"
+	"testing"
+
+	mockdb "github.com/LamThanhNguyen/banking-system/db/mock"
+	db "github.com/LamThanhNguyen/banking-system/db/sqlc"
+	"github.com/LamThanhNguyen/banking-system/logging"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/mock/gomock"
+)
+
+// setupTestTracing records the spans of the test with the global tracer provider.
+func setupTestTracing(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+	return recorder
+}
+
+// requireTaskSpan checks that the only span recorded for taskType is a child
+// of parent, which stands for the request that enqueued the task.
+func requireTaskSpan(t *testing.T, recorder *tracetest.SpanRecorder, taskType string, parent trace.SpanContext) {
+	var found []sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == taskType {
+			found = append(found, span)
+		}
+	}
+	require.Len(t, found, 1)
+
+	span := found[0]
+	require.Equal(t, trace.SpanKindConsumer, span.SpanKind())
+	require.Equal(t, parent.TraceID(), span.SpanContext().TraceID())
+	require.Equal(t, parent.SpanID(), span.Parent().SpanID())
+	require.True(t, span.Parent().IsRemote())
+}
+
+func TestTraceMemoryTask(t *testing.T) {
+	recorder := setupTestTracing(t)
+
+	ctx, request := otel.Tracer("test").Start(context.Background(), "createUser")
+	queue := NewMemoryQueue()
+	distributor := NewMemoryTaskDistributor(queue)
+	require.NoError(t, distributor.DistributeTaskSendVerifyEmail(ctx, &PayloadSendVerifyEmail{Username: "alice"}))
+	request.End()
+
+	var taskCtx trace.SpanContext
+	handler := traceTask(asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		taskCtx = trace.SpanContextFromContext(ctx)
+		return nil
+	}))
+	require.Equal(t, 1, drain(queue, handler))
+
+	requireTaskSpan(t, recorder, TaskSendVerifyEmail, request.SpanContext())
+	require.Equal(t, request.SpanContext().TraceID(), taskCtx.TraceID())
+}
+
+func TestTraceOutboxTask(t *testing.T) {
+	recorder := setupTestTracing(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// The trace context is stored with the task in the outbox...
+	var stored db.Outbox
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		CreateOutbox(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateOutboxParams) (db.Outbox, error) {
+			stored = db.Outbox{
+				ID:        1,
+				TaskType:  arg.TaskType,
+				Payload:   arg.Payload,
+				Queue:     arg.Queue,
+				MaxRetry:  arg.MaxRetry,
+				ProcessAt: arg.ProcessAt,
+				Headers:   arg.Headers,
+			}
+			return stored, nil
+		})
+
+	ctx, request := otel.Tracer("test").Start(logging.WithRequestID(context.Background(), "req-42"), "createUser")
+	distributor := NewOutboxTaskDistributor(store)
+	require.NoError(t, distributor.DistributeTaskSendVerifyEmail(ctx, &PayloadSendVerifyEmail{Username: "alice"}))
+	request.End()
+	require.Contains(t, string(stored.Headers), "traceparent")
+	require.Contains(t, string(stored.Headers), logging.RequestIDKey)
+
+	// ...and published with the task by the relay, which runs outside the request.
+	queue := NewMemoryQueue()
+	relay := NewOutboxRelay(queue, store, 0)
+	require.NoError(t, relay.publish(context.Background(), stored))
+
+	handler := traceTask(asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		return nil
+	}))
+	require.Equal(t, 1, drain(queue, handler))
+
+	requireTaskSpan(t, recorder, TaskSendVerifyEmail, request.SpanContext())
+}
+
+func TestTraceTaskWithoutHeaders(t *testing.T) {
+	recorder := setupTestTracing(t)
+
+	handler := traceTask(asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		return nil
+	}))
+	require.NoError(t, handler.ProcessTask(context.Background(), asynq.NewTask(TaskPruneSessions, nil)))
+
+	// Tasks enqueued outside a request, such as maintenance jobs, start their own trace.
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	require.False(t, spans[0].Parent().IsValid())
+}
@@ -0,0 +1,49 @@
+package worker
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+
+	"github.com/LamThanhNguyen/banking-system/val"
+)
+
+// ErrWebhookAddressNotAllowed is returned when a webhook URL resolves to an
+// address that is not public.
+var ErrWebhookAddressNotAllowed = errors.New("webhook address is not allowed")
+
+// newWebhookClient returns the client that delivers webhooks. Subscribers
+// choose the URL, so the client only connects to public addresses. The check
+// runs on the address being dialed, after DNS resolution, so a name that
+// resolves to a private address later is rejected too. Redirects are not
+// followed and proxies are not used, since both would connect elsewhere.
+func newWebhookClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: webhookTimeout,
+		Control: func(network string, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			addr, err := netip.ParseAddr(host)
+			if err != nil || !val.IsPublicAddr(addr) {
+				return fmt.Errorf("%w: %s", ErrWebhookAddressNotAllowed, host)
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}